@@ -0,0 +1,166 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"log"
+	"os"
+
+	"github.com/souravsspace/texly.chat/configs"
+	"github.com/souravsspace/texly.chat/internal/db"
+	"github.com/souravsspace/texly.chat/internal/models"
+	botRepoPkg "github.com/souravsspace/texly.chat/internal/repo/bot"
+	goldenRepoPkg "github.com/souravsspace/texly.chat/internal/repo/golden"
+	messageRepoPkg "github.com/souravsspace/texly.chat/internal/repo/message"
+	userRepoPkg "github.com/souravsspace/texly.chat/internal/repo/user"
+	vectorRepoPkg "github.com/souravsspace/texly.chat/internal/repo/vector"
+	"github.com/souravsspace/texly.chat/internal/server"
+	"github.com/souravsspace/texly.chat/internal/services/chat"
+	"github.com/souravsspace/texly.chat/internal/services/embedding"
+	"github.com/souravsspace/texly.chat/internal/services/eval"
+	"github.com/souravsspace/texly.chat/internal/services/llm"
+	"github.com/souravsspace/texly.chat/internal/services/vector"
+	"gorm.io/gorm"
+)
+
+/*
+* main runs a bot against a golden set and prints recall@k, MRR and answer correctness
+*
+* Usage:
+*   go run ./cmd/eval -bot <bot-id> -set <golden-set-id> [-k 5] [-format json|markdown] [-out report.md]
+*   go run ./cmd/eval -bot <bot-id> -file golden.json
+ */
+func main() {
+	botID := flag.String("bot", "", "ID of the bot to evaluate (required)")
+	setID := flag.String("set", "", "ID of a stored golden set")
+	file := flag.String("file", "", "Path to a golden set JSON file (same shape as the create golden set request)")
+	k := flag.Int("k", eval.DefaultK, "Chunks retrieved per question")
+	format := flag.String("format", "json", "Report format: json or markdown")
+	out := flag.String("out", "", "Write the report to this file instead of stdout")
+	graderModel := flag.String("grader-model", "", "Model grading answers (default: QUERY_REWRITE_MODEL)")
+	noAnswers := flag.Bool("retrieval-only", false, "Skip answering and grading")
+	flag.Parse()
+
+	if *botID == "" || (*setID == "") == (*file == "") {
+		flag.Usage()
+		log.Fatal("-bot and exactly one of -set or -file are required")
+	}
+	if *format != "json" && *format != "markdown" {
+		log.Fatalf("unknown format %q", *format)
+	}
+
+	cfg := configs.Load()
+	if *graderModel == "" {
+		*graderModel = cfg.QueryRewriteModel
+	}
+
+	gormDb, err := db.Connect(cfg.DatabaseURL)
+	if err != nil {
+		log.Fatalf("failed to connect db: %v", err)
+	}
+
+	botRepo := botRepoPkg.NewBotRepo(gormDb, nil)
+	bot, err := botRepo.GetByIDPublic(*botID)
+	if err != nil {
+		log.Fatalf("failed to load bot: %v", err)
+	}
+	if bot == nil {
+		log.Fatalf("bot %s not found", *botID)
+	}
+
+	set, err := loadGoldenSet(gormDb, bot.ID, *setID, *file)
+	if err != nil {
+		log.Fatalf("failed to load golden set: %v", err)
+	}
+
+	embeddingService := embedding.NewEmbeddingService(cfg.OpenAIAPIKey, cfg.EmbeddingModel, cfg.EmbeddingDimension)
+	vectorRepo := vectorRepoPkg.NewVectorRepository(gormDb)
+	vectorRepo.SetIndexOptions(server.VectorIndexOptions(cfg))
+	searchService := vector.NewSearchService(gormDb, vectorRepo, embeddingService)
+
+	// Retrieval is scored through the chat service too, so reranking and the distance threshold apply
+	chatService := chat.NewChatService(
+		embeddingService,
+		searchService,
+		messageRepoPkg.New(gormDb),
+		cfg.ChatModel,
+		cfg.ChatTemperature,
+		cfg.MaxContextChunks,
+		cfg.OpenAIAPIKey,
+		cfg.HistoryTokenBudget,
+	)
+	server.RegisterChatProviders(cfg, chatService)
+	chatService.SetQueryRewriteModel(cfg.QueryRewriteModel)
+
+	answerService := chatService
+	var grader llm.ChatProvider
+	if *noAnswers {
+		answerService = nil
+	} else {
+		grader = llm.NewOpenAIProvider(cfg.OpenAIAPIKey, *graderModel)
+	}
+
+	// Answer with the owner's plan limits, as the widget would
+	limits := configs.GetTierLimits(configs.TierFree)
+	if owner, err := userRepoPkg.NewUserRepo(gormDb, nil).GetByID(bot.UserID); err == nil && owner != nil {
+		limits = configs.GetTierLimits(owner.Tier)
+	}
+	settings := chatService.ResolveSettings(bot, limits)
+
+	evalService := eval.NewEvalService(chatService, answerService, grader, *graderModel)
+	report, err := evalService.Run(context.Background(), bot, settings, set, *k)
+	if err != nil {
+		log.Fatalf("evaluation failed: %v", err)
+	}
+
+	var output []byte
+	if *format == "markdown" {
+		output = []byte(eval.FormatMarkdown(report))
+	} else {
+		output, err = json.MarshalIndent(report, "", "  ")
+		if err != nil {
+			log.Fatalf("failed to encode report: %v", err)
+		}
+		output = append(output, '\n')
+	}
+
+	if *out == "" {
+		fmt.Print(string(output))
+		return
+	}
+	if err := os.WriteFile(*out, output, 0o644); err != nil {
+		log.Fatalf("failed to write report: %v", err)
+	}
+	log.Printf("Report written to %s\n", *out)
+}
+
+/*
+* loadGoldenSet reads the golden set from the database or from a JSON file
+ */
+func loadGoldenSet(gormDb *gorm.DB, botID string, setID string, file string) (*models.GoldenSet, error) {
+	if file != "" {
+		data, err := os.ReadFile(file)
+		if err != nil {
+			return nil, err
+		}
+		var req models.CreateGoldenSetRequest
+		if err := json.Unmarshal(data, &req); err != nil {
+			return nil, fmt.Errorf("invalid golden set file: %w", err)
+		}
+		if req.Name == "" {
+			req.Name = file
+		}
+		return models.NewGoldenSet(botID, req), nil
+	}
+
+	set, err := goldenRepoPkg.New(gormDb).GetByID(context.Background(), setID, botID)
+	if err != nil {
+		return nil, err
+	}
+	if set == nil {
+		return nil, fmt.Errorf("golden set %s not found for bot %s", setID, botID)
+	}
+	return set, nil
+}
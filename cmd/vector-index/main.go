@@ -0,0 +1,81 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"strings"
+
+	"github.com/souravsspace/texly.chat/configs"
+	"github.com/souravsspace/texly.chat/internal/db"
+	vectorRepoPkg "github.com/souravsspace/texly.chat/internal/repo/vector"
+	"github.com/souravsspace/texly.chat/internal/server"
+)
+
+/*
+* main manages the approximate nearest neighbour index on chunk embeddings
+*
+* Usage:
+*   go run ./cmd/vector-index status            Show the index, its size and whether it needs rebuilding
+*   go run ./cmd/vector-index create            Create the configured index if it is missing
+*   go run ./cmd/vector-index rebuild           Rebuild the configured index (e.g. after a large import or a config change)
+*   go run ./cmd/vector-index drop              Drop the index and fall back to exact search
+*
+* The index type and parameters come from VECTOR_INDEX_TYPE, HNSW_* and IVFFLAT_*; -type overrides the type
+ */
+func main() {
+	indexType := flag.String("type", "", "Index type: hnsw or ivfflat (default: VECTOR_INDEX_TYPE)")
+	flag.Parse()
+
+	command := flag.Arg(0)
+	if command == "" {
+		command = "status"
+	}
+
+	cfg := configs.Load()
+	if *indexType != "" {
+		cfg.VectorIndexType = *indexType
+	}
+
+	gormDb, err := db.Connect(cfg.DatabaseURL)
+	if err != nil {
+		log.Fatalf("failed to connect db: %v", err)
+	}
+
+	vectorRepo := vectorRepoPkg.NewVectorRepository(gormDb)
+	vectorRepo.SetIndexOptions(server.VectorIndexOptions(cfg))
+
+	ctx := context.Background()
+	switch strings.ToLower(command) {
+	case "status":
+	case "create":
+		if server.VectorIndexOptions(cfg).Type == vectorRepoPkg.IndexTypeNone {
+			log.Fatal("VECTOR_INDEX_TYPE is none; set it or pass -type hnsw|ivfflat")
+		}
+		err = vectorRepo.Initialize(ctx, cfg.EmbeddingDimension)
+	case "rebuild":
+		err = vectorRepo.RebuildIndex(ctx)
+	case "drop":
+		err = vectorRepo.DropIndex(ctx)
+	default:
+		flag.Usage()
+		log.Fatalf("unknown command %q (expected status, create, rebuild or drop)", command)
+	}
+	if err != nil {
+		log.Fatalf("%s failed: %v", command, err)
+	}
+
+	status, err := vectorRepo.IndexStatus(ctx)
+	if err != nil {
+		log.Fatalf("failed to read index status: %v", err)
+	}
+
+	encoded, err := json.MarshalIndent(status, "", "  ")
+	if err != nil {
+		log.Fatalf("failed to encode index status: %v", err)
+	}
+	fmt.Fprintln(os.Stdout, string(encoded))
+}
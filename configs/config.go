@@ -23,6 +23,34 @@ type Config struct {
 	ChatModel            string
 	ChatTemperature      float64
 	MaxContextChunks     int
+	HistoryTokenBudget   int
+	QueryRewriteModel    string
+	// Semantic answer cache: minimum cosine similarity to replay a cached answer (0 = disabled)
+	SemanticCacheThreshold float64
+	// Let bot actions call loopback and private network addresses (self-hosted internal APIs)
+	ActionsAllowPrivateNetworks bool
+	// Approximate nearest neighbour index on chunk embeddings
+	VectorIndexType      string // "none" | "hnsw" | "ivfflat"
+	VectorIndexOnStartup bool   // Create the index at startup if it is missing (rebuilds are left to cmd/vector-index)
+	HNSWM                int
+	HNSWEfConstruction   int
+	HNSWEfSearch         int
+	IVFFlatLists         int // 0 = sized from the number of embedded chunks
+	IVFFlatProbes        int
+	VectorIterativeScan  bool // Keep scanning the index until filtered searches fill their page (pgvector 0.8+)
+	// Additional Chat Providers (selectable per bot)
+	OpenAICompatibleBaseURL string
+	OpenAICompatibleAPIKey  string
+	OpenAICompatibleModel   string
+	// Older OpenAI-compatible servers only accept max_tokens and reject stream_options
+	OpenAICompatibleLegacyMaxTokens bool
+	OpenAICompatibleStreamUsage     bool
+	AzureOpenAIBaseURL              string
+	AzureOpenAIAPIKey               string
+	AzureOpenAIModel                string
+	AnthropicBaseURL                string
+	AnthropicAPIKey                 string
+	AnthropicModel                  string
 	// MinIO Configuration
 	MinIOEndpoint   string
 	MinIOAccessKey  string
@@ -60,36 +88,59 @@ func Load() Config {
 	}
 
 	return Config{
-		DatabaseURL:           getEnv("DATABASE_URL", true),
-		DatabaseMaxConns:      getEnvAsInt("DATABASE_MAX_CONNS", 25),
-		DatabaseMaxIdleConns:  getEnvAsInt("DATABASE_MAX_IDLE_CONNS", 5),
-		Port:                  getEnv("PORT", false, "8080"),
-		JWTSecret:             getEnv("JWT_SECRET", true),
-		OpenAIAPIKey:          getEnv("OPENAI_API_KEY", true),
-		EmbeddingModel:        getEnv("EMBEDDING_MODEL", false, "text-embedding-3-small"),
-		EmbeddingDimension:    getEnvAsInt("EMBEDDING_DIMENSION", 1536),
-		ChatModel:             getEnv("OPENAI_CHAT_MODEL", false, "gpt-4o-mini"),
-		ChatTemperature:       getEnvAsFloat("CHAT_TEMPERATURE", 0.7),
-		MaxContextChunks:      getEnvAsInt("MAX_CONTEXT_CHUNKS", 5),
-		MinIOEndpoint:         getEnv("MINIO_ENDPOINT", true),
-		MinIOAccessKey:        getEnv("MINIO_ACCESS_KEY", true),
-		MinIOSecretKey:        getEnv("MINIO_SECRET_KEY", true),
-		MinIOBucket:           getEnv("MINIO_BUCKET", false, "texly-uploads"),
-		MinIOUseSSL:           getEnvAsBool("MINIO_USE_SSL", false),
-		MaxUploadSizeMB:       getEnvAsInt("MAX_UPLOAD_SIZE_MB", 100),
-		RedisURL:              getEnv("REDIS_URL", true),
-		RedisMaxConns:         getEnvAsInt("REDIS_MAX_CONNS", 50),
-		RedisMinIdleConns:     getEnvAsInt("REDIS_MIN_IDLE_CONNS", 10),
-		GoogleClientID:        getEnv("GOOGLE_CLIENT_ID", false),
-		GoogleClientSecret:    getEnv("GOOGLE_CLIENT_SECRET", false),
-		GoogleRedirectURL:     getEnv("GOOGLE_REDIRECT_URL", false),
-		FrontendURL:           getEnv("FRONTEND_URL", false, "http://localhost:5173"),
-		PolarAccessToken:      getEnv("POLAR_ACCESS_TOKEN", false),
-		PolarWebhookSecret:    getEnv("POLAR_WEBHOOK_SECRET", false),
-		PolarOrganizationID:   getEnv("POLAR_ORGANIZATION_ID", false),
-		PolarProProductID:     getEnv("POLAR_PRO_PRODUCT_ID", false),
-		PolarCreditsProductID: getEnv("POLAR_CREDITS_PRODUCT_ID", false),
-		PolarServerURL:        getEnv("POLAR_SERVER_URL", false, "https://sandbox-api.polar.sh"),
+		DatabaseURL:                     getEnv("DATABASE_URL", true),
+		DatabaseMaxConns:                getEnvAsInt("DATABASE_MAX_CONNS", 25),
+		DatabaseMaxIdleConns:            getEnvAsInt("DATABASE_MAX_IDLE_CONNS", 5),
+		Port:                            getEnv("PORT", false, "8080"),
+		JWTSecret:                       getEnv("JWT_SECRET", true),
+		OpenAIAPIKey:                    getEnv("OPENAI_API_KEY", true),
+		EmbeddingModel:                  getEnv("EMBEDDING_MODEL", false, "text-embedding-3-small"),
+		EmbeddingDimension:              getEnvAsInt("EMBEDDING_DIMENSION", 1536),
+		ChatModel:                       getEnv("OPENAI_CHAT_MODEL", false, "gpt-4o-mini"),
+		ChatTemperature:                 getEnvAsFloat("CHAT_TEMPERATURE", 0.7),
+		MaxContextChunks:                getEnvAsInt("MAX_CONTEXT_CHUNKS", 5),
+		HistoryTokenBudget:              getEnvAsInt("CHAT_HISTORY_TOKEN_BUDGET", 2000),
+		QueryRewriteModel:               getEnv("QUERY_REWRITE_MODEL", false, "gpt-4o-mini"),
+		SemanticCacheThreshold:          getEnvAsFloat("SEMANTIC_CACHE_THRESHOLD", 0.95),
+		ActionsAllowPrivateNetworks:     getEnvAsBool("ACTIONS_ALLOW_PRIVATE_NETWORKS", false),
+		VectorIndexType:                 getEnv("VECTOR_INDEX_TYPE", false, "none"),
+		VectorIndexOnStartup:            getEnvAsBool("VECTOR_INDEX_ON_STARTUP", false),
+		HNSWM:                           getEnvAsInt("HNSW_M", 16),
+		HNSWEfConstruction:              getEnvAsInt("HNSW_EF_CONSTRUCTION", 64),
+		HNSWEfSearch:                    getEnvAsInt("HNSW_EF_SEARCH", 40),
+		IVFFlatLists:                    getEnvAsInt("IVFFLAT_LISTS", 0),
+		IVFFlatProbes:                   getEnvAsInt("IVFFLAT_PROBES", 10),
+		VectorIterativeScan:             getEnvAsBool("VECTOR_ITERATIVE_SCAN", false),
+		OpenAICompatibleBaseURL:         getEnv("OPENAI_COMPATIBLE_BASE_URL", false),
+		OpenAICompatibleAPIKey:          getEnv("OPENAI_COMPATIBLE_API_KEY", false),
+		OpenAICompatibleModel:           getEnv("OPENAI_COMPATIBLE_MODEL", false),
+		OpenAICompatibleLegacyMaxTokens: getEnvAsBool("OPENAI_COMPATIBLE_LEGACY_MAX_TOKENS", true),
+		OpenAICompatibleStreamUsage:     getEnvAsBool("OPENAI_COMPATIBLE_STREAM_USAGE", false),
+		AzureOpenAIBaseURL:              getEnv("AZURE_OPENAI_BASE_URL", false),
+		AzureOpenAIAPIKey:               getEnv("AZURE_OPENAI_API_KEY", false),
+		AzureOpenAIModel:                getEnv("AZURE_OPENAI_MODEL", false),
+		AnthropicBaseURL:                getEnv("ANTHROPIC_BASE_URL", false, "https://api.anthropic.com/v1/"),
+		AnthropicAPIKey:                 getEnv("ANTHROPIC_API_KEY", false),
+		AnthropicModel:                  getEnv("ANTHROPIC_MODEL", false, "claude-sonnet-4-5"),
+		MinIOEndpoint:                   getEnv("MINIO_ENDPOINT", true),
+		MinIOAccessKey:                  getEnv("MINIO_ACCESS_KEY", true),
+		MinIOSecretKey:                  getEnv("MINIO_SECRET_KEY", true),
+		MinIOBucket:                     getEnv("MINIO_BUCKET", false, "texly-uploads"),
+		MinIOUseSSL:                     getEnvAsBool("MINIO_USE_SSL", false),
+		MaxUploadSizeMB:                 getEnvAsInt("MAX_UPLOAD_SIZE_MB", 100),
+		RedisURL:                        getEnv("REDIS_URL", true),
+		RedisMaxConns:                   getEnvAsInt("REDIS_MAX_CONNS", 50),
+		RedisMinIdleConns:               getEnvAsInt("REDIS_MIN_IDLE_CONNS", 10),
+		GoogleClientID:                  getEnv("GOOGLE_CLIENT_ID", false),
+		GoogleClientSecret:              getEnv("GOOGLE_CLIENT_SECRET", false),
+		GoogleRedirectURL:               getEnv("GOOGLE_REDIRECT_URL", false),
+		FrontendURL:                     getEnv("FRONTEND_URL", false, "http://localhost:5173"),
+		PolarAccessToken:                getEnv("POLAR_ACCESS_TOKEN", false),
+		PolarWebhookSecret:              getEnv("POLAR_WEBHOOK_SECRET", false),
+		PolarOrganizationID:             getEnv("POLAR_ORGANIZATION_ID", false),
+		PolarProProductID:               getEnv("POLAR_PRO_PRODUCT_ID", false),
+		PolarCreditsProductID:           getEnv("POLAR_CREDITS_PRODUCT_ID", false),
+		PolarServerURL:                  getEnv("POLAR_SERVER_URL", false, "https://sandbox-api.polar.sh"),
 	}
 }
 
@@ -1,5 +1,7 @@
 package configs
 
+import "strings"
+
 /*
  * Pricing Configuration
  *
@@ -58,6 +60,38 @@ const (
 	CostPerExtraBotMonthly = 1.50
 )
 
+// ---------------------------------------------------------------------------
+// Chat Model Token Pricing (70% profit margin)
+// ---------------------------------------------------------------------------
+
+// ModelPricing is the per-1K-token pricing of a chat model in USD.
+type ModelPricing struct {
+	PricePerInput1KTokens  float64 // charge per 1K prompt tokens
+	PricePerOutput1KTokens float64 // charge per 1K completion tokens
+	CostPerInput1KTokens   float64 // actual provider cost per 1K prompt tokens
+	CostPerOutput1KTokens  float64 // actual provider cost per 1K completion tokens
+}
+
+// ChatModelPricing maps chat models to their token pricing.
+// Models are matched by longest prefix, so dated snapshots share their family's price.
+var ChatModelPricing = map[string]ModelPricing{
+	"gpt-4o-mini":       {PricePerInput1KTokens: 0.0005, PricePerOutput1KTokens: 0.002, CostPerInput1KTokens: 0.00015, CostPerOutput1KTokens: 0.0006},
+	"gpt-4o":            {PricePerInput1KTokens: 0.0083, PricePerOutput1KTokens: 0.0333, CostPerInput1KTokens: 0.0025, CostPerOutput1KTokens: 0.01},
+	"gpt-4.1-nano":      {PricePerInput1KTokens: 0.00033, PricePerOutput1KTokens: 0.0013, CostPerInput1KTokens: 0.0001, CostPerOutput1KTokens: 0.0004},
+	"gpt-4.1-mini":      {PricePerInput1KTokens: 0.0013, PricePerOutput1KTokens: 0.0053, CostPerInput1KTokens: 0.0004, CostPerOutput1KTokens: 0.0016},
+	"gpt-4.1":           {PricePerInput1KTokens: 0.0067, PricePerOutput1KTokens: 0.0267, CostPerInput1KTokens: 0.002, CostPerOutput1KTokens: 0.008},
+	"o4-mini":           {PricePerInput1KTokens: 0.0037, PricePerOutput1KTokens: 0.0147, CostPerInput1KTokens: 0.0011, CostPerOutput1KTokens: 0.0044},
+	"gpt-4-turbo":       {PricePerInput1KTokens: 0.0333, PricePerOutput1KTokens: 0.1, CostPerInput1KTokens: 0.01, CostPerOutput1KTokens: 0.03},
+	"gpt-4":             {PricePerInput1KTokens: 0.1, PricePerOutput1KTokens: 0.2, CostPerInput1KTokens: 0.03, CostPerOutput1KTokens: 0.06},
+	"gpt-3.5-turbo":     {PricePerInput1KTokens: 0.0017, PricePerOutput1KTokens: 0.005, CostPerInput1KTokens: 0.0005, CostPerOutput1KTokens: 0.0015},
+	"claude-haiku-4-5":  {PricePerInput1KTokens: 0.0033, PricePerOutput1KTokens: 0.0167, CostPerInput1KTokens: 0.001, CostPerOutput1KTokens: 0.005},
+	"claude-sonnet-4-5": {PricePerInput1KTokens: 0.01, PricePerOutput1KTokens: 0.05, CostPerInput1KTokens: 0.003, CostPerOutput1KTokens: 0.015},
+}
+
+// DefaultChatModelPricing applies to models without an entry (e.g. self-hosted or
+// third-party models), priced like gpt-4o so unknown models are never undercharged.
+var DefaultChatModelPricing = ChatModelPricing["gpt-4o"]
+
 // ---------------------------------------------------------------------------
 // Billing Rules
 // ---------------------------------------------------------------------------
@@ -88,6 +122,11 @@ type TierLimits struct {
 	MaxOriginsPerBot int     // -1 = unlimited
 	IncludedCredits  float64 // monthly credit allocation in USD
 	IncludedBots     int     // bots included before extra-bot charges apply
+
+	// Per-bot generation settings
+	AllowedChatModels map[string][]string // chat models bots may select per provider; nil = any model on any provider
+	MaxOutputTokens   int                 // -1 = unlimited
+	MaxContextChunks  int                 // -1 = unlimited
 }
 
 const (
@@ -107,6 +146,14 @@ var Tiers = map[string]TierLimits{
 		MaxOriginsPerBot: 1,
 		IncludedCredits:  0,
 		IncludedBots:     1,
+
+		AllowedChatModels: map[string][]string{
+			"openai":    {"gpt-4o-mini", "gpt-4.1-mini"},
+			"azure":     {"gpt-4o-mini", "gpt-4.1-mini"},
+			"anthropic": {"claude-haiku-4-5"},
+		},
+		MaxOutputTokens:  1024,
+		MaxContextChunks: 5,
 	},
 	TierPro: {
 		Tier:             TierPro,
@@ -117,6 +164,14 @@ var Tiers = map[string]TierLimits{
 		MaxOriginsPerBot: 10,
 		IncludedCredits:  ProIncludedCredits,
 		IncludedBots:     5, // 5 included, $5/mo per additional
+
+		AllowedChatModels: map[string][]string{
+			"openai":    {"gpt-4o-mini", "gpt-4o", "gpt-4.1-mini", "gpt-4.1", "o4-mini"},
+			"azure":     {"gpt-4o-mini", "gpt-4o", "gpt-4.1-mini", "gpt-4.1", "o4-mini"},
+			"anthropic": {"claude-haiku-4-5", "claude-sonnet-4-5"},
+		},
+		MaxOutputTokens:  4096,
+		MaxContextChunks: 15,
 	},
 	TierEnterprise: {
 		Tier:             TierEnterprise,
@@ -127,6 +182,10 @@ var Tiers = map[string]TierLimits{
 		MaxOriginsPerBot: -1,
 		IncludedCredits:  0, // custom billing
 		IncludedBots:     -1,
+
+		AllowedChatModels: nil, // any model
+		MaxOutputTokens:   -1,
+		MaxContextChunks:  -1,
 	},
 }
 
@@ -139,6 +198,24 @@ func CalculateMessageCost(count int) float64 {
 	return float64(count) * PricePerMessage
 }
 
+// GetChatModelPricing returns the token pricing for a chat model, matched by longest prefix.
+func GetChatModelPricing(model string) ModelPricing {
+	pricing, matched := DefaultChatModelPricing, ""
+	for prefix, p := range ChatModelPricing {
+		if strings.HasPrefix(model, prefix) && len(prefix) > len(matched) {
+			pricing, matched = p, prefix
+		}
+	}
+	return pricing
+}
+
+// CalculateChatTokenCost returns the total price for a chat completion's token usage.
+func CalculateChatTokenCost(model string, promptTokens, completionTokens int) float64 {
+	pricing := GetChatModelPricing(model)
+	return float64(promptTokens)/1000.0*pricing.PricePerInput1KTokens +
+		float64(completionTokens)/1000.0*pricing.PricePerOutput1KTokens
+}
+
 // CalculateEmbeddingCost returns the total price for a given token count.
 func CalculateEmbeddingCost(tokens int) float64 {
 	return float64(tokens) / 1000.0 * PricePerEmbedding1KTokens
@@ -165,6 +242,24 @@ func CalculateExtraBotCost(totalBots int, tier string) float64 {
 	return float64(extra) * PricePerExtraBotMonthly
 }
 
+// AllowsChatModel reports whether bots on this tier may use the given chat model on the given provider.
+// An empty provider is the default "openai" one and an empty model is the provider's own default,
+// which is always allowed; providers missing from the allow-list only offer their default model.
+func (t TierLimits) AllowsChatModel(provider, model string) bool {
+	if t.AllowedChatModels == nil || model == "" {
+		return true
+	}
+	if provider == "" {
+		provider = "openai"
+	}
+	for _, allowed := range t.AllowedChatModels[provider] {
+		if allowed == model {
+			return true
+		}
+	}
+	return false
+}
+
 // GetTierLimits returns the limits for a given tier name, defaulting to Free.
 func GetTierLimits(tier string) TierLimits {
 	if t, ok := Tiers[tier]; ok {
@@ -51,15 +51,45 @@ func Migrate(db *gorm.DB) error {
 	err := db.AutoMigrate(
 		&models.User{},
 		&models.Bot{},
+		&models.BotAction{},
+		&models.BotAPIKey{},
+		&models.PromptVersion{},
+		&models.PromptExperiment{},
+		&models.GoldenSet{},
+		&models.GoldenQuestion{},
 		&models.Source{},
 		&models.DocumentChunk{},
 		&models.Message{},
+		&models.MessageFeedback{},
+		&models.SessionSummary{},
 		&models.UsageRecord{},
 	)
 	if err != nil {
 		return fmt.Errorf("auto migrate failed: %w", err)
 	}
 
+	if err := MigrateFullTextSearch(db); err != nil {
+		return err
+	}
+
 	fmt.Println("✅ Database migrations completed successfully")
 	return nil
 }
+
+/*
+ * MigrateFullTextSearch adds the full-text search column and its GIN index to document_chunks
+ * The column is generated by PostgreSQL, so chunks are keyword searchable with or without an embedding
+ */
+func MigrateFullTextSearch(db *gorm.DB) error {
+	statements := []string{
+		`ALTER TABLE document_chunks ADD COLUMN IF NOT EXISTS search_vector tsvector
+			GENERATED ALWAYS AS (to_tsvector('english', coalesce(content, ''))) STORED`,
+		`CREATE INDEX IF NOT EXISTS idx_document_chunks_search_vector ON document_chunks USING GIN (search_vector)`,
+	}
+	for _, statement := range statements {
+		if err := db.Exec(statement).Error; err != nil {
+			return fmt.Errorf("full-text search migration failed: %w", err)
+		}
+	}
+	return nil
+}
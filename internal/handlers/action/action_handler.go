@@ -0,0 +1,253 @@
+package action
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/souravsspace/texly.chat/internal/models"
+	actionRepo "github.com/souravsspace/texly.chat/internal/repo/action"
+	botRepo "github.com/souravsspace/texly.chat/internal/repo/bot"
+	"github.com/souravsspace/texly.chat/internal/services/action"
+	"gorm.io/gorm"
+)
+
+/*
+ * ActionHandler handles HTTP requests for bot actions
+ */
+type ActionHandler struct {
+	actionRepo           *actionRepo.ActionRepository
+	botRepo              *botRepo.BotRepo
+	allowPrivateNetworks bool
+}
+
+/*
+ * NewActionHandler creates a new action handler instance
+ */
+func NewActionHandler(actionRepo *actionRepo.ActionRepository, botRepo *botRepo.BotRepo) *ActionHandler {
+	return &ActionHandler{
+		actionRepo: actionRepo,
+		botRepo:    botRepo,
+	}
+}
+
+/*
+ * SetAllowPrivateNetworks lets actions target loopback and private network addresses
+ */
+func (h *ActionHandler) SetAllowPrivateNetworks(allow bool) {
+	h.allowPrivateNetworks = allow
+}
+
+/*
+ * CreateAction handles POST /api/bots/:id/actions
+ */
+func (h *ActionHandler) CreateAction(c *gin.Context) {
+	botID, ok := h.authorizeBot(c)
+	if !ok {
+		return
+	}
+
+	var req models.CreateBotActionRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"message": err.Error()})
+		return
+	}
+
+	botAction := &models.BotAction{
+		BotID:          botID,
+		Name:           req.Name,
+		Description:    req.Description,
+		Method:         strings.ToUpper(req.Method),
+		URL:            req.URL,
+		TimeoutSeconds: req.TimeoutSeconds,
+	}
+	if botAction.Method == "" {
+		botAction.Method = http.MethodGet
+	}
+	if err := applyJSONFields(botAction, req.Parameters, req.Headers); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"message": err.Error()})
+		return
+	}
+	if err := action.Validate(botAction, h.allowPrivateNetworks); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"message": err.Error()})
+		return
+	}
+	if !h.uniqueName(c, botAction) {
+		return
+	}
+
+	if err := h.actionRepo.Create(c.Request.Context(), botAction); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"message": "Failed to create action"})
+		return
+	}
+
+	c.JSON(http.StatusCreated, botAction)
+}
+
+/*
+ * ListActions handles GET /api/bots/:id/actions
+ */
+func (h *ActionHandler) ListActions(c *gin.Context) {
+	botID, ok := h.authorizeBot(c)
+	if !ok {
+		return
+	}
+
+	actions, err := h.actionRepo.ListByBotID(c.Request.Context(), botID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"message": "Failed to fetch actions"})
+		return
+	}
+
+	c.JSON(http.StatusOK, actions)
+}
+
+/*
+ * UpdateAction handles PUT /api/bots/:id/actions/:actionId
+ */
+func (h *ActionHandler) UpdateAction(c *gin.Context) {
+	botID, ok := h.authorizeBot(c)
+	if !ok {
+		return
+	}
+
+	var req models.UpdateBotActionRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"message": err.Error()})
+		return
+	}
+
+	botAction, err := h.actionRepo.GetByID(c.Request.Context(), c.Param("actionId"), botID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"message": "Failed to fetch action"})
+		return
+	}
+	if botAction == nil {
+		c.JSON(http.StatusNotFound, gin.H{"message": "Action not found"})
+		return
+	}
+
+	// Update fields if provided
+	if req.Name != "" {
+		botAction.Name = req.Name
+	}
+	if req.Description != "" {
+		botAction.Description = req.Description
+	}
+	if req.Method != "" {
+		botAction.Method = strings.ToUpper(req.Method)
+	}
+	if req.URL != "" {
+		botAction.URL = req.URL
+	}
+	if req.TimeoutSeconds != 0 {
+		botAction.TimeoutSeconds = req.TimeoutSeconds
+	}
+	if err := applyJSONFields(botAction, req.Parameters, req.Headers); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"message": err.Error()})
+		return
+	}
+	if err := action.Validate(botAction, h.allowPrivateNetworks); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"message": err.Error()})
+		return
+	}
+	if !h.uniqueName(c, botAction) {
+		return
+	}
+
+	if err := h.actionRepo.Update(c.Request.Context(), botAction); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"message": "Failed to update action"})
+		return
+	}
+
+	c.JSON(http.StatusOK, botAction)
+}
+
+/*
+ * DeleteAction handles DELETE /api/bots/:id/actions/:actionId
+ */
+func (h *ActionHandler) DeleteAction(c *gin.Context) {
+	botID, ok := h.authorizeBot(c)
+	if !ok {
+		return
+	}
+
+	if err := h.actionRepo.Delete(c.Request.Context(), c.Param("actionId"), botID); err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{"message": "Action not found"})
+		} else {
+			c.JSON(http.StatusInternalServerError, gin.H{"message": "Failed to delete action"})
+		}
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Action deleted successfully"})
+}
+
+/*
+ * uniqueName verifies no other action of the bot has the same name, since names identify tools to the model
+ * Writes the error response and returns false on failure
+ */
+func (h *ActionHandler) uniqueName(c *gin.Context, botAction *models.BotAction) bool {
+	taken, err := h.actionRepo.NameExists(c.Request.Context(), botAction.BotID, botAction.Name, botAction.ID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"message": "Failed to check action name"})
+		return false
+	}
+	if taken {
+		c.JSON(http.StatusConflict, gin.H{"message": fmt.Sprintf("an action named %q already exists", botAction.Name)})
+		return false
+	}
+	return true
+}
+
+/*
+ * authorizeBot verifies the authenticated user owns the bot in the URL
+ * Writes the error response and returns false on failure
+ */
+func (h *ActionHandler) authorizeBot(c *gin.Context) (string, bool) {
+	userID := c.GetString("user_id")
+	if userID == "" {
+		c.JSON(http.StatusUnauthorized, gin.H{"message": "Unauthorized"})
+		return "", false
+	}
+
+	botID := c.Param("id")
+	bot, err := h.botRepo.GetByID(botID, userID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"message": "Failed to fetch bot"})
+		return "", false
+	}
+	if bot == nil {
+		c.JSON(http.StatusNotFound, gin.H{"message": "Bot not found"})
+		return "", false
+	}
+
+	return botID, true
+}
+
+/*
+ * applyJSONFields stores the parameter schema and headers as JSON text when provided
+ */
+func applyJSONFields(botAction *models.BotAction, parameters map[string]interface{}, headers map[string]string) error {
+	if parameters != nil {
+		parametersJSON, err := json.Marshal(parameters)
+		if err != nil {
+			return errors.New("invalid parameters schema")
+		}
+		botAction.Parameters = string(parametersJSON)
+	}
+
+	if headers != nil {
+		headersJSON, err := json.Marshal(headers)
+		if err != nil {
+			return errors.New("invalid headers")
+		}
+		botAction.Headers = string(headersJSON)
+	}
+
+	return nil
+}
@@ -0,0 +1,197 @@
+package action_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/souravsspace/texly.chat/internal/handlers/action"
+	"github.com/souravsspace/texly.chat/internal/models"
+	actionRepo "github.com/souravsspace/texly.chat/internal/repo/action"
+	botRepo "github.com/souravsspace/texly.chat/internal/repo/bot"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+)
+
+// setupTestDB creates an in-memory SQLite database and migrates the schema.
+func setupTestDB() *gorm.DB {
+	db, err := gorm.Open(sqlite.Open(":memory:"), &gorm.Config{})
+	if err != nil {
+		panic("failed to connect database")
+	}
+	db.AutoMigrate(&models.Bot{}, &models.BotAction{})
+	return db
+}
+
+func setupRouter(db *gorm.DB) *gin.Engine {
+	r := gin.Default()
+	handler := action.NewActionHandler(actionRepo.New(db), botRepo.NewBotRepo(db, nil))
+
+	// Mock Auth middleware by setting user_id in context
+	r.Use(func(c *gin.Context) {
+		c.Set("user_id", "test-user-id")
+		c.Next()
+	})
+
+	r.POST("/api/bots/:id/actions", handler.CreateAction)
+	r.GET("/api/bots/:id/actions", handler.ListActions)
+	r.PUT("/api/bots/:id/actions/:actionId", handler.UpdateAction)
+	r.DELETE("/api/bots/:id/actions/:actionId", handler.DeleteAction)
+
+	return r
+}
+
+func TestCreateAction(t *testing.T) {
+	db := setupTestDB()
+	r := setupRouter(db)
+
+	bot := models.Bot{UserID: "test-user-id", Name: "Support Bot"}
+	db.Create(&bot)
+
+	reqBody := models.CreateBotActionRequest{
+		Name:        "lookup_order_status",
+		Description: "Look up the status of an order",
+		URL:         "https://api.example.com/orders/{order_id}",
+		Parameters: map[string]interface{}{
+			"type":       "object",
+			"properties": map[string]interface{}{"order_id": map[string]interface{}{"type": "string"}},
+		},
+		Headers: map[string]string{"Authorization": "Bearer secret"},
+	}
+	jsonValue, _ := json.Marshal(reqBody)
+
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest("POST", "/api/bots/"+bot.ID+"/actions", bytes.NewBuffer(jsonValue))
+	r.ServeHTTP(w, req)
+
+	require.Equal(t, http.StatusCreated, w.Code)
+
+	// Headers are stored but never returned
+	assert.NotContains(t, w.Body.String(), "secret")
+
+	var created models.BotAction
+	json.Unmarshal(w.Body.Bytes(), &created)
+	assert.Equal(t, "GET", created.Method)
+	assert.Contains(t, created.Parameters, "order_id")
+
+	var stored models.BotAction
+	db.First(&stored, "id = ?", created.ID)
+	assert.Contains(t, stored.Headers, "Bearer secret")
+}
+
+func TestCreateAction_InvalidName(t *testing.T) {
+	db := setupTestDB()
+	r := setupRouter(db)
+
+	bot := models.Bot{UserID: "test-user-id", Name: "Support Bot"}
+	db.Create(&bot)
+
+	reqBody := models.CreateBotActionRequest{Name: "look up order", URL: "https://api.example.com/orders"}
+	jsonValue, _ := json.Marshal(reqBody)
+
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest("POST", "/api/bots/"+bot.ID+"/actions", bytes.NewBuffer(jsonValue))
+	r.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+}
+
+func TestCreateAndUpdateAction_DuplicateName(t *testing.T) {
+	db := setupTestDB()
+	r := setupRouter(db)
+
+	bot := models.Bot{UserID: "test-user-id", Name: "Support Bot"}
+	db.Create(&bot)
+	db.Create(&models.BotAction{BotID: bot.ID, Name: "ping", URL: "https://api.example.com/ping"})
+	other := models.BotAction{BotID: bot.ID, Name: "pong", URL: "https://api.example.com/pong"}
+	db.Create(&other)
+
+	jsonValue, _ := json.Marshal(models.CreateBotActionRequest{Name: "ping", URL: "https://api.example.com/ping2"})
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest("POST", "/api/bots/"+bot.ID+"/actions", bytes.NewBuffer(jsonValue))
+	r.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusConflict, w.Code)
+
+	jsonValue, _ = json.Marshal(models.UpdateBotActionRequest{Name: "ping"})
+	w = httptest.NewRecorder()
+	req, _ = http.NewRequest("PUT", "/api/bots/"+bot.ID+"/actions/"+other.ID, bytes.NewBuffer(jsonValue))
+	r.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusConflict, w.Code)
+
+	// Keeping its own name is not a conflict
+	jsonValue, _ = json.Marshal(models.UpdateBotActionRequest{Name: "pong", Description: "Replies"})
+	w = httptest.NewRecorder()
+	req, _ = http.NewRequest("PUT", "/api/bots/"+bot.ID+"/actions/"+other.ID, bytes.NewBuffer(jsonValue))
+	r.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusOK, w.Code)
+}
+
+func TestCreateAction_PrivateURL(t *testing.T) {
+	db := setupTestDB()
+	r := setupRouter(db)
+
+	bot := models.Bot{UserID: "test-user-id", Name: "Support Bot"}
+	db.Create(&bot)
+
+	reqBody := models.CreateBotActionRequest{Name: "metadata", URL: "http://169.254.169.254/latest/meta-data/"}
+	jsonValue, _ := json.Marshal(reqBody)
+
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest("POST", "/api/bots/"+bot.ID+"/actions", bytes.NewBuffer(jsonValue))
+	r.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+}
+
+func TestActions_OtherUsersBot(t *testing.T) {
+	db := setupTestDB()
+	r := setupRouter(db)
+
+	bot := models.Bot{UserID: "other-user", Name: "Not Mine"}
+	db.Create(&bot)
+
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest("GET", "/api/bots/"+bot.ID+"/actions", nil)
+	r.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusNotFound, w.Code)
+}
+
+func TestUpdateAndDeleteAction(t *testing.T) {
+	db := setupTestDB()
+	r := setupRouter(db)
+
+	bot := models.Bot{UserID: "test-user-id", Name: "Support Bot"}
+	db.Create(&bot)
+	botAction := models.BotAction{BotID: bot.ID, Name: "ping", URL: "https://api.example.com/ping"}
+	db.Create(&botAction)
+
+	updateReq := models.UpdateBotActionRequest{Method: "post", TimeoutSeconds: 5}
+	jsonValue, _ := json.Marshal(updateReq)
+
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest("PUT", "/api/bots/"+bot.ID+"/actions/"+botAction.ID, bytes.NewBuffer(jsonValue))
+	r.ServeHTTP(w, req)
+
+	require.Equal(t, http.StatusOK, w.Code)
+
+	var updated models.BotAction
+	db.First(&updated, "id = ?", botAction.ID)
+	assert.Equal(t, "POST", updated.Method)
+	assert.Equal(t, 5, updated.TimeoutSeconds)
+
+	w = httptest.NewRecorder()
+	req, _ = http.NewRequest("DELETE", "/api/bots/"+bot.ID+"/actions/"+botAction.ID, nil)
+	r.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+
+	var count int64
+	db.Model(&models.BotAction{}).Where("id = ?", botAction.ID).Count(&count)
+	assert.Equal(t, int64(0), count)
+}
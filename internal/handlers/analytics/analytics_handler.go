@@ -89,6 +89,95 @@ func (h *AnalyticsHandler) GetBotDailyStats(c *gin.Context) {
 	c.JSON(http.StatusOK, stats)
 }
 
+/*
+ * GetUnansweredQuestions handles GET /api/analytics/bots/:id/unanswered
+ * Returns recent questions the bot could not answer from its knowledge base
+ * Query params: limit (default: 50)
+ */
+func (h *AnalyticsHandler) GetUnansweredQuestions(c *gin.Context) {
+	userID := c.GetString("user_id")
+	if userID == "" {
+		c.JSON(http.StatusUnauthorized, gin.H{"message": "Unauthorized"})
+		return
+	}
+
+	botID := c.Param("id")
+	if botID == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"message": "Bot ID is required"})
+		return
+	}
+
+	// Parse limit parameter
+	limitStr := c.DefaultQuery("limit", "50")
+	limit, err := strconv.Atoi(limitStr)
+	if err != nil || limit < 1 {
+		limit = 50
+	}
+	if limit > 500 {
+		limit = 500
+	}
+
+	questions, err := h.analyticsService.GetUnansweredQuestions(c.Request.Context(), botID, limit)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"message": "Failed to fetch unanswered questions"})
+		return
+	}
+
+	c.JSON(http.StatusOK, questions)
+}
+
+/*
+ * GetAnswerCacheStats handles GET /api/analytics/bots/:id/answer-cache
+ * Returns how often the bot answered repeated questions from its semantic cache
+ */
+func (h *AnalyticsHandler) GetAnswerCacheStats(c *gin.Context) {
+	userID := c.GetString("user_id")
+	if userID == "" {
+		c.JSON(http.StatusUnauthorized, gin.H{"message": "Unauthorized"})
+		return
+	}
+
+	botID := c.Param("id")
+	if botID == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"message": "Bot ID is required"})
+		return
+	}
+
+	stats, err := h.analyticsService.GetAnswerCacheStats(c.Request.Context(), botID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"message": "Failed to fetch answer cache stats"})
+		return
+	}
+
+	c.JSON(http.StatusOK, stats)
+}
+
+/*
+ * GetPromptVersionStats handles GET /api/analytics/bots/:id/prompts
+ * Compares fallback rates and feedback scores of the answers given with each prompt version
+ */
+func (h *AnalyticsHandler) GetPromptVersionStats(c *gin.Context) {
+	userID := c.GetString("user_id")
+	if userID == "" {
+		c.JSON(http.StatusUnauthorized, gin.H{"message": "Unauthorized"})
+		return
+	}
+
+	botID := c.Param("id")
+	if botID == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"message": "Bot ID is required"})
+		return
+	}
+
+	stats, err := h.analyticsService.GetPromptVersionStats(c.Request.Context(), botID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"message": "Failed to fetch prompt version stats"})
+		return
+	}
+
+	c.JSON(http.StatusOK, stats)
+}
+
 /*
  * GetUserAnalytics handles GET /api/analytics/user
  * Returns analytics for all bots owned by the current user
@@ -111,7 +200,7 @@ func (h *AnalyticsHandler) GetUserAnalytics(c *gin.Context) {
 
 /*
  * GetSessionMessages handles GET /api/analytics/sessions/:id/messages
- * Returns all messages for a specific session
+ * Returns all messages for a specific session with the summary of its older turns
  */
 func (h *AnalyticsHandler) GetSessionMessages(c *gin.Context) {
 	userID := c.GetString("user_id")
@@ -126,11 +215,11 @@ func (h *AnalyticsHandler) GetSessionMessages(c *gin.Context) {
 		return
 	}
 
-	messages, err := h.analyticsService.GetSessionMessages(c.Request.Context(), sessionID)
+	transcript, err := h.analyticsService.GetSessionMessages(c.Request.Context(), sessionID)
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"message": "Failed to fetch messages"})
 		return
 	}
 
-	c.JSON(http.StatusOK, messages)
+	c.JSON(http.StatusOK, transcript)
 }
@@ -288,11 +288,13 @@ func TestGetSessionMessages_Handler(t *testing.T) {
 	// Assert response
 	assert.Equal(t, http.StatusOK, w.Code)
 
-	var response []models.Message
+	var response models.SessionTranscript
 	err := json.Unmarshal(w.Body.Bytes(), &response)
 	require.NoError(t, err)
-	assert.GreaterOrEqual(t, len(response), 2)
-	assert.Equal(t, "First", response[0].Content)
+	assert.Equal(t, sessionID, response.SessionID)
+	assert.Nil(t, response.Summary)
+	assert.GreaterOrEqual(t, len(response.Messages), 2)
+	assert.Equal(t, "First", response.Messages[0].Content)
 }
 
 /*
@@ -0,0 +1,120 @@
+package apikey
+
+import (
+	"errors"
+	"io"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/souravsspace/texly.chat/internal/models"
+	botRepo "github.com/souravsspace/texly.chat/internal/repo/bot"
+	apiKeySvc "github.com/souravsspace/texly.chat/internal/services/apikey"
+	"gorm.io/gorm"
+)
+
+/*
+ * APIKeyHandler handles HTTP requests for managing bot API keys
+ */
+type APIKeyHandler struct {
+	apiKeyService *apiKeySvc.APIKeyService
+	botRepo       *botRepo.BotRepo
+}
+
+/*
+ * NewAPIKeyHandler creates a new API key handler instance
+ */
+func NewAPIKeyHandler(apiKeyService *apiKeySvc.APIKeyService, botRepo *botRepo.BotRepo) *APIKeyHandler {
+	return &APIKeyHandler{
+		apiKeyService: apiKeyService,
+		botRepo:       botRepo,
+	}
+}
+
+/*
+ * CreateAPIKey handles POST /api/bots/:id/api-keys
+ * The plaintext key is only returned in this response
+ */
+func (h *APIKeyHandler) CreateAPIKey(c *gin.Context) {
+	bot, ok := h.authorizeBot(c)
+	if !ok {
+		return
+	}
+
+	// The request body is optional
+	var req models.CreateBotAPIKeyRequest
+	if err := c.ShouldBindJSON(&req); err != nil && !errors.Is(err, io.EOF) {
+		c.JSON(http.StatusBadRequest, gin.H{"message": err.Error()})
+		return
+	}
+
+	apiKey, key, err := h.apiKeyService.Create(c.Request.Context(), bot, req.Name)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"message": "Failed to create API key"})
+		return
+	}
+
+	c.JSON(http.StatusCreated, models.CreateBotAPIKeyResponse{APIKey: *apiKey, Key: key})
+}
+
+/*
+ * ListAPIKeys handles GET /api/bots/:id/api-keys
+ */
+func (h *APIKeyHandler) ListAPIKeys(c *gin.Context) {
+	bot, ok := h.authorizeBot(c)
+	if !ok {
+		return
+	}
+
+	keys, err := h.apiKeyService.List(c.Request.Context(), bot.ID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"message": "Failed to fetch API keys"})
+		return
+	}
+
+	c.JSON(http.StatusOK, keys)
+}
+
+/*
+ * RevokeAPIKey handles DELETE /api/bots/:id/api-keys/:keyId
+ */
+func (h *APIKeyHandler) RevokeAPIKey(c *gin.Context) {
+	bot, ok := h.authorizeBot(c)
+	if !ok {
+		return
+	}
+
+	if err := h.apiKeyService.Revoke(c.Request.Context(), c.Param("keyId"), bot.ID); err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{"message": "API key not found"})
+		} else {
+			c.JSON(http.StatusInternalServerError, gin.H{"message": "Failed to revoke API key"})
+		}
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "API key revoked successfully"})
+}
+
+/*
+ * authorizeBot verifies the authenticated user owns the bot in the URL
+ * Writes the error response and returns false on failure
+ */
+func (h *APIKeyHandler) authorizeBot(c *gin.Context) (*models.Bot, bool) {
+	userID := c.GetString("user_id")
+	if userID == "" {
+		c.JSON(http.StatusUnauthorized, gin.H{"message": "Unauthorized"})
+		return nil, false
+	}
+
+	bot, err := h.botRepo.GetByID(c.Param("id"), userID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"message": "Failed to fetch bot"})
+		return nil, false
+	}
+	if bot == nil {
+		c.JSON(http.StatusNotFound, gin.H{"message": "Bot not found"})
+		return nil, false
+	}
+
+	return bot, true
+}
@@ -3,20 +3,46 @@ package bot
 import (
 	"encoding/json"
 	"errors"
+	"fmt"
 	"net/http"
+	"slices"
+	"strings"
 
 	"github.com/gin-gonic/gin"
+	"github.com/souravsspace/texly.chat/configs"
 	"github.com/souravsspace/texly.chat/internal/models"
 	botRepo "github.com/souravsspace/texly.chat/internal/repo/bot"
+	userRepo "github.com/souravsspace/texly.chat/internal/repo/user"
+	"github.com/souravsspace/texly.chat/internal/services/llm"
+	"github.com/souravsspace/texly.chat/internal/services/prompt"
 	"gorm.io/gorm"
 )
 
+// Limits on the starter questions shown by the widget
+const (
+	maxStarterQuestions      = 5
+	maxStarterQuestionLength = 200
+)
+
 type BotHandler struct {
-	repo *botRepo.BotRepo
+	repo          *botRepo.BotRepo
+	userRepo      *userRepo.UserRepo    // Optional: resolves the owner's tier (nil = free tier limits)
+	promptService *prompt.PromptService // Optional: keeps the history of system prompts
+	providers     []string              // Optional: registered chat providers (nil = only the default provider)
+}
+
+func NewBotHandler(repo *botRepo.BotRepo, userRepo *userRepo.UserRepo) *BotHandler {
+	return &BotHandler{repo: repo, userRepo: userRepo}
+}
+
+// SetPromptService enables versioning of system prompt changes.
+func (h *BotHandler) SetPromptService(promptService *prompt.PromptService) {
+	h.promptService = promptService
 }
 
-func NewBotHandler(repo *botRepo.BotRepo) *BotHandler {
-	return &BotHandler{repo: repo}
+// SetChatProviders sets the chat providers bots may select.
+func (h *BotHandler) SetChatProviders(providers []string) {
+	h.providers = providers
 }
 
 // CreateBot - POST /api/bots
@@ -37,6 +63,25 @@ func (h *BotHandler) CreateBot(c *gin.Context) {
 		UserID:       userID,
 		Name:         req.Name,
 		SystemPrompt: req.SystemPrompt,
+		Provider:     req.Provider,
+	}
+	if req.QueryRewrite != nil {
+		bot.QueryRewrite = *req.QueryRewrite
+	}
+	if req.SuggestFollowUps != nil {
+		bot.SuggestFollowUps = *req.SuggestFollowUps
+	}
+	if req.Reranker != nil {
+		bot.Reranker = *req.Reranker
+	}
+	applyRetrievalMode(&bot, req.RetrievalMode, req.MMRLambda)
+	applyFallbackSettings(&bot, req.FallbackMode, req.FallbackMessage)
+
+	// Apply and validate generation settings against the user's tier
+	applyGenerationSettings(&bot, req.Model, req.Temperature, req.MaxOutputTokens, req.MaxContextChunks, req.DistanceThreshold)
+	if err := validateGenerationSettings(&bot, h.tierLimits(userID), h.providers); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"message": err.Error()})
+		return
 	}
 
 	// Marshal AllowedOrigins to JSON if provided
@@ -49,8 +94,22 @@ func (h *BotHandler) CreateBot(c *gin.Context) {
 		bot.AllowedOrigins = string(allowedOriginsJSON)
 	}
 
+	// Marshal HandoffKeywords to JSON if provided
+	if len(req.HandoffKeywords) > 0 {
+		handoffKeywordsJSON, err := json.Marshal(req.HandoffKeywords)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"message": "Invalid handoff keywords"})
+			return
+		}
+		bot.HandoffKeywords = string(handoffKeywordsJSON)
+	}
+
 	// Marshal WidgetConfig to JSON if provided
 	if req.WidgetConfig != nil {
+		if err := validateWidgetConfig(req.WidgetConfig); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"message": err.Error()})
+			return
+		}
 		widgetConfigJSON, err := json.Marshal(req.WidgetConfig)
 		if err != nil {
 			c.JSON(http.StatusBadRequest, gin.H{"message": "Invalid widget config"})
@@ -64,6 +123,15 @@ func (h *BotHandler) CreateBot(c *gin.Context) {
 		return
 	}
 
+	// Record the first prompt version; unversioned bots get one on their next prompt change
+	if h.promptService != nil {
+		if _, err := h.promptService.RecordVersion(c.Request.Context(), &bot, userID, "Initial prompt"); err != nil {
+			fmt.Printf("Warning: failed to record prompt version: %v\n", err)
+		} else if err := h.repo.Update(&bot); err != nil {
+			fmt.Printf("Warning: failed to save prompt version: %v\n", err)
+		}
+	}
+
 	c.JSON(http.StatusCreated, bot)
 }
 
@@ -127,11 +195,38 @@ func (h *BotHandler) UpdateBot(c *gin.Context) {
 	if req.Name != "" {
 		bot.Name = req.Name
 	}
-	// SystemPrompt can be empty, but usually check if present.
-	// For simplicity, we update if provided in JSON (even empty string).
-	// But struct zero value check is tricky.
-	// Let's assume we update if needed. Alternatively map fields.
-	bot.SystemPrompt = req.SystemPrompt
+	// Switch chat provider if provided
+	if req.Provider != "" {
+		bot.Provider = req.Provider
+	}
+
+	// Toggle query rewriting if provided
+	if req.QueryRewrite != nil {
+		bot.QueryRewrite = *req.QueryRewrite
+	}
+
+	// Toggle follow-up suggestions if provided
+	if req.SuggestFollowUps != nil {
+		bot.SuggestFollowUps = *req.SuggestFollowUps
+	}
+
+	// Switch reranker if provided
+	if req.Reranker != nil {
+		bot.Reranker = *req.Reranker
+	}
+
+	// Switch retrieval mode if provided
+	applyRetrievalMode(bot, req.RetrievalMode, req.MMRLambda)
+
+	// Update low-confidence fallback if provided
+	applyFallbackSettings(bot, req.FallbackMode, req.FallbackMessage)
+
+	// Update generation settings if provided
+	applyGenerationSettings(bot, req.Model, req.Temperature, req.MaxOutputTokens, req.MaxContextChunks, req.DistanceThreshold)
+	if err := validateGenerationSettings(bot, updateLimits(h.tierLimits(userID), req), h.providers); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"message": err.Error()})
+		return
+	}
 
 	// Update AllowedOrigins if provided
 	if len(req.AllowedOrigins) > 0 {
@@ -143,8 +238,22 @@ func (h *BotHandler) UpdateBot(c *gin.Context) {
 		bot.AllowedOrigins = string(allowedOriginsJSON)
 	}
 
+	// Update HandoffKeywords if provided (an empty list clears them)
+	if req.HandoffKeywords != nil {
+		handoffKeywordsJSON, err := json.Marshal(*req.HandoffKeywords)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"message": "Invalid handoff keywords"})
+			return
+		}
+		bot.HandoffKeywords = string(handoffKeywordsJSON)
+	}
+
 	// Update WidgetConfig if provided
 	if req.WidgetConfig != nil {
+		if err := validateWidgetConfig(req.WidgetConfig); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"message": err.Error()})
+			return
+		}
 		widgetConfigJSON, err := json.Marshal(req.WidgetConfig)
 		if err != nil {
 			c.JSON(http.StatusBadRequest, gin.H{"message": "Invalid widget config"})
@@ -153,6 +262,18 @@ func (h *BotHandler) UpdateBot(c *gin.Context) {
 		bot.WidgetConfig = string(widgetConfigJSON)
 	}
 
+	// Update system prompt if provided (an empty string clears it)
+	// A changed prompt is recorded as a new version, so it can be diffed and rolled back.
+	if req.SystemPrompt != nil {
+		if h.promptService != nil && *req.SystemPrompt != bot.SystemPrompt {
+			if _, err := h.promptService.RecordChange(c.Request.Context(), bot, *req.SystemPrompt, userID, req.PromptNote); err != nil {
+				c.JSON(http.StatusInternalServerError, gin.H{"message": "Failed to record prompt version"})
+				return
+			}
+		}
+		bot.SystemPrompt = *req.SystemPrompt
+	}
+
 	if err := h.repo.Update(bot); err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"message": "Failed to update bot"})
 		return
@@ -178,3 +299,142 @@ func (h *BotHandler) DeleteBot(c *gin.Context) {
 
 	c.JSON(http.StatusOK, gin.H{"message": "Bot deleted successfully"})
 }
+
+// tierLimits returns the tier limits of the given user, defaulting to Free.
+func (h *BotHandler) tierLimits(userID string) configs.TierLimits {
+	if h.userRepo == nil {
+		return configs.GetTierLimits(configs.TierFree)
+	}
+	user, err := h.userRepo.GetByID(userID)
+	if err != nil || user == nil {
+		return configs.GetTierLimits(configs.TierFree)
+	}
+	return configs.GetTierLimits(user.Tier)
+}
+
+// applyGenerationSettings copies the provided (non-nil) generation settings onto the bot.
+func applyGenerationSettings(
+	bot *models.Bot,
+	model string,
+	temperature *float64,
+	maxOutputTokens *int,
+	maxContextChunks *int,
+	distanceThreshold *float64,
+) {
+	if model != "" {
+		bot.Model = model
+	}
+	if temperature != nil {
+		bot.Temperature = temperature
+	}
+	if maxOutputTokens != nil {
+		bot.MaxOutputTokens = *maxOutputTokens
+	}
+	if maxContextChunks != nil {
+		bot.MaxContextChunks = *maxContextChunks
+	}
+	if distanceThreshold != nil {
+		bot.DistanceThreshold = *distanceThreshold
+	}
+}
+
+// updateLimits lifts the tier limits on settings an update leaves alone.
+// After a downgrade the stored values may exceed the new plan; they are clamped when answering,
+// and must not block unrelated edits such as a rename.
+func updateLimits(limits configs.TierLimits, req models.UpdateBotRequest) configs.TierLimits {
+	if req.Provider == "" && req.Model == "" {
+		limits.AllowedChatModels = nil
+	}
+	if req.MaxOutputTokens == nil {
+		limits.MaxOutputTokens = -1
+	}
+	if req.MaxContextChunks == nil {
+		limits.MaxContextChunks = -1
+	}
+	return limits
+}
+
+// validateGenerationSettings checks the bot's generation settings against the tier limits
+// and the registered chat providers.
+// Zero values mean "use the server default" and are always accepted.
+func validateGenerationSettings(bot *models.Bot, limits configs.TierLimits, providers []string) error {
+	if bot.Provider != "" && bot.Provider != llm.DefaultProvider && !slices.Contains(providers, bot.Provider) {
+		return fmt.Errorf("provider %q is not available", bot.Provider)
+	}
+	if !limits.AllowsChatModel(bot.Provider, bot.Model) {
+		return fmt.Errorf("model %q is not available on the %s plan", bot.Model, limits.Tier)
+	}
+	if bot.Temperature != nil && (*bot.Temperature < 0 || *bot.Temperature > 2) {
+		return errors.New("temperature must be between 0 and 2")
+	}
+	if bot.MaxOutputTokens < 0 {
+		return errors.New("max_output_tokens must be positive")
+	}
+	if limits.MaxOutputTokens != -1 && bot.MaxOutputTokens > limits.MaxOutputTokens {
+		return fmt.Errorf("max_output_tokens exceeds the %s plan limit of %d", limits.Tier, limits.MaxOutputTokens)
+	}
+	if bot.MaxContextChunks < 0 {
+		return errors.New("max_context_chunks must be positive")
+	}
+	if limits.MaxContextChunks != -1 && bot.MaxContextChunks > limits.MaxContextChunks {
+		return fmt.Errorf("max_context_chunks exceeds the %s plan limit of %d", limits.Tier, limits.MaxContextChunks)
+	}
+	if bot.DistanceThreshold < 0 || bot.DistanceThreshold > 2 {
+		return errors.New("distance_threshold must be between 0 and 2")
+	}
+	switch bot.Reranker {
+	case models.RerankerNone, models.RerankerBM25, models.RerankerLLM:
+	default:
+		return fmt.Errorf("reranker must be one of %q, %q or %q", models.RerankerNone, models.RerankerBM25, models.RerankerLLM)
+	}
+	switch bot.RetrievalMode {
+	case models.RetrievalModeSimilarity, models.RetrievalModeMMR:
+	default:
+		return fmt.Errorf("retrieval_mode must be %q or %q", models.RetrievalModeSimilarity, models.RetrievalModeMMR)
+	}
+	if bot.MMRLambda != nil && (*bot.MMRLambda < 0 || *bot.MMRLambda > 1) {
+		return errors.New("mmr_lambda must be between 0 and 1")
+	}
+	switch bot.FallbackMode {
+	case models.FallbackModeNone, models.FallbackModeMessage, models.FallbackModeHuman:
+	default:
+		return fmt.Errorf("fallback_mode must be one of %q, %q or %q", models.FallbackModeNone, models.FallbackModeMessage, models.FallbackModeHuman)
+	}
+	return nil
+}
+
+// validateWidgetConfig checks the owner-provided starter questions.
+func validateWidgetConfig(config *models.WidgetConfig) error {
+	if len(config.StarterQuestions) > maxStarterQuestions {
+		return fmt.Errorf("at most %d starter questions are allowed", maxStarterQuestions)
+	}
+	for _, question := range config.StarterQuestions {
+		if strings.TrimSpace(question) == "" {
+			return errors.New("starter questions must not be empty")
+		}
+		if len(question) > maxStarterQuestionLength {
+			return fmt.Errorf("starter questions must be at most %d characters", maxStarterQuestionLength)
+		}
+	}
+	return nil
+}
+
+// applyRetrievalMode copies the provided (non-nil) retrieval mode and MMR lambda onto the bot.
+func applyRetrievalMode(bot *models.Bot, mode *string, lambda *float64) {
+	if mode != nil {
+		bot.RetrievalMode = *mode
+	}
+	if lambda != nil {
+		bot.MMRLambda = lambda
+	}
+}
+
+// applyFallbackSettings copies the provided (non-nil) fallback settings onto the bot.
+func applyFallbackSettings(bot *models.Bot, mode *string, message *string) {
+	if mode != nil {
+		bot.FallbackMode = *mode
+	}
+	if message != nil {
+		bot.FallbackMessage = *message
+	}
+}
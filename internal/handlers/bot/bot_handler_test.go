@@ -29,7 +29,7 @@ func setupTestDB() *gorm.DB {
 func setupRouter(db *gorm.DB) *gin.Engine {
 	r := gin.Default()
 	repo := botRepo.NewBotRepo(db, nil)
-	handler := bot.NewBotHandler(repo)
+	handler := bot.NewBotHandler(repo, nil)
 
 	// Mock Auth middleware by setting user_id in context
 	r.Use(func(c *gin.Context) {
@@ -110,6 +110,44 @@ func TestUpdateBot(t *testing.T) {
 	assert.Equal(t, "New Name", updatedBot.Name)
 }
 
+func TestUpdateBot_AfterDowngrade(t *testing.T) {
+	db := setupTestDB()
+	r := setupRouter(db)
+
+	// Settings chosen on a higher plan exceed the free tier the owner is now on
+	botInstance := models.Bot{UserID: "test-user-id", Name: "Old Name", Model: "gpt-4.1", MaxOutputTokens: 100000, MaxContextChunks: 50}
+	db.Create(&botInstance)
+
+	tooManyChunks := 50
+	testCases := []struct {
+		reqBody models.UpdateBotRequest
+		status  int
+	}{
+		// Unrelated edits keep working
+		{models.UpdateBotRequest{Name: "New Name"}, http.StatusOK},
+		{models.UpdateBotRequest{AllowedOrigins: []string{"https://example.com"}}, http.StatusOK},
+		// Settings the update sets are checked against the current plan
+		{models.UpdateBotRequest{Model: "gpt-4.1"}, http.StatusBadRequest},
+		{models.UpdateBotRequest{Provider: "openai"}, http.StatusBadRequest},
+		{models.UpdateBotRequest{MaxContextChunks: &tooManyChunks}, http.StatusBadRequest},
+	}
+
+	for _, tc := range testCases {
+		jsonValue, _ := json.Marshal(tc.reqBody)
+
+		w := httptest.NewRecorder()
+		req, _ := http.NewRequest("PUT", "/api/bots/"+botInstance.ID, bytes.NewBuffer(jsonValue))
+		r.ServeHTTP(w, req)
+
+		assert.Equal(t, tc.status, w.Code, "%+v", tc.reqBody)
+	}
+
+	var updatedBot models.Bot
+	db.First(&updatedBot, "id = ?", botInstance.ID)
+	assert.Equal(t, "New Name", updatedBot.Name)
+	assert.Equal(t, "gpt-4.1", updatedBot.Model)
+}
+
 func TestDeleteBot(t *testing.T) {
 	db := setupTestDB()
 	r := setupRouter(db)
@@ -135,10 +173,11 @@ func TestCreateBot_WithWidgetConfig(t *testing.T) {
 	r := setupRouter(db)
 
 	widgetConfig := &models.WidgetConfig{
-		ThemeColor:     "#ff5733",
-		InitialMessage: "Welcome to our bot!",
-		Position:       "bottom-left",
-		BotAvatar:      "https://example.com/avatar.png",
+		ThemeColor:       "#ff5733",
+		InitialMessage:   "Welcome to our bot!",
+		Position:         "bottom-left",
+		BotAvatar:        "https://example.com/avatar.png",
+		StarterQuestions: []string{"What are your opening hours?", "Do you ship abroad?"},
 	}
 
 	reqBody := models.CreateBotRequest{
@@ -171,6 +210,7 @@ func TestCreateBot_WithWidgetConfig(t *testing.T) {
 	json.Unmarshal([]byte(createdBot.WidgetConfig), &parsedConfig)
 	assert.Equal(t, "#ff5733", parsedConfig.ThemeColor)
 	assert.Equal(t, "Welcome to our bot!", parsedConfig.InitialMessage)
+	assert.Equal(t, []string{"What are your opening hours?", "Do you ship abroad?"}, parsedConfig.StarterQuestions)
 }
 
 func TestUpdateBot_WithWidgetConfig(t *testing.T) {
@@ -208,3 +248,105 @@ func TestUpdateBot_WithWidgetConfig(t *testing.T) {
 	assert.NotEmpty(t, updatedBot.AllowedOrigins)
 	assert.NotEmpty(t, updatedBot.WidgetConfig)
 }
+
+func TestCreateBot_WithGenerationSettings(t *testing.T) {
+	db := setupTestDB()
+	r := setupRouter(db)
+
+	temperature := 0.2
+	maxContextChunks := 3
+	reqBody := models.CreateBotRequest{
+		Name:             "Tuned Bot",
+		Model:            "gpt-4o-mini",
+		Temperature:      &temperature,
+		MaxContextChunks: &maxContextChunks,
+	}
+	jsonValue, _ := json.Marshal(reqBody)
+
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest("POST", "/api/bots", bytes.NewBuffer(jsonValue))
+	r.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusCreated, w.Code)
+
+	var createdBot models.Bot
+	json.Unmarshal(w.Body.Bytes(), &createdBot)
+	assert.Equal(t, "gpt-4o-mini", createdBot.Model)
+	assert.Equal(t, 0.2, *createdBot.Temperature)
+	assert.Equal(t, 3, createdBot.MaxContextChunks)
+}
+
+func TestCreateBot_RejectsSettingsOutsideTier(t *testing.T) {
+	db := setupTestDB()
+	r := setupRouter(db)
+
+	// No user repo configured, so the free tier applies
+	tooManyChunks := 50
+	invalidTemperature := 3.0
+	invalidFallbackMode := "escalate"
+	invalidReranker := "cohere"
+	invalidRetrievalMode := "random"
+	invalidLambda := 1.5
+	testCases := []models.CreateBotRequest{
+		{Name: "Bot", Model: "gpt-4.1"},
+		{Name: "Bot", Provider: "anthropc"},
+		{Name: "Bot", Provider: "anthropic"},
+		{Name: "Bot", MaxContextChunks: &tooManyChunks},
+		{Name: "Bot", Temperature: &invalidTemperature},
+		{Name: "Bot", FallbackMode: &invalidFallbackMode},
+		{Name: "Bot", Reranker: &invalidReranker},
+		{Name: "Bot", RetrievalMode: &invalidRetrievalMode},
+		{Name: "Bot", MMRLambda: &invalidLambda},
+		{Name: "Bot", WidgetConfig: &models.WidgetConfig{StarterQuestions: []string{"1", "2", "3", "4", "5", "6"}}},
+		{Name: "Bot", WidgetConfig: &models.WidgetConfig{StarterQuestions: []string{" "}}},
+	}
+
+	for _, reqBody := range testCases {
+		jsonValue, _ := json.Marshal(reqBody)
+
+		w := httptest.NewRecorder()
+		req, _ := http.NewRequest("POST", "/api/bots", bytes.NewBuffer(jsonValue))
+		r.ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+	}
+
+	var count int64
+	db.Model(&models.Bot{}).Count(&count)
+	assert.Equal(t, int64(0), count)
+}
+
+func TestCreateBot_ProviderModels(t *testing.T) {
+	db := setupTestDB()
+	handler := bot.NewBotHandler(botRepo.NewBotRepo(db, nil), nil)
+	handler.SetChatProviders([]string{"anthropic", "openai"})
+
+	r := gin.Default()
+	r.Use(func(c *gin.Context) {
+		c.Set("user_id", "test-user-id")
+		c.Next()
+	})
+	r.POST("/api/bots", handler.CreateBot)
+
+	// The free tier allows the provider's default model and its listed models only
+	testCases := []struct {
+		reqBody models.CreateBotRequest
+		status  int
+	}{
+		{models.CreateBotRequest{Name: "Bot", Provider: "anthropic"}, http.StatusCreated},
+		{models.CreateBotRequest{Name: "Bot", Provider: "anthropic", Model: "claude-haiku-4-5"}, http.StatusCreated},
+		{models.CreateBotRequest{Name: "Bot", Provider: "anthropic", Model: "claude-sonnet-4-5"}, http.StatusBadRequest},
+		{models.CreateBotRequest{Name: "Bot", Provider: "anthropic", Model: "gpt-4o-mini"}, http.StatusBadRequest},
+		{models.CreateBotRequest{Name: "Bot", Provider: "azure"}, http.StatusBadRequest},
+	}
+
+	for _, tc := range testCases {
+		jsonValue, _ := json.Marshal(tc.reqBody)
+
+		w := httptest.NewRecorder()
+		req, _ := http.NewRequest("POST", "/api/bots", bytes.NewBuffer(jsonValue))
+		r.ServeHTTP(w, req)
+
+		assert.Equal(t, tc.status, w.Code, "%s %q", tc.reqBody.Provider, tc.reqBody.Model)
+	}
+}
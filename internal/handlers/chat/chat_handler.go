@@ -2,13 +2,13 @@ package chat
 
 import (
 	"encoding/json"
-	"fmt"
 	"net/http"
 
 	"github.com/gin-gonic/gin"
+	"github.com/souravsspace/texly.chat/configs"
 	"github.com/souravsspace/texly.chat/internal/models"
 	botRepo "github.com/souravsspace/texly.chat/internal/repo/bot"
-	usage "github.com/souravsspace/texly.chat/internal/services/billing/usage"
+	userRepo "github.com/souravsspace/texly.chat/internal/repo/user"
 	"github.com/souravsspace/texly.chat/internal/services/chat"
 )
 
@@ -18,17 +18,21 @@ import (
 type ChatHandler struct {
 	botRepo     *botRepo.BotRepo
 	chatService *chat.ChatService
-	usageSvc    *usage.UsageService
+	userRepo    *userRepo.UserRepo
 }
 
 /*
  * NewChatHandler creates a new chat handler instance
  */
-func NewChatHandler(botRepo *botRepo.BotRepo, chatService *chat.ChatService, usageSvc *usage.UsageService) *ChatHandler {
+func NewChatHandler(
+	botRepo *botRepo.BotRepo,
+	chatService *chat.ChatService,
+	userRepo *userRepo.UserRepo,
+) *ChatHandler {
 	return &ChatHandler{
 		botRepo:     botRepo,
 		chatService: chatService,
-		usageSvc:    usageSvc,
+		userRepo:    userRepo,
 	}
 }
 
@@ -75,22 +79,6 @@ func (h *ChatHandler) StreamChat(c *gin.Context) {
 		return
 	}
 
-	// Track usage (billing owner is Bot.UserID)
-	if h.usageSvc != nil {
-		if err := h.usageSvc.TrackChatMessage(bot.UserID, bot.ID); err != nil {
-			// Failed to track usage/charge - should we block?
-			// For now, log error and proceed, or block if strict
-			// Ideally we block if payment required
-			fmt.Printf("Error tracking chat usage: %v\n", err)
-			// If we want to block on insufficient funds (already handled in UsageService which returns error?
-			// UsageService returns error if DB error. It updates balance but doesn't strictly block UNLESS logic added.
-			// Current UsageService implementation returns error only on DB failure.
-			// Pro tier logic: "Deduct from credits if available".
-			// If 0 credits, we assume pay-as-you-go and just record usage.
-			// So we don't block here unless DB fails.
-		}
-	}
-
 	// Stream tokens using manual SSE writing
 	c.Writer.Header().Set("Content-Type", "text/event-stream")
 	c.Writer.Header().Set("Cache-Control", "no-cache")
@@ -106,19 +94,20 @@ func (h *ChatHandler) StreamChat(c *gin.Context) {
 	// Start streaming from chat service
 	// Generate a session ID for this chat (in dashboard, session = one conversation)
 	sessionID := botID + "-" + userID
-	tokenChan, errChan := h.chatService.StreamChat(
+	settings := h.chatService.ResolveSettings(bot, h.tierLimits(c, bot.UserID))
+	eventChan, errChan := h.chatService.StreamChat(
 		c.Request.Context(),
-		botID,
-		bot.SystemPrompt,
+		bot,
+		settings,
 		req.Message,
 		sessionID,
 		&userID,
 	)
 
-	// Stream tokens
+	// Stream events (sources, tokens)
 	for {
 		select {
-		case token, ok := <-tokenChan:
+		case event, ok := <-eventChan:
 			if !ok {
 				// Channel closed, stream done
 				response := models.ChatTokenResponse{
@@ -130,12 +119,8 @@ func (h *ChatHandler) StreamChat(c *gin.Context) {
 				return
 			}
 
-			// Send token
-			response := models.ChatTokenResponse{
-				Type:    "token",
-				Content: token,
-			}
-			data, _ := json.Marshal(response)
+			// Send event as-is
+			data, _ := json.Marshal(event)
 			c.Writer.Write([]byte("data: " + string(data) + "\n\n"))
 			flusher.Flush()
 
@@ -154,3 +139,23 @@ func (h *ChatHandler) StreamChat(c *gin.Context) {
 		}
 	}
 }
+
+/*
+ * tierLimits returns the tier limits of the bot owner, defaulting to Free
+ * Prefers the user loaded by the entitlement middleware
+ */
+func (h *ChatHandler) tierLimits(c *gin.Context, ownerID string) configs.TierLimits {
+	if userCtx, exists := c.Get("user"); exists {
+		if user, ok := userCtx.(*models.User); ok && user.ID == ownerID {
+			return configs.GetTierLimits(user.Tier)
+		}
+	}
+	if h.userRepo == nil {
+		return configs.GetTierLimits(configs.TierFree)
+	}
+	user, err := h.userRepo.GetByID(ownerID)
+	if err != nil || user == nil {
+		return configs.GetTierLimits(configs.TierFree)
+	}
+	return configs.GetTierLimits(user.Tier)
+}
@@ -12,10 +12,12 @@ import (
 	"github.com/alicebob/miniredis/v2"
 	"github.com/gin-gonic/gin"
 	"github.com/redis/go-redis/v9"
+	"github.com/souravsspace/texly.chat/configs"
 	"github.com/souravsspace/texly.chat/internal/handlers/chat"
 	"github.com/souravsspace/texly.chat/internal/models"
 	botRepo "github.com/souravsspace/texly.chat/internal/repo/bot"
 	messageRepo "github.com/souravsspace/texly.chat/internal/repo/message"
+	userRepo "github.com/souravsspace/texly.chat/internal/repo/user"
 	vectorRepo "github.com/souravsspace/texly.chat/internal/repo/vector"
 	usage "github.com/souravsspace/texly.chat/internal/services/billing/usage"
 	"github.com/souravsspace/texly.chat/internal/services/cache"
@@ -45,6 +47,7 @@ func TestChatHandler_StreamChat(t *testing.T) {
 			w.Header().Set("Content-Type", "text/event-stream")
 			w.WriteHeader(http.StatusOK)
 			w.Write([]byte(`data: {"id":"chatcmpl-123","choices":[{"index":0,"delta":{"content":"Hello"}}],"created":123}` + "\n\n"))
+			w.Write([]byte(`data: {"id":"chatcmpl-123","choices":[],"created":123,"usage":{"prompt_tokens":1200,"completion_tokens":30,"total_tokens":1230}}` + "\n\n"))
 			w.Write([]byte("data: [DONE]\n\n"))
 			return
 		}
@@ -69,6 +72,7 @@ func TestChatHandler_StreamChat(t *testing.T) {
 	// Repos
 	repoBot := botRepo.NewBotRepo(db, cacheSvc)
 	repoMsg := messageRepo.New(db)
+	repoUser := userRepo.NewUserRepo(db, cacheSvc)
 	repoVec := vectorRepo.NewVectorRepository(db)
 
 	// Services
@@ -89,11 +93,13 @@ func TestChatHandler_StreamChat(t *testing.T) {
 		0.7,
 		3,
 		"test-key",
+		2000,
 	)
 	chatService.SetBaseURL(openAIServer.URL)
+	chatService.SetUsageService(usageSvc)
 
 	// Handler
-	chatHandler := chat.NewChatHandler(repoBot, chatService, usageSvc)
+	chatHandler := chat.NewChatHandler(repoBot, chatService, repoUser)
 
 	// Setup Data
 	userID := "user_chat_full"
@@ -142,11 +148,12 @@ func TestChatHandler_StreamChat(t *testing.T) {
 	assert.Equal(t, 200, w.Code)
 	assert.Contains(t, w.Body.String(), "Hello")
 
-	// Usage should be tracked by Handler -> UsageService
-	// Wait, ChatHandler calls usageSvc.TrackChatMessage?
-	// Let's verify usage record created
+	// Usage should be tracked by ChatService -> UsageService with the provider's token counts
 	time.Sleep(100 * time.Millisecond) // async tracking?
-	var count int64
-	db.Model(&models.UsageRecord{}).Where("user_id = ? AND type = ?", userID, "chat_message").Count(&count)
-	assert.Equal(t, int64(1), count)
+	var record models.UsageRecord
+	err = db.Where("user_id = ? AND type = ?", userID, models.UsageTypeChatTokens).First(&record).Error
+	assert.NoError(t, err)
+	assert.Equal(t, 1230.0, record.Quantity)
+	assert.Equal(t, "gpt-3.5-turbo", record.Model)
+	assert.InDelta(t, configs.CalculateChatTokenCost("gpt-3.5-turbo", 1200, 30), record.Cost, 0.000001)
 }
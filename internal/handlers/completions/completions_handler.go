@@ -0,0 +1,263 @@
+package completions
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/souravsspace/texly.chat/configs"
+	"github.com/souravsspace/texly.chat/internal/models"
+	botRepo "github.com/souravsspace/texly.chat/internal/repo/bot"
+	userRepo "github.com/souravsspace/texly.chat/internal/repo/user"
+	"github.com/souravsspace/texly.chat/internal/services/chat"
+)
+
+/*
+ * CompletionsHandler serves bots through an OpenAI-compatible chat completions API
+ */
+type CompletionsHandler struct {
+	botRepo     *botRepo.BotRepo
+	chatService *chat.ChatService
+	userRepo    *userRepo.UserRepo
+}
+
+/*
+ * NewCompletionsHandler creates a new completions handler instance
+ */
+func NewCompletionsHandler(
+	botRepo *botRepo.BotRepo,
+	chatService *chat.ChatService,
+	userRepo *userRepo.UserRepo,
+) *CompletionsHandler {
+	return &CompletionsHandler{
+		botRepo:     botRepo,
+		chatService: chatService,
+		userRepo:    userRepo,
+	}
+}
+
+/*
+ * ListModels handles GET /v1/models
+ * Lists the bot the API key grants access to
+ */
+func (h *CompletionsHandler) ListModels(c *gin.Context) {
+	bot := h.resolveBot(c, c.GetString("api_key_bot_id"))
+	if bot == nil {
+		return
+	}
+
+	c.JSON(http.StatusOK, ModelList{
+		Object: "list",
+		Data: []Model{{
+			ID:      bot.ID,
+			Object:  "model",
+			Created: bot.CreatedAt.Unix(),
+			OwnedBy: "texly",
+		}},
+	})
+}
+
+/*
+ * ChatCompletions handles POST /v1/chat/completions
+ * The request's model is the bot ID; the last message must come from the user
+ */
+func (h *CompletionsHandler) ChatCompletions(c *gin.Context) {
+	var req ChatCompletionRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		writeError(c, http.StatusBadRequest, "Invalid request: "+err.Error(), "invalid_request")
+		return
+	}
+
+	bot := h.resolveBot(c, req.Model)
+	if bot == nil {
+		return
+	}
+
+	// Validate chat service is available
+	if h.chatService == nil {
+		writeError(c, http.StatusServiceUnavailable, "Chat service not available", "service_unavailable")
+		return
+	}
+
+	// Split the conversation into history and the new question
+	last := req.Messages[len(req.Messages)-1]
+	if last.Role != "user" || strings.TrimSpace(string(last.Content)) == "" {
+		writeError(c, http.StatusBadRequest, "The last message must be a non-empty user message", "invalid_messages")
+		return
+	}
+	history := toHistory(req.Messages[:len(req.Messages)-1])
+
+	settings := h.chatService.ResolveSettings(bot, h.ownerTierLimits(bot.UserID))
+	eventChan, errChan := h.chatService.StreamChatWithHistory(
+		c.Request.Context(),
+		bot,
+		settings,
+		history,
+		string(last.Content),
+	)
+
+	completionID := "chatcmpl-" + uuid.New().String()
+	created := time.Now().Unix()
+
+	if req.Stream {
+		h.streamResponse(c, completionID, created, bot.ID, eventChan, errChan)
+		return
+	}
+
+	// Collect the full answer for a non-streaming response
+	var content strings.Builder
+	var usage ChatCompletionUsage
+	for event := range eventChan {
+		switch {
+		case event.Type == "token":
+			content.WriteString(event.Content)
+		case event.Type == "usage" && event.Usage != nil:
+			usage = ChatCompletionUsage(*event.Usage)
+		}
+	}
+	if err := <-errChan; err != nil {
+		writeError(c, http.StatusInternalServerError, err.Error(), "generation_failed")
+		return
+	}
+
+	c.JSON(http.StatusOK, ChatCompletion{
+		ID:      completionID,
+		Object:  "chat.completion",
+		Created: created,
+		Model:   bot.ID,
+		Choices: []ChatCompletionChoice{{
+			Index:        0,
+			Message:      ChatCompletionMessage{Role: "assistant", Content: messageContent(content.String())},
+			FinishReason: "stop",
+		}},
+		Usage: usage,
+	})
+}
+
+/*
+ * streamResponse writes tokens as OpenAI chat.completion.chunk SSE events followed by [DONE]
+ */
+func (h *CompletionsHandler) streamResponse(
+	c *gin.Context,
+	completionID string,
+	created int64,
+	model string,
+	eventChan <-chan models.ChatTokenResponse,
+	errChan <-chan error,
+) {
+	c.Writer.Header().Set("Content-Type", "text/event-stream")
+	c.Writer.Header().Set("Cache-Control", "no-cache")
+	c.Writer.Header().Set("Connection", "keep-alive")
+	c.Writer.Header().Set("X-Accel-Buffering", "no")
+
+	flusher, ok := c.Writer.(http.Flusher)
+	if !ok {
+		writeError(c, http.StatusInternalServerError, "Streaming not supported", "streaming_unsupported")
+		return
+	}
+
+	writeChunk := func(delta chunkDelta, finishReason *string) {
+		chunk := ChatCompletionChunk{
+			ID:      completionID,
+			Object:  "chat.completion.chunk",
+			Created: created,
+			Model:   model,
+			Choices: []ChatCompletionChunkChoice{{Index: 0, Delta: delta, FinishReason: finishReason}},
+		}
+		data, _ := json.Marshal(chunk)
+		fmt.Fprintf(c.Writer, "data: %s\n\n", data)
+		flusher.Flush()
+	}
+
+	// The first chunk announces the assistant role
+	writeChunk(chunkDelta{Role: "assistant"}, nil)
+
+	// Only answer tokens are forwarded; sources and tool progress have no OpenAI equivalent
+	for event := range eventChan {
+		if event.Type == "token" {
+			writeChunk(chunkDelta{Content: event.Content}, nil)
+		}
+	}
+
+	if err := <-errChan; err != nil {
+		data, _ := json.Marshal(gin.H{"error": gin.H{"message": err.Error(), "type": "server_error", "code": "generation_failed"}})
+		fmt.Fprintf(c.Writer, "data: %s\n\n", data)
+		flusher.Flush()
+		return
+	}
+
+	stop := "stop"
+	writeChunk(chunkDelta{}, &stop)
+	fmt.Fprint(c.Writer, "data: [DONE]\n\n")
+	flusher.Flush()
+}
+
+/*
+ * resolveBot loads the requested bot, enforcing the API key's bot scope
+ * Writes an OpenAI-style error and returns nil on failure
+ */
+func (h *CompletionsHandler) resolveBot(c *gin.Context, model string) *models.Bot {
+	keyBotID := c.GetString("api_key_bot_id")
+	if model == "" || model != keyBotID {
+		writeError(c, http.StatusNotFound, fmt.Sprintf("The model '%s' does not exist or you do not have access to it.", model), "model_not_found")
+		return nil
+	}
+
+	bot, err := h.botRepo.GetByID(model, c.GetString("user_id"))
+	if err != nil {
+		writeError(c, http.StatusInternalServerError, "Failed to fetch bot", "internal_error")
+		return nil
+	}
+	if bot == nil {
+		writeError(c, http.StatusNotFound, fmt.Sprintf("The model '%s' does not exist or you do not have access to it.", model), "model_not_found")
+		return nil
+	}
+
+	return bot
+}
+
+/*
+ * ownerTierLimits returns the tier limits of the bot owner, defaulting to Free
+ */
+func (h *CompletionsHandler) ownerTierLimits(ownerID string) configs.TierLimits {
+	if h.userRepo == nil {
+		return configs.GetTierLimits(configs.TierFree)
+	}
+	user, err := h.userRepo.GetByID(ownerID)
+	if err != nil || user == nil {
+		return configs.GetTierLimits(configs.TierFree)
+	}
+	return configs.GetTierLimits(user.Tier)
+}
+
+/*
+ * toHistory converts prior OpenAI messages to conversation history
+ * Client system messages are dropped; the bot's own system prompt applies
+ */
+func toHistory(messages []ChatCompletionMessage) []models.Message {
+	history := make([]models.Message, 0, len(messages))
+	for _, msg := range messages {
+		if msg.Role != "user" && msg.Role != "assistant" {
+			continue
+		}
+		history = append(history, models.Message{Role: msg.Role, Content: string(msg.Content)})
+	}
+	return history
+}
+
+/*
+ * writeError responds with an error in the OpenAI error format
+ */
+func writeError(c *gin.Context, status int, message string, code string) {
+	c.AbortWithStatusJSON(status, gin.H{
+		"error": gin.H{
+			"message": message,
+			"type":    "invalid_request_error",
+			"code":    code,
+		},
+	})
+}
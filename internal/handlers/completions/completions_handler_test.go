@@ -0,0 +1,162 @@
+package completions_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/souravsspace/texly.chat/internal/handlers/completions"
+	"github.com/souravsspace/texly.chat/internal/models"
+	botRepo "github.com/souravsspace/texly.chat/internal/repo/bot"
+	chatSvc "github.com/souravsspace/texly.chat/internal/services/chat"
+	"github.com/souravsspace/texly.chat/internal/services/llm"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+)
+
+func setupRouter(t *testing.T, fake *llm.FakeProvider) (*gin.Engine, *models.Bot) {
+	db, err := gorm.Open(sqlite.Open(":memory:"), &gorm.Config{})
+	require.NoError(t, err)
+	db.AutoMigrate(&models.Bot{})
+
+	bot := &models.Bot{UserID: "owner-id", Name: "Support Bot", SystemPrompt: "You are helpful"}
+	require.NoError(t, db.Create(bot).Error)
+
+	// Chat service without retrieval, answering from the fake provider
+	chatService := chatSvc.NewChatService(nil, nil, nil, "gpt-4o-mini", 0.7, 5, "test-key", 2000)
+	chatService.RegisterProvider(llm.DefaultProvider, fake)
+
+	handler := completions.NewCompletionsHandler(botRepo.NewBotRepo(db, nil), chatService, nil)
+
+	r := gin.New()
+	// Mock API key middleware
+	r.Use(func(c *gin.Context) {
+		c.Set("user_id", "owner-id")
+		c.Set("api_key_bot_id", bot.ID)
+		c.Next()
+	})
+	r.GET("/v1/models", handler.ListModels)
+	r.POST("/v1/chat/completions", handler.ChatCompletions)
+
+	return r, bot
+}
+
+func postCompletion(r *gin.Engine, body map[string]interface{}) *httptest.ResponseRecorder {
+	jsonValue, _ := json.Marshal(body)
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest("POST", "/v1/chat/completions", bytes.NewBuffer(jsonValue))
+	req.Header.Set("Content-Type", "application/json")
+	r.ServeHTTP(w, req)
+	return w
+}
+
+func TestChatCompletions_NonStreaming(t *testing.T) {
+	fake := llm.NewFakeProvider("Orders ship in two days.")
+	fake.Usage = &llm.Usage{PromptTokens: 42, CompletionTokens: 6}
+	r, bot := setupRouter(t, fake)
+
+	w := postCompletion(r, map[string]interface{}{
+		"model": bot.ID,
+		"messages": []map[string]interface{}{
+			{"role": "system", "content": "Ignored client prompt"},
+			{"role": "user", "content": "Do you ship abroad?"},
+			{"role": "assistant", "content": "Yes, worldwide."},
+			{"role": "user", "content": []map[string]string{{"type": "text", "text": "How fast?"}}},
+		},
+	})
+
+	require.Equal(t, http.StatusOK, w.Code)
+
+	var completion completions.ChatCompletion
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &completion))
+	assert.Equal(t, "chat.completion", completion.Object)
+	assert.Equal(t, bot.ID, completion.Model)
+	require.Len(t, completion.Choices, 1)
+	assert.Equal(t, "assistant", completion.Choices[0].Message.Role)
+	assert.Equal(t, "Orders ship in two days.", string(completion.Choices[0].Message.Content))
+	assert.Equal(t, "stop", completion.Choices[0].FinishReason)
+	assert.Equal(t, completions.ChatCompletionUsage{PromptTokens: 42, CompletionTokens: 6, TotalTokens: 48}, completion.Usage)
+
+	// Bot prompt + history (without the client system message) + question
+	sent := fake.LastRequest().Messages
+	require.Len(t, sent, 4)
+	assert.Equal(t, "You are helpful", sent[0].Content)
+	assert.Equal(t, "Do you ship abroad?", sent[1].Content)
+	assert.Equal(t, "How fast?", sent[3].Content)
+}
+
+func TestChatCompletions_Streaming(t *testing.T) {
+	fake := llm.NewFakeProvider("Hello there")
+	r, bot := setupRouter(t, fake)
+
+	w := postCompletion(r, map[string]interface{}{
+		"model":    bot.ID,
+		"stream":   true,
+		"messages": []map[string]interface{}{{"role": "user", "content": "Hi"}},
+	})
+
+	require.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, "text/event-stream", w.Header().Get("Content-Type"))
+
+	var content strings.Builder
+	var finishReason string
+	events := strings.Split(strings.TrimSpace(w.Body.String()), "\n\n")
+	require.Equal(t, "data: [DONE]", events[len(events)-1])
+
+	for _, event := range events[:len(events)-1] {
+		var chunk completions.ChatCompletionChunk
+		require.NoError(t, json.Unmarshal([]byte(strings.TrimPrefix(event, "data: ")), &chunk))
+		assert.Equal(t, "chat.completion.chunk", chunk.Object)
+		content.WriteString(chunk.Choices[0].Delta.Content)
+		if chunk.Choices[0].FinishReason != nil {
+			finishReason = *chunk.Choices[0].FinishReason
+		}
+	}
+
+	assert.Equal(t, "Hello there", content.String())
+	assert.Equal(t, "stop", finishReason)
+}
+
+func TestChatCompletions_UnknownModel(t *testing.T) {
+	r, _ := setupRouter(t, llm.NewFakeProvider("unused"))
+
+	w := postCompletion(r, map[string]interface{}{
+		"model":    "some-other-bot",
+		"messages": []map[string]interface{}{{"role": "user", "content": "Hi"}},
+	})
+
+	assert.Equal(t, http.StatusNotFound, w.Code)
+	assert.Contains(t, w.Body.String(), "model_not_found")
+}
+
+func TestChatCompletions_LastMessageMustBeUser(t *testing.T) {
+	r, bot := setupRouter(t, llm.NewFakeProvider("unused"))
+
+	w := postCompletion(r, map[string]interface{}{
+		"model":    bot.ID,
+		"messages": []map[string]interface{}{{"role": "assistant", "content": "Hi"}},
+	})
+
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+}
+
+func TestListModels(t *testing.T) {
+	r, bot := setupRouter(t, llm.NewFakeProvider("unused"))
+
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest("GET", "/v1/models", nil)
+	r.ServeHTTP(w, req)
+
+	require.Equal(t, http.StatusOK, w.Code)
+
+	var list completions.ModelList
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &list))
+	require.Len(t, list.Data, 1)
+	assert.Equal(t, bot.ID, list.Data[0].ID)
+}
@@ -0,0 +1,133 @@
+package completions
+
+import (
+	"encoding/json"
+	"errors"
+	"strings"
+)
+
+/*
+ * ChatCompletionRequest is the subset of the OpenAI chat completions request we support
+ * Generation settings come from the bot; sampling parameters in the request are ignored
+ */
+type ChatCompletionRequest struct {
+	Model    string                  `json:"model" binding:"required"` // Bot ID
+	Messages []ChatCompletionMessage `json:"messages" binding:"required,min=1"`
+	Stream   bool                    `json:"stream"`
+}
+
+/*
+ * ChatCompletionMessage is a single message in OpenAI format
+ */
+type ChatCompletionMessage struct {
+	Role    string         `json:"role"`
+	Content messageContent `json:"content"`
+}
+
+/*
+ * messageContent accepts both plain string content and arrays of text parts
+ */
+type messageContent string
+
+func (m *messageContent) UnmarshalJSON(data []byte) error {
+	var text string
+	if err := json.Unmarshal(data, &text); err == nil {
+		*m = messageContent(text)
+		return nil
+	}
+
+	var parts []struct {
+		Type string `json:"type"`
+		Text string `json:"text"`
+	}
+	if err := json.Unmarshal(data, &parts); err != nil {
+		if string(data) == "null" {
+			*m = ""
+			return nil
+		}
+		return errors.New("content must be a string or an array of text parts")
+	}
+
+	var builder strings.Builder
+	for _, part := range parts {
+		if part.Type == "text" {
+			builder.WriteString(part.Text)
+		}
+	}
+	*m = messageContent(builder.String())
+	return nil
+}
+
+/*
+ * ChatCompletion is a non-streaming response
+ */
+type ChatCompletion struct {
+	ID      string                 `json:"id"`
+	Object  string                 `json:"object"` // "chat.completion"
+	Created int64                  `json:"created"`
+	Model   string                 `json:"model"`
+	Choices []ChatCompletionChoice `json:"choices"`
+	Usage   ChatCompletionUsage    `json:"usage"`
+}
+
+/*
+ * ChatCompletionUsage reports the tokens billed for a non-streaming response
+ * Zero when the answer was replayed from the cache or no model was called
+ */
+type ChatCompletionUsage struct {
+	PromptTokens     int `json:"prompt_tokens"`
+	CompletionTokens int `json:"completion_tokens"`
+	TotalTokens      int `json:"total_tokens"`
+}
+
+/*
+ * ChatCompletionChoice is a choice in a non-streaming response
+ */
+type ChatCompletionChoice struct {
+	Index        int                   `json:"index"`
+	Message      ChatCompletionMessage `json:"message"`
+	FinishReason string                `json:"finish_reason"`
+}
+
+/*
+ * ChatCompletionChunk is a streamed response chunk
+ */
+type ChatCompletionChunk struct {
+	ID      string                      `json:"id"`
+	Object  string                      `json:"object"` // "chat.completion.chunk"
+	Created int64                       `json:"created"`
+	Model   string                      `json:"model"`
+	Choices []ChatCompletionChunkChoice `json:"choices"`
+}
+
+/*
+ * ChatCompletionChunkChoice is a choice in a streamed chunk
+ */
+type ChatCompletionChunkChoice struct {
+	Index        int        `json:"index"`
+	Delta        chunkDelta `json:"delta"`
+	FinishReason *string    `json:"finish_reason"`
+}
+
+type chunkDelta struct {
+	Role    string `json:"role,omitempty"`
+	Content string `json:"content,omitempty"`
+}
+
+/*
+ * ModelList is the response of GET /v1/models
+ */
+type ModelList struct {
+	Object string  `json:"object"` // "list"
+	Data   []Model `json:"data"`
+}
+
+/*
+ * Model describes a bot in OpenAI model format
+ */
+type Model struct {
+	ID      string `json:"id"`
+	Object  string `json:"object"` // "model"
+	Created int64  `json:"created"`
+	OwnedBy string `json:"owned_by"`
+}
@@ -0,0 +1,116 @@
+package feedback
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/souravsspace/texly.chat/internal/models"
+	botRepo "github.com/souravsspace/texly.chat/internal/repo/bot"
+	feedbackSvc "github.com/souravsspace/texly.chat/internal/services/feedback"
+	"github.com/souravsspace/texly.chat/internal/services/session"
+)
+
+/*
+ * FeedbackHandler handles ratings of assistant messages from visitors and owners
+ */
+type FeedbackHandler struct {
+	feedbackService *feedbackSvc.FeedbackService
+	sessionService  *session.SessionService
+	botRepo         *botRepo.BotRepo
+}
+
+/*
+ * NewFeedbackHandler creates a new feedback handler instance
+ */
+func NewFeedbackHandler(
+	feedbackService *feedbackSvc.FeedbackService,
+	sessionService *session.SessionService,
+	botRepo *botRepo.BotRepo,
+) *FeedbackHandler {
+	return &FeedbackHandler{
+		feedbackService: feedbackService,
+		sessionService:  sessionService,
+		botRepo:         botRepo,
+	}
+}
+
+/*
+ * SubmitPublicFeedback handles POST /api/public/chats/:session_id/messages/:message_id/feedback
+ * Lets a widget visitor rate an answer from their own session
+ */
+func (h *FeedbackHandler) SubmitPublicFeedback(c *gin.Context) {
+	chatSession, err := h.sessionService.GetSession(c.Param("session_id"))
+	if err != nil {
+		if err == session.ErrSessionExpired {
+			c.JSON(http.StatusUnauthorized, gin.H{"message": "Session has expired"})
+			return
+		}
+		c.JSON(http.StatusNotFound, gin.H{"message": "Session not found"})
+		return
+	}
+
+	var req models.MessageFeedbackRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"message": "Invalid request: " + err.Error()})
+		return
+	}
+
+	feedback, err := h.feedbackService.SubmitVisitorFeedback(c.Request.Context(), chatSession.ID, c.Param("message_id"), req)
+	if err != nil {
+		writeError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, feedback)
+}
+
+/*
+ * SubmitFeedback handles POST /api/bots/:id/messages/:message_id/feedback
+ * Lets the bot owner rate an answer from the dashboard
+ */
+func (h *FeedbackHandler) SubmitFeedback(c *gin.Context) {
+	userID := c.GetString("user_id")
+	if userID == "" {
+		c.JSON(http.StatusUnauthorized, gin.H{"message": "Unauthorized"})
+		return
+	}
+
+	bot, err := h.botRepo.GetByID(c.Param("id"), userID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"message": "Failed to fetch bot"})
+		return
+	}
+	if bot == nil {
+		c.JSON(http.StatusNotFound, gin.H{"message": "Bot not found"})
+		return
+	}
+
+	var req models.MessageFeedbackRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"message": err.Error()})
+		return
+	}
+
+	feedback, err := h.feedbackService.SubmitOwnerFeedback(c.Request.Context(), bot.ID, userID, c.Param("message_id"), req)
+	if err != nil {
+		writeError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, feedback)
+}
+
+/*
+ * writeError maps feedback service errors to HTTP responses
+ */
+func writeError(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, feedbackSvc.ErrMessageNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"message": "Message not found"})
+	case errors.Is(err, feedbackSvc.ErrNotRatable):
+		c.JSON(http.StatusBadRequest, gin.H{"message": err.Error()})
+	default:
+		c.JSON(http.StatusInternalServerError, gin.H{"message": "Failed to save feedback"})
+	}
+}
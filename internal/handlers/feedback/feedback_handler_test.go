@@ -0,0 +1,128 @@
+package feedback_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/souravsspace/texly.chat/internal/handlers/feedback"
+	"github.com/souravsspace/texly.chat/internal/models"
+	botRepo "github.com/souravsspace/texly.chat/internal/repo/bot"
+	feedbackRepo "github.com/souravsspace/texly.chat/internal/repo/feedback"
+	messageRepo "github.com/souravsspace/texly.chat/internal/repo/message"
+	feedbackSvc "github.com/souravsspace/texly.chat/internal/services/feedback"
+	"github.com/souravsspace/texly.chat/internal/services/session"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+)
+
+func setupRouter(t *testing.T) (*gin.Engine, *gorm.DB, *session.SessionService) {
+	db, err := gorm.Open(sqlite.Open(":memory:"), &gorm.Config{})
+	require.NoError(t, err)
+	db.AutoMigrate(&models.Bot{}, &models.Message{}, &models.MessageFeedback{})
+
+	sessionService := session.NewSessionService()
+	service := feedbackSvc.NewFeedbackService(feedbackRepo.New(db), messageRepo.New(db))
+	handler := feedback.NewFeedbackHandler(service, sessionService, botRepo.NewBotRepo(db, nil))
+
+	r := gin.New()
+	r.POST("/api/public/chats/:session_id/messages/:message_id/feedback", handler.SubmitPublicFeedback)
+	// Mock Auth middleware by setting user_id in context
+	authed := r.Group("/", func(c *gin.Context) {
+		c.Set("user_id", "test-user-id")
+		c.Next()
+	})
+	authed.POST("/api/bots/:id/messages/:message_id/feedback", handler.SubmitFeedback)
+
+	return r, db, sessionService
+}
+
+func postFeedback(r *gin.Engine, url string, body models.MessageFeedbackRequest) *httptest.ResponseRecorder {
+	jsonValue, _ := json.Marshal(body)
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest("POST", url, bytes.NewBuffer(jsonValue))
+	req.Header.Set("Content-Type", "application/json")
+	r.ServeHTTP(w, req)
+	return w
+}
+
+func TestSubmitPublicFeedback(t *testing.T) {
+	r, db, sessionService := setupRouter(t)
+
+	bot := models.Bot{UserID: "test-user-id", Name: "Support Bot"}
+	db.Create(&bot)
+	chatSession := sessionService.CreateSession(bot.ID)
+	question := models.Message{SessionID: chatSession.ID, BotID: bot.ID, Role: "user", Content: "Do you ship abroad?"}
+	answer := models.Message{SessionID: chatSession.ID, BotID: bot.ID, Role: "assistant", Content: "No."}
+	db.Create(&question)
+	db.Create(&answer)
+
+	url := "/api/public/chats/" + chatSession.ID + "/messages/" + answer.ID + "/feedback"
+	w := postFeedback(r, url, models.MessageFeedbackRequest{Rating: "up"})
+	require.Equal(t, http.StatusOK, w.Code)
+
+	// Rating again replaces the earlier rating
+	w = postFeedback(r, url, models.MessageFeedbackRequest{Rating: "down", Comment: "We do ship to Canada"})
+	require.Equal(t, http.StatusOK, w.Code)
+
+	var stored []models.MessageFeedback
+	db.Find(&stored)
+	require.Len(t, stored, 1)
+	assert.Equal(t, models.FeedbackRatingDown, stored[0].Rating)
+	assert.Equal(t, models.FeedbackSourceVisitor, stored[0].Source)
+	assert.Equal(t, "We do ship to Canada", stored[0].Comment)
+	assert.Equal(t, bot.ID, stored[0].BotID)
+
+	var response models.MessageFeedback
+	json.Unmarshal(w.Body.Bytes(), &response)
+	assert.Equal(t, stored[0].ID, response.ID)
+
+	// Questions cannot be rated
+	w = postFeedback(r, "/api/public/chats/"+chatSession.ID+"/messages/"+question.ID+"/feedback", models.MessageFeedbackRequest{Rating: "up"})
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+
+	// Invalid rating
+	w = postFeedback(r, url, models.MessageFeedbackRequest{Rating: "meh"})
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+}
+
+func TestSubmitPublicFeedback_OtherSessionsMessage(t *testing.T) {
+	r, db, sessionService := setupRouter(t)
+
+	bot := models.Bot{UserID: "test-user-id", Name: "Support Bot"}
+	db.Create(&bot)
+	chatSession := sessionService.CreateSession(bot.ID)
+	answer := models.Message{SessionID: "another-session", BotID: bot.ID, Role: "assistant", Content: "Hello"}
+	db.Create(&answer)
+
+	w := postFeedback(r, "/api/public/chats/"+chatSession.ID+"/messages/"+answer.ID+"/feedback", models.MessageFeedbackRequest{Rating: "up"})
+	assert.Equal(t, http.StatusNotFound, w.Code)
+}
+
+func TestSubmitFeedback_Owner(t *testing.T) {
+	r, db, _ := setupRouter(t)
+
+	bot := models.Bot{UserID: "test-user-id", Name: "Support Bot"}
+	otherBot := models.Bot{UserID: "other-user", Name: "Not Mine"}
+	db.Create(&bot)
+	db.Create(&otherBot)
+	answer := models.Message{SessionID: "dashboard-session", BotID: bot.ID, Role: "assistant", Content: "Hello"}
+	db.Create(&answer)
+
+	w := postFeedback(r, "/api/bots/"+bot.ID+"/messages/"+answer.ID+"/feedback", models.MessageFeedbackRequest{Rating: "up"})
+	require.Equal(t, http.StatusOK, w.Code)
+
+	var stored models.MessageFeedback
+	db.First(&stored)
+	assert.Equal(t, models.FeedbackSourceOwner, stored.Source)
+	assert.Equal(t, "test-user-id", *stored.UserID)
+
+	// Not the owner of the bot
+	w = postFeedback(r, "/api/bots/"+otherBot.ID+"/messages/"+answer.ID+"/feedback", models.MessageFeedbackRequest{Rating: "up"})
+	assert.Equal(t, http.StatusNotFound, w.Code)
+}
@@ -0,0 +1,140 @@
+package golden
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/souravsspace/texly.chat/internal/models"
+	botRepo "github.com/souravsspace/texly.chat/internal/repo/bot"
+	goldenRepo "github.com/souravsspace/texly.chat/internal/repo/golden"
+	"gorm.io/gorm"
+)
+
+/*
+ * GoldenHandler handles HTTP requests for the golden question sets used to evaluate bots
+ */
+type GoldenHandler struct {
+	repo    *goldenRepo.GoldenRepository
+	botRepo *botRepo.BotRepo
+}
+
+/*
+ * NewGoldenHandler creates a new golden set handler instance
+ */
+func NewGoldenHandler(repo *goldenRepo.GoldenRepository, botRepo *botRepo.BotRepo) *GoldenHandler {
+	return &GoldenHandler{
+		repo:    repo,
+		botRepo: botRepo,
+	}
+}
+
+/*
+ * CreateSet handles POST /api/bots/:id/golden-sets
+ */
+func (h *GoldenHandler) CreateSet(c *gin.Context) {
+	bot, ok := h.authorizeBot(c)
+	if !ok {
+		return
+	}
+
+	var req models.CreateGoldenSetRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"message": err.Error()})
+		return
+	}
+
+	set := models.NewGoldenSet(bot.ID, req)
+	if err := h.repo.Create(c.Request.Context(), set); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"message": "Failed to create golden set"})
+		return
+	}
+
+	c.JSON(http.StatusCreated, set)
+}
+
+/*
+ * ListSets handles GET /api/bots/:id/golden-sets
+ * Returns the sets without their questions
+ */
+func (h *GoldenHandler) ListSets(c *gin.Context) {
+	bot, ok := h.authorizeBot(c)
+	if !ok {
+		return
+	}
+
+	sets, err := h.repo.ListByBotID(c.Request.Context(), bot.ID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"message": "Failed to fetch golden sets"})
+		return
+	}
+
+	c.JSON(http.StatusOK, sets)
+}
+
+/*
+ * GetSet handles GET /api/bots/:id/golden-sets/:setId
+ */
+func (h *GoldenHandler) GetSet(c *gin.Context) {
+	bot, ok := h.authorizeBot(c)
+	if !ok {
+		return
+	}
+
+	set, err := h.repo.GetByID(c.Request.Context(), c.Param("setId"), bot.ID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"message": "Failed to fetch golden set"})
+		return
+	}
+	if set == nil {
+		c.JSON(http.StatusNotFound, gin.H{"message": "Golden set not found"})
+		return
+	}
+
+	c.JSON(http.StatusOK, set)
+}
+
+/*
+ * DeleteSet handles DELETE /api/bots/:id/golden-sets/:setId
+ */
+func (h *GoldenHandler) DeleteSet(c *gin.Context) {
+	bot, ok := h.authorizeBot(c)
+	if !ok {
+		return
+	}
+
+	if err := h.repo.Delete(c.Request.Context(), c.Param("setId"), bot.ID); err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{"message": "Golden set not found"})
+		} else {
+			c.JSON(http.StatusInternalServerError, gin.H{"message": "Failed to delete golden set"})
+		}
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Golden set deleted successfully"})
+}
+
+/*
+ * authorizeBot loads the bot in the URL if the authenticated user owns it
+ * Writes the error response and returns false on failure
+ */
+func (h *GoldenHandler) authorizeBot(c *gin.Context) (*models.Bot, bool) {
+	userID := c.GetString("user_id")
+	if userID == "" {
+		c.JSON(http.StatusUnauthorized, gin.H{"message": "Unauthorized"})
+		return nil, false
+	}
+
+	bot, err := h.botRepo.GetByID(c.Param("id"), userID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"message": "Failed to fetch bot"})
+		return nil, false
+	}
+	if bot == nil {
+		c.JSON(http.StatusNotFound, gin.H{"message": "Bot not found"})
+		return nil, false
+	}
+
+	return bot, true
+}
@@ -0,0 +1,103 @@
+package golden_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+
+	"github.com/souravsspace/texly.chat/internal/handlers/golden"
+	"github.com/souravsspace/texly.chat/internal/models"
+	botRepo "github.com/souravsspace/texly.chat/internal/repo/bot"
+	goldenRepo "github.com/souravsspace/texly.chat/internal/repo/golden"
+)
+
+func setupRouter(t *testing.T) (*gin.Engine, *models.Bot) {
+	gin.SetMode(gin.TestMode)
+
+	db, err := gorm.Open(sqlite.Open(":memory:"), &gorm.Config{})
+	require.NoError(t, err)
+	require.NoError(t, db.AutoMigrate(&models.Bot{}, &models.GoldenSet{}, &models.GoldenQuestion{}))
+
+	bots := botRepo.NewBotRepo(db, nil)
+	bot := &models.Bot{UserID: "test-user-id", Name: "Support"}
+	require.NoError(t, bots.Create(bot))
+	handler := golden.NewGoldenHandler(goldenRepo.New(db), bots)
+
+	r := gin.New()
+	r.Use(func(c *gin.Context) {
+		c.Set("user_id", "test-user-id")
+		c.Next()
+	})
+	r.POST("/api/bots/:id/golden-sets", handler.CreateSet)
+	r.GET("/api/bots/:id/golden-sets", handler.ListSets)
+	r.GET("/api/bots/:id/golden-sets/:setId", handler.GetSet)
+	r.DELETE("/api/bots/:id/golden-sets/:setId", handler.DeleteSet)
+	return r, bot
+}
+
+func doJSON(r *gin.Engine, method string, path string, body interface{}, out interface{}) int {
+	var payload []byte
+	if body != nil {
+		payload, _ = json.Marshal(body)
+	}
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest(method, path, bytes.NewBuffer(payload))
+	req.Header.Set("Content-Type", "application/json")
+	r.ServeHTTP(w, req)
+	if out != nil {
+		_ = json.Unmarshal(w.Body.Bytes(), out)
+	}
+	return w.Code
+}
+
+func TestGoldenSetLifecycle(t *testing.T) {
+	r, bot := setupRouter(t)
+	base := "/api/bots/" + bot.ID + "/golden-sets"
+
+	req := models.CreateGoldenSetRequest{
+		Name: "Support FAQ",
+		Questions: []models.GoldenQuestionRequest{
+			{Question: "How long do refunds take?", ExpectedSourceIDs: []string{"source-1"}, ReferenceAnswer: "Five days."},
+			{Question: "Do you ship abroad?", ReferenceAnswer: "Only to the EU."},
+			{Question: "When are you open?", ExpectedSourceIDs: []string{"source-2", "source-3"}},
+		},
+	}
+	var created models.GoldenSet
+	require.Equal(t, http.StatusCreated, doJSON(r, "POST", base, req, &created))
+	require.NotEmpty(t, created.ID)
+
+	var sets []models.GoldenSet
+	require.Equal(t, http.StatusOK, doJSON(r, "GET", base, nil, &sets))
+	require.Len(t, sets, 1)
+	assert.Empty(t, sets[0].Questions)
+
+	// Questions come back in the order they were given
+	var set models.GoldenSet
+	require.Equal(t, http.StatusOK, doJSON(r, "GET", base+"/"+created.ID, nil, &set))
+	require.Len(t, set.Questions, 3)
+	assert.Equal(t, "How long do refunds take?", set.Questions[0].Question)
+	assert.Equal(t, `["source-1"]`, set.Questions[0].ExpectedSourceIDs)
+	assert.Equal(t, `[]`, set.Questions[1].ExpectedSourceIDs)
+	assert.Equal(t, "When are you open?", set.Questions[2].Question)
+
+	require.Equal(t, http.StatusOK, doJSON(r, "DELETE", base+"/"+created.ID, nil, nil))
+	assert.Equal(t, http.StatusNotFound, doJSON(r, "GET", base+"/"+created.ID, nil, nil))
+	assert.Equal(t, http.StatusNotFound, doJSON(r, "DELETE", base+"/"+created.ID, nil, nil))
+}
+
+func TestGoldenSetValidation(t *testing.T) {
+	r, bot := setupRouter(t)
+
+	missing := models.CreateGoldenSetRequest{Name: "Empty question", Questions: []models.GoldenQuestionRequest{{}}}
+	assert.Equal(t, http.StatusBadRequest, doJSON(r, "POST", "/api/bots/"+bot.ID+"/golden-sets", missing, nil))
+
+	assert.Equal(t, http.StatusNotFound, doJSON(r, "GET", "/api/bots/other-bot/golden-sets", nil, nil))
+}
@@ -0,0 +1,212 @@
+package handoff
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/souravsspace/texly.chat/internal/models"
+	botRepo "github.com/souravsspace/texly.chat/internal/repo/bot"
+	handoffSvc "github.com/souravsspace/texly.chat/internal/services/handoff"
+	"github.com/souravsspace/texly.chat/internal/services/session"
+)
+
+/*
+ * HandoffHandler handles the operator inbox for conversations handed to a human
+ */
+type HandoffHandler struct {
+	handoffService *handoffSvc.HandoffService
+	sessionService *session.SessionService
+	botRepo        *botRepo.BotRepo
+}
+
+/*
+ * NewHandoffHandler creates a new handoff handler instance
+ */
+func NewHandoffHandler(
+	handoffService *handoffSvc.HandoffService,
+	sessionService *session.SessionService,
+	botRepo *botRepo.BotRepo,
+) *HandoffHandler {
+	return &HandoffHandler{
+		handoffService: handoffService,
+		sessionService: sessionService,
+		botRepo:        botRepo,
+	}
+}
+
+/*
+ * ListHandoffs handles GET /api/bots/:id/handoffs
+ * Returns the bot's open conversations waiting for or handled by an operator
+ */
+func (h *HandoffHandler) ListHandoffs(c *gin.Context) {
+	bot, ok := h.authorizeBot(c)
+	if !ok {
+		return
+	}
+
+	c.JSON(http.StatusOK, h.handoffService.List(bot.ID))
+}
+
+/*
+ * Reply handles POST /api/bots/:id/handoffs/:session_id/messages
+ * Sends an operator reply to the visitor, taking over the conversation if needed
+ */
+func (h *HandoffHandler) Reply(c *gin.Context) {
+	bot, ok := h.authorizeBot(c)
+	if !ok {
+		return
+	}
+
+	chatSession, ok := h.botSession(c, bot)
+	if !ok {
+		return
+	}
+
+	var req models.OperatorReplyRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"message": err.Error()})
+		return
+	}
+
+	message, err := h.handoffService.Reply(c.Request.Context(), chatSession, c.GetString("user_id"), req.Content)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"message": "Failed to send reply"})
+		return
+	}
+
+	c.JSON(http.StatusCreated, message)
+}
+
+/*
+ * Typing handles POST /api/bots/:id/handoffs/:session_id/typing
+ * Shows or hides the operator typing indicator in the visitor's widget
+ */
+func (h *HandoffHandler) Typing(c *gin.Context) {
+	bot, ok := h.authorizeBot(c)
+	if !ok {
+		return
+	}
+
+	chatSession, ok := h.botSession(c, bot)
+	if !ok {
+		return
+	}
+
+	var req models.TypingRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"message": err.Error()})
+		return
+	}
+
+	h.handoffService.OperatorTyping(chatSession, req.Typing)
+
+	c.JSON(http.StatusOK, gin.H{"typing": req.Typing})
+}
+
+/*
+ * CloseHandoff handles POST /api/bots/:id/handoffs/:session_id/close
+ * Returns the conversation to the bot
+ */
+func (h *HandoffHandler) CloseHandoff(c *gin.Context) {
+	bot, ok := h.authorizeBot(c)
+	if !ok {
+		return
+	}
+
+	chatSession, ok := h.botSession(c, bot)
+	if !ok {
+		return
+	}
+
+	if err := h.handoffService.Close(chatSession); err != nil {
+		if errors.Is(err, session.ErrNotInHandoff) {
+			c.JSON(http.StatusConflict, gin.H{"message": "Conversation is not in handoff"})
+		} else {
+			c.JSON(http.StatusInternalServerError, gin.H{"message": "Failed to close handoff"})
+		}
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Handoff closed successfully"})
+}
+
+/*
+ * StreamEvents handles GET /api/bots/:id/handoffs/events
+ * Notifies operators of new handoffs and visitor messages using SSE
+ */
+func (h *HandoffHandler) StreamEvents(c *gin.Context) {
+	bot, ok := h.authorizeBot(c)
+	if !ok {
+		return
+	}
+
+	c.Writer.Header().Set("Content-Type", "text/event-stream")
+	c.Writer.Header().Set("Cache-Control", "no-cache")
+	c.Writer.Header().Set("Connection", "keep-alive")
+	c.Writer.Header().Set("X-Accel-Buffering", "no")
+
+	flusher, ok := c.Writer.(http.Flusher)
+	if !ok {
+		c.JSON(http.StatusInternalServerError, gin.H{"message": "Streaming not supported"})
+		return
+	}
+
+	events, unsubscribe := h.handoffService.SubscribeBot(bot.ID)
+	defer unsubscribe()
+
+	// Flush headers so the client knows the stream is open
+	flusher.Flush()
+
+	for {
+		select {
+		case event, ok := <-events:
+			if !ok {
+				return
+			}
+			data, _ := json.Marshal(event)
+			c.Writer.Write([]byte("data: " + string(data) + "\n\n"))
+			flusher.Flush()
+		case <-c.Request.Context().Done():
+			return
+		}
+	}
+}
+
+/*
+ * authorizeBot verifies the authenticated user owns the bot in the URL
+ * Writes the error response and returns false on failure
+ */
+func (h *HandoffHandler) authorizeBot(c *gin.Context) (*models.Bot, bool) {
+	userID := c.GetString("user_id")
+	if userID == "" {
+		c.JSON(http.StatusUnauthorized, gin.H{"message": "Unauthorized"})
+		return nil, false
+	}
+
+	bot, err := h.botRepo.GetByID(c.Param("id"), userID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"message": "Failed to fetch bot"})
+		return nil, false
+	}
+	if bot == nil {
+		c.JSON(http.StatusNotFound, gin.H{"message": "Bot not found"})
+		return nil, false
+	}
+
+	return bot, true
+}
+
+/*
+ * botSession loads the widget session in the URL, ensuring it belongs to the bot
+ * Writes the error response and returns false on failure
+ */
+func (h *HandoffHandler) botSession(c *gin.Context, bot *models.Bot) (*models.ChatSession, bool) {
+	chatSession, err := h.sessionService.GetSession(c.Param("session_id"))
+	if err != nil || chatSession.BotID != bot.ID {
+		c.JSON(http.StatusNotFound, gin.H{"message": "Conversation not found"})
+		return nil, false
+	}
+	return chatSession, true
+}
@@ -0,0 +1,140 @@
+package handoff_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/souravsspace/texly.chat/internal/handlers/handoff"
+	"github.com/souravsspace/texly.chat/internal/models"
+	botRepo "github.com/souravsspace/texly.chat/internal/repo/bot"
+	messageRepo "github.com/souravsspace/texly.chat/internal/repo/message"
+	handoffSvc "github.com/souravsspace/texly.chat/internal/services/handoff"
+	"github.com/souravsspace/texly.chat/internal/services/session"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+)
+
+func setupRouter(t *testing.T) (*gin.Engine, *gorm.DB, *session.SessionService, *handoffSvc.HandoffService) {
+	db, err := gorm.Open(sqlite.Open(":memory:"), &gorm.Config{})
+	require.NoError(t, err)
+	db.AutoMigrate(&models.Bot{}, &models.Message{})
+
+	sessionService := session.NewSessionService()
+	handoffService := handoffSvc.NewHandoffService(sessionService, messageRepo.New(db))
+	handler := handoff.NewHandoffHandler(handoffService, sessionService, botRepo.NewBotRepo(db, nil))
+
+	r := gin.New()
+	// Mock Auth middleware by setting user_id in context
+	r.Use(func(c *gin.Context) {
+		c.Set("user_id", "test-user-id")
+		c.Next()
+	})
+	r.GET("/api/bots/:id/handoffs", handler.ListHandoffs)
+	r.GET("/api/bots/:id/handoffs/events", handler.StreamEvents)
+	r.POST("/api/bots/:id/handoffs/:session_id/messages", handler.Reply)
+	r.POST("/api/bots/:id/handoffs/:session_id/typing", handler.Typing)
+	r.POST("/api/bots/:id/handoffs/:session_id/close", handler.CloseHandoff)
+
+	return r, db, sessionService, handoffService
+}
+
+func TestOperatorInbox(t *testing.T) {
+	r, db, sessionService, handoffService := setupRouter(t)
+
+	bot := models.Bot{UserID: "test-user-id", Name: "Support Bot"}
+	db.Create(&bot)
+	chatSession := sessionService.CreateSession(bot.ID)
+	require.NoError(t, handoffService.Request(chatSession, models.HandoffReasonVisitor))
+
+	// List open conversations
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest("GET", "/api/bots/"+bot.ID+"/handoffs", nil)
+	r.ServeHTTP(w, req)
+
+	require.Equal(t, http.StatusOK, w.Code)
+	var handoffs []models.ChatSession
+	json.Unmarshal(w.Body.Bytes(), &handoffs)
+	require.Len(t, handoffs, 1)
+	assert.Equal(t, chatSession.ID, handoffs[0].ID)
+	assert.Equal(t, models.HandoffReasonVisitor, handoffs[0].HandoffReason)
+
+	// Reply to the visitor
+	jsonValue, _ := json.Marshal(models.OperatorReplyRequest{Content: "Hi, how can I help?"})
+	w = httptest.NewRecorder()
+	req, _ = http.NewRequest("POST", "/api/bots/"+bot.ID+"/handoffs/"+chatSession.ID+"/messages", bytes.NewBuffer(jsonValue))
+	r.ServeHTTP(w, req)
+
+	require.Equal(t, http.StatusCreated, w.Code)
+	var stored models.Message
+	db.First(&stored, "session_id = ?", chatSession.ID)
+	assert.Equal(t, "operator", stored.Role)
+	assert.Equal(t, "test-user-id", *stored.UserID)
+
+	// Hand the conversation back to the bot
+	w = httptest.NewRecorder()
+	req, _ = http.NewRequest("POST", "/api/bots/"+bot.ID+"/handoffs/"+chatSession.ID+"/close", nil)
+	r.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.False(t, chatSession.InHandoff())
+
+	// Closing again conflicts
+	w = httptest.NewRecorder()
+	req, _ = http.NewRequest("POST", "/api/bots/"+bot.ID+"/handoffs/"+chatSession.ID+"/close", nil)
+	r.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusConflict, w.Code)
+}
+
+func TestOperatorInbox_OtherBotsSession(t *testing.T) {
+	r, db, sessionService, _ := setupRouter(t)
+
+	bot := models.Bot{UserID: "test-user-id", Name: "Support Bot"}
+	otherBot := models.Bot{UserID: "other-user", Name: "Not Mine"}
+	db.Create(&bot)
+	db.Create(&otherBot)
+	otherSession := sessionService.CreateSession(otherBot.ID)
+
+	// Session belongs to another bot
+	jsonValue, _ := json.Marshal(models.OperatorReplyRequest{Content: "Hello"})
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest("POST", "/api/bots/"+bot.ID+"/handoffs/"+otherSession.ID+"/messages", bytes.NewBuffer(jsonValue))
+	r.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusNotFound, w.Code)
+
+	// Bot belongs to another user
+	w = httptest.NewRecorder()
+	req, _ = http.NewRequest("GET", "/api/bots/"+otherBot.ID+"/handoffs", nil)
+	r.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusNotFound, w.Code)
+}
+
+func TestOperatorTyping(t *testing.T) {
+	r, db, sessionService, handoffService := setupRouter(t)
+
+	bot := models.Bot{UserID: "test-user-id", Name: "Support Bot"}
+	db.Create(&bot)
+	chatSession := sessionService.CreateSession(bot.ID)
+
+	visitorEvents, unsubscribe := handoffService.SubscribeSession(chatSession.ID)
+	defer unsubscribe()
+
+	jsonValue, _ := json.Marshal(models.TypingRequest{Typing: true})
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest("POST", "/api/bots/"+bot.ID+"/handoffs/"+chatSession.ID+"/typing", bytes.NewBuffer(jsonValue))
+	r.ServeHTTP(w, req)
+
+	require.Equal(t, http.StatusOK, w.Code)
+	event := <-visitorEvents
+	assert.Equal(t, "operator_typing", event.Type)
+	require.NotNil(t, event.Typing)
+	assert.True(t, *event.Typing)
+}
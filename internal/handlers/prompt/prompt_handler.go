@@ -0,0 +1,201 @@
+package prompt
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/souravsspace/texly.chat/internal/models"
+	botRepo "github.com/souravsspace/texly.chat/internal/repo/bot"
+	promptSvc "github.com/souravsspace/texly.chat/internal/services/prompt"
+)
+
+/*
+ * PromptHandler handles HTTP requests for system prompt versions and experiments
+ */
+type PromptHandler struct {
+	promptService *promptSvc.PromptService
+	botRepo       *botRepo.BotRepo
+}
+
+/*
+ * NewPromptHandler creates a new prompt handler instance
+ */
+func NewPromptHandler(promptService *promptSvc.PromptService, botRepo *botRepo.BotRepo) *PromptHandler {
+	return &PromptHandler{
+		promptService: promptService,
+		botRepo:       botRepo,
+	}
+}
+
+/*
+ * ListVersions handles GET /api/bots/:id/prompts
+ * Returns the bot's prompt history, newest first
+ */
+func (h *PromptHandler) ListVersions(c *gin.Context) {
+	bot, ok := h.authorizeBot(c)
+	if !ok {
+		return
+	}
+
+	versions, err := h.promptService.ListVersions(c.Request.Context(), bot.ID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"message": "Failed to fetch prompt versions"})
+		return
+	}
+
+	c.JSON(http.StatusOK, versions)
+}
+
+/*
+ * DiffVersions handles GET /api/bots/:id/prompts/diff
+ * Query params: from (required), to (default: the current version)
+ */
+func (h *PromptHandler) DiffVersions(c *gin.Context) {
+	bot, ok := h.authorizeBot(c)
+	if !ok {
+		return
+	}
+
+	from := c.Query("from")
+	to := c.DefaultQuery("to", bot.PromptVersionID)
+	if from == "" || to == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"message": "from and to versions are required"})
+		return
+	}
+
+	diff, err := h.promptService.Diff(c.Request.Context(), bot.ID, from, to)
+	if err != nil {
+		writeError(c, err, "Failed to diff prompt versions")
+		return
+	}
+
+	c.JSON(http.StatusOK, diff)
+}
+
+/*
+ * Rollback handles POST /api/bots/:id/prompts/rollback
+ * Restores an earlier prompt as a new version and returns the updated bot
+ */
+func (h *PromptHandler) Rollback(c *gin.Context) {
+	bot, ok := h.authorizeBot(c)
+	if !ok {
+		return
+	}
+
+	var req models.RollbackPromptRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"message": err.Error()})
+		return
+	}
+
+	if _, err := h.promptService.Rollback(c.Request.Context(), bot, req.VersionID, c.GetString("user_id")); err != nil {
+		writeError(c, err, "Failed to roll back prompt")
+		return
+	}
+
+	c.JSON(http.StatusOK, bot)
+}
+
+/*
+ * ListExperiments handles GET /api/bots/:id/prompts/experiments
+ */
+func (h *PromptHandler) ListExperiments(c *gin.Context) {
+	bot, ok := h.authorizeBot(c)
+	if !ok {
+		return
+	}
+
+	experiments, err := h.promptService.ListExperiments(c.Request.Context(), bot.ID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"message": "Failed to fetch experiments"})
+		return
+	}
+
+	c.JSON(http.StatusOK, experiments)
+}
+
+/*
+ * CreateExperiment handles POST /api/bots/:id/prompts/experiments
+ * Starts splitting new widget messages across prompt versions by session
+ */
+func (h *PromptHandler) CreateExperiment(c *gin.Context) {
+	bot, ok := h.authorizeBot(c)
+	if !ok {
+		return
+	}
+
+	var req models.CreatePromptExperimentRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"message": err.Error()})
+		return
+	}
+
+	experiment, err := h.promptService.CreateExperiment(c.Request.Context(), bot.ID, req)
+	if err != nil {
+		writeError(c, err, "Failed to create experiment")
+		return
+	}
+
+	c.JSON(http.StatusCreated, experiment)
+}
+
+/*
+ * StopExperiment handles POST /api/bots/:id/prompts/experiments/:experimentId/stop
+ */
+func (h *PromptHandler) StopExperiment(c *gin.Context) {
+	bot, ok := h.authorizeBot(c)
+	if !ok {
+		return
+	}
+
+	experiment, err := h.promptService.StopExperiment(c.Request.Context(), bot.ID, c.Param("experimentId"))
+	if err != nil {
+		writeError(c, err, "Failed to stop experiment")
+		return
+	}
+
+	c.JSON(http.StatusOK, experiment)
+}
+
+/*
+ * authorizeBot loads the bot in the URL if the authenticated user owns it
+ * Writes the error response and returns false on failure
+ */
+func (h *PromptHandler) authorizeBot(c *gin.Context) (*models.Bot, bool) {
+	userID := c.GetString("user_id")
+	if userID == "" {
+		c.JSON(http.StatusUnauthorized, gin.H{"message": "Unauthorized"})
+		return nil, false
+	}
+
+	bot, err := h.botRepo.GetByID(c.Param("id"), userID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"message": "Failed to fetch bot"})
+		return nil, false
+	}
+	if bot == nil {
+		c.JSON(http.StatusNotFound, gin.H{"message": "Bot not found"})
+		return nil, false
+	}
+
+	return bot, true
+}
+
+/*
+ * writeError maps prompt service errors to HTTP responses
+ */
+func writeError(c *gin.Context, err error, fallback string) {
+	switch {
+	case errors.Is(err, promptSvc.ErrVersionNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"message": "Prompt version not found"})
+	case errors.Is(err, promptSvc.ErrExperimentNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"message": "Experiment not found"})
+	case errors.Is(err, promptSvc.ErrInvalidVariants):
+		c.JSON(http.StatusBadRequest, gin.H{"message": err.Error()})
+	case errors.Is(err, promptSvc.ErrExperimentRunning), errors.Is(err, promptSvc.ErrExperimentStopped):
+		c.JSON(http.StatusConflict, gin.H{"message": err.Error()})
+	default:
+		c.JSON(http.StatusInternalServerError, gin.H{"message": fallback})
+	}
+}
@@ -0,0 +1,137 @@
+package prompt_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+
+	botHandlerPkg "github.com/souravsspace/texly.chat/internal/handlers/bot"
+	"github.com/souravsspace/texly.chat/internal/handlers/prompt"
+	"github.com/souravsspace/texly.chat/internal/models"
+	botRepo "github.com/souravsspace/texly.chat/internal/repo/bot"
+	promptRepo "github.com/souravsspace/texly.chat/internal/repo/prompt"
+	promptSvc "github.com/souravsspace/texly.chat/internal/services/prompt"
+)
+
+func setupRouter(t *testing.T) *gin.Engine {
+	gin.SetMode(gin.TestMode)
+
+	db, err := gorm.Open(sqlite.Open(":memory:"), &gorm.Config{})
+	require.NoError(t, err)
+	require.NoError(t, db.AutoMigrate(&models.Bot{}, &models.PromptVersion{}, &models.PromptExperiment{}))
+
+	bots := botRepo.NewBotRepo(db, nil)
+	promptService := promptSvc.NewPromptService(promptRepo.New(db), bots)
+	botHandler := botHandlerPkg.NewBotHandler(bots, nil)
+	botHandler.SetPromptService(promptService)
+	handler := prompt.NewPromptHandler(promptService, bots)
+
+	r := gin.New()
+	r.Use(func(c *gin.Context) {
+		c.Set("user_id", "test-user-id")
+		c.Next()
+	})
+	r.POST("/api/bots", botHandler.CreateBot)
+	r.PUT("/api/bots/:id", botHandler.UpdateBot)
+	r.GET("/api/bots/:id/prompts", handler.ListVersions)
+	r.GET("/api/bots/:id/prompts/diff", handler.DiffVersions)
+	r.POST("/api/bots/:id/prompts/rollback", handler.Rollback)
+	r.POST("/api/bots/:id/prompts/experiments", handler.CreateExperiment)
+	r.POST("/api/bots/:id/prompts/experiments/:experimentId/stop", handler.StopExperiment)
+	return r
+}
+
+func doJSON(r *gin.Engine, method string, path string, body interface{}, out interface{}) int {
+	var payload []byte
+	if body != nil {
+		payload, _ = json.Marshal(body)
+	}
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest(method, path, bytes.NewBuffer(payload))
+	req.Header.Set("Content-Type", "application/json")
+	r.ServeHTTP(w, req)
+	if out != nil {
+		_ = json.Unmarshal(w.Body.Bytes(), out)
+	}
+	return w.Code
+}
+
+func TestPromptVersioning(t *testing.T) {
+	r := setupRouter(t)
+
+	var bot models.Bot
+	require.Equal(t, http.StatusCreated, doJSON(r, "POST", "/api/bots", models.CreateBotRequest{Name: "Support", SystemPrompt: "Be brief."}, &bot))
+	require.NotEmpty(t, bot.PromptVersionID)
+
+	// Only a changed prompt creates a version
+	thorough := "Be thorough."
+	update := models.UpdateBotRequest{SystemPrompt: &thorough, PromptNote: "More detail"}
+	require.Equal(t, http.StatusOK, doJSON(r, "PUT", "/api/bots/"+bot.ID, update, &bot))
+	require.Equal(t, http.StatusOK, doJSON(r, "PUT", "/api/bots/"+bot.ID, update, &bot))
+
+	// Updates without a system prompt leave it untouched
+	require.Equal(t, http.StatusOK, doJSON(r, "PUT", "/api/bots/"+bot.ID, models.UpdateBotRequest{Name: "Helpdesk"}, &bot))
+	assert.Equal(t, thorough, bot.SystemPrompt)
+
+	var versions []models.PromptVersion
+	require.Equal(t, http.StatusOK, doJSON(r, "GET", "/api/bots/"+bot.ID+"/prompts", nil, &versions))
+	require.Len(t, versions, 2)
+	assert.Equal(t, "More detail", versions[0].Note)
+	assert.Equal(t, versions[0].ID, bot.PromptVersionID)
+
+	// Diff against the current version by default
+	var diff models.PromptDiff
+	require.Equal(t, http.StatusOK, doJSON(r, "GET", "/api/bots/"+bot.ID+"/prompts/diff?from="+versions[1].ID, nil, &diff))
+	assert.Equal(t, []models.PromptDiffLine{
+		{Op: models.DiffOpRemove, Text: "Be brief."},
+		{Op: models.DiffOpAdd, Text: "Be thorough."},
+	}, diff.Lines)
+
+	assert.Equal(t, http.StatusNotFound, doJSON(r, "GET", "/api/bots/"+bot.ID+"/prompts/diff?from=missing", nil, nil))
+
+	require.Equal(t, http.StatusOK, doJSON(r, "POST", "/api/bots/"+bot.ID+"/prompts/rollback", models.RollbackPromptRequest{VersionID: versions[1].ID}, &bot))
+	assert.Equal(t, "Be brief.", bot.SystemPrompt)
+
+	require.Equal(t, http.StatusOK, doJSON(r, "GET", "/api/bots/"+bot.ID+"/prompts", nil, &versions))
+	require.Len(t, versions, 3)
+	assert.Equal(t, "Rollback to version 1", versions[0].Note)
+}
+
+func TestPromptExperiments(t *testing.T) {
+	r := setupRouter(t)
+
+	var bot models.Bot
+	require.Equal(t, http.StatusCreated, doJSON(r, "POST", "/api/bots", models.CreateBotRequest{Name: "Support", SystemPrompt: "Be brief."}, &bot))
+	control := bot.PromptVersionID
+	thorough := "Be thorough."
+	require.Equal(t, http.StatusOK, doJSON(r, "PUT", "/api/bots/"+bot.ID, models.UpdateBotRequest{SystemPrompt: &thorough}, &bot))
+
+	req := models.CreatePromptExperimentRequest{
+		Name:     "Tone",
+		Variants: []models.PromptVariant{{PromptVersionID: control, Percent: 50}},
+	}
+	var experiment models.PromptExperiment
+	require.Equal(t, http.StatusCreated, doJSON(r, "POST", "/api/bots/"+bot.ID+"/prompts/experiments", req, &experiment))
+	assert.Equal(t, models.ExperimentStatusRunning, experiment.Status)
+
+	assert.Equal(t, http.StatusConflict, doJSON(r, "POST", "/api/bots/"+bot.ID+"/prompts/experiments", req, nil))
+
+	invalid := models.CreatePromptExperimentRequest{
+		Name:     "Broken",
+		Variants: []models.PromptVariant{{PromptVersionID: control, Percent: 150}},
+	}
+	assert.Equal(t, http.StatusBadRequest, doJSON(r, "POST", "/api/bots/"+bot.ID+"/prompts/experiments", invalid, nil))
+
+	require.Equal(t, http.StatusOK, doJSON(r, "POST", "/api/bots/"+bot.ID+"/prompts/experiments/"+experiment.ID+"/stop", nil, &experiment))
+	assert.Equal(t, models.ExperimentStatusStopped, experiment.Status)
+
+	assert.Equal(t, http.StatusNotFound, doJSON(r, "POST", "/api/bots/other/prompts/experiments", req, nil))
+}
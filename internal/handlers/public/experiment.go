@@ -0,0 +1,39 @@
+package public
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/souravsspace/texly.chat/internal/services/chat"
+	"github.com/souravsspace/texly.chat/internal/services/prompt"
+)
+
+/*
+ * SetPromptService enables prompt experiments for widget conversations
+ */
+func (h *PublicHandler) SetPromptService(promptService *prompt.PromptService) {
+	h.promptService = promptService
+}
+
+/*
+ * applyExperiment swaps in the system prompt the session is assigned to by the bot's running
+ * experiment; sessions outside every variant keep the bot's current prompt
+ */
+func (h *PublicHandler) applyExperiment(ctx context.Context, botID string, sessionID string, settings *chat.GenerationSettings) {
+	if h.promptService == nil {
+		return
+	}
+
+	version, err := h.promptService.ResolveForSession(ctx, botID, sessionID)
+	if err != nil {
+		// Log error but answer with the current prompt
+		fmt.Printf("Warning: failed to resolve prompt experiment: %v\n", err)
+		return
+	}
+	if version == nil {
+		return
+	}
+
+	settings.SystemPrompt = version.SystemPrompt
+	settings.PromptVersionID = version.ID
+}
@@ -1,14 +1,24 @@
 package public
 
 import (
+	"context"
 	"encoding/json"
+	"fmt"
 	"net/http"
+	"time"
 
 	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/souravsspace/texly.chat/configs"
+	rateLimitMiddleware "github.com/souravsspace/texly.chat/internal/middleware/rate_limit"
 	"github.com/souravsspace/texly.chat/internal/models"
 	botRepo "github.com/souravsspace/texly.chat/internal/repo/bot"
+	userRepo "github.com/souravsspace/texly.chat/internal/repo/user"
 	"github.com/souravsspace/texly.chat/internal/services/chat"
+	"github.com/souravsspace/texly.chat/internal/services/handoff"
+	"github.com/souravsspace/texly.chat/internal/services/prompt"
 	"github.com/souravsspace/texly.chat/internal/services/session"
+	"github.com/souravsspace/texly.chat/internal/services/stream"
 )
 
 /*
@@ -18,6 +28,11 @@ type PublicHandler struct {
 	botRepo        *botRepo.BotRepo
 	sessionService *session.SessionService
 	chatService    *chat.ChatService
+	userRepo       *userRepo.UserRepo
+	handoffService *handoff.HandoffService
+	streamBuffer   *stream.StreamBuffer
+	rateLimiter    *rateLimitMiddleware.RateLimiter // Optional: limits messages sent over WebSockets
+	promptService  *prompt.PromptService            // Optional: assigns sessions to prompt experiments
 }
 
 /*
@@ -27,11 +42,17 @@ func NewPublicHandler(
 	botRepo *botRepo.BotRepo,
 	sessionService *session.SessionService,
 	chatService *chat.ChatService,
+	userRepo *userRepo.UserRepo,
+	handoffService *handoff.HandoffService,
+	streamBuffer *stream.StreamBuffer,
 ) *PublicHandler {
 	return &PublicHandler{
 		botRepo:        botRepo,
 		sessionService: sessionService,
 		chatService:    chatService,
+		userRepo:       userRepo,
+		handoffService: handoffService,
+		streamBuffer:   streamBuffer,
 	}
 }
 
@@ -160,73 +181,368 @@ func (h *PublicHandler) StreamChatPublic(c *gin.Context) {
 		return
 	}
 
+	// Update session activity
+	h.sessionService.UpdateActivity(sessionID)
+
+	// While a human handles the conversation, messages go to the operators instead of the LLM
+	if h.inHandoff(chatSession, bot, req.Message) {
+		h.forwardToOperators(c, chatSession, req.Message)
+		return
+	}
+
 	// Validate chat service is available
 	if h.chatService == nil {
 		c.JSON(http.StatusServiceUnavailable, gin.H{"message": "Chat service not available"})
 		return
 	}
 
-	// Update session activity
-	h.sessionService.UpdateActivity(sessionID)
-
 	// Stream tokens using manual SSE writing
-	c.Writer.Header().Set("Content-Type", "text/event-stream")
-	c.Writer.Header().Set("Cache-Control", "no-cache")
-	c.Writer.Header().Set("Connection", "keep-alive")
-	c.Writer.Header().Set("X-Accel-Buffering", "no")
-
-	flusher, ok := c.Writer.(http.Flusher)
+	w, ok := newSSEWriter(c)
 	if !ok {
 		c.JSON(http.StatusInternalServerError, gin.H{"message": "Streaming not supported"})
 		return
 	}
 
+	// Generate independently of the request, so a visitor who drops can resume from the buffer
+	ctx, cancel := context.WithTimeout(context.WithoutCancel(c.Request.Context()), generationTimeout)
+	defer cancel()
+
 	// Start streaming from chat service
-	tokenChan, errChan := h.chatService.StreamChat(
-		c.Request.Context(),
-		bot.ID,
-		bot.SystemPrompt,
+	settings := h.chatService.ResolveSettings(bot, h.ownerTierLimits(bot.UserID))
+	h.applyExperiment(ctx, bot.ID, sessionID, &settings)
+	eventChan, errChan := h.chatService.StreamChat(
+		ctx,
+		bot,
+		settings,
 		req.Message,
 		sessionID,
 		nil, // Widget users don't have user IDs
 	)
 
-	// Stream tokens
+	// Buffer each event under the assistant message announced by the first one, then send it
+	// while the client is still connected
+	var messageID string
+	seq := 0
+	send := func(event models.ChatTokenResponse) {
+		if messageID == "" {
+			messageID = event.MessageID
+			if messageID == "" {
+				messageID = uuid.New().String()
+			}
+			if err := h.streamBuffer.Start(ctx, sessionID, messageID); err != nil {
+				fmt.Printf("Warning: failed to buffer stream: %v\n", err)
+			}
+		}
+		seq++
+		if err := h.streamBuffer.Append(ctx, sessionID, messageID, event); err != nil {
+			fmt.Printf("Warning: failed to buffer stream event: %v\n", err)
+		}
+		if c.Request.Context().Err() == nil {
+			w.event(stream.EventID(messageID, seq), event)
+		}
+	}
+
+	heartbeat := time.NewTicker(heartbeatInterval)
+	defer heartbeat.Stop()
+
+	// Stream events (sources, tokens)
 	for {
 		select {
-		case token, ok := <-tokenChan:
+		case event, ok := <-eventChan:
 			if !ok {
 				// Channel closed, stream done
-				response := models.ChatTokenResponse{
-					Type: "done",
-				}
-				data, _ := json.Marshal(response)
-				c.Writer.Write([]byte("data: " + string(data) + "\n\n"))
-				flusher.Flush()
+				send(models.ChatTokenResponse{Type: "done"})
 				return
 			}
 
-			// Send token
-			response := models.ChatTokenResponse{
-				Type:    "token",
-				Content: token,
-			}
-			data, _ := json.Marshal(response)
-			c.Writer.Write([]byte("data: " + string(data) + "\n\n"))
-			flusher.Flush()
+			h.handleFallback(chatSession, event)
+
+			// Send event as-is
+			send(event)
 
 		case err := <-errChan:
 			if err != nil {
-				// Send error
-				response := models.ChatTokenResponse{
-					Type:  "error",
-					Error: err.Error(),
-				}
-				data, _ := json.Marshal(response)
-				c.Writer.Write([]byte("data: " + string(data) + "\n\n"))
-				flusher.Flush()
+				send(models.ChatTokenResponse{Type: "error", Error: err.Error()})
+			} else {
+				send(models.ChatTokenResponse{Type: "done"})
+			}
+			return
+
+		case <-heartbeat.C:
+			if c.Request.Context().Err() == nil {
+				w.heartbeat()
+			}
+		}
+	}
+}
+
+/*
+ * ResumeStream handles GET /api/public/chats/:session_id/stream
+ * Replays a buffered answer after the Last-Event-ID header (or last_event_id query parameter) and
+ * follows it until it finishes. Without an event ID the session's latest answer is replayed in full
+ */
+func (h *PublicHandler) ResumeStream(c *gin.Context) {
+	chatSession := h.validSession(c)
+	if chatSession == nil {
+		return
+	}
+
+	if !h.streamBuffer.Enabled() {
+		c.JSON(http.StatusServiceUnavailable, gin.H{"message": "Stream resume not available"})
+		return
+	}
+
+	ctx := c.Request.Context()
+
+	lastEventID := c.GetHeader("Last-Event-ID")
+	if lastEventID == "" {
+		lastEventID = c.Query("last_event_id")
+	}
+
+	var messageID string
+	seq := 0
+	if lastEventID != "" {
+		var err error
+		messageID, seq, err = stream.ParseEventID(lastEventID)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"message": "Invalid Last-Event-ID"})
+			return
+		}
+	} else {
+		latest, err := h.streamBuffer.Latest(ctx, chatSession.ID)
+		if err != nil {
+			c.JSON(http.StatusNotFound, gin.H{"message": "No stream to resume"})
+			return
+		}
+		messageID = latest
+	}
+
+	exists, err := h.streamBuffer.Exists(ctx, chatSession.ID, messageID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"message": "Failed to load stream"})
+		return
+	}
+	if !exists {
+		c.JSON(http.StatusNotFound, gin.H{"message": "No stream to resume"})
+		return
+	}
+
+	w, ok := newSSEWriter(c)
+	if !ok {
+		c.JSON(http.StatusInternalServerError, gin.H{"message": "Streaming not supported"})
+		return
+	}
+
+	poll := time.NewTicker(resumePollInterval)
+	defer poll.Stop()
+	heartbeat := time.NewTicker(heartbeatInterval)
+	defer heartbeat.Stop()
+	deadline := time.After(generationTimeout)
+
+	for {
+		events, err := h.streamBuffer.Since(ctx, chatSession.ID, messageID, seq)
+		if err != nil {
+			w.event(stream.EventID(messageID, seq), models.ChatTokenResponse{Type: "error", Error: "Failed to resume stream"})
+			return
+		}
+		for _, event := range events {
+			seq++
+			w.event(stream.EventID(messageID, seq), event)
+			if stream.IsFinal(event) {
+				return
 			}
+		}
+
+		// Wait for the answer to make progress
+		select {
+		case <-ctx.Done():
+			return
+		case <-deadline:
 			return
+		case <-heartbeat.C:
+			w.heartbeat()
+		case <-poll.C:
 		}
 	}
 }
+
+/*
+ * RequestHandoff handles POST /api/public/chats/:session_id/handoff
+ * Lets the visitor ask for a human; the bot stops answering until the handoff is closed
+ */
+func (h *PublicHandler) RequestHandoff(c *gin.Context) {
+	chatSession := h.validSession(c)
+	if chatSession == nil {
+		return
+	}
+
+	if h.handoffService == nil {
+		c.JSON(http.StatusServiceUnavailable, gin.H{"message": "Human handoff not available"})
+		return
+	}
+
+	if err := h.handoffService.Request(chatSession, models.HandoffReasonVisitor); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"message": "Failed to request handoff"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"handoff_status": h.handoffStatus(chatSession.ID)})
+}
+
+/*
+ * StreamEvents handles GET /api/public/chats/:session_id/events
+ * Streams handoff events (operator replies, handoff closed) to the widget using SSE
+ */
+func (h *PublicHandler) StreamEvents(c *gin.Context) {
+	chatSession := h.validSession(c)
+	if chatSession == nil {
+		return
+	}
+
+	if h.handoffService == nil {
+		c.JSON(http.StatusServiceUnavailable, gin.H{"message": "Human handoff not available"})
+		return
+	}
+
+	c.Writer.Header().Set("Content-Type", "text/event-stream")
+	c.Writer.Header().Set("Cache-Control", "no-cache")
+	c.Writer.Header().Set("Connection", "keep-alive")
+	c.Writer.Header().Set("X-Accel-Buffering", "no")
+
+	flusher, ok := c.Writer.(http.Flusher)
+	if !ok {
+		c.JSON(http.StatusInternalServerError, gin.H{"message": "Streaming not supported"})
+		return
+	}
+
+	events, unsubscribe := h.handoffService.SubscribeSession(chatSession.ID)
+	defer unsubscribe()
+
+	// Flush headers so the client knows the stream is open
+	flusher.Flush()
+
+	for {
+		select {
+		case event, ok := <-events:
+			if !ok {
+				return
+			}
+			data, _ := json.Marshal(event)
+			c.Writer.Write([]byte("data: " + string(data) + "\n\n"))
+			flusher.Flush()
+		case <-c.Request.Context().Done():
+			return
+		}
+	}
+}
+
+/*
+ * inHandoff reports whether a visitor message goes to a human instead of the LLM
+ * Messages matching one of the bot's handoff rules start a handoff
+ */
+func (h *PublicHandler) inHandoff(chatSession *models.ChatSession, bot *models.Bot, content string) bool {
+	if h.handoffService == nil {
+		return false
+	}
+
+	// Hand the conversation to a human when the message matches one of the bot's rules
+	state, err := h.sessionService.HandoffState(chatSession.ID)
+	if err != nil {
+		return false
+	}
+	if !state.InHandoff() && handoff.MatchesRule(bot, content) {
+		if err := h.handoffService.Request(chatSession, models.HandoffReasonRule); err != nil {
+			fmt.Printf("Warning: failed to request handoff: %v\n", err)
+		}
+	}
+
+	return h.handoffStatus(chatSession.ID) != ""
+}
+
+/*
+ * handoffStatus reads the session's current handoff status under the session lock
+ */
+func (h *PublicHandler) handoffStatus(sessionID string) string {
+	state, err := h.sessionService.HandoffState(sessionID)
+	if err != nil {
+		return ""
+	}
+	return state.Status
+}
+
+/*
+ * handleFallback hands the conversation over when a low-confidence answer falls back to a human
+ */
+func (h *PublicHandler) handleFallback(chatSession *models.ChatSession, event models.ChatTokenResponse) {
+	if event.Type != "fallback" || event.Fallback != models.FallbackModeHuman || h.handoffService == nil {
+		return
+	}
+	if err := h.handoffService.Request(chatSession, models.HandoffReasonLowConfidence); err != nil {
+		fmt.Printf("Warning: failed to request handoff: %v\n", err)
+	}
+}
+
+/*
+ * forwardToOperators records a visitor message during handoff and tells the widget a human will reply
+ */
+func (h *PublicHandler) forwardToOperators(c *gin.Context, chatSession *models.ChatSession, content string) {
+	if _, err := h.handoffService.RecordVisitorMessage(c.Request.Context(), chatSession, content); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"message": "Failed to deliver message"})
+		return
+	}
+
+	c.Writer.Header().Set("Content-Type", "text/event-stream")
+	c.Writer.Header().Set("Cache-Control", "no-cache")
+	c.Writer.Header().Set("Connection", "keep-alive")
+	c.Writer.Header().Set("X-Accel-Buffering", "no")
+
+	for _, event := range []models.ChatTokenResponse{
+		{Type: "handoff", Handoff: h.handoffStatus(chatSession.ID)},
+		{Type: "done"},
+	} {
+		data, _ := json.Marshal(event)
+		c.Writer.Write([]byte("data: " + string(data) + "\n\n"))
+	}
+	c.Writer.Flush()
+}
+
+/*
+ * validSession loads the session from the URL, writing an error response if it is invalid
+ */
+func (h *PublicHandler) validSession(c *gin.Context) *models.ChatSession {
+	sessionID := c.Param("session_id")
+	if sessionID == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"message": "Session ID is required"})
+		return nil
+	}
+
+	chatSession, err := h.sessionService.GetSession(sessionID)
+	if err != nil {
+		if err == session.ErrSessionNotFound {
+			c.JSON(http.StatusNotFound, gin.H{"message": "Session not found"})
+			return nil
+		}
+		if err == session.ErrSessionExpired {
+			c.JSON(http.StatusUnauthorized, gin.H{"message": "Session has expired"})
+			return nil
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"message": "Failed to validate session"})
+		return nil
+	}
+
+	return chatSession
+}
+
+/*
+ * ownerTierLimits returns the tier limits of the bot owner, defaulting to Free
+ */
+func (h *PublicHandler) ownerTierLimits(ownerID string) configs.TierLimits {
+	if h.userRepo == nil {
+		return configs.GetTierLimits(configs.TierFree)
+	}
+	user, err := h.userRepo.GetByID(ownerID)
+	if err != nil || user == nil {
+		return configs.GetTierLimits(configs.TierFree)
+	}
+	return configs.GetTierLimits(user.Tier)
+}
@@ -2,18 +2,29 @@ package public
 
 import (
 	"bytes"
+	"context"
 	"encoding/json"
 	"net/http"
 	"net/http/httptest"
+	"strings"
 	"testing"
+	"time"
 
+	"github.com/alicebob/miniredis/v2"
 	"github.com/gin-gonic/gin"
 	"github.com/google/uuid"
+	"github.com/redis/go-redis/v9"
 	"github.com/souravsspace/texly.chat/internal/models"
 	botRepo "github.com/souravsspace/texly.chat/internal/repo/bot"
+	"github.com/souravsspace/texly.chat/internal/services/cache"
+	"github.com/souravsspace/texly.chat/internal/services/handoff"
 	"github.com/souravsspace/texly.chat/internal/services/session"
+	"github.com/souravsspace/texly.chat/internal/services/stream"
 	"github.com/souravsspace/texly.chat/internal/shared"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/net/websocket"
+	"gorm.io/driver/sqlite"
 	"gorm.io/gorm"
 )
 
@@ -24,7 +35,9 @@ func setupTestHandler() (*PublicHandler, *gin.Engine, *botRepo.BotRepo, *gorm.DB
 	repo := botRepo.NewBotRepo(testDB, nil)
 	sessionService := session.NewSessionService()
 
-	handler := NewPublicHandler(repo, sessionService, nil) // chatService is nil for these tests
+	handoffService := handoff.NewHandoffService(sessionService, nil)
+
+	handler := NewPublicHandler(repo, sessionService, nil, nil, handoffService, nil) // chatService is nil for these tests
 	router := gin.New()
 
 	return handler, router, repo, testDB
@@ -218,3 +231,197 @@ func TestPublicHandler_StreamChatPublic_InvalidRequest(t *testing.T) {
 
 	assert.Equal(t, http.StatusBadRequest, w.Code)
 }
+
+func TestPublicHandler_StreamChatPublic_DuringHandoff(t *testing.T) {
+	handler, router, repo, db := setupTestHandler()
+	router.POST("/api/public/chats/:session_id/handoff", handler.RequestHandoff)
+	router.POST("/api/public/chats/:session_id/messages", handler.StreamChatPublic)
+
+	// Create bot and session
+	botID := uuid.New().String()
+	bot := &models.Bot{
+		ID:           botID,
+		UserID:       "user-1",
+		Name:         "Test Bot",
+		SystemPrompt: "Test prompt",
+	}
+	repo.Create(bot)
+	defer db.Unscoped().Delete(bot)
+
+	chatSession := handler.sessionService.CreateSession(botID)
+
+	// Visitor asks for a human
+	req := httptest.NewRequest("POST", "/api/public/chats/"+chatSession.ID+"/handoff", nil)
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.True(t, chatSession.InHandoff())
+
+	// Messages go to operators; the chat service (nil here) is never called
+	bodyBytes, _ := json.Marshal(models.ChatRequest{Message: "Hello?"})
+	req = httptest.NewRequest("POST", "/api/public/chats/"+chatSession.ID+"/messages", bytes.NewBuffer(bodyBytes))
+	req.Header.Set("Content-Type", "application/json")
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Contains(t, w.Body.String(), `"type":"handoff"`)
+}
+
+func setupResumeHandler(t *testing.T) (*PublicHandler, *gin.Engine) {
+	gin.SetMode(gin.TestMode)
+
+	mr, err := miniredis.Run()
+	require.NoError(t, err)
+	t.Cleanup(mr.Close)
+
+	buffer := stream.NewStreamBuffer(cache.NewCacheService(redis.NewClient(&redis.Options{Addr: mr.Addr()})))
+	handler := NewPublicHandler(nil, session.NewSessionService(), nil, nil, nil, buffer)
+
+	router := gin.New()
+	router.GET("/api/public/chats/:session_id/stream", handler.ResumeStream)
+
+	return handler, router
+}
+
+func TestPublicHandler_ResumeStream(t *testing.T) {
+	handler, router := setupResumeHandler(t)
+	ctx := context.Background()
+
+	chatSession := handler.sessionService.CreateSession("bot-1")
+	require.NoError(t, handler.streamBuffer.Start(ctx, chatSession.ID, "msg-1"))
+	for _, event := range []models.ChatTokenResponse{
+		{Type: "start", MessageID: "msg-1"},
+		{Type: "token", Content: "Hello"},
+		{Type: "token", Content: " world"},
+		{Type: "done"},
+	} {
+		require.NoError(t, handler.streamBuffer.Append(ctx, chatSession.ID, "msg-1", event))
+	}
+
+	// Resume after the first token
+	req := httptest.NewRequest("GET", "/api/public/chats/"+chatSession.ID+"/stream", nil)
+	req.Header.Set("Last-Event-ID", "msg-1:2")
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	body := w.Body.String()
+	assert.NotContains(t, body, `"content":"Hello"`)
+	assert.Contains(t, body, "id: msg-1:3\ndata: {\"type\":\"token\",\"content\":\" world\"}\n\n")
+	assert.Contains(t, body, "id: msg-1:4\ndata: {\"type\":\"done\"}\n\n")
+
+	// Without an event ID the latest stream is replayed in full
+	req = httptest.NewRequest("GET", "/api/public/chats/"+chatSession.ID+"/stream", nil)
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Contains(t, w.Body.String(), "id: msg-1:1\n")
+	assert.Contains(t, w.Body.String(), `"content":"Hello"`)
+}
+
+func TestPublicHandler_ResumeStream_NotFound(t *testing.T) {
+	handler, router := setupResumeHandler(t)
+	chatSession := handler.sessionService.CreateSession("bot-1")
+
+	// No stream for the session yet
+	req := httptest.NewRequest("GET", "/api/public/chats/"+chatSession.ID+"/stream", nil)
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusNotFound, w.Code)
+
+	// Unknown message
+	req = httptest.NewRequest("GET", "/api/public/chats/"+chatSession.ID+"/stream?last_event_id=msg-9:1", nil)
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusNotFound, w.Code)
+
+	// Malformed event ID
+	req = httptest.NewRequest("GET", "/api/public/chats/"+chatSession.ID+"/stream", nil)
+	req.Header.Set("Last-Event-ID", "garbage")
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+}
+
+func TestPublicHandler_ChatSocket(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	db, err := gorm.Open(sqlite.Open(":memory:"), &gorm.Config{})
+	require.NoError(t, err)
+	require.NoError(t, db.AutoMigrate(&models.Bot{}))
+
+	bot := models.Bot{ID: uuid.New().String(), UserID: "user-1", Name: "Test Bot"}
+	require.NoError(t, db.Create(&bot).Error)
+
+	sessionService := session.NewSessionService()
+	handoffService := handoff.NewHandoffService(sessionService, nil)
+	handler := NewPublicHandler(botRepo.NewBotRepo(db, nil), sessionService, nil, nil, handoffService, nil)
+
+	router := gin.New()
+	router.GET("/api/public/chats/:session_id/ws", handler.ChatSocket)
+	server := httptest.NewServer(router)
+	defer server.Close()
+
+	// Unknown sessions are rejected before the upgrade
+	resp, err := http.Get(server.URL + "/api/public/chats/non-existent-session/ws")
+	require.NoError(t, err)
+	resp.Body.Close()
+	assert.Equal(t, http.StatusNotFound, resp.StatusCode)
+
+	chatSession := sessionService.CreateSession(bot.ID)
+	operatorEvents, unsubscribe := handoffService.SubscribeBot(bot.ID)
+	defer unsubscribe()
+
+	conn, err := websocket.Dial("ws"+strings.TrimPrefix(server.URL, "http")+"/api/public/chats/"+chatSession.ID+"/ws", "", server.URL)
+	require.NoError(t, err)
+	defer conn.Close()
+
+	receive := func() models.ChatTokenResponse {
+		var frame models.ChatTokenResponse
+		require.NoError(t, conn.SetReadDeadline(time.Now().Add(2*time.Second)))
+		require.NoError(t, websocket.JSON.Receive(conn, &frame))
+		return frame
+	}
+
+	// The visitor learns whether operators are around; operators see the visitor arrive
+	frame := receive()
+	assert.Equal(t, "presence", frame.Type)
+	assert.Equal(t, models.PresenceOnline, frame.Presence)
+	assert.Equal(t, "visitor_presence", (<-operatorEvents).Type)
+
+	// Visitor typing reaches operators
+	require.NoError(t, websocket.JSON.Send(conn, models.ChatSocketRequest{Type: "typing", Typing: true}))
+	event := <-operatorEvents
+	assert.Equal(t, "visitor_typing", event.Type)
+	require.NotNil(t, event.Typing)
+	assert.True(t, *event.Typing)
+
+	// Operator typing and replies are pushed to the visitor
+	handoffService.OperatorTyping(chatSession, true)
+	frame = receive()
+	assert.Equal(t, "typing", frame.Type)
+	require.NotNil(t, frame.Typing)
+	assert.True(t, *frame.Typing)
+
+	_, err = handoffService.Reply(context.Background(), chatSession, "user-1", "Hi, I'm here to help.")
+	require.NoError(t, err)
+	assert.Equal(t, "handoff", receive().Type)
+	frame = receive()
+	assert.Equal(t, "message", frame.Type)
+	assert.Equal(t, "Hi, I'm here to help.", frame.Content)
+
+	// During handoff visitor messages go to the operators
+	require.NoError(t, websocket.JSON.Send(conn, models.ChatSocketRequest{Type: "message", Message: "Thanks!"}))
+	frame = receive()
+	assert.Equal(t, "handoff", frame.Type)
+	assert.Equal(t, models.HandoffStatusActive, frame.Handoff)
+	assert.Equal(t, "done", receive().Type)
+
+	// Unknown frames are reported without closing the socket
+	require.NoError(t, websocket.JSON.Send(conn, models.ChatSocketRequest{Type: "ping"}))
+	frame = receive()
+	assert.Equal(t, "error", frame.Type)
+	assert.Contains(t, frame.Error, "Unknown frame type")
+}
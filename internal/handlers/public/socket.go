@@ -0,0 +1,252 @@
+package public
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"strings"
+	"sync"
+	"sync/atomic"
+
+	"github.com/gin-gonic/gin"
+	"golang.org/x/net/websocket"
+
+	rateLimitMiddleware "github.com/souravsspace/texly.chat/internal/middleware/rate_limit"
+	"github.com/souravsspace/texly.chat/internal/models"
+	"github.com/souravsspace/texly.chat/internal/services/session"
+)
+
+/*
+ * SetRateLimiter applies the public rate limit to each message sent over a WebSocket,
+ * as it is applied to each POST to the SSE endpoint
+ */
+func (h *PublicHandler) SetRateLimiter(rateLimiter *rateLimitMiddleware.RateLimiter) {
+	h.rateLimiter = rateLimiter
+}
+
+/*
+ * ChatSocket handles GET /api/public/chats/:session_id/ws
+ * Carries the widget conversation over a WebSocket: visitor messages and typing in; answer
+ * events, operator replies, typing and presence out, all as models.ChatTokenResponse frames
+ */
+func (h *PublicHandler) ChatSocket(c *gin.Context) {
+	chatSession := h.validSession(c)
+	if chatSession == nil {
+		return
+	}
+
+	clientIP := c.ClientIP()
+	server := websocket.Server{
+		// Any origin may connect, as with the SSE endpoint
+		Handshake: func(*websocket.Config, *http.Request) error { return nil },
+		Handler: func(conn *websocket.Conn) {
+			h.serveSocket(conn, chatSession, clientIP)
+		},
+	}
+	server.ServeHTTP(c.Writer, c.Request)
+}
+
+/*
+ * chatSocket serializes frames written from the answer stream and the handoff events
+ */
+type chatSocket struct {
+	conn      *websocket.Conn
+	mu        sync.Mutex
+	answering atomic.Bool // An answer is being generated; the visitor must wait for it before sending another message
+}
+
+/*
+ * send writes a frame; a closed connection is noticed by the read loop
+ */
+func (s *chatSocket) send(frame models.ChatTokenResponse) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	_ = websocket.JSON.Send(s.conn, frame)
+}
+
+/*
+ * serveSocket reads visitor frames until the connection closes
+ */
+func (h *PublicHandler) serveSocket(conn *websocket.Conn, chatSession *models.ChatSession, clientIP string) {
+	defer conn.Close()
+
+	ctx := conn.Request().Context()
+	socket := &chatSocket{conn: conn}
+
+	// Operators see the visitor come and go; the visitor gets operator replies and typing
+	if h.handoffService != nil {
+		h.handoffService.SetVisitorPresence(chatSession, models.PresenceOnline)
+		defer h.handoffService.SetVisitorPresence(chatSession, models.PresenceOffline)
+
+		events, unsubscribe := h.handoffService.SubscribeSession(chatSession.ID)
+		defer unsubscribe()
+		go func() {
+			for event := range events {
+				if frame, ok := handoffFrame(event); ok {
+					socket.send(frame)
+				}
+			}
+		}()
+
+		presence := models.PresenceOffline
+		if h.handoffService.OperatorsOnline(chatSession.BotID) {
+			presence = models.PresenceOnline
+		}
+		socket.send(models.ChatTokenResponse{Type: "presence", Presence: presence})
+	}
+
+	for {
+		var data string
+		if err := websocket.Message.Receive(conn, &data); err != nil {
+			return
+		}
+
+		var req models.ChatSocketRequest
+		if err := json.Unmarshal([]byte(data), &req); err != nil {
+			socket.send(models.ChatTokenResponse{Type: "error", Error: "Invalid frame: " + err.Error()})
+			continue
+		}
+
+		switch req.Type {
+		case "message":
+			// Answer in the background so typing frames and a closing client are still read
+			if !socket.answering.CompareAndSwap(false, true) {
+				socket.send(models.ChatTokenResponse{Type: "error", Error: "An answer is already in progress"})
+				continue
+			}
+			go func(message string) {
+				defer socket.answering.Store(false)
+				if !h.answerOverSocket(ctx, socket, chatSession.ID, clientIP, message) {
+					// Ends the read loop
+					conn.Close()
+				}
+			}(req.Message)
+		case "typing":
+			if h.handoffService != nil {
+				h.handoffService.VisitorTyping(chatSession, req.Typing)
+			}
+		default:
+			socket.send(models.ChatTokenResponse{Type: "error", Error: "Unknown frame type: " + req.Type})
+		}
+	}
+}
+
+/*
+ * answerOverSocket runs a visitor message through the same checks and pipeline as StreamChatPublic
+ * Returns false when the session is no longer valid and the socket should close
+ */
+func (h *PublicHandler) answerOverSocket(
+	ctx context.Context,
+	socket *chatSocket,
+	sessionID string,
+	clientIP string,
+	message string,
+) bool {
+	fail := func(message string) {
+		socket.send(models.ChatTokenResponse{Type: "error", Error: message})
+	}
+
+	if strings.TrimSpace(message) == "" {
+		fail("Message is required")
+		return true
+	}
+
+	if h.rateLimiter != nil {
+		allowed, err := h.rateLimiter.AllowPublic(ctx, clientIP)
+		if err != nil {
+			fail("Rate limit check failed")
+			return true
+		}
+		if !allowed {
+			fail("Rate limit exceeded")
+			return true
+		}
+	}
+
+	// Sessions can expire while the socket is open
+	chatSession, err := h.sessionService.GetSession(sessionID)
+	if err != nil {
+		switch err {
+		case session.ErrSessionNotFound:
+			fail("Session not found")
+		case session.ErrSessionExpired:
+			fail("Session has expired")
+		default:
+			fail("Failed to validate session")
+		}
+		return false
+	}
+
+	bot, err := h.botRepo.GetByIDPublic(chatSession.BotID)
+	if err != nil || bot == nil {
+		fail("Failed to fetch bot")
+		return true
+	}
+
+	h.sessionService.UpdateActivity(sessionID)
+
+	// While a human handles the conversation, messages go to the operators instead of the LLM
+	if h.inHandoff(chatSession, bot, message) {
+		if _, err := h.handoffService.RecordVisitorMessage(ctx, chatSession, message); err != nil {
+			fail("Failed to deliver message")
+			return true
+		}
+		socket.send(models.ChatTokenResponse{Type: "handoff", Handoff: h.handoffStatus(chatSession.ID)})
+		socket.send(models.ChatTokenResponse{Type: "done"})
+		return true
+	}
+
+	if h.chatService == nil {
+		fail("Chat service not available")
+		return true
+	}
+
+	// Finish the answer even if the socket drops, as the SSE endpoint does
+	genCtx, cancel := context.WithTimeout(context.WithoutCancel(ctx), generationTimeout)
+	defer cancel()
+
+	settings := h.chatService.ResolveSettings(bot, h.ownerTierLimits(bot.UserID))
+	h.applyExperiment(genCtx, bot.ID, sessionID, &settings)
+	eventChan, errChan := h.chatService.StreamChat(genCtx, bot, settings, message, sessionID, nil)
+
+	for {
+		select {
+		case event, ok := <-eventChan:
+			if !ok {
+				socket.send(models.ChatTokenResponse{Type: "done"})
+				return true
+			}
+			h.handleFallback(chatSession, event)
+			socket.send(event)
+
+		case err := <-errChan:
+			if err != nil {
+				fail(err.Error())
+			} else {
+				socket.send(models.ChatTokenResponse{Type: "done"})
+			}
+			return true
+		}
+	}
+}
+
+/*
+ * handoffFrame converts a handoff event for the visitor into a socket frame
+ */
+func handoffFrame(event models.HandoffEvent) (models.ChatTokenResponse, bool) {
+	switch event.Type {
+	case "handoff_requested":
+		return models.ChatTokenResponse{Type: "handoff", Handoff: models.HandoffStatusPending}, true
+	case "operator_message":
+		if event.Message == nil {
+			return models.ChatTokenResponse{}, false
+		}
+		return models.ChatTokenResponse{Type: "message", MessageID: event.Message.ID, Content: event.Message.Content}, true
+	case "handoff_closed":
+		return models.ChatTokenResponse{Type: "handoff", Handoff: "closed"}, true
+	case "operator_typing":
+		return models.ChatTokenResponse{Type: "typing", Typing: event.Typing}, true
+	default:
+		return models.ChatTokenResponse{}, false
+	}
+}
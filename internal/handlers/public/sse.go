@@ -0,0 +1,61 @@
+package public
+
+import (
+	"encoding/json"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/souravsspace/texly.chat/internal/models"
+)
+
+const (
+	// heartbeatInterval keeps proxies from closing idle streams during long generations
+	heartbeatInterval = 15 * time.Second
+	// generationTimeout bounds an answer that keeps generating after its client disconnected
+	generationTimeout = 5 * time.Minute
+	// resumePollInterval is how often a resumed stream checks the buffer for new events
+	resumePollInterval = 250 * time.Millisecond
+)
+
+/*
+ * sseWriter writes server-sent events to a gin response
+ */
+type sseWriter struct {
+	writer  gin.ResponseWriter
+	flusher http.Flusher
+}
+
+/*
+ * newSSEWriter sets the event stream headers
+ * Returns false when the response cannot be streamed
+ */
+func newSSEWriter(c *gin.Context) (*sseWriter, bool) {
+	c.Writer.Header().Set("Content-Type", "text/event-stream")
+	c.Writer.Header().Set("Cache-Control", "no-cache")
+	c.Writer.Header().Set("Connection", "keep-alive")
+	c.Writer.Header().Set("X-Accel-Buffering", "no")
+
+	flusher, ok := c.Writer.(http.Flusher)
+	if !ok {
+		return nil, false
+	}
+	return &sseWriter{writer: c.Writer, flusher: flusher}, true
+}
+
+/*
+ * event writes an event with its ID
+ */
+func (w *sseWriter) event(id string, event models.ChatTokenResponse) {
+	data, _ := json.Marshal(event)
+	w.writer.Write([]byte("id: " + id + "\ndata: " + string(data) + "\n\n"))
+	w.flusher.Flush()
+}
+
+/*
+ * heartbeat writes a comment line, which clients ignore
+ */
+func (w *sseWriter) heartbeat() {
+	w.writer.Write([]byte(": heartbeat\n\n"))
+	w.flusher.Flush()
+}
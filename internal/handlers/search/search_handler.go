@@ -0,0 +1,102 @@
+package search
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/souravsspace/texly.chat/configs"
+	"github.com/souravsspace/texly.chat/internal/models"
+	botRepo "github.com/souravsspace/texly.chat/internal/repo/bot"
+	userRepo "github.com/souravsspace/texly.chat/internal/repo/user"
+	"github.com/souravsspace/texly.chat/internal/services/chat"
+)
+
+/*
+ * SearchHandler handles HTTP requests for debugging a bot's retrieval
+ */
+type SearchHandler struct {
+	botRepo     *botRepo.BotRepo
+	chatService *chat.ChatService
+	userRepo    *userRepo.UserRepo
+}
+
+/*
+ * NewSearchHandler creates a new search handler instance
+ */
+func NewSearchHandler(
+	botRepo *botRepo.BotRepo,
+	chatService *chat.ChatService,
+	userRepo *userRepo.UserRepo,
+) *SearchHandler {
+	return &SearchHandler{
+		botRepo:     botRepo,
+		chatService: chatService,
+		userRepo:    userRepo,
+	}
+}
+
+/*
+ * Explain handles POST /api/bots/:id/search/explain
+ * Returns the chunks the bot would retrieve for a question and the prompt it would send, without answering
+ */
+func (h *SearchHandler) Explain(c *gin.Context) {
+	userID := c.GetString("user_id")
+	if userID == "" {
+		c.JSON(http.StatusUnauthorized, gin.H{"message": "Unauthorized"})
+		return
+	}
+
+	var req models.ExplainSearchRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"message": "Invalid request: " + err.Error()})
+		return
+	}
+
+	bot, err := h.botRepo.GetByID(c.Param("id"), userID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"message": "Failed to fetch bot"})
+		return
+	}
+	if bot == nil {
+		c.JSON(http.StatusNotFound, gin.H{"message": "Bot not found"})
+		return
+	}
+
+	if h.chatService == nil {
+		c.JSON(http.StatusServiceUnavailable, gin.H{"message": "Search service not available"})
+		return
+	}
+
+	settings := h.chatService.ResolveSettings(bot, h.tierLimits(c, bot.UserID))
+	explanation, err := h.chatService.ExplainRetrieval(c.Request.Context(), bot, settings, req)
+	if err != nil {
+		if errors.Is(err, chat.ErrSearchUnavailable) {
+			c.JSON(http.StatusServiceUnavailable, gin.H{"message": "Search service not available"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"message": "Failed to search knowledge base"})
+		return
+	}
+
+	c.JSON(http.StatusOK, explanation)
+}
+
+/*
+ * tierLimits returns the limits of the bot owner's plan, preferring the user loaded by the auth middleware
+ */
+func (h *SearchHandler) tierLimits(c *gin.Context, ownerID string) configs.TierLimits {
+	if userCtx, exists := c.Get("user"); exists {
+		if user, ok := userCtx.(*models.User); ok && user.ID == ownerID {
+			return configs.GetTierLimits(user.Tier)
+		}
+	}
+	if h.userRepo == nil {
+		return configs.GetTierLimits(configs.TierFree)
+	}
+	user, err := h.userRepo.GetByID(ownerID)
+	if err != nil || user == nil {
+		return configs.GetTierLimits(configs.TierFree)
+	}
+	return configs.GetTierLimits(user.Tier)
+}
@@ -0,0 +1,68 @@
+package search_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+
+	"github.com/souravsspace/texly.chat/internal/handlers/search"
+	"github.com/souravsspace/texly.chat/internal/models"
+	botRepo "github.com/souravsspace/texly.chat/internal/repo/bot"
+	"github.com/souravsspace/texly.chat/internal/services/chat"
+)
+
+func setupRouter(t *testing.T, chatService *chat.ChatService) (*gin.Engine, *models.Bot) {
+	gin.SetMode(gin.TestMode)
+
+	db, err := gorm.Open(sqlite.Open(":memory:"), &gorm.Config{})
+	require.NoError(t, err)
+	require.NoError(t, db.AutoMigrate(&models.Bot{}))
+
+	bots := botRepo.NewBotRepo(db, nil)
+	bot := &models.Bot{UserID: "test-user-id", Name: "Support"}
+	require.NoError(t, bots.Create(bot))
+	handler := search.NewSearchHandler(bots, chatService, nil)
+
+	r := gin.New()
+	r.Use(func(c *gin.Context) {
+		c.Set("user_id", "test-user-id")
+		c.Next()
+	})
+	r.POST("/api/bots/:id/search/explain", handler.Explain)
+	return r, bot
+}
+
+func explain(r *gin.Engine, botID string, body interface{}) int {
+	payload, _ := json.Marshal(body)
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest("POST", "/api/bots/"+botID+"/search/explain", bytes.NewBuffer(payload))
+	req.Header.Set("Content-Type", "application/json")
+	r.ServeHTTP(w, req)
+	return w.Code
+}
+
+func TestExplain_Validation(t *testing.T) {
+	chatService := chat.NewChatService(nil, nil, nil, "gpt-4o-mini", 0.7, 5, "test-key", 2000)
+	r, bot := setupRouter(t, chatService)
+
+	limit := 100
+	assert.Equal(t, http.StatusBadRequest, explain(r, bot.ID, models.ExplainSearchRequest{}))
+	assert.Equal(t, http.StatusBadRequest, explain(r, bot.ID, models.ExplainSearchRequest{Query: "Hi", Limit: &limit}))
+	assert.Equal(t, http.StatusNotFound, explain(r, "other-bot", models.ExplainSearchRequest{Query: "Hi"}))
+
+	// Retrieval needs the embedding and vector search services
+	assert.Equal(t, http.StatusServiceUnavailable, explain(r, bot.ID, models.ExplainSearchRequest{Query: "Hi"}))
+}
+
+func TestExplain_NoChatService(t *testing.T) {
+	r, bot := setupRouter(t, nil)
+	assert.Equal(t, http.StatusServiceUnavailable, explain(r, bot.ID, models.ExplainSearchRequest{Query: "Hi"}))
+}
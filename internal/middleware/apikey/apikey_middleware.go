@@ -0,0 +1,51 @@
+package apikey
+
+import (
+	"errors"
+	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	apiKeySvc "github.com/souravsspace/texly.chat/internal/services/apikey"
+)
+
+/*
+* Auth middleware verifies a bot-scoped API key sent as a Bearer token
+* Sets "user_id" to the bot owner and "api_key_bot_id" to the bot the key grants access to
+* Errors use the OpenAI error format so existing SDKs surface them
+ */
+func Auth(service *apiKeySvc.APIKeyService) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		authHeader := c.GetHeader("Authorization")
+		key, found := strings.CutPrefix(authHeader, "Bearer ")
+		if !found || key == "" {
+			abortWithError(c, http.StatusUnauthorized, "Missing API key. Send it as 'Authorization: Bearer <key>'.", "missing_api_key")
+			return
+		}
+
+		apiKey, err := service.Authenticate(c.Request.Context(), key)
+		if err != nil {
+			if errors.Is(err, apiKeySvc.ErrInvalidKey) {
+				abortWithError(c, http.StatusUnauthorized, "Invalid API key.", "invalid_api_key")
+			} else {
+				abortWithError(c, http.StatusInternalServerError, "Failed to verify API key.", "internal_error")
+			}
+			return
+		}
+
+		// Store key owner and scope in context
+		c.Set("user_id", apiKey.UserID)
+		c.Set("api_key_bot_id", apiKey.BotID)
+		c.Next()
+	}
+}
+
+func abortWithError(c *gin.Context, status int, message string, code string) {
+	c.AbortWithStatusJSON(status, gin.H{
+		"error": gin.H{
+			"message": message,
+			"type":    "invalid_request_error",
+			"code":    code,
+		},
+	})
+}
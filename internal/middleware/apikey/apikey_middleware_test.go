@@ -0,0 +1,76 @@
+package apikey_test
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	middleware "github.com/souravsspace/texly.chat/internal/middleware/apikey"
+	"github.com/souravsspace/texly.chat/internal/models"
+	apiKeyRepo "github.com/souravsspace/texly.chat/internal/repo/apikey"
+	apiKeySvc "github.com/souravsspace/texly.chat/internal/services/apikey"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+)
+
+func TestAuth(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	db, err := gorm.Open(sqlite.Open(":memory:"), &gorm.Config{})
+	require.NoError(t, err)
+	db.AutoMigrate(&models.BotAPIKey{})
+
+	service := apiKeySvc.NewAPIKeyService(apiKeyRepo.New(db))
+	bot := &models.Bot{ID: "bot-123", UserID: "owner-id"}
+	apiKey, key, err := service.Create(context.Background(), bot, "Backend")
+	require.NoError(t, err)
+	assert.Equal(t, key[:len(apiKey.Prefix)], apiKey.Prefix)
+	assert.NotContains(t, apiKey.KeyHash, key)
+
+	router := gin.New()
+	router.GET("/v1/models", middleware.Auth(service), func(c *gin.Context) {
+		c.JSON(http.StatusOK, gin.H{
+			"user_id": c.GetString("user_id"),
+			"bot_id":  c.GetString("api_key_bot_id"),
+		})
+	})
+
+	request := func(authHeader string) *httptest.ResponseRecorder {
+		req, _ := http.NewRequest("GET", "/v1/models", nil)
+		if authHeader != "" {
+			req.Header.Set("Authorization", authHeader)
+		}
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		return w
+	}
+
+	t.Run("Missing Header", func(t *testing.T) {
+		w := request("")
+		assert.Equal(t, http.StatusUnauthorized, w.Code)
+		assert.Contains(t, w.Body.String(), "missing_api_key")
+	})
+
+	t.Run("Unknown Key", func(t *testing.T) {
+		w := request("Bearer txk_doesnotexist")
+		assert.Equal(t, http.StatusUnauthorized, w.Code)
+		assert.Contains(t, w.Body.String(), "invalid_api_key")
+	})
+
+	t.Run("Valid Key", func(t *testing.T) {
+		w := request("Bearer " + key)
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.Contains(t, w.Body.String(), `"user_id":"owner-id"`)
+		assert.Contains(t, w.Body.String(), `"bot_id":"bot-123"`)
+	})
+
+	t.Run("Revoked Key", func(t *testing.T) {
+		require.NoError(t, service.Revoke(context.Background(), apiKey.ID, bot.ID))
+		w := request("Bearer " + key)
+		assert.Equal(t, http.StatusUnauthorized, w.Code)
+	})
+}
@@ -30,7 +30,7 @@ func WidgetCORS(botRepo *botRepo.BotRepo) gin.HandlerFunc {
 		// Handle preflight OPTIONS requests
 		if c.Request.Method == "OPTIONS" {
 			c.Header("Access-Control-Allow-Methods", "GET, POST, PUT, DELETE, OPTIONS")
-			c.Header("Access-Control-Allow-Headers", "Content-Type, Authorization, Origin")
+			c.Header("Access-Control-Allow-Headers", "Content-Type, Authorization, Origin, Last-Event-ID")
 			c.Header("Access-Control-Max-Age", "86400")
 
 			// For preflight, we need to validate if we have a bot ID
@@ -59,7 +59,7 @@ func WidgetCORS(botRepo *botRepo.BotRepo) gin.HandlerFunc {
 			c.Header("Access-Control-Allow-Origin", origin)
 			c.Header("Access-Control-Allow-Credentials", "true")
 			c.Header("Access-Control-Allow-Methods", "GET, POST, PUT, DELETE, OPTIONS")
-			c.Header("Access-Control-Allow-Headers", "Content-Type, Authorization, Origin")
+			c.Header("Access-Control-Allow-Headers", "Content-Type, Authorization, Origin, Last-Event-ID")
 		}
 
 		c.Next()
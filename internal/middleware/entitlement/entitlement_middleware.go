@@ -76,11 +76,11 @@ func (m *EntitlementMiddleware) EnforceLimit(limitType string) gin.HandlerFunc {
 				c.Next()
 				return
 			}
-			// Count messages in current period (start of month)
+			// Count messages in current period (start of month); helper completions are billed but are not messages
 			startOfMonth := getStartOfMonth()
 			var count int64
 			if err := m.db.Model(&models.UsageRecord{}).
-				Where("user_id = ? AND type = ? AND created_at >= ?", user.ID, models.UsageTypeChatMessage, startOfMonth).
+				Where("user_id = ? AND type IN ? AND created_at >= ?", user.ID, []string{models.UsageTypeChatMessage, models.UsageTypeChatTokens}, startOfMonth).
 				Count(&count).Error; err != nil {
 				c.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"error": "Failed to check message limit"})
 				return
@@ -115,4 +115,28 @@ func TestEntitlementMiddleware(t *testing.T) {
 
 		assert.Equal(t, 403, w.Code)
 	})
+
+	t.Run("MessageLimit_HelperTokensNotCounted", func(t *testing.T) {
+		userID := "user_free_helpers"
+		setupUser(userID, "free")
+
+		// Query rewrites, reranks and suggestions are billed but are not messages
+		for i := 0; i < 101; i++ {
+			db.Create(&models.UsageRecord{
+				UserID:    userID,
+				Type:      models.UsageTypeHelperTokens,
+				CreatedAt: time.Now(),
+			})
+		}
+
+		r.POST("/msg_helpers", mockAuth(userID), entitlementMw.EnforceLimit("message_send"), func(c *gin.Context) {
+			c.Status(200)
+		})
+
+		req, _ := http.NewRequest("POST", "/msg_helpers", nil)
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+
+		assert.Equal(t, 200, w.Code)
+	})
 }
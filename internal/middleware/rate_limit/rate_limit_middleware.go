@@ -163,26 +163,20 @@ func (rl *RateLimiter) rateLimitByIP(c *gin.Context, config RateLimitConfig) {
 	c.Next()
 }
 
+// publicRateLimitConfig is shared by every public widget endpoint
+var publicRateLimitConfig = RateLimitConfig{
+	FreeLimit: 60, // 60 requests per hour for public endpoints
+	ProLimit:  60, // Same limit for consistency
+	Period:    time.Hour,
+}
+
 // PublicRateLimitMiddleware provides rate limiting for public widget endpoints
 func (rl *RateLimiter) PublicRateLimitMiddleware() gin.HandlerFunc {
-	config := RateLimitConfig{
-		FreeLimit: 60, // 60 requests per hour for public endpoints
-		ProLimit:  60, // Same limit for consistency
-		Period:    time.Hour,
-	}
+	config := publicRateLimitConfig
 
 	return func(c *gin.Context) {
 		// Use IP-based rate limiting for public endpoints
-		clientIP := c.ClientIP()
-		key := fmt.Sprintf("rate_limit:public:%s", clientIP)
-
-		// Check rate limit
-		ctx := c.Request.Context()
-		result, err := rl.limiter.Allow(ctx, key, redis_rate.Limit{
-			Rate:   config.FreeLimit,
-			Burst:  config.FreeLimit,
-			Period: config.Period,
-		})
+		result, err := rl.allowPublic(c.Request.Context(), c.ClientIP())
 
 		if err != nil {
 			c.JSON(http.StatusInternalServerError, gin.H{"error": "Rate limit check failed"})
@@ -208,3 +202,23 @@ func (rl *RateLimiter) PublicRateLimitMiddleware() gin.HandlerFunc {
 		c.Next()
 	}
 }
+
+// AllowPublic counts a request against the public widget limit of a client IP
+// Used for messages that arrive over an open connection rather than as separate requests
+func (rl *RateLimiter) AllowPublic(ctx context.Context, clientIP string) (bool, error) {
+	result, err := rl.allowPublic(ctx, clientIP)
+	if err != nil {
+		return false, err
+	}
+	return result.Allowed > 0, nil
+}
+
+// allowPublic checks the public widget limit of a client IP
+func (rl *RateLimiter) allowPublic(ctx context.Context, clientIP string) (*redis_rate.Result, error) {
+	key := fmt.Sprintf("rate_limit:public:%s", clientIP)
+	return rl.limiter.Allow(ctx, key, redis_rate.Limit{
+		Rate:   publicRateLimitConfig.FreeLimit,
+		Burst:  publicRateLimitConfig.FreeLimit,
+		Period: publicRateLimitConfig.Period,
+	})
+}
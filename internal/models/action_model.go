@@ -0,0 +1,65 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+/*
+* BotAction is an owner-defined HTTP endpoint the bot can call as a tool
+ */
+type BotAction struct {
+	ID             string         `json:"id" gorm:"primaryKey"`
+	BotID          string         `json:"bot_id" gorm:"not null;index"`
+	Name           string         `json:"name" gorm:"not null"` // Tool name exposed to the model (e.g. "lookup_order_status")
+	Description    string         `json:"description"`          // Tells the model when to use the action
+	Method         string         `json:"method" gorm:"not null;default:'GET'"`
+	URL            string         `json:"url" gorm:"not null"`         // May contain {param} placeholders
+	Parameters     string         `json:"parameters" gorm:"type:text"` // JSON schema of the tool arguments
+	Headers        string         `json:"-" gorm:"type:text"`          // JSON-encoded auth headers (never returned to clients)
+	TimeoutSeconds int            `json:"timeout_seconds"`             // 0 = default timeout
+	CreatedAt      time.Time      `json:"created_at"`
+	UpdatedAt      time.Time      `json:"updated_at"`
+	DeletedAt      gorm.DeletedAt `json:"deleted_at" gorm:"index"`
+}
+
+/*
+* BeforeCreate generates a new UUID for the action and sets defaults
+ */
+func (a *BotAction) BeforeCreate(tx *gorm.DB) (err error) {
+	if a.ID == "" {
+		a.ID = uuid.New().String()
+	}
+	if a.Method == "" {
+		a.Method = "GET"
+	}
+	return
+}
+
+/*
+* CreateBotActionRequest holds data for creating a new bot action
+ */
+type CreateBotActionRequest struct {
+	Name           string                 `json:"name" binding:"required"`
+	Description    string                 `json:"description"`
+	Method         string                 `json:"method"` // GET, POST, PUT, PATCH or DELETE (default GET)
+	URL            string                 `json:"url" binding:"required,url"`
+	Parameters     map[string]interface{} `json:"parameters"`      // JSON schema of the tool arguments
+	Headers        map[string]string      `json:"headers"`         // Optional: auth headers sent with every call
+	TimeoutSeconds int                    `json:"timeout_seconds"` // Optional: per-call timeout
+}
+
+/*
+* UpdateBotActionRequest holds data for updating an existing bot action
+ */
+type UpdateBotActionRequest struct {
+	Name           string                 `json:"name"`
+	Description    string                 `json:"description"`
+	Method         string                 `json:"method"`
+	URL            string                 `json:"url"`
+	Parameters     map[string]interface{} `json:"parameters"`
+	Headers        map[string]string      `json:"headers"` // Replaces all headers when provided
+	TimeoutSeconds int                    `json:"timeout_seconds"`
+}
@@ -0,0 +1,50 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+/*
+* BotAPIKey is a secret that lets backend services call a single bot through the
+* OpenAI-compatible API. Only a SHA-256 hash of the key is stored.
+ */
+type BotAPIKey struct {
+	ID         string         `json:"id" gorm:"primaryKey"`
+	BotID      string         `json:"bot_id" gorm:"not null;index"`
+	UserID     string         `json:"user_id" gorm:"not null;index"`
+	Name       string         `json:"name"`
+	Prefix     string         `json:"prefix"`                        // First characters of the key, for identification
+	KeyHash    string         `json:"-" gorm:"not null;uniqueIndex"` // SHA-256 hex digest of the key
+	LastUsedAt *time.Time     `json:"last_used_at"`
+	CreatedAt  time.Time      `json:"created_at"`
+	UpdatedAt  time.Time      `json:"updated_at"`
+	DeletedAt  gorm.DeletedAt `json:"deleted_at" gorm:"index"`
+}
+
+/*
+* BeforeCreate generates a new UUID for the API key
+ */
+func (k *BotAPIKey) BeforeCreate(tx *gorm.DB) (err error) {
+	if k.ID == "" {
+		k.ID = uuid.New().String()
+	}
+	return
+}
+
+/*
+* CreateBotAPIKeyRequest holds data for creating a bot API key
+ */
+type CreateBotAPIKeyRequest struct {
+	Name string `json:"name"`
+}
+
+/*
+* CreateBotAPIKeyResponse returns the plaintext key; it is only shown once
+ */
+type CreateBotAPIKeyResponse struct {
+	APIKey BotAPIKey `json:"api_key"`
+	Key    string    `json:"key"`
+}
@@ -11,27 +11,63 @@ import (
 * WidgetConfig holds configuration for the embeddable widget
  */
 type WidgetConfig struct {
-	ThemeColor     string `json:"theme_color"`     // Primary color for widget UI (e.g., "#6366f1")
-	InitialMessage string `json:"initial_message"` // Welcome message shown when widget opens
-	Position       string `json:"position"`        // Widget position: "bottom-right" or "bottom-left"
-	BotAvatar      string `json:"bot_avatar"`      // URL to bot avatar image
+	ThemeColor       string   `json:"theme_color"`                 // Primary color for widget UI (e.g., "#6366f1")
+	InitialMessage   string   `json:"initial_message"`             // Welcome message shown when widget opens
+	Position         string   `json:"position"`                    // Widget position: "bottom-right" or "bottom-left"
+	BotAvatar        string   `json:"bot_avatar"`                  // URL to bot avatar image
+	StarterQuestions []string `json:"starter_questions,omitempty"` // Questions offered before the visitor types
 }
 
 /*
 * Bot represents a user's chatbot
  */
 type Bot struct {
-	ID             string         `json:"id" gorm:"primaryKey"`
-	UserID         string         `json:"user_id" gorm:"not null;index"`
-	Name           string         `json:"name" gorm:"not null"`
-	SystemPrompt   string         `json:"system_prompt"`
-	AllowedOrigins string         `json:"allowed_origins" gorm:"type:text"` // JSON array of whitelisted domains
-	WidgetConfig   string         `json:"widget_config" gorm:"type:text"`   // JSON-encoded WidgetConfig
-	CreatedAt      time.Time      `json:"created_at"`
-	UpdatedAt      time.Time      `json:"updated_at"`
-	DeletedAt      gorm.DeletedAt `json:"deleted_at" gorm:"index"`
+	ID                string         `json:"id" gorm:"primaryKey"`
+	UserID            string         `json:"user_id" gorm:"not null;index"`
+	Name              string         `json:"name" gorm:"not null"`
+	SystemPrompt      string         `json:"system_prompt"`
+	PromptVersionID   string         `json:"prompt_version_id"`                 // Current PromptVersion of the system prompt (empty = never versioned)
+	Provider          string         `json:"provider"`                          // Chat provider name (empty = default OpenAI)
+	Model             string         `json:"model"`                             // Chat model name
+	Temperature       *float64       `json:"temperature"`                       // Sampling temperature (0-2)
+	MaxOutputTokens   int            `json:"max_output_tokens"`                 // Max tokens per answer
+	MaxContextChunks  int            `json:"max_context_chunks"`                // Knowledge base chunks retrieved per question
+	DistanceThreshold float64        `json:"distance_threshold"`                // Max cosine distance of retrieved chunks (0 = no threshold)
+	QueryRewrite      bool           `json:"query_rewrite"`                     // Rewrite follow-up questions into standalone search queries
+	SuggestFollowUps  bool           `json:"suggest_follow_ups"`                // Suggest follow-up questions after each answer
+	Reranker          string         `json:"reranker"`                          // Reorders retrieved chunks before answering: "" (none), "bm25" or "llm"
+	RetrievalMode     string         `json:"retrieval_mode"`                    // How chunks are selected: "" (most similar) or "mmr" (relevant and diverse)
+	MMRLambda         *float64       `json:"mmr_lambda"`                        // MMR relevance/diversity balance (0-1, nil = 0.5)
+	FallbackMode      string         `json:"fallback_mode"`                     // Reply when no chunk clears the threshold: "" (ask the model), "message" or "human"
+	FallbackMessage   string         `json:"fallback_message"`                  // Custom fallback reply (empty = default)
+	HandoffKeywords   string         `json:"handoff_keywords" gorm:"type:text"` // JSON array of phrases that hand the conversation to a human
+	AllowedOrigins    string         `json:"allowed_origins" gorm:"type:text"`  // JSON array of whitelisted domains
+	WidgetConfig      string         `json:"widget_config" gorm:"type:text"`    // JSON-encoded WidgetConfig
+	CreatedAt         time.Time      `json:"created_at"`
+	UpdatedAt         time.Time      `json:"updated_at"`
+	DeletedAt         gorm.DeletedAt `json:"deleted_at" gorm:"index"`
 }
 
+// Rerankers that reorder retrieved chunks by relevance to the question
+const (
+	RerankerNone = ""     // Keep the retrieval order
+	RerankerBM25 = "bm25" // Local lexical scoring of the candidates
+	RerankerLLM  = "llm"  // The bot's small model scores each candidate
+)
+
+// Retrieval modes selecting the chunks an answer is grounded in
+const (
+	RetrievalModeSimilarity = ""    // The most relevant chunks
+	RetrievalModeMMR        = "mmr" // Maximal marginal relevance: relevant chunks unlike those already picked
+)
+
+// Fallback modes used when no knowledge base chunk clears the bot's distance threshold
+const (
+	FallbackModeNone    = ""        // Answer with the model anyway
+	FallbackModeMessage = "message" // Reply with the fallback message
+	FallbackModeHuman   = "human"   // Offer to contact a human
+)
+
 /*
 * BeforeCreate generates a new UUID for the bot
  */
@@ -46,18 +82,47 @@ func (b *Bot) BeforeCreate(tx *gorm.DB) (err error) {
 * CreateBotRequest holds data for creating a new bot
  */
 type CreateBotRequest struct {
-	Name           string        `json:"name" binding:"required"`
-	SystemPrompt   string        `json:"system_prompt"`
-	AllowedOrigins []string      `json:"allowed_origins"` // Optional: whitelisted domains for widget
-	WidgetConfig   *WidgetConfig `json:"widget_config"`   // Optional: widget configuration
+	Name              string        `json:"name" binding:"required"`
+	SystemPrompt      string        `json:"system_prompt"`
+	Provider          string        `json:"provider"`           // Optional: chat provider name
+	Model             string        `json:"model"`              // Optional: chat model (must be allowed by tier)
+	Temperature       *float64      `json:"temperature"`        // Optional: sampling temperature (0-2)
+	MaxOutputTokens   *int          `json:"max_output_tokens"`  // Optional: max tokens per answer
+	MaxContextChunks  *int          `json:"max_context_chunks"` // Optional: chunks retrieved per question
+	DistanceThreshold *float64      `json:"distance_threshold"` // Optional: max cosine distance of retrieved chunks
+	QueryRewrite      *bool         `json:"query_rewrite"`      // Optional: rewrite follow-ups before retrieval
+	SuggestFollowUps  *bool         `json:"suggest_follow_ups"` // Optional: suggest follow-up questions after answers
+	Reranker          *string       `json:"reranker"`           // Optional: "", "bm25" or "llm"
+	RetrievalMode     *string       `json:"retrieval_mode"`     // Optional: "" or "mmr"
+	MMRLambda         *float64      `json:"mmr_lambda"`         // Optional: MMR relevance/diversity balance (0-1)
+	FallbackMode      *string       `json:"fallback_mode"`      // Optional: "", "message" or "human"
+	FallbackMessage   *string       `json:"fallback_message"`   // Optional: custom fallback reply
+	HandoffKeywords   []string      `json:"handoff_keywords"`   // Optional: phrases that hand the conversation to a human
+	AllowedOrigins    []string      `json:"allowed_origins"`    // Optional: whitelisted domains for widget
+	WidgetConfig      *WidgetConfig `json:"widget_config"`      // Optional: widget configuration
 }
 
 /*
 * UpdateBotRequest holds data for updating an existing bot
  */
 type UpdateBotRequest struct {
-	Name           string        `json:"name"`
-	SystemPrompt   string        `json:"system_prompt"`
-	AllowedOrigins []string      `json:"allowed_origins"` // Optional: whitelisted domains for widget
-	WidgetConfig   *WidgetConfig `json:"widget_config"`   // Optional: widget configuration
+	Name              string        `json:"name"`
+	SystemPrompt      *string       `json:"system_prompt"`      // Optional: new system prompt (empty string clears)
+	PromptNote        string        `json:"prompt_note"`        // Optional: describes a system prompt change
+	Provider          string        `json:"provider"`           // Optional: chat provider name
+	Model             string        `json:"model"`              // Optional: chat model (must be allowed by tier)
+	Temperature       *float64      `json:"temperature"`        // Optional: sampling temperature (0-2)
+	MaxOutputTokens   *int          `json:"max_output_tokens"`  // Optional: max tokens per answer
+	MaxContextChunks  *int          `json:"max_context_chunks"` // Optional: chunks retrieved per question
+	DistanceThreshold *float64      `json:"distance_threshold"` // Optional: max cosine distance of retrieved chunks
+	QueryRewrite      *bool         `json:"query_rewrite"`      // Optional: rewrite follow-ups before retrieval
+	SuggestFollowUps  *bool         `json:"suggest_follow_ups"` // Optional: suggest follow-up questions after answers
+	Reranker          *string       `json:"reranker"`           // Optional: "", "bm25" or "llm"
+	RetrievalMode     *string       `json:"retrieval_mode"`     // Optional: "" or "mmr"
+	MMRLambda         *float64      `json:"mmr_lambda"`         // Optional: MMR relevance/diversity balance (0-1)
+	FallbackMode      *string       `json:"fallback_mode"`      // Optional: "", "message" or "human"
+	FallbackMessage   *string       `json:"fallback_message"`   // Optional: custom fallback reply
+	HandoffKeywords   *[]string     `json:"handoff_keywords"`   // Optional: phrases that hand the conversation to a human (empty list clears)
+	AllowedOrigins    []string      `json:"allowed_origins"`    // Optional: whitelisted domains for widget
+	WidgetConfig      *WidgetConfig `json:"widget_config"`      // Optional: widget configuration
 }
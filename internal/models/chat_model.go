@@ -11,7 +11,54 @@ type ChatRequest struct {
  * ChatTokenResponse represents a streaming token or event in SSE format
  */
 type ChatTokenResponse struct {
-	Type    string `json:"type"`              // "token" | "done" | "error"
-	Content string `json:"content,omitempty"` // Token content for type="token"
-	Error   string `json:"error,omitempty"`   // Error message for type="error"
+	Type        string      `json:"type"`                  // "start" | "token" | "sources" | "tool_call" | "usage" | "fallback" | "handoff" | "suggestions" | "message" | "typing" | "presence" | "done" | "error"
+	MessageID   string      `json:"message_id,omitempty"`  // ID of the assistant message for type="start" (used for feedback) and of the operator message for type="message"
+	Content     string      `json:"content,omitempty"`     // Token content for type="token", operator reply for type="message"
+	Sources     []Citation  `json:"sources,omitempty"`     // Retrieved documents for type="sources"
+	ToolCall    *ToolCall   `json:"tool_call,omitempty"`   // Action progress for type="tool_call"
+	Usage       *TokenUsage `json:"usage,omitempty"`       // Tokens billed for the answer for type="usage"
+	Fallback    string      `json:"fallback,omitempty"`    // Fallback mode for type="fallback" ("message" | "human")
+	Handoff     string      `json:"handoff,omitempty"`     // Handoff status for type="handoff" ("pending" | "active" | "closed")
+	Suggestions []string    `json:"suggestions,omitempty"` // Follow-up questions for type="suggestions"
+	Typing      *bool       `json:"typing,omitempty"`      // Whether an operator is typing for type="typing"
+	Presence    string      `json:"presence,omitempty"`    // Operator availability for type="presence" ("online" | "offline")
+	Error       string      `json:"error,omitempty"`       // Error message for type="error"
+}
+
+/*
+ * TokenUsage reports the prompt and completion tokens the provider billed for an answer
+ */
+type TokenUsage struct {
+	PromptTokens     int `json:"prompt_tokens"`
+	CompletionTokens int `json:"completion_tokens"`
+	TotalTokens      int `json:"total_tokens"`
+}
+
+/*
+ * ChatSocketRequest is a frame sent by the widget over the chat WebSocket
+ */
+type ChatSocketRequest struct {
+	Type    string `json:"type"`              // "message" | "typing"
+	Message string `json:"message,omitempty"` // Visitor message for type="message"
+	Typing  bool   `json:"typing,omitempty"`  // Whether the visitor is typing for type="typing"
+}
+
+/*
+ * ToolCall reports the progress of a bot action invoked by the model
+ */
+type ToolCall struct {
+	ID     string `json:"id"`
+	Name   string `json:"name"`
+	Status string `json:"status"` // "running" | "completed" | "failed"
+}
+
+/*
+ * Citation references a knowledge base chunk used to answer a message
+ */
+type Citation struct {
+	ChunkID  string   `json:"chunk_id"`
+	SourceID string   `json:"source_id"`
+	URL      string   `json:"url,omitempty"`      // Set for URL sources
+	Filename string   `json:"filename,omitempty"` // Original filename for file and text sources
+	Distance *float32 `json:"distance,omitempty"` // Cosine distance to the query (lower = closer; unset for keyword matches without an embedding)
 }
@@ -13,7 +13,7 @@ import (
  */
 type DocumentChunk struct {
 	ID         string           `json:"id" gorm:"primaryKey"`
-	SourceID   string           `json:"source_id" gorm:"not null;index"`
+	SourceID   string           `json:"source_id" gorm:"not null;index;index:idx_document_chunks_embedded,where:embedding IS NOT NULL"` // Embedded chunks of a source
 	Content    string           `json:"content" gorm:"not null"`
 	ChunkIndex int              `json:"chunk_index"`
 	Embedding  *pgvector.Vector `json:"-" gorm:"type:vector(1536)"`
@@ -0,0 +1,134 @@
+package models
+
+import (
+	"encoding/json"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+/*
+* GoldenSet is a list of questions with known good sources and answers used to evaluate a bot
+ */
+type GoldenSet struct {
+	ID          string           `json:"id" gorm:"primaryKey"`
+	BotID       string           `json:"bot_id" gorm:"not null;index"`
+	Name        string           `json:"name" gorm:"not null"`
+	Description string           `json:"description"`
+	Questions   []GoldenQuestion `json:"questions" gorm:"foreignKey:GoldenSetID;constraint:OnDelete:CASCADE"`
+	CreatedAt   time.Time        `json:"created_at"`
+	UpdatedAt   time.Time        `json:"updated_at"`
+}
+
+/*
+* BeforeCreate generates a new UUID for the golden set
+ */
+func (g *GoldenSet) BeforeCreate(tx *gorm.DB) (err error) {
+	if g.ID == "" {
+		g.ID = uuid.New().String()
+	}
+	return
+}
+
+/*
+* NewGoldenSet builds a golden set for a bot from a create request
+ */
+func NewGoldenSet(botID string, req CreateGoldenSetRequest) *GoldenSet {
+	set := &GoldenSet{
+		BotID:       botID,
+		Name:        req.Name,
+		Description: req.Description,
+		Questions:   make([]GoldenQuestion, 0, len(req.Questions)),
+	}
+	for i, q := range req.Questions {
+		expected := q.ExpectedSourceIDs
+		if expected == nil {
+			expected = []string{}
+		}
+		expectedJSON, _ := json.Marshal(expected)
+		set.Questions = append(set.Questions, GoldenQuestion{
+			Position:          i,
+			Question:          q.Question,
+			ExpectedSourceIDs: string(expectedJSON),
+			ReferenceAnswer:   q.ReferenceAnswer,
+		})
+	}
+	return set
+}
+
+/*
+* GoldenQuestion is a question of a golden set with the sources and/or answer expected for it
+ */
+type GoldenQuestion struct {
+	ID                string    `json:"id" gorm:"primaryKey"`
+	GoldenSetID       string    `json:"golden_set_id" gorm:"not null;index"`
+	Position          int       `json:"position"` // Order within the set
+	Question          string    `json:"question" gorm:"type:text;not null"`
+	ExpectedSourceIDs string    `json:"expected_source_ids" gorm:"type:text"` // JSON array of sources that should be retrieved
+	ReferenceAnswer   string    `json:"reference_answer" gorm:"type:text"`    // Answer the bot's answer is graded against
+	CreatedAt         time.Time `json:"created_at"`
+}
+
+/*
+* BeforeCreate generates a new UUID for the golden question
+ */
+func (q *GoldenQuestion) BeforeCreate(tx *gorm.DB) (err error) {
+	if q.ID == "" {
+		q.ID = uuid.New().String()
+	}
+	return
+}
+
+/*
+* GoldenQuestionRequest holds a question of a golden set
+ */
+type GoldenQuestionRequest struct {
+	Question          string   `json:"question" binding:"required"`
+	ExpectedSourceIDs []string `json:"expected_source_ids"` // Optional: sources that should be retrieved
+	ReferenceAnswer   string   `json:"reference_answer"`    // Optional: answer to grade against
+}
+
+/*
+* CreateGoldenSetRequest holds data for creating a golden set
+ */
+type CreateGoldenSetRequest struct {
+	Name        string                  `json:"name" binding:"required"`
+	Description string                  `json:"description"`
+	Questions   []GoldenQuestionRequest `json:"questions" binding:"required,dive"`
+}
+
+/*
+* EvalReport summarizes an evaluation of a bot against a golden set
+ */
+type EvalReport struct {
+	BotID             string       `json:"bot_id"`
+	GoldenSetID       string       `json:"golden_set_id"`
+	GoldenSetName     string       `json:"golden_set_name"`
+	K                 int          `json:"k"`                  // Chunks retrieved per question
+	Questions         int          `json:"questions"`          // Questions evaluated
+	RetrievalScored   int          `json:"retrieval_scored"`   // Questions with expected sources
+	RecallAtK         float64      `json:"recall_at_k"`        // Mean share of expected sources in the top k (0-1)
+	MRR               float64      `json:"mrr"`                // Mean reciprocal rank of the first expected source (0-1)
+	AnswersGraded     int          `json:"answers_graded"`     // Questions with a reference answer
+	AnswerCorrectness float64      `json:"answer_correctness"` // Mean LLM-graded correctness (0-1)
+	Errors            int          `json:"errors"`             // Questions that failed to run
+	StartedAt         time.Time    `json:"started_at"`
+	DurationMs        int64        `json:"duration_ms"`
+	Results           []EvalResult `json:"results"`
+}
+
+/*
+* EvalResult is the evaluation of a single golden question
+ */
+type EvalResult struct {
+	Question           string   `json:"question"`
+	ExpectedSourceIDs  []string `json:"expected_source_ids"`
+	RetrievedSourceIDs []string `json:"retrieved_source_ids"` // Sources of the top k chunks, in rank order
+	Recall             *float64 `json:"recall"`               // Nil without expected sources
+	ReciprocalRank     *float64 `json:"reciprocal_rank"`      // Nil without expected sources
+	Answer             string   `json:"answer"`
+	Correctness        *float64 `json:"correctness"`    // Nil without a reference answer
+	GraderVerdict      string   `json:"grader_verdict"` // "correct", "partial" or "incorrect"
+	Error              string   `json:"error,omitempty"`
+}
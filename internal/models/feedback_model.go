@@ -0,0 +1,67 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// Feedback ratings
+const (
+	FeedbackRatingUp   = "up"
+	FeedbackRatingDown = "down"
+)
+
+// Who left the feedback
+const (
+	FeedbackSourceVisitor = "visitor" // Widget visitor
+	FeedbackSourceOwner   = "owner"   // Bot owner from the dashboard
+)
+
+/*
+* MessageFeedback is a rating of an assistant message
+* Each message holds at most one rating per source; rating again replaces it
+ */
+type MessageFeedback struct {
+	ID        string    `json:"id" gorm:"primaryKey"`
+	MessageID string    `json:"message_id" gorm:"not null;uniqueIndex:idx_feedback_message_source"`
+	Source    string    `json:"source" gorm:"not null;uniqueIndex:idx_feedback_message_source"` // "visitor" or "owner"
+	SessionID string    `json:"session_id" gorm:"not null;index"`
+	BotID     string    `json:"bot_id" gorm:"not null;index"`
+	UserID    *string   `json:"user_id"`                  // Owner who rated; null for visitors
+	Rating    string    `json:"rating" gorm:"not null"`   // "up" or "down"
+	Comment   string    `json:"comment" gorm:"type:text"` // Optional explanation
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+/*
+* BeforeCreate generates a new UUID for the feedback
+ */
+func (f *MessageFeedback) BeforeCreate(tx *gorm.DB) (err error) {
+	if f.ID == "" {
+		f.ID = uuid.New().String()
+	}
+	return
+}
+
+/*
+* MessageFeedbackRequest holds a rating for an assistant message
+ */
+type MessageFeedbackRequest struct {
+	Rating  string `json:"rating" binding:"required,oneof=up down"`
+	Comment string `json:"comment" binding:"max=2000"`
+}
+
+/*
+* RatedAnswer is a negatively rated assistant message with the question it answered
+ */
+type RatedAnswer struct {
+	MessageID string    `json:"message_id"`
+	SessionID string    `json:"session_id"`
+	Question  string    `json:"question"`
+	Answer    string    `json:"answer"`
+	Comment   string    `json:"comment"`
+	RatedAt   time.Time `json:"rated_at"`
+}
@@ -0,0 +1,48 @@
+package models
+
+// Handoff statuses of a chat session
+const (
+	HandoffStatusPending = "pending" // Waiting for an operator
+	HandoffStatusActive  = "active"  // An operator has replied
+)
+
+// Presence of a participant in a conversation
+const (
+	PresenceOnline  = "online"
+	PresenceOffline = "offline"
+)
+
+// What triggered a handoff
+const (
+	HandoffReasonVisitor       = "visitor"        // The visitor asked for a human
+	HandoffReasonRule          = "rule"           // The message matched one of the bot's handoff keywords
+	HandoffReasonLowConfidence = "low_confidence" // Nothing relevant was found and the bot falls back to a human
+	HandoffReasonOperator      = "operator"       // An operator took over the conversation
+)
+
+/*
+ * HandoffEvent notifies operators and visitors about a human handoff
+ */
+type HandoffEvent struct {
+	Type      string   `json:"type"` // "handoff_requested" | "visitor_message" | "operator_message" | "handoff_closed" | "visitor_presence" | "visitor_typing" | "operator_typing"
+	SessionID string   `json:"session_id"`
+	BotID     string   `json:"bot_id"`
+	Reason    string   `json:"reason,omitempty"`   // Trigger for type="handoff_requested"
+	Message   *Message `json:"message,omitempty"`  // Chat message for type="visitor_message" and "operator_message"
+	Presence  string   `json:"presence,omitempty"` // "online" | "offline" for type="visitor_presence"
+	Typing    *bool    `json:"typing,omitempty"`   // Typing state for type="visitor_typing" and "operator_typing"
+}
+
+/*
+ * OperatorReplyRequest holds an operator's reply to a visitor
+ */
+type OperatorReplyRequest struct {
+	Content string `json:"content" binding:"required"`
+}
+
+/*
+ * TypingRequest tells the visitor whether an operator is typing
+ */
+type TypingRequest struct {
+	Typing bool `json:"typing"`
+}
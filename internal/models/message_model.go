@@ -11,15 +11,39 @@ import (
  * Message represents a chat message exchanged between user and bot
  */
 type Message struct {
-	ID         string         `json:"id" gorm:"primaryKey"`
-	SessionID  string         `json:"session_id" gorm:"not null;index"`
-	BotID      string         `json:"bot_id" gorm:"not null;index"`
-	UserID     *string        `json:"user_id" gorm:"index"` // Null for widget users
-	Role       string         `json:"role" gorm:"not null"` // "user" or "assistant"
-	Content    string         `json:"content" gorm:"type:text;not null"`
-	TokenCount int            `json:"token_count" gorm:"default:0"`
-	CreatedAt  time.Time      `json:"created_at" gorm:"autoCreateTime"`
-	DeletedAt  gorm.DeletedAt `json:"deleted_at" gorm:"index"`
+	ID               string         `json:"id" gorm:"primaryKey"`
+	SessionID        string         `json:"session_id" gorm:"not null;index"`
+	BotID            string         `json:"bot_id" gorm:"not null;index"`
+	UserID           *string        `json:"user_id" gorm:"index"` // Null for widget users
+	Role             string         `json:"role" gorm:"not null"` // "user", "assistant" or "operator"
+	Content          string         `json:"content" gorm:"type:text;not null"`
+	TokenCount       int            `json:"token_count" gorm:"default:0"`
+	Citations        string         `json:"citations" gorm:"type:text"`                   // JSON-encoded []Citation for assistant messages
+	RewrittenQuery   string         `json:"rewritten_query,omitempty" gorm:"type:text"`   // Standalone search query used for retrieval (user messages)
+	TokenBreakdown   string         `json:"token_breakdown,omitempty" gorm:"type:text"`   // JSON-encoded TokenBreakdown for assistant messages
+	PromptTokens     int            `json:"prompt_tokens,omitempty" gorm:"default:0"`     // Prompt tokens billed by the provider (assistant messages)
+	CompletionTokens int            `json:"completion_tokens,omitempty" gorm:"default:0"` // Completion tokens billed by the provider (assistant messages)
+	PromptVersionID  string         `json:"prompt_version_id,omitempty" gorm:"index"`     // System prompt version the answer was generated with
+	Unanswered       bool           `json:"unanswered" gorm:"default:false;index"`        // User question the knowledge base could not answer
+	CreatedAt        time.Time      `json:"created_at" gorm:"autoCreateTime"`
+	DeletedAt        gorm.DeletedAt `json:"deleted_at" gorm:"index"`
+}
+
+/*
+ * TokenBreakdown reports how the model's context window was spent on an answer
+ */
+type TokenBreakdown struct {
+	ContextWindow   int `json:"context_window"`   // Model context limit
+	System          int `json:"system"`           // System prompt
+	Knowledge       int `json:"knowledge"`        // Knowledge base context
+	Summary         int `json:"summary"`          // Summary of older conversation turns
+	History         int `json:"history"`          // Prior conversation turns
+	Question        int `json:"question"`         // The user's message
+	ReservedAnswer  int `json:"reserved_answer"`  // Room kept free for the answer
+	Answer          int `json:"answer"`           // Tokens actually generated
+	ChunksUsed      int `json:"chunks_used"`      // Retrieved chunks included in the prompt
+	ChunksTruncated int `json:"chunks_truncated"` // Included chunks that were cut to fit
+	ChunksDropped   int `json:"chunks_dropped"`   // Retrieved chunks left out for lack of room
 }
 
 /*
@@ -49,12 +73,30 @@ type MessageStats struct {
  * BotAnalytics represents overall analytics for a bot
  */
 type BotAnalytics struct {
-	BotID              string     `json:"bot_id"`
-	TotalMessages      int        `json:"total_messages"`
-	TotalTokens        int        `json:"total_tokens"`
-	TotalSessions      int        `json:"total_sessions"`
-	AvgMessagesPerDay  float64    `json:"avg_messages_per_day"`
-	AvgTokensPerDay    float64    `json:"avg_tokens_per_day"`
-	AvgMessagesSession float64    `json:"avg_messages_per_session"`
-	LastMessageAt      *time.Time `json:"last_message_at"`
+	BotID              string        `json:"bot_id"`
+	TotalMessages      int           `json:"total_messages"`
+	TotalTokens        int           `json:"total_tokens"`
+	TotalSessions      int           `json:"total_sessions"`
+	AvgMessagesPerDay  float64       `json:"avg_messages_per_day"`
+	AvgTokensPerDay    float64       `json:"avg_tokens_per_day"`
+	AvgMessagesSession float64       `json:"avg_messages_per_session"`
+	LastMessageAt      *time.Time    `json:"last_message_at"`
+	UnansweredCount    int           `json:"unanswered_count"` // Questions answered with a fallback
+	UnansweredRate     float64       `json:"unanswered_rate"`  // Unanswered share of user questions (0-1)
+	PositiveFeedback   int           `json:"positive_feedback"`
+	NegativeFeedback   int           `json:"negative_feedback"`
+	SatisfactionRate   float64       `json:"satisfaction_rate"` // Positive share of rated answers (0-1)
+	NegativeAnswers    []RatedAnswer `json:"negative_answers"`  // Most recent negatively rated answers
+}
+
+/*
+ * AnswerCacheStats reports how often repeated questions were answered from the semantic cache
+ */
+type AnswerCacheStats struct {
+	BotID   string  `json:"bot_id"`
+	Enabled bool    `json:"enabled"`
+	Hits    int64   `json:"hits"`
+	Misses  int64   `json:"misses"`
+	HitRate float64 `json:"hit_rate"` // Hit share of lookups (0-1)
+	Entries int     `json:"entries"`  // Answers currently cached for the bot
 }
@@ -0,0 +1,128 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+/*
+* PromptVersion is a saved revision of a bot's system prompt
+* Versions are never edited; rolling back records a new version with the old prompt
+ */
+type PromptVersion struct {
+	ID           string    `json:"id" gorm:"primaryKey"`
+	BotID        string    `json:"bot_id" gorm:"not null;uniqueIndex:idx_prompt_version_bot_number"`
+	Version      int       `json:"version" gorm:"not null;uniqueIndex:idx_prompt_version_bot_number"` // 1, 2, 3... per bot
+	SystemPrompt string    `json:"system_prompt" gorm:"type:text"`
+	Note         string    `json:"note"`           // Optional description of the change
+	UserID       *string   `json:"user_id"`        // Owner who made the change
+	RolledBackTo *int      `json:"rolled_back_to"` // Version restored by a rollback
+	CreatedAt    time.Time `json:"created_at"`
+}
+
+/*
+* BeforeCreate generates a new UUID for the prompt version
+ */
+func (v *PromptVersion) BeforeCreate(tx *gorm.DB) (err error) {
+	if v.ID == "" {
+		v.ID = uuid.New().String()
+	}
+	return
+}
+
+// Prompt experiment statuses
+const (
+	ExperimentStatusRunning = "running"
+	ExperimentStatusStopped = "stopped"
+)
+
+/*
+* PromptExperiment splits widget sessions across prompt versions
+* Sessions outside every variant's share get the bot's current prompt
+ */
+type PromptExperiment struct {
+	ID        string     `json:"id" gorm:"primaryKey"`
+	BotID     string     `json:"bot_id" gorm:"not null;index"`
+	Name      string     `json:"name" gorm:"not null"`
+	Status    string     `json:"status" gorm:"not null;default:'running';index"` // "running" or "stopped"
+	Variants  string     `json:"variants" gorm:"type:text"`                      // JSON-encoded []PromptVariant
+	CreatedAt time.Time  `json:"created_at"`
+	StoppedAt *time.Time `json:"stopped_at"`
+}
+
+/*
+* BeforeCreate generates a new UUID for the experiment and sets defaults
+ */
+func (e *PromptExperiment) BeforeCreate(tx *gorm.DB) (err error) {
+	if e.ID == "" {
+		e.ID = uuid.New().String()
+	}
+	if e.Status == "" {
+		e.Status = ExperimentStatusRunning
+	}
+	return
+}
+
+/*
+* PromptVariant assigns a share of an experiment's sessions to a prompt version
+ */
+type PromptVariant struct {
+	PromptVersionID string `json:"prompt_version_id"`
+	Percent         int    `json:"percent"` // Share of sessions (1-100)
+}
+
+/*
+* RollbackPromptRequest selects the version to restore
+ */
+type RollbackPromptRequest struct {
+	VersionID string `json:"version_id" binding:"required"`
+}
+
+/*
+* CreatePromptExperimentRequest holds data for starting a prompt experiment
+ */
+type CreatePromptExperimentRequest struct {
+	Name     string          `json:"name" binding:"required"`
+	Variants []PromptVariant `json:"variants" binding:"required"` // Shares must add up to at most 100
+}
+
+// Prompt diff line operations
+const (
+	DiffOpEqual  = "equal"
+	DiffOpAdd    = "add"
+	DiffOpRemove = "remove"
+)
+
+/*
+* PromptDiffLine is one line of a diff between two prompt versions
+ */
+type PromptDiffLine struct {
+	Op   string `json:"op"` // "equal", "add" or "remove"
+	Text string `json:"text"`
+}
+
+/*
+* PromptDiff compares two prompt versions line by line
+ */
+type PromptDiff struct {
+	From  PromptVersion    `json:"from"`
+	To    PromptVersion    `json:"to"`
+	Lines []PromptDiffLine `json:"lines"`
+}
+
+/*
+* PromptVersionStats compares the answers given with one prompt version
+ */
+type PromptVersionStats struct {
+	PromptVersionID  string  `json:"prompt_version_id"`
+	Version          int     `json:"version"`
+	UserMessages     int     `json:"user_messages"`
+	Answers          int     `json:"answers"`
+	UnansweredCount  int     `json:"unanswered_count"` // Questions answered with a fallback
+	FallbackRate     float64 `json:"fallback_rate"`    // Unanswered share of user questions (0-1)
+	PositiveFeedback int     `json:"positive_feedback"`
+	NegativeFeedback int     `json:"negative_feedback"`
+	SatisfactionRate float64 `json:"satisfaction_rate"` // Positive share of rated answers (0-1)
+}
@@ -0,0 +1,74 @@
+package models
+
+/*
+ * Status of a retrieved chunk in a search explanation
+ */
+const (
+	ChunkStatusUsed      = "used"      // Sent to the model as retrieved
+	ChunkStatusTruncated = "truncated" // Sent to the model, cut to fit the context window
+	ChunkStatusDropped   = "dropped"   // Left out for lack of room in the context window
+	ChunkStatusOutranked = "outranked" // Ranked below the context chunk limit by the reranker
+	ChunkStatusFiltered  = "filtered"  // Farther from the query than the distance threshold and no full-text match
+)
+
+/*
+ * ExplainSearchRequest holds a test question and optional retrieval overrides
+ */
+type ExplainSearchRequest struct {
+	Query             string   `json:"query" binding:"required"`
+	RewrittenQuery    string   `json:"rewritten_query"`                                         // Optional: searched instead of the query
+	Limit             *int     `json:"limit" binding:"omitempty,min=1,max=50"`                  // Optional: overrides the bot's max context chunks
+	DistanceThreshold *float64 `json:"distance_threshold" binding:"omitempty,min=0,max=2"`      // Optional: overrides the bot's threshold (0 = none)
+	Reranker          *string  `json:"reranker" binding:"omitempty,oneof=none bm25 llm"`        // Optional: overrides the bot's reranker ("none" = retrieval order)
+	RetrievalMode     *string  `json:"retrieval_mode" binding:"omitempty,oneof=similarity mmr"` // Optional: overrides the bot's retrieval mode
+	MMRLambda         *float64 `json:"mmr_lambda" binding:"omitempty,min=0,max=1"`              // Optional: overrides the bot's MMR relevance/diversity balance
+}
+
+/*
+ * ExplainedChunk is a retrieved chunk and what the chat pipeline would do with it
+ */
+type ExplainedChunk struct {
+	Rank         int          `json:"rank"` // Retrieval rank (1 = most relevant to the query)
+	ChunkID      string       `json:"chunk_id"`
+	SourceID     string       `json:"source_id"`
+	ChunkIndex   int          `json:"chunk_index"` // Position of the chunk within its source
+	Content      string       `json:"content"`
+	TokenCount   int          `json:"token_count"`
+	Distance     *float32     `json:"distance"`     // Cosine distance to the query (lower = closer; null without an embedding)
+	Score        float64      `json:"score"`        // Reciprocal rank fusion score (higher = more relevant)
+	VectorRank   int          `json:"vector_rank"`  // Rank among the vector matches (0 = not matched)
+	KeywordRank  int          `json:"keyword_rank"` // Rank among the full-text matches (0 = not matched)
+	RerankRank   int          `json:"rerank_rank"`  // Rank after reranking (0 = not reranked)
+	RerankScore  float64      `json:"rerank_score"` // Relevance assigned by the reranker (higher = more relevant)
+	URL          string       `json:"url,omitempty"`
+	Filename     string       `json:"filename,omitempty"`
+	SourceStatus SourceStatus `json:"source_status"`
+	Status       string       `json:"status"` // "used" | "truncated" | "dropped" | "outranked" | "filtered"
+}
+
+/*
+ * PromptMessage is a message of the prompt sent to the model
+ */
+type PromptMessage struct {
+	Role    string `json:"role"` // "system" | "user" | "assistant"
+	Content string `json:"content"`
+}
+
+/*
+ * SearchExplanation shows what a bot would retrieve for a question and the prompt it would send
+ */
+type SearchExplanation struct {
+	Query             string           `json:"query"`
+	SearchQuery       string           `json:"search_query"` // Query embedded for retrieval
+	Limit             int              `json:"limit"`
+	DistanceThreshold float64          `json:"distance_threshold"`
+	Model             string           `json:"model"`
+	Reranker          string           `json:"reranker"`               // "" when chunks keep the retrieval order
+	RerankError       string           `json:"rerank_error,omitempty"` // Why reranking failed (the retrieval order was used)
+	RetrievalMode     string           `json:"retrieval_mode"`         // "" = most similar chunks, "mmr" = relevant and diverse chunks
+	MMRLambda         float64          `json:"mmr_lambda"`             // Only applies in "mmr" mode
+	Chunks            []ExplainedChunk `json:"chunks"`
+	Prompt            []PromptMessage  `json:"prompt"`             // Empty when the fallback reply is sent instead
+	Fallback          string           `json:"fallback,omitempty"` // Reply sent instead of the model's when nothing relevant was found
+	Breakdown         TokenBreakdown   `json:"breakdown"`
+}
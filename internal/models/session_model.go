@@ -10,11 +10,14 @@ import (
 * ChatSession represents an anonymous user session for the widget
  */
 type ChatSession struct {
-	ID             string    `json:"id"`
-	BotID          string    `json:"bot_id"`
-	CreatedAt      time.Time `json:"created_at"`
-	LastActivityAt time.Time `json:"last_activity_at"`
-	ExpiresAt      time.Time `json:"expires_at"`
+	ID             string     `json:"id"`
+	BotID          string     `json:"bot_id"`
+	CreatedAt      time.Time  `json:"created_at"`
+	LastActivityAt time.Time  `json:"last_activity_at"`
+	ExpiresAt      time.Time  `json:"expires_at"`
+	HandoffStatus  string     `json:"handoff_status,omitempty"` // "" (bot answers), "pending" or "active"
+	HandoffReason  string     `json:"handoff_reason,omitempty"` // What triggered the handoff
+	HandoffAt      *time.Time `json:"handoff_at,omitempty"`     // When the handoff was requested
 }
 
 /*
@@ -54,6 +57,13 @@ func (s *ChatSession) IsExpired() bool {
 	return time.Now().After(s.ExpiresAt)
 }
 
+/*
+* InHandoff reports whether a human has taken over the conversation
+ */
+func (s *ChatSession) InHandoff() bool {
+	return s.HandoffStatus != ""
+}
+
 /*
 * UpdateActivity updates the last activity timestamp
  */
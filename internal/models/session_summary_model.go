@@ -0,0 +1,43 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+/*
+ * SessionSummary is a rolling LLM summary of the older turns of a chat session
+ * Messages up to and including LastMessageID are condensed into Content and no longer sent verbatim
+ */
+type SessionSummary struct {
+	ID            string    `json:"id" gorm:"primaryKey"`
+	SessionID     string    `json:"session_id" gorm:"not null;uniqueIndex"`
+	BotID         string    `json:"bot_id" gorm:"not null;index"`
+	Content       string    `json:"content" gorm:"type:text;not null"`
+	LastMessageID string    `json:"last_message_id" gorm:"not null"`
+	MessageCount  int       `json:"message_count" gorm:"default:0"` // Messages condensed so far
+	TokenCount    int       `json:"token_count" gorm:"default:0"`
+	CreatedAt     time.Time `json:"created_at" gorm:"autoCreateTime"`
+	UpdatedAt     time.Time `json:"updated_at" gorm:"autoUpdateTime"`
+}
+
+/*
+ * BeforeCreate generates a UUID for the summary if not set
+ */
+func (s *SessionSummary) BeforeCreate(tx *gorm.DB) error {
+	if s.ID == "" {
+		s.ID = uuid.New().String()
+	}
+	return nil
+}
+
+/*
+ * SessionTranscript is a session's messages with the summary of its older turns, if any
+ */
+type SessionTranscript struct {
+	SessionID string          `json:"session_id"`
+	Summary   *SessionSummary `json:"summary"`
+	Messages  []Message       `json:"messages"`
+}
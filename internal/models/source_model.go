@@ -35,13 +35,13 @@ const (
  */
 type Source struct {
 	ID                 string         `json:"id" gorm:"primaryKey"`
-	BotID              string         `json:"bot_id" gorm:"not null;index"`
+	BotID              string         `json:"bot_id" gorm:"not null;index;index:idx_sources_searchable,priority:1,where:deleted_at IS NULL"` // Searchable sources of a bot
 	SourceType         SourceType     `json:"source_type" gorm:"not null;default:'url'"`
 	URL                string         `json:"url"`
 	FilePath           string         `json:"file_path"`         // MinIO object path
 	OriginalFilename   string         `json:"original_filename"` // Original uploaded filename
 	ContentType        string         `json:"content_type"`      // MIME type
-	Status             SourceStatus   `json:"status" gorm:"not null;default:'pending'"`
+	Status             SourceStatus   `json:"status" gorm:"not null;default:'pending';index:idx_sources_searchable,priority:2"`
 	ProcessingProgress int            `json:"processing_progress"` // 0-100
 	ErrorMessage       string         `json:"error_message"`
 	ProcessedAt        *time.Time     `json:"processed_at"`
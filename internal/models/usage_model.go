@@ -14,9 +14,10 @@ type UsageRecord struct {
 	ID        string    `json:"id" gorm:"primaryKey"`
 	UserID    string    `json:"user_id" gorm:"not null;index"`
 	BotID     string    `json:"bot_id" gorm:"index"`
-	Type      string    `json:"type"`     // "chat_message", "embedding", "storage", "extra_bot"
-	Quantity  float64   `json:"quantity"` // Amount used (e.g., 1 message, 1000 tokens, 0.5 GB)
-	Cost      float64   `json:"cost"`     // Cost in USD
+	Type      string    `json:"type"`            // "chat_message", "chat_tokens", "helper_tokens", "embedding", "storage", "extra_bot"
+	Quantity  float64   `json:"quantity"`        // Amount used (e.g., 1 message, 1000 tokens, 0.5 GB)
+	Model     string    `json:"model,omitempty"` // Chat model for "chat_tokens" and "helper_tokens" records
+	Cost      float64   `json:"cost"`            // Cost in USD
 	BilledAt  time.Time `json:"billed_at"`
 	CreatedAt time.Time `json:"created_at"`
 }
@@ -33,8 +34,10 @@ func (u *UsageRecord) BeforeCreate(tx *gorm.DB) (err error) {
 
 // Usage Types
 const (
-	UsageTypeChatMessage = "chat_message"
-	UsageTypeEmbedding   = "embedding"
-	UsageTypeStorage     = "storage"
-	UsageTypeExtraBot    = "extra_bot"
+	UsageTypeChatMessage  = "chat_message"  // Legacy flat-priced chat message
+	UsageTypeChatTokens   = "chat_tokens"   // One answer, priced by the model's prompt and completion tokens
+	UsageTypeHelperTokens = "helper_tokens" // Supporting completion (query rewrite, rerank, summary, suggestions); not a message
+	UsageTypeEmbedding    = "embedding"
+	UsageTypeStorage      = "storage"
+	UsageTypeExtraBot     = "extra_bot"
 )
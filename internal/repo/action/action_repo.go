@@ -0,0 +1,103 @@
+package action
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/souravsspace/texly.chat/internal/models"
+	"gorm.io/gorm"
+)
+
+/*
+ * ActionRepository handles database operations for bot actions
+ */
+type ActionRepository struct {
+	db *gorm.DB
+}
+
+/*
+ * New creates a new ActionRepository instance
+ */
+func New(db *gorm.DB) *ActionRepository {
+	return &ActionRepository{db: db}
+}
+
+/*
+ * Create saves a new action to the database
+ */
+func (r *ActionRepository) Create(ctx context.Context, action *models.BotAction) error {
+	if err := r.db.WithContext(ctx).Create(action).Error; err != nil {
+		return fmt.Errorf("failed to create action: %w", err)
+	}
+	return nil
+}
+
+/*
+ * GetByID retrieves an action by ID scoped to its bot
+ * Returns nil if the action does not exist
+ */
+func (r *ActionRepository) GetByID(ctx context.Context, id string, botID string) (*models.BotAction, error) {
+	var action models.BotAction
+	err := r.db.WithContext(ctx).Where("id = ? AND bot_id = ?", id, botID).First(&action).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get action: %w", err)
+	}
+	return &action, nil
+}
+
+/*
+ * ListByBotID retrieves all actions for a bot
+ */
+func (r *ActionRepository) ListByBotID(ctx context.Context, botID string) ([]models.BotAction, error) {
+	var actions []models.BotAction
+	if err := r.db.WithContext(ctx).
+		Where("bot_id = ?", botID).
+		Order("created_at ASC").
+		Find(&actions).Error; err != nil {
+		return nil, fmt.Errorf("failed to list actions: %w", err)
+	}
+	return actions, nil
+}
+
+/*
+ * NameExists reports whether the bot has an action with the given name other than excludeID
+ */
+func (r *ActionRepository) NameExists(ctx context.Context, botID string, name string, excludeID string) (bool, error) {
+	var count int64
+	query := r.db.WithContext(ctx).Model(&models.BotAction{}).Where("bot_id = ? AND name = ?", botID, name)
+	if excludeID != "" {
+		query = query.Where("id <> ?", excludeID)
+	}
+	if err := query.Count(&count).Error; err != nil {
+		return false, fmt.Errorf("failed to check action name: %w", err)
+	}
+	return count > 0, nil
+}
+
+/*
+ * Update saves changes to an existing action
+ */
+func (r *ActionRepository) Update(ctx context.Context, action *models.BotAction) error {
+	if err := r.db.WithContext(ctx).Save(action).Error; err != nil {
+		return fmt.Errorf("failed to update action: %w", err)
+	}
+	return nil
+}
+
+/*
+ * Delete soft deletes an action scoped to its bot
+ */
+func (r *ActionRepository) Delete(ctx context.Context, id string, botID string) error {
+	result := r.db.WithContext(ctx).Where("id = ? AND bot_id = ?", id, botID).Delete(&models.BotAction{})
+	if result.Error != nil {
+		return fmt.Errorf("failed to delete action: %w", result.Error)
+	}
+	if result.RowsAffected == 0 {
+		return gorm.ErrRecordNotFound
+	}
+	return nil
+}
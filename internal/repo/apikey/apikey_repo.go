@@ -0,0 +1,89 @@
+package apikey
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/souravsspace/texly.chat/internal/models"
+	"gorm.io/gorm"
+)
+
+/*
+ * APIKeyRepository handles database operations for bot API keys
+ */
+type APIKeyRepository struct {
+	db *gorm.DB
+}
+
+/*
+ * New creates a new APIKeyRepository instance
+ */
+func New(db *gorm.DB) *APIKeyRepository {
+	return &APIKeyRepository{db: db}
+}
+
+/*
+ * Create saves a new API key to the database
+ */
+func (r *APIKeyRepository) Create(ctx context.Context, key *models.BotAPIKey) error {
+	if err := r.db.WithContext(ctx).Create(key).Error; err != nil {
+		return fmt.Errorf("failed to create api key: %w", err)
+	}
+	return nil
+}
+
+/*
+ * GetByHash retrieves an API key by the hash of its secret
+ * Returns nil if no active key matches
+ */
+func (r *APIKeyRepository) GetByHash(ctx context.Context, keyHash string) (*models.BotAPIKey, error) {
+	var key models.BotAPIKey
+	err := r.db.WithContext(ctx).Where("key_hash = ?", keyHash).First(&key).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get api key: %w", err)
+	}
+	return &key, nil
+}
+
+/*
+ * ListByBotID retrieves all API keys for a bot
+ */
+func (r *APIKeyRepository) ListByBotID(ctx context.Context, botID string) ([]models.BotAPIKey, error) {
+	var keys []models.BotAPIKey
+	if err := r.db.WithContext(ctx).
+		Where("bot_id = ?", botID).
+		Order("created_at DESC").
+		Find(&keys).Error; err != nil {
+		return nil, fmt.Errorf("failed to list api keys: %w", err)
+	}
+	return keys, nil
+}
+
+/*
+ * TouchLastUsed records when a key was last used
+ */
+func (r *APIKeyRepository) TouchLastUsed(ctx context.Context, id string) error {
+	return r.db.WithContext(ctx).
+		Model(&models.BotAPIKey{}).
+		Where("id = ?", id).
+		Update("last_used_at", time.Now()).Error
+}
+
+/*
+ * Delete revokes an API key scoped to its bot
+ */
+func (r *APIKeyRepository) Delete(ctx context.Context, id string, botID string) error {
+	result := r.db.WithContext(ctx).Where("id = ? AND bot_id = ?", id, botID).Delete(&models.BotAPIKey{})
+	if result.Error != nil {
+		return fmt.Errorf("failed to delete api key: %w", result.Error)
+	}
+	if result.RowsAffected == 0 {
+		return gorm.ErrRecordNotFound
+	}
+	return nil
+}
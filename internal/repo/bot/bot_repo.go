@@ -6,6 +6,7 @@ import (
 	"fmt"
 
 	"github.com/souravsspace/texly.chat/internal/models"
+	"github.com/souravsspace/texly.chat/internal/services/answercache"
 	"github.com/souravsspace/texly.chat/internal/services/cache"
 	"gorm.io/gorm"
 )
@@ -14,8 +15,9 @@ import (
 * BotRepo handles database operations for bots
  */
 type BotRepo struct {
-	db    *gorm.DB
-	cache *cache.CacheService
+	db          *gorm.DB
+	cache       *cache.CacheService
+	answerCache *answercache.AnswerCacheService // Optional: cached answers dropped when a bot changes
 }
 
 /*
@@ -25,6 +27,13 @@ func NewBotRepo(db *gorm.DB, cache *cache.CacheService) *BotRepo {
 	return &BotRepo{db: db, cache: cache}
 }
 
+/*
+* SetAnswerCache drops a bot's cached answers whenever the bot is updated or deleted
+ */
+func (r *BotRepo) SetAnswerCache(answerCache *answercache.AnswerCacheService) {
+	r.answerCache = answerCache
+}
+
 /*
 * Create inserts a new bot into the database
  */
@@ -103,6 +112,8 @@ func (r *BotRepo) Update(bot *models.Bot) error {
 	ctx := context.Background()
 	_ = r.cache.Delete(ctx, fmt.Sprintf(cache.BotCacheKey, bot.ID))
 	_ = r.cache.DeletePattern(ctx, fmt.Sprintf(cache.BotListCacheKey, bot.UserID))
+	// Answers depend on the bot's prompt and generation settings
+	_ = r.answerCache.Invalidate(ctx, bot.ID)
 	return nil
 }
 
@@ -121,6 +132,7 @@ func (r *BotRepo) Delete(id string, userID string) error {
 	ctx := context.Background()
 	_ = r.cache.Delete(ctx, fmt.Sprintf(cache.BotCacheKey, id))
 	_ = r.cache.DeletePattern(ctx, fmt.Sprintf(cache.BotListCacheKey, userID))
+	_ = r.answerCache.Invalidate(ctx, id)
 	return nil
 }
 
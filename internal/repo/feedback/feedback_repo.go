@@ -0,0 +1,60 @@
+package feedback
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/souravsspace/texly.chat/internal/models"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+/*
+ * FeedbackRepository handles database operations for message feedback
+ */
+type FeedbackRepository struct {
+	db *gorm.DB
+}
+
+/*
+ * New creates a new FeedbackRepository instance
+ */
+func New(db *gorm.DB) *FeedbackRepository {
+	return &FeedbackRepository{db: db}
+}
+
+/*
+ * Upsert saves a rating, replacing an earlier rating of the message from the same source
+ * The feedback is reloaded so it reflects the stored row
+ */
+func (r *FeedbackRepository) Upsert(ctx context.Context, feedback *models.MessageFeedback) error {
+	if err := r.db.WithContext(ctx).Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "message_id"}, {Name: "source"}},
+		DoUpdates: clause.AssignmentColumns([]string{"rating", "comment", "user_id", "updated_at"}),
+	}).Create(feedback).Error; err != nil {
+		return fmt.Errorf("failed to save feedback: %w", err)
+	}
+
+	var stored models.MessageFeedback
+	if err := r.db.WithContext(ctx).
+		Where("message_id = ? AND source = ?", feedback.MessageID, feedback.Source).
+		First(&stored).Error; err != nil {
+		return fmt.Errorf("failed to reload feedback: %w", err)
+	}
+	*feedback = stored
+	return nil
+}
+
+/*
+ * ListByMessageID retrieves all ratings of a message
+ */
+func (r *FeedbackRepository) ListByMessageID(ctx context.Context, messageID string) ([]models.MessageFeedback, error) {
+	var feedback []models.MessageFeedback
+	if err := r.db.WithContext(ctx).
+		Where("message_id = ?", messageID).
+		Order("created_at ASC").
+		Find(&feedback).Error; err != nil {
+		return nil, fmt.Errorf("failed to list feedback: %w", err)
+	}
+	return feedback, nil
+}
@@ -0,0 +1,86 @@
+package golden
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/souravsspace/texly.chat/internal/models"
+	"gorm.io/gorm"
+)
+
+/*
+ * GoldenRepository handles database operations for golden question sets
+ */
+type GoldenRepository struct {
+	db *gorm.DB
+}
+
+/*
+ * New creates a new GoldenRepository instance
+ */
+func New(db *gorm.DB) *GoldenRepository {
+	return &GoldenRepository{db: db}
+}
+
+/*
+ * Create saves a golden set together with its questions
+ */
+func (r *GoldenRepository) Create(ctx context.Context, set *models.GoldenSet) error {
+	if err := r.db.WithContext(ctx).Create(set).Error; err != nil {
+		return fmt.Errorf("failed to create golden set: %w", err)
+	}
+	return nil
+}
+
+/*
+ * GetByID retrieves a golden set with its questions scoped to its bot
+ * Returns nil if the set does not exist
+ */
+func (r *GoldenRepository) GetByID(ctx context.Context, id string, botID string) (*models.GoldenSet, error) {
+	var set models.GoldenSet
+	err := r.db.WithContext(ctx).
+		Preload("Questions", func(db *gorm.DB) *gorm.DB { return db.Order("position ASC") }).
+		Where("id = ? AND bot_id = ?", id, botID).
+		First(&set).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get golden set: %w", err)
+	}
+	return &set, nil
+}
+
+/*
+ * ListByBotID retrieves the golden sets of a bot without their questions
+ */
+func (r *GoldenRepository) ListByBotID(ctx context.Context, botID string) ([]models.GoldenSet, error) {
+	var sets []models.GoldenSet
+	if err := r.db.WithContext(ctx).
+		Where("bot_id = ?", botID).
+		Order("created_at DESC").
+		Find(&sets).Error; err != nil {
+		return nil, fmt.Errorf("failed to list golden sets: %w", err)
+	}
+	return sets, nil
+}
+
+/*
+ * Delete removes a golden set and its questions scoped to its bot
+ */
+func (r *GoldenRepository) Delete(ctx context.Context, id string, botID string) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		result := tx.Where("id = ? AND bot_id = ?", id, botID).Delete(&models.GoldenSet{})
+		if result.Error != nil {
+			return fmt.Errorf("failed to delete golden set: %w", result.Error)
+		}
+		if result.RowsAffected == 0 {
+			return gorm.ErrRecordNotFound
+		}
+		if err := tx.Where("golden_set_id = ?", id).Delete(&models.GoldenQuestion{}).Error; err != nil {
+			return fmt.Errorf("failed to delete golden questions: %w", err)
+		}
+		return nil
+	})
+}
@@ -2,6 +2,7 @@ package message
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"time"
 
@@ -33,6 +34,21 @@ func (r *MessageRepository) Create(ctx context.Context, message *models.Message)
 	return nil
 }
 
+/*
+ * GetByID retrieves a message by ID
+ * Returns nil if the message does not exist
+ */
+func (r *MessageRepository) GetByID(ctx context.Context, id string) (*models.Message, error) {
+	var message models.Message
+	if err := r.db.WithContext(ctx).Where("id = ?", id).First(&message).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to get message: %w", err)
+	}
+	return &message, nil
+}
+
 /*
  * GetBySessionID retrieves all messages for a session
  */
@@ -47,6 +63,31 @@ func (r *MessageRepository) GetBySessionID(ctx context.Context, sessionID string
 	return messages, nil
 }
 
+/*
+ * GetSessionSummary retrieves the rolling summary of a session
+ * Returns nil if the session has not been summarized
+ */
+func (r *MessageRepository) GetSessionSummary(ctx context.Context, sessionID string) (*models.SessionSummary, error) {
+	var summary models.SessionSummary
+	if err := r.db.WithContext(ctx).Where("session_id = ?", sessionID).First(&summary).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to get session summary: %w", err)
+	}
+	return &summary, nil
+}
+
+/*
+ * SaveSessionSummary creates or updates the rolling summary of a session
+ */
+func (r *MessageRepository) SaveSessionSummary(ctx context.Context, summary *models.SessionSummary) error {
+	if err := r.db.WithContext(ctx).Save(summary).Error; err != nil {
+		return fmt.Errorf("failed to save session summary: %w", err)
+	}
+	return nil
+}
+
 /*
  * GetByBotID retrieves all messages for a bot
  */
@@ -137,6 +178,8 @@ func (r *MessageRepository) GetBotAnalytics(ctx context.Context, botID string) (
 		AvgTokensPerDay    float64
 		AvgMessagesSession float64
 		LastMessageAt      *string
+		UserMessages       int
+		UnansweredCount    int
 	}
 
 	var raw rawAnalytics
@@ -150,7 +193,9 @@ func (r *MessageRepository) GetBotAnalytics(ctx context.Context, botID string) (
 			CAST(COUNT(*) AS FLOAT) / NULLIF(EXTRACT(EPOCH FROM (MAX(created_at) - MIN(created_at))) / 86400, 0) as avg_messages_per_day,
 			CAST(SUM(token_count) AS FLOAT) / NULLIF(EXTRACT(EPOCH FROM (MAX(created_at) - MIN(created_at))) / 86400, 0) as avg_tokens_per_day,
 			CAST(COUNT(*) AS FLOAT) / NULLIF(COUNT(DISTINCT session_id), 0) as avg_messages_session,
-			TO_CHAR(MAX(created_at), 'YYYY-MM-DD HH24:MI:SS') as last_message_at
+			TO_CHAR(MAX(created_at), 'YYYY-MM-DD HH24:MI:SS') as last_message_at,
+			SUM(CASE WHEN role = 'user' THEN 1 ELSE 0 END) as user_messages,
+			SUM(CASE WHEN role = 'user' AND unanswered THEN 1 ELSE 0 END) as unanswered_count
 		FROM messages
 		WHERE bot_id = $1
 		AND deleted_at IS NULL
@@ -178,6 +223,8 @@ func (r *MessageRepository) GetBotAnalytics(ctx context.Context, botID string) (
 		AvgMessagesPerDay:  raw.AvgMessagesPerDay,
 		AvgTokensPerDay:    raw.AvgTokensPerDay,
 		AvgMessagesSession: raw.AvgMessagesSession,
+		UnansweredCount:    raw.UnansweredCount,
+		UnansweredRate:     unansweredRate(raw.UnansweredCount, raw.UserMessages),
 	}
 
 	// Parse LastMessageAt if available
@@ -188,9 +235,119 @@ func (r *MessageRepository) GetBotAnalytics(ctx context.Context, botID string) (
 		}
 	}
 
+	if err := r.loadFeedbackStats(ctx, analytics); err != nil {
+		return nil, err
+	}
+
 	return analytics, nil
 }
 
+/*
+ * negativeAnswersLimit caps how many negatively rated answers analytics returns
+ */
+const negativeAnswersLimit = 10
+
+/*
+ * loadFeedbackStats adds satisfaction rates and recent negatively rated answers to bot analytics
+ */
+func (r *MessageRepository) loadFeedbackStats(ctx context.Context, analytics *models.BotAnalytics) error {
+	var counts struct {
+		PositiveFeedback int
+		NegativeFeedback int
+	}
+
+	countQuery := `
+		SELECT
+			COALESCE(SUM(CASE WHEN rating = 'up' THEN 1 ELSE 0 END), 0) as positive_feedback,
+			COALESCE(SUM(CASE WHEN rating = 'down' THEN 1 ELSE 0 END), 0) as negative_feedback
+		FROM message_feedbacks
+		WHERE bot_id = $1
+	`
+
+	if err := r.db.WithContext(ctx).Raw(countQuery, analytics.BotID).Scan(&counts).Error; err != nil {
+		return fmt.Errorf("failed to get feedback stats: %w", err)
+	}
+
+	analytics.PositiveFeedback = counts.PositiveFeedback
+	analytics.NegativeFeedback = counts.NegativeFeedback
+	if rated := counts.PositiveFeedback + counts.NegativeFeedback; rated > 0 {
+		analytics.SatisfactionRate = float64(counts.PositiveFeedback) / float64(rated)
+	}
+
+	// Each negatively rated answer with the closest preceding question of its session
+	answersQuery := `
+		SELECT
+			f.message_id,
+			f.session_id,
+			m.content as answer,
+			f.comment,
+			f.updated_at as rated_at,
+			COALESCE((
+				SELECT q.content FROM messages q
+				WHERE q.session_id = m.session_id
+				AND q.role = 'user'
+				AND q.created_at <= m.created_at
+				AND q.deleted_at IS NULL
+				ORDER BY q.created_at DESC
+				LIMIT 1
+			), '') as question
+		FROM message_feedbacks f
+		INNER JOIN messages m ON m.id = f.message_id
+		WHERE f.bot_id = $1
+		AND f.rating = 'down'
+		AND m.deleted_at IS NULL
+		ORDER BY f.updated_at DESC
+		LIMIT $2
+	`
+
+	analytics.NegativeAnswers = []models.RatedAnswer{}
+	if err := r.db.WithContext(ctx).Raw(answersQuery, analytics.BotID, negativeAnswersLimit).Scan(&analytics.NegativeAnswers).Error; err != nil {
+		return fmt.Errorf("failed to get negatively rated answers: %w", err)
+	}
+
+	return nil
+}
+
+/*
+ * GetPromptVersionStats compares fallback rates and feedback of the answers given with each
+ * system prompt version of a bot. Messages stored before prompts were versioned are left out
+ */
+func (r *MessageRepository) GetPromptVersionStats(ctx context.Context, botID string) ([]models.PromptVersionStats, error) {
+	// Ratings only exist on assistant messages, so joining them leaves user message counts intact
+	query := `
+		SELECT
+			m.prompt_version_id,
+			COALESCE(v.version, 0) as version,
+			COUNT(DISTINCT CASE WHEN m.role = 'user' THEN m.id END) as user_messages,
+			COUNT(DISTINCT CASE WHEN m.role = 'assistant' THEN m.id END) as answers,
+			COUNT(DISTINCT CASE WHEN m.role = 'user' AND m.unanswered THEN m.id END) as unanswered_count,
+			COALESCE(SUM(CASE WHEN f.rating = 'up' THEN 1 ELSE 0 END), 0) as positive_feedback,
+			COALESCE(SUM(CASE WHEN f.rating = 'down' THEN 1 ELSE 0 END), 0) as negative_feedback
+		FROM messages m
+		LEFT JOIN prompt_versions v ON v.id = m.prompt_version_id
+		LEFT JOIN message_feedbacks f ON f.message_id = m.id
+		WHERE m.bot_id = $1
+		AND m.prompt_version_id <> ''
+		AND m.deleted_at IS NULL
+		GROUP BY m.prompt_version_id, v.version
+		ORDER BY version DESC
+	`
+
+	stats := []models.PromptVersionStats{}
+	if err := r.db.WithContext(ctx).Raw(query, botID).Scan(&stats).Error; err != nil {
+		return nil, fmt.Errorf("failed to get prompt version stats: %w", err)
+	}
+
+	for i := range stats {
+		stats[i].FallbackRate = unansweredRate(stats[i].UnansweredCount, stats[i].UserMessages)
+		if rated := stats[i].PositiveFeedback + stats[i].NegativeFeedback; rated > 0 {
+			stats[i].SatisfactionRate = float64(stats[i].PositiveFeedback) / float64(rated)
+		}
+	}
+
+	return stats, nil
+}
+
 /*
  * GetUserAnalytics retrieves analytics for all bots owned by a user
  */
@@ -204,6 +361,8 @@ func (r *MessageRepository) GetUserAnalytics(ctx context.Context, userID string)
 		AvgTokensPerDay    float64
 		AvgMessagesSession float64
 		LastMessageAt      *string
+		UserMessages       int
+		UnansweredCount    int
 	}
 
 	var results []rawResult
@@ -217,7 +376,9 @@ func (r *MessageRepository) GetUserAnalytics(ctx context.Context, userID string)
 			CAST(COUNT(*) AS FLOAT) / NULLIF(EXTRACT(EPOCH FROM (MAX(m.created_at) - MIN(m.created_at))) / 86400, 0) as avg_messages_per_day,
 			CAST(SUM(m.token_count) AS FLOAT) / NULLIF(EXTRACT(EPOCH FROM (MAX(m.created_at) - MIN(m.created_at))) / 86400, 0) as avg_tokens_per_day,
 			CAST(COUNT(*) AS FLOAT) / NULLIF(COUNT(DISTINCT m.session_id), 0) as avg_messages_session,
-			TO_CHAR(MAX(m.created_at), 'YYYY-MM-DD HH24:MI:SS') as last_message_at
+			TO_CHAR(MAX(m.created_at), 'YYYY-MM-DD HH24:MI:SS') as last_message_at,
+			SUM(CASE WHEN m.role = 'user' THEN 1 ELSE 0 END) as user_messages,
+			SUM(CASE WHEN m.role = 'user' AND m.unanswered THEN 1 ELSE 0 END) as unanswered_count
 		FROM messages m
 		INNER JOIN bots b ON m.bot_id = b.id
 		WHERE b.user_id = $1
@@ -240,6 +401,8 @@ func (r *MessageRepository) GetUserAnalytics(ctx context.Context, userID string)
 			AvgMessagesPerDay:  result.AvgMessagesPerDay,
 			AvgTokensPerDay:    result.AvgTokensPerDay,
 			AvgMessagesSession: result.AvgMessagesSession,
+			UnansweredCount:    result.UnansweredCount,
+			UnansweredRate:     unansweredRate(result.UnansweredCount, result.UserMessages),
 		}
 
 		// Parse LastMessageAt if available
@@ -256,6 +419,16 @@ func (r *MessageRepository) GetUserAnalytics(ctx context.Context, userID string)
 	return analytics, nil
 }
 
+/*
+ * unansweredRate returns the share of user questions that were unanswered
+ */
+func unansweredRate(unanswered, userMessages int) float64 {
+	if userMessages == 0 {
+		return 0
+	}
+	return float64(unanswered) / float64(userMessages)
+}
+
 /*
  * CountMessagesByBot counts total messages for a bot
  */
@@ -269,3 +442,31 @@ func (r *MessageRepository) CountMessagesByBot(ctx context.Context, botID string
 	}
 	return count, nil
 }
+
+/*
+ * MarkUnanswered flags a user message as a question the bot could not answer
+ */
+func (r *MessageRepository) MarkUnanswered(ctx context.Context, messageID string) error {
+	if err := r.db.WithContext(ctx).
+		Model(&models.Message{}).
+		Where("id = ?", messageID).
+		Update("unanswered", true).Error; err != nil {
+		return fmt.Errorf("failed to mark message unanswered: %w", err)
+	}
+	return nil
+}
+
+/*
+ * GetUnansweredByBotID retrieves the most recent unanswered questions for a bot
+ */
+func (r *MessageRepository) GetUnansweredByBotID(ctx context.Context, botID string, limit int) ([]models.Message, error) {
+	var messages []models.Message
+	if err := r.db.WithContext(ctx).
+		Where("bot_id = ? AND role = ? AND unanswered = ?", botID, "user", true).
+		Order("created_at DESC").
+		Limit(limit).
+		Find(&messages).Error; err != nil {
+		return nil, fmt.Errorf("failed to get unanswered messages: %w", err)
+	}
+	return messages, nil
+}
@@ -324,3 +324,135 @@ func TestGetUserAnalytics(t *testing.T) {
 	assert.Contains(t, analytics, bot.ID)
 	assert.GreaterOrEqual(t, analytics[bot.ID].TotalMessages, 1)
 }
+
+/*
+ * Test MarkUnanswered and GetUnansweredByBotID
+ */
+func TestUnansweredQuestions(t *testing.T) {
+	db := shared.SetupTestDB()
+	repo := New(db)
+	ctx := context.Background()
+
+	botID := "bot-unanswered-test"
+	sessionID := "session-unanswered"
+
+	messages := []models.Message{
+		{SessionID: sessionID, BotID: botID, Role: "user", Content: "Do you ship to Mars?", TokenCount: 5},
+		{SessionID: sessionID, BotID: botID, Role: "assistant", Content: "I'm sorry, I don't know.", TokenCount: 6},
+		{SessionID: sessionID, BotID: botID, Role: "user", Content: "What are your hours?", TokenCount: 5},
+	}
+	for i := range messages {
+		require.NoError(t, repo.Create(ctx, &messages[i]))
+	}
+
+	require.NoError(t, repo.MarkUnanswered(ctx, messages[0].ID))
+
+	unanswered, err := repo.GetUnansweredByBotID(ctx, botID, 10)
+	require.NoError(t, err)
+	require.Len(t, unanswered, 1)
+	assert.Equal(t, "Do you ship to Mars?", unanswered[0].Content)
+
+	analytics, err := repo.GetBotAnalytics(ctx, botID)
+	require.NoError(t, err)
+	assert.Equal(t, 1, analytics.UnansweredCount)
+	assert.Equal(t, 0.5, analytics.UnansweredRate)
+}
+
+/*
+ * Test GetBotAnalytics includes satisfaction rates and negatively rated answers
+ */
+func TestGetBotAnalytics_Feedback(t *testing.T) {
+	db := shared.SetupTestDB()
+	repo := New(db)
+	ctx := context.Background()
+
+	botID := "bot-feedback-test"
+	sessionID := "session-feedback"
+
+	messages := []models.Message{
+		{SessionID: sessionID, BotID: botID, Role: "user", Content: "Do you ship abroad?", TokenCount: 5},
+		{SessionID: sessionID, BotID: botID, Role: "assistant", Content: "No, we don't.", TokenCount: 5},
+		{SessionID: sessionID, BotID: botID, Role: "user", Content: "What are your hours?", TokenCount: 5},
+		{SessionID: sessionID, BotID: botID, Role: "assistant", Content: "9 to 5.", TokenCount: 5},
+	}
+	for i := range messages {
+		require.NoError(t, repo.Create(ctx, &messages[i]))
+		time.Sleep(10 * time.Millisecond) // Keep created_at ordering stable
+	}
+
+	feedback := []models.MessageFeedback{
+		{MessageID: messages[1].ID, Source: models.FeedbackSourceVisitor, SessionID: sessionID, BotID: botID, Rating: models.FeedbackRatingDown, Comment: "You ship to Canada"},
+		{MessageID: messages[3].ID, Source: models.FeedbackSourceVisitor, SessionID: sessionID, BotID: botID, Rating: models.FeedbackRatingUp},
+		{MessageID: messages[3].ID, Source: models.FeedbackSourceOwner, SessionID: sessionID, BotID: botID, Rating: models.FeedbackRatingUp},
+	}
+	for i := range feedback {
+		require.NoError(t, db.Create(&feedback[i]).Error)
+	}
+
+	analytics, err := repo.GetBotAnalytics(ctx, botID)
+	require.NoError(t, err)
+	assert.Equal(t, 2, analytics.PositiveFeedback)
+	assert.Equal(t, 1, analytics.NegativeFeedback)
+	assert.InDelta(t, 2.0/3.0, analytics.SatisfactionRate, 0.001)
+	require.Len(t, analytics.NegativeAnswers, 1)
+	assert.Equal(t, "Do you ship abroad?", analytics.NegativeAnswers[0].Question)
+	assert.Equal(t, "No, we don't.", analytics.NegativeAnswers[0].Answer)
+	assert.Equal(t, "You ship to Canada", analytics.NegativeAnswers[0].Comment)
+}
+
+/*
+ * Test GetPromptVersionStats compares fallback rates and feedback per prompt version
+ */
+func TestGetPromptVersionStats(t *testing.T) {
+	db := shared.SetupTestDB()
+	repo := New(db)
+	ctx := context.Background()
+
+	botID := "bot-prompt-test"
+	versions := []models.PromptVersion{
+		{BotID: botID, Version: 1, SystemPrompt: "Be brief."},
+		{BotID: botID, Version: 2, SystemPrompt: "Be thorough."},
+	}
+	for i := range versions {
+		require.NoError(t, db.Create(&versions[i]).Error)
+	}
+
+	messages := []models.Message{
+		{SessionID: "session-a", BotID: botID, Role: "user", Content: "Do you ship abroad?", PromptVersionID: versions[0].ID},
+		{SessionID: "session-a", BotID: botID, Role: "assistant", Content: "Sorry, I don't know.", PromptVersionID: versions[0].ID},
+		{SessionID: "session-b", BotID: botID, Role: "user", Content: "Do you ship abroad?", PromptVersionID: versions[1].ID},
+		{SessionID: "session-b", BotID: botID, Role: "assistant", Content: "Yes, to Canada.", PromptVersionID: versions[1].ID},
+		{SessionID: "session-c", BotID: botID, Role: "user", Content: "Before versioning"},
+	}
+	for i := range messages {
+		require.NoError(t, repo.Create(ctx, &messages[i]))
+	}
+	require.NoError(t, repo.MarkUnanswered(ctx, messages[0].ID))
+
+	feedback := []models.MessageFeedback{
+		{MessageID: messages[1].ID, Source: models.FeedbackSourceVisitor, SessionID: "session-a", BotID: botID, Rating: models.FeedbackRatingDown},
+		{MessageID: messages[3].ID, Source: models.FeedbackSourceVisitor, SessionID: "session-b", BotID: botID, Rating: models.FeedbackRatingUp},
+		{MessageID: messages[3].ID, Source: models.FeedbackSourceOwner, SessionID: "session-b", BotID: botID, Rating: models.FeedbackRatingUp},
+	}
+	for i := range feedback {
+		require.NoError(t, db.Create(&feedback[i]).Error)
+	}
+
+	stats, err := repo.GetPromptVersionStats(ctx, botID)
+	require.NoError(t, err)
+	require.Len(t, stats, 2)
+
+	// Newest version first
+	assert.Equal(t, 2, stats[0].Version)
+	assert.Equal(t, 1, stats[0].UserMessages)
+	assert.Equal(t, 1, stats[0].Answers)
+	assert.Equal(t, 0.0, stats[0].FallbackRate)
+	assert.Equal(t, 2, stats[0].PositiveFeedback)
+	assert.Equal(t, 1.0, stats[0].SatisfactionRate)
+
+	assert.Equal(t, 1, stats[1].Version)
+	assert.Equal(t, 1, stats[1].UnansweredCount)
+	assert.Equal(t, 1.0, stats[1].FallbackRate)
+	assert.Equal(t, 1, stats[1].NegativeFeedback)
+	assert.Equal(t, 0.0, stats[1].SatisfactionRate)
+}
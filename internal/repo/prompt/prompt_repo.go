@@ -0,0 +1,148 @@
+package prompt
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/souravsspace/texly.chat/internal/models"
+	"gorm.io/gorm"
+)
+
+/*
+ * PromptRepository handles database operations for prompt versions and experiments
+ */
+type PromptRepository struct {
+	db *gorm.DB
+}
+
+/*
+ * New creates a new PromptRepository instance
+ */
+func New(db *gorm.DB) *PromptRepository {
+	return &PromptRepository{db: db}
+}
+
+/*
+ * CreateVersion saves a prompt version under the next version number of its bot
+ */
+func (r *PromptRepository) CreateVersion(ctx context.Context, version *models.PromptVersion) error {
+	err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		var latest int
+		if err := tx.Model(&models.PromptVersion{}).
+			Where("bot_id = ?", version.BotID).
+			Select("COALESCE(MAX(version), 0)").
+			Scan(&latest).Error; err != nil {
+			return err
+		}
+		version.Version = latest + 1
+		return tx.Create(version).Error
+	})
+	if err != nil {
+		return fmt.Errorf("failed to create prompt version: %w", err)
+	}
+	return nil
+}
+
+/*
+ * GetVersion retrieves a prompt version by ID scoped to its bot
+ * Returns nil if the version does not exist
+ */
+func (r *PromptRepository) GetVersion(ctx context.Context, id string, botID string) (*models.PromptVersion, error) {
+	var version models.PromptVersion
+	err := r.db.WithContext(ctx).Where("id = ? AND bot_id = ?", id, botID).First(&version).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get prompt version: %w", err)
+	}
+	return &version, nil
+}
+
+/*
+ * ListVersions retrieves all prompt versions of a bot, newest first
+ */
+func (r *PromptRepository) ListVersions(ctx context.Context, botID string) ([]models.PromptVersion, error) {
+	var versions []models.PromptVersion
+	if err := r.db.WithContext(ctx).
+		Where("bot_id = ?", botID).
+		Order("version DESC").
+		Find(&versions).Error; err != nil {
+		return nil, fmt.Errorf("failed to list prompt versions: %w", err)
+	}
+	return versions, nil
+}
+
+/*
+ * CreateExperiment saves a new prompt experiment
+ */
+func (r *PromptRepository) CreateExperiment(ctx context.Context, experiment *models.PromptExperiment) error {
+	if err := r.db.WithContext(ctx).Create(experiment).Error; err != nil {
+		return fmt.Errorf("failed to create prompt experiment: %w", err)
+	}
+	return nil
+}
+
+/*
+ * GetExperiment retrieves a prompt experiment by ID scoped to its bot
+ * Returns nil if the experiment does not exist
+ */
+func (r *PromptRepository) GetExperiment(ctx context.Context, id string, botID string) (*models.PromptExperiment, error) {
+	var experiment models.PromptExperiment
+	err := r.db.WithContext(ctx).Where("id = ? AND bot_id = ?", id, botID).First(&experiment).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get prompt experiment: %w", err)
+	}
+	return &experiment, nil
+}
+
+/*
+ * GetRunningExperiment retrieves the experiment currently running for a bot
+ * Returns nil if no experiment is running
+ */
+func (r *PromptRepository) GetRunningExperiment(ctx context.Context, botID string) (*models.PromptExperiment, error) {
+	var experiment models.PromptExperiment
+	err := r.db.WithContext(ctx).
+		Where("bot_id = ? AND status = ?", botID, models.ExperimentStatusRunning).
+		Order("created_at DESC").
+		First(&experiment).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get running prompt experiment: %w", err)
+	}
+	return &experiment, nil
+}
+
+/*
+ * ListExperiments retrieves all prompt experiments of a bot, newest first
+ */
+func (r *PromptRepository) ListExperiments(ctx context.Context, botID string) ([]models.PromptExperiment, error) {
+	var experiments []models.PromptExperiment
+	if err := r.db.WithContext(ctx).
+		Where("bot_id = ?", botID).
+		Order("created_at DESC").
+		Find(&experiments).Error; err != nil {
+		return nil, fmt.Errorf("failed to list prompt experiments: %w", err)
+	}
+	return experiments, nil
+}
+
+/*
+ * StopExperiment marks a running experiment as stopped
+ */
+func (r *PromptRepository) StopExperiment(ctx context.Context, experiment *models.PromptExperiment) error {
+	now := time.Now()
+	experiment.Status = models.ExperimentStatusStopped
+	experiment.StoppedAt = &now
+	if err := r.db.WithContext(ctx).Save(experiment).Error; err != nil {
+		return fmt.Errorf("failed to stop prompt experiment: %w", err)
+	}
+	return nil
+}
@@ -6,6 +6,7 @@ import (
 	"time"
 
 	"github.com/souravsspace/texly.chat/internal/models"
+	"github.com/souravsspace/texly.chat/internal/services/answercache"
 	"github.com/souravsspace/texly.chat/internal/services/cache"
 	"gorm.io/gorm"
 )
@@ -14,8 +15,9 @@ import (
 * SourceRepo handles database operations for sources
  */
 type SourceRepo struct {
-	db    *gorm.DB
-	cache *cache.CacheService
+	db          *gorm.DB
+	cache       *cache.CacheService
+	answerCache *answercache.AnswerCacheService // Optional: cached answers dropped when a source changes
 }
 
 /*
@@ -25,6 +27,13 @@ func NewSourceRepo(db *gorm.DB, cache *cache.CacheService) *SourceRepo {
 	return &SourceRepo{db: db, cache: cache}
 }
 
+/*
+* SetAnswerCache drops the owning bot's cached answers when a source is processed or deleted
+ */
+func (r *SourceRepo) SetAnswerCache(answerCache *answercache.AnswerCacheService) {
+	r.answerCache = answerCache
+}
+
 /*
 * Create creates a new source
  */
@@ -103,6 +112,10 @@ func (r *SourceRepo) UpdateStatus(id string, status models.SourceStatus, errorMs
 
 	// Invalidate source cache
 	_ = r.cache.Delete(context.Background(), fmt.Sprintf(cache.SourceCacheKey, id))
+	// Newly processed content changes what the bot knows
+	if status == models.SourceStatusCompleted {
+		r.invalidateAnswers(id)
+	}
 	return nil
 }
 
@@ -115,9 +128,26 @@ func (r *SourceRepo) Delete(id string) error {
 	}
 	// Invalidate source cache
 	_ = r.cache.Delete(context.Background(), fmt.Sprintf(cache.SourceCacheKey, id))
+	r.invalidateAnswers(id)
 	return nil
 }
 
+/*
+* invalidateAnswers drops the cached answers of the bot owning a source
+ */
+func (r *SourceRepo) invalidateAnswers(id string) {
+	if r.answerCache == nil || !r.cache.IsEnabled() {
+		return
+	}
+	var botIDs []string
+	if err := r.db.Unscoped().Model(&models.Source{}).Where("id = ?", id).Pluck("bot_id", &botIDs).Error; err != nil {
+		return
+	}
+	for _, botID := range botIDs {
+		_ = r.answerCache.Invalidate(context.Background(), botID)
+	}
+}
+
 /*
 * GetByBotIDAndSourceID retrieves a source by bot ID and source ID (for authorization)
  */
@@ -0,0 +1,389 @@
+package vector
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/souravsspace/texly.chat/internal/models"
+	"gorm.io/gorm"
+)
+
+/*
+ * Approximate nearest neighbour index types supported by pgvector
+ */
+const (
+	IndexTypeNone    = "none"    // Exact search (sequential scan)
+	IndexTypeHNSW    = "hnsw"    // Graph index: best speed/recall, slower to build
+	IndexTypeIVFFlat = "ivfflat" // Cluster index: fast to build, needs rebuilding as data grows
+)
+
+// IndexName is the name of the managed index on document_chunks.embedding
+const IndexName = "idx_document_chunks_embedding"
+
+// maxIndexDimension is the largest vector dimension pgvector can index
+const maxIndexDimension = 2000
+
+// indexLockKey is the Postgres advisory lock held while the managed index is built
+const indexLockKey int64 = 0x7465786c79 // "texly"
+
+var (
+	ErrUnknownIndexType     = errors.New("unknown vector index type")
+	ErrNoIndexType          = errors.New("no vector index type configured")
+	ErrIndexBuildInProgress = errors.New("vector index build already in progress")
+)
+
+/*
+ * IndexOptions configures the managed index and the search parameters used with it
+ */
+type IndexOptions struct {
+	Type               string // IndexTypeNone | IndexTypeHNSW | IndexTypeIVFFlat
+	HNSWM              int    // Connections per graph node
+	HNSWEfConstruction int    // Candidates examined while building the graph
+	HNSWEfSearch       int    // Candidates examined per search (raised to the search limit)
+	IVFFlatLists       int    // Clusters (0 = sized from the number of embedded chunks)
+	IVFFlatProbes      int    // Clusters searched per query
+	IterativeScan      bool   // Keep scanning until filtered searches fill their page (pgvector 0.8+)
+}
+
+/*
+ * IndexStatus describes the managed index and whether it matches the configured options
+ */
+type IndexStatus struct {
+	Name             string         `json:"name"`
+	Configured       string         `json:"configured"` // Configured index type
+	Exists           bool           `json:"exists"`
+	Method           string         `json:"method,omitempty"` // Index type in the database
+	Params           map[string]int `json:"params,omitempty"` // e.g. m, ef_construction or lists
+	Definition       string         `json:"definition,omitempty"`
+	SizeBytes        int64          `json:"size_bytes"`
+	Valid            bool           `json:"valid"` // False after a failed concurrent build
+	EmbeddedChunks   int64          `json:"embedded_chunks"`
+	RecommendedLists int            `json:"recommended_lists,omitempty"` // IVFFlat only
+	NeedsRebuild     bool           `json:"needs_rebuild"`
+	Reason           string         `json:"reason,omitempty"` // Why a rebuild is needed
+}
+
+/*
+ * SetIndexOptions sets the index managed by the repository and the search parameters used with it
+ */
+func (r *VectorRepository) SetIndexOptions(opts IndexOptions) {
+	r.index = opts
+}
+
+/*
+ * IndexStatus reports the managed index, its size and whether it should be rebuilt
+ */
+func (r *VectorRepository) IndexStatus(ctx context.Context) (*IndexStatus, error) {
+	status := &IndexStatus{Name: IndexName, Configured: r.indexType()}
+
+	if err := r.db.WithContext(ctx).
+		Model(&models.DocumentChunk{}).
+		Where("embedding IS NOT NULL").
+		Count(&status.EmbeddedChunks).Error; err != nil {
+		return nil, fmt.Errorf("failed to count embedded chunks: %w", err)
+	}
+
+	var rows []struct {
+		Method     string
+		Definition string
+		SizeBytes  int64
+		Valid      bool
+	}
+	err := r.db.WithContext(ctx).Raw(`
+		SELECT am.amname AS method, pg_get_indexdef(i.indexrelid) AS definition,
+			pg_relation_size(i.indexrelid) AS size_bytes, i.indisvalid AS valid
+		FROM pg_index i
+		JOIN pg_class c ON c.oid = i.indexrelid
+		JOIN pg_am am ON am.oid = c.relam
+		WHERE i.indrelid = 'document_chunks'::regclass AND c.relname = ?`, IndexName).
+		Scan(&rows).Error
+	if err != nil {
+		return nil, fmt.Errorf("failed to read vector index: %w", err)
+	}
+
+	if len(rows) > 0 {
+		status.Exists = true
+		status.Method = rows[0].Method
+		status.Definition = rows[0].Definition
+		status.SizeBytes = rows[0].SizeBytes
+		status.Valid = rows[0].Valid
+		status.Params = parseIndexParams(rows[0].Definition)
+	}
+	if status.Configured == IndexTypeIVFFlat {
+		status.RecommendedLists = RecommendedLists(status.EmbeddedChunks)
+	}
+
+	status.NeedsRebuild, status.Reason = r.index.rebuildReason(status)
+	return status, nil
+}
+
+/*
+ * EnsureIndex creates the configured index if it is missing; an index that no longer matches the
+ * configuration or has been outgrown by the data is only reported, rebuilding is left to cmd/vector-index
+ * Creation is skipped when another process already holds the index lock
+ */
+func (r *VectorRepository) EnsureIndex(ctx context.Context) error {
+	if r.indexType() == IndexTypeNone {
+		return nil
+	}
+
+	err := r.withIndexLock(ctx, func() error {
+		// Read the status under the lock: another process may have just built the index
+		status, err := r.IndexStatus(ctx)
+		if err != nil {
+			return err
+		}
+
+		switch {
+		case !status.Exists:
+			if err := r.createIndex(ctx, IndexName, status.EmbeddedChunks); err != nil {
+				return err
+			}
+			fmt.Printf("✅ Vector index %s created (%s)\n", IndexName, r.indexType())
+		case status.NeedsRebuild:
+			fmt.Printf("Warning: vector index %s needs a rebuild (%s); run `go run ./cmd/vector-index rebuild`\n", IndexName, status.Reason)
+		}
+		return nil
+	})
+	if errors.Is(err, ErrIndexBuildInProgress) {
+		fmt.Printf("Vector index %s is being built by another process; skipping\n", IndexName)
+		return nil
+	}
+	return err
+}
+
+/*
+ * RebuildIndex builds a fresh index alongside the current one and swaps it in
+ * Both indexes are built and dropped concurrently so searches and writes are never blocked
+ */
+func (r *VectorRepository) RebuildIndex(ctx context.Context) error {
+	if r.indexType() == IndexTypeNone {
+		return ErrNoIndexType
+	}
+
+	return r.withIndexLock(ctx, func() error {
+		return r.rebuildIndex(ctx)
+	})
+}
+
+/*
+ * rebuildIndex swaps in a freshly built index; callers must hold the index lock
+ */
+func (r *VectorRepository) rebuildIndex(ctx context.Context) error {
+	var embedded int64
+	if err := r.db.WithContext(ctx).
+		Model(&models.DocumentChunk{}).
+		Where("embedding IS NOT NULL").
+		Count(&embedded).Error; err != nil {
+		return fmt.Errorf("failed to count embedded chunks: %w", err)
+	}
+
+	// A previous rebuild may have left a half-built index behind
+	tmpName := IndexName + "_new"
+	if err := r.dropIndex(ctx, tmpName); err != nil {
+		return err
+	}
+	if err := r.createIndex(ctx, tmpName, embedded); err != nil {
+		return err
+	}
+	if err := r.dropIndex(ctx, IndexName); err != nil {
+		return err
+	}
+	if err := r.db.WithContext(ctx).Exec(fmt.Sprintf("ALTER INDEX %s RENAME TO %s", tmpName, IndexName)).Error; err != nil {
+		return fmt.Errorf("failed to swap in vector index: %w", err)
+	}
+
+	fmt.Printf("✅ Vector index %s rebuilt (%s)\n", IndexName, r.indexType())
+	return nil
+}
+
+/*
+ * DropIndex removes the managed index, returning searches to exact sequential scans
+ */
+func (r *VectorRepository) DropIndex(ctx context.Context) error {
+	return r.dropIndex(ctx, IndexName)
+}
+
+/*
+ * withIndexLock runs fn while holding the index advisory lock so only one process builds the index at a time
+ * The lock is session-scoped, so it is taken and released on one pooled connection while fn uses the others
+ */
+func (r *VectorRepository) withIndexLock(ctx context.Context, fn func() error) error {
+	return r.db.WithContext(ctx).Connection(func(conn *gorm.DB) error {
+		var locked bool
+		if err := conn.Raw("SELECT pg_try_advisory_lock(?)", indexLockKey).Scan(&locked).Error; err != nil {
+			return fmt.Errorf("failed to lock vector index: %w", err)
+		}
+		if !locked {
+			return ErrIndexBuildInProgress
+		}
+		// Unlock even if ctx was cancelled, or the pooled connection would keep holding the lock
+		defer conn.WithContext(context.WithoutCancel(ctx)).Exec("SELECT pg_advisory_unlock(?)", indexLockKey)
+
+		return fn()
+	})
+}
+
+/*
+ * createIndex builds the configured index under the given name
+ */
+func (r *VectorRepository) createIndex(ctx context.Context, name string, embedded int64) error {
+	ddl, err := r.index.createIndexSQL(name, embedded)
+	if err != nil {
+		return err
+	}
+	if err := r.db.WithContext(ctx).Exec(ddl).Error; err != nil {
+		return fmt.Errorf("failed to create vector index: %w", err)
+	}
+	return nil
+}
+
+/*
+ * dropIndex drops an index by name without blocking searches
+ */
+func (r *VectorRepository) dropIndex(ctx context.Context, name string) error {
+	if err := r.db.WithContext(ctx).Exec(fmt.Sprintf("DROP INDEX CONCURRENTLY IF EXISTS %s", name)).Error; err != nil {
+		return fmt.Errorf("failed to drop vector index %s: %w", name, err)
+	}
+	return nil
+}
+
+/*
+ * indexType returns the configured index type, treating an unset type as none
+ */
+func (r *VectorRepository) indexType() string {
+	if r.index.Type == "" {
+		return IndexTypeNone
+	}
+	return r.index.Type
+}
+
+/*
+ * RecommendedLists sizes an IVFFlat index following the pgvector guidance:
+ * rows / 1000 up to 1M rows and sqrt(rows) beyond
+ */
+func RecommendedLists(rows int64) int {
+	lists := int(rows / 1000)
+	if rows > 1_000_000 {
+		lists = int(math.Sqrt(float64(rows)))
+	}
+	return max(lists, 1)
+}
+
+/*
+ * createIndexSQL builds the CREATE INDEX statement for the configured index type
+ * CONCURRENTLY cannot run inside a transaction, so the statement must be executed on its own
+ */
+func (o IndexOptions) createIndexSQL(name string, embedded int64) (string, error) {
+	var with string
+	switch o.Type {
+	case IndexTypeHNSW:
+		with = fmt.Sprintf("m = %d, ef_construction = %d", o.HNSWM, o.HNSWEfConstruction)
+	case IndexTypeIVFFlat:
+		with = fmt.Sprintf("lists = %d", o.lists(embedded))
+	case "", IndexTypeNone:
+		return "", ErrNoIndexType
+	default:
+		return "", fmt.Errorf("%w: %q", ErrUnknownIndexType, o.Type)
+	}
+
+	return fmt.Sprintf(
+		"CREATE INDEX CONCURRENTLY IF NOT EXISTS %s ON document_chunks USING %s (embedding vector_cosine_ops) WITH (%s)",
+		name, o.Type, with,
+	), nil
+}
+
+/*
+ * lists returns the configured IVFFlat list count or the one recommended for the data
+ */
+func (o IndexOptions) lists(embedded int64) int {
+	if o.IVFFlatLists > 0 {
+		return o.IVFFlatLists
+	}
+	return RecommendedLists(embedded)
+}
+
+/*
+ * rebuildReason reports whether an existing index should be rebuilt and why
+ */
+func (o IndexOptions) rebuildReason(status *IndexStatus) (bool, string) {
+	if !status.Exists || status.Configured == IndexTypeNone {
+		return false, ""
+	}
+	if !status.Valid {
+		return true, "index is invalid (interrupted build)"
+	}
+	if status.Method != status.Configured {
+		return true, fmt.Sprintf("index is %s, configured %s", status.Method, status.Configured)
+	}
+
+	switch status.Method {
+	case IndexTypeHNSW:
+		if status.Params["m"] != o.HNSWM || status.Params["ef_construction"] != o.HNSWEfConstruction {
+			return true, fmt.Sprintf("built with m=%d ef_construction=%d, configured m=%d ef_construction=%d",
+				status.Params["m"], status.Params["ef_construction"], o.HNSWM, o.HNSWEfConstruction)
+		}
+	case IndexTypeIVFFlat:
+		current := status.Params["lists"]
+		if o.IVFFlatLists > 0 {
+			if current != o.IVFFlatLists {
+				return true, fmt.Sprintf("built with %d lists, configured %d", current, o.IVFFlatLists)
+			}
+			return false, ""
+		}
+		// Clusters are fixed at build time; rebuild once the data has outgrown (or shrunk below) them
+		if current*2 < status.RecommendedLists || current > status.RecommendedLists*2 {
+			return true, fmt.Sprintf("built with %d lists, %d recommended for %d chunks",
+				current, status.RecommendedLists, status.EmbeddedChunks)
+		}
+	}
+	return false, ""
+}
+
+/*
+ * searchSettings returns the SET LOCAL statements tuning a search of up to limit results
+ * SET does not accept bind parameters, so the values are formatted in
+ */
+func (o IndexOptions) searchSettings(limit int) []string {
+	switch o.Type {
+	case IndexTypeHNSW:
+		// HNSW returns at most ef_search results
+		settings := []string{fmt.Sprintf("SET LOCAL hnsw.ef_search = %d", max(o.HNSWEfSearch, limit))}
+		if o.IterativeScan {
+			settings = append(settings, "SET LOCAL hnsw.iterative_scan = strict_order")
+		}
+		return settings
+	case IndexTypeIVFFlat:
+		settings := []string{fmt.Sprintf("SET LOCAL ivfflat.probes = %d", max(o.IVFFlatProbes, 1))}
+		if o.IterativeScan {
+			settings = append(settings, "SET LOCAL ivfflat.iterative_scan = relaxed_order")
+		}
+		return settings
+	}
+	return nil
+}
+
+var indexParamPattern = regexp.MustCompile(`(\w+)\s*=\s*'?(\d+)'?`)
+
+/*
+ * parseIndexParams reads the storage parameters from an index definition,
+ * e.g. "... WITH (m='16', ef_construction='64')"
+ */
+func parseIndexParams(definition string) map[string]int {
+	params := map[string]int{}
+	start := strings.LastIndex(definition, "WITH (")
+	if start < 0 {
+		return params
+	}
+	for _, match := range indexParamPattern.FindAllStringSubmatch(definition[start:], -1) {
+		if value, err := strconv.Atoi(match[2]); err == nil {
+			params[match[1]] = value
+		}
+	}
+	return params
+}
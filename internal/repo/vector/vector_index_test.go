@@ -0,0 +1,220 @@
+package vector
+
+import (
+	"context"
+	"testing"
+
+	"github.com/souravsspace/texly.chat/internal/models"
+	"github.com/souravsspace/texly.chat/internal/shared"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gorm.io/gorm"
+)
+
+/*
+ * TestRecommendedLists tests IVFFlat list sizing across data sizes
+ */
+func TestRecommendedLists(t *testing.T) {
+	assert.Equal(t, 1, RecommendedLists(0))
+	assert.Equal(t, 1, RecommendedLists(999))
+	assert.Equal(t, 50, RecommendedLists(50_000))
+	assert.Equal(t, 1000, RecommendedLists(1_000_000))
+	assert.Equal(t, 2000, RecommendedLists(4_000_000))
+}
+
+/*
+ * TestParseIndexParams tests reading storage parameters from index definitions
+ */
+func TestParseIndexParams(t *testing.T) {
+	hnsw := "CREATE INDEX idx_document_chunks_embedding ON public.document_chunks USING hnsw (embedding vector_cosine_ops) WITH (m='16', ef_construction='64')"
+	assert.Equal(t, map[string]int{"m": 16, "ef_construction": 64}, parseIndexParams(hnsw))
+
+	ivfflat := "CREATE INDEX idx_document_chunks_embedding ON public.document_chunks USING ivfflat (embedding vector_cosine_ops) WITH (lists='100')"
+	assert.Equal(t, map[string]int{"lists": 100}, parseIndexParams(ivfflat))
+
+	assert.Empty(t, parseIndexParams("CREATE INDEX idx ON public.document_chunks USING btree (source_id)"))
+}
+
+/*
+ * TestCreateIndexSQL tests the DDL built for each index type
+ */
+func TestCreateIndexSQL(t *testing.T) {
+	ddl, err := IndexOptions{Type: IndexTypeHNSW, HNSWM: 16, HNSWEfConstruction: 64}.createIndexSQL(IndexName, 0)
+	require.NoError(t, err)
+	assert.Equal(t, "CREATE INDEX CONCURRENTLY IF NOT EXISTS idx_document_chunks_embedding ON document_chunks USING hnsw (embedding vector_cosine_ops) WITH (m = 16, ef_construction = 64)", ddl)
+
+	// Lists are sized from the data unless configured
+	ddl, err = IndexOptions{Type: IndexTypeIVFFlat}.createIndexSQL(IndexName, 250_000)
+	require.NoError(t, err)
+	assert.Contains(t, ddl, "USING ivfflat (embedding vector_cosine_ops) WITH (lists = 250)")
+
+	ddl, err = IndexOptions{Type: IndexTypeIVFFlat, IVFFlatLists: 40}.createIndexSQL(IndexName, 250_000)
+	require.NoError(t, err)
+	assert.Contains(t, ddl, "WITH (lists = 40)")
+
+	_, err = IndexOptions{Type: IndexTypeNone}.createIndexSQL(IndexName, 0)
+	assert.ErrorIs(t, err, ErrNoIndexType)
+
+	_, err = IndexOptions{Type: "diskann"}.createIndexSQL(IndexName, 0)
+	assert.ErrorIs(t, err, ErrUnknownIndexType)
+}
+
+/*
+ * TestRebuildReason tests when an existing index is considered stale
+ */
+func TestRebuildReason(t *testing.T) {
+	hnsw := IndexOptions{Type: IndexTypeHNSW, HNSWM: 16, HNSWEfConstruction: 64}
+	current := &IndexStatus{Configured: IndexTypeHNSW, Exists: true, Valid: true, Method: IndexTypeHNSW, Params: map[string]int{"m": 16, "ef_construction": 64}}
+
+	rebuild, _ := hnsw.rebuildReason(current)
+	assert.False(t, rebuild)
+
+	rebuild, reason := hnsw.rebuildReason(&IndexStatus{Configured: IndexTypeHNSW, Exists: true, Valid: false, Method: IndexTypeHNSW})
+	assert.True(t, rebuild)
+	assert.Contains(t, reason, "invalid")
+
+	rebuild, _ = IndexOptions{Type: IndexTypeHNSW, HNSWM: 32, HNSWEfConstruction: 64}.rebuildReason(current)
+	assert.True(t, rebuild, "parameter change should rebuild")
+
+	rebuild, reason = IndexOptions{Type: IndexTypeIVFFlat}.rebuildReason(&IndexStatus{Configured: IndexTypeIVFFlat, Exists: true, Valid: true, Method: IndexTypeHNSW})
+	assert.True(t, rebuild)
+	assert.Contains(t, reason, "configured ivfflat")
+
+	// Automatic lists rebuild once the data outgrows them
+	ivfflat := IndexOptions{Type: IndexTypeIVFFlat}
+	grown := &IndexStatus{Configured: IndexTypeIVFFlat, Exists: true, Valid: true, Method: IndexTypeIVFFlat, Params: map[string]int{"lists": 10}, EmbeddedChunks: 50_000, RecommendedLists: 50}
+	rebuild, _ = ivfflat.rebuildReason(grown)
+	assert.True(t, rebuild)
+
+	grown.Params["lists"] = 40
+	rebuild, _ = ivfflat.rebuildReason(grown)
+	assert.False(t, rebuild)
+
+	rebuild, _ = IndexOptions{Type: IndexTypeNone}.rebuildReason(&IndexStatus{Configured: IndexTypeNone, Exists: true, Valid: true, Method: IndexTypeHNSW})
+	assert.False(t, rebuild, "an index is never rebuilt without a configured type")
+}
+
+/*
+ * TestSearchSettings tests the per-query index parameters
+ */
+func TestSearchSettings(t *testing.T) {
+	assert.Empty(t, IndexOptions{}.searchSettings(10))
+
+	hnsw := IndexOptions{Type: IndexTypeHNSW, HNSWEfSearch: 40}
+	assert.Equal(t, []string{"SET LOCAL hnsw.ef_search = 40"}, hnsw.searchSettings(10))
+	assert.Equal(t, []string{"SET LOCAL hnsw.ef_search = 100"}, hnsw.searchSettings(100), "ef_search should cover the limit")
+
+	hnsw.IterativeScan = true
+	assert.Contains(t, hnsw.searchSettings(10), "SET LOCAL hnsw.iterative_scan = strict_order")
+
+	ivfflat := IndexOptions{Type: IndexTypeIVFFlat, IVFFlatProbes: 10}
+	assert.Equal(t, []string{"SET LOCAL ivfflat.probes = 10"}, ivfflat.searchSettings(10))
+}
+
+/*
+ * TestEnsureIndex tests creating, inspecting, rebuilding and dropping the managed index
+ */
+func TestEnsureIndex(t *testing.T) {
+	gormDB := shared.SetupTestDB()
+	repo := NewVectorRepository(gormDB)
+	ctx := context.Background()
+	defer repo.DropIndex(ctx)
+
+	// Without a configured type nothing is created
+	require.NoError(t, repo.Initialize(ctx, 1536))
+	status, err := repo.IndexStatus(ctx)
+	require.NoError(t, err)
+	assert.False(t, status.Exists)
+	assert.Equal(t, IndexTypeNone, status.Configured)
+
+	repo.SetIndexOptions(IndexOptions{Type: IndexTypeHNSW, HNSWM: 8, HNSWEfConstruction: 32, HNSWEfSearch: 40})
+	require.NoError(t, repo.Initialize(ctx, 1536))
+
+	status, err = repo.IndexStatus(ctx)
+	require.NoError(t, err)
+	assert.True(t, status.Exists)
+	assert.True(t, status.Valid)
+	assert.Equal(t, IndexTypeHNSW, status.Method)
+	assert.Equal(t, map[string]int{"m": 8, "ef_construction": 32}, status.Params)
+	assert.False(t, status.NeedsRebuild)
+
+	// Switching type only reports the mismatch; the rebuild is explicit
+	repo.SetIndexOptions(IndexOptions{Type: IndexTypeIVFFlat, IVFFlatProbes: 10})
+	require.NoError(t, repo.EnsureIndex(ctx))
+	status, err = repo.IndexStatus(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, IndexTypeHNSW, status.Method)
+	assert.True(t, status.NeedsRebuild)
+
+	require.NoError(t, repo.RebuildIndex(ctx))
+	status, err = repo.IndexStatus(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, IndexTypeIVFFlat, status.Method)
+	assert.Equal(t, 1, status.Params["lists"])
+	assert.False(t, status.NeedsRebuild)
+
+	require.NoError(t, repo.DropIndex(ctx))
+	status, err = repo.IndexStatus(ctx)
+	require.NoError(t, err)
+	assert.False(t, status.Exists)
+}
+
+/*
+ * TestIndexLock tests that only one process builds the index at a time
+ */
+func TestIndexLock(t *testing.T) {
+	gormDB := shared.SetupTestDB()
+	repo := NewVectorRepository(gormDB)
+	ctx := context.Background()
+	defer repo.DropIndex(ctx)
+	repo.SetIndexOptions(IndexOptions{Type: IndexTypeHNSW, HNSWM: 16, HNSWEfConstruction: 64, HNSWEfSearch: 40})
+
+	// Another process holds the lock: startup skips creation and explicit rebuilds fail
+	require.NoError(t, gormDB.Connection(func(conn *gorm.DB) error {
+		require.NoError(t, conn.Exec("SELECT pg_advisory_lock(?)", indexLockKey).Error)
+		defer conn.Exec("SELECT pg_advisory_unlock(?)", indexLockKey)
+
+		require.NoError(t, repo.EnsureIndex(ctx))
+		assert.ErrorIs(t, repo.RebuildIndex(ctx), ErrIndexBuildInProgress)
+		return nil
+	}))
+
+	status, err := repo.IndexStatus(ctx)
+	require.NoError(t, err)
+	assert.False(t, status.Exists)
+
+	// Once released the lock can be taken again
+	require.NoError(t, repo.EnsureIndex(ctx))
+	status, err = repo.IndexStatus(ctx)
+	require.NoError(t, err)
+	assert.True(t, status.Exists)
+}
+
+/*
+ * TestSearchSimilar_WithIndex tests that searches return the same nearest chunks through an index
+ */
+func TestSearchSimilar_WithIndex(t *testing.T) {
+	gormDB := shared.SetupTestDB()
+	repo := NewVectorRepository(gormDB)
+	ctx := context.Background()
+	defer repo.DropIndex(ctx)
+
+	botID, sourceID := setupTestBotAndSource(t, gormDB)
+	for i, seed := range []float32{0.1, 0.5, 0.9} {
+		chunk := models.DocumentChunk{SourceID: sourceID, Content: "chunk", ChunkIndex: i}
+		require.NoError(t, gormDB.Create(&chunk).Error)
+		require.NoError(t, repo.InsertEmbedding(ctx, chunk.ID, generateTestEmbedding(seed)))
+	}
+
+	for _, opts := range []IndexOptions{
+		{Type: IndexTypeHNSW, HNSWM: 16, HNSWEfConstruction: 64, HNSWEfSearch: 40},
+		{Type: IndexTypeIVFFlat, IVFFlatProbes: 10},
+	} {
+		repo.SetIndexOptions(opts)
+		require.NoError(t, repo.RebuildIndex(ctx))
+
+		matches, err := repo.SearchSimilar(ctx, generateTestEmbedding(0.1), botID, 3)
+		require.NoError(t, err, opts.Type)
+		assert.Len(t, matches, 3, opts.Type)
+	}
+}
@@ -13,7 +13,8 @@ import (
  * VectorRepository handles vector storage and search operations using pgvector
  */
 type VectorRepository struct {
-	db *gorm.DB
+	db    *gorm.DB
+	index IndexOptions // Managed ANN index; the zero value searches exactly
 }
 
 /*
@@ -40,24 +41,27 @@ type VectorMatch struct {
 }
 
 /*
- * Initialize creates vector indexes for better performance
- * This should be called after initial data loading
+ * KeywordMatch represents a full-text search result with its relevance
+ */
+type KeywordMatch struct {
+	ChunkID string
+	Rank    float32 // ts_rank_cd relevance (higher = more relevant)
+}
+
+/*
+ * Initialize creates the configured vector index if it is missing and reports one that needs a rebuild
+ * Without an index type configured searches stay exact and nothing is created
  */
 func (r *VectorRepository) Initialize(ctx context.Context, dimension int) error {
-	// Create IVFFlat index for faster similarity search (recommended for production)
-	// Note: This requires some data to be present for clustering
-	// For now, we'll just ensure the vector extension is enabled (done in db.Connect)
-
-	// Optionally create index if you have enough data (>1000 rows recommended)
-	// indexQuery := `
-	// CREATE INDEX IF NOT EXISTS document_chunks_embedding_idx
-	// ON document_chunks
-	// USING ivfflat (embedding vector_cosine_ops)
-	// WITH (lists = 100);
-	// `
-	// return r.db.WithContext(ctx).Exec(indexQuery).Error
-
-	fmt.Println("✅ Vector repository initialized (indexes can be added later)")
+	if r.indexType() != IndexTypeNone && dimension > maxIndexDimension {
+		return fmt.Errorf("%s indexes support at most %d dimensions, got %d", r.indexType(), maxIndexDimension, dimension)
+	}
+
+	if err := r.EnsureIndex(ctx); err != nil {
+		return err
+	}
+
+	fmt.Println("✅ Vector repository initialized")
 	return nil
 }
 
@@ -109,10 +113,18 @@ func (r *VectorRepository) BulkInsertEmbeddings(ctx context.Context, data []Vect
 }
 
 /*
- * SearchSimilar performs cosine similarity search using pgvector
+ * SearchSimilar performs cosine similarity search using pgvector over a bot's searchable chunks
  * Returns the most similar chunks ordered by distance (ascending)
  */
-func (r *VectorRepository) SearchSimilar(ctx context.Context, embedding []float32, limit int) ([]VectorMatch, error) {
+func (r *VectorRepository) SearchSimilar(ctx context.Context, embedding []float32, botID string, limit int) ([]VectorMatch, error) {
+	return r.SearchSimilarForBots(ctx, embedding, []string{botID}, limit)
+}
+
+/*
+ * SearchSimilarForBots performs cosine similarity search over the searchable chunks of several bots
+ * An empty bot list searches every bot (e.g. for admin features)
+ */
+func (r *VectorRepository) SearchSimilarForBots(ctx context.Context, embedding []float32, botIDs []string, limit int) ([]VectorMatch, error) {
 	vec := pgvector.NewVector(embedding)
 
 	var results []struct {
@@ -122,14 +134,29 @@ func (r *VectorRepository) SearchSimilar(ctx context.Context, embedding []float3
 
 	// Use cosine distance operator <=> for similarity search
 	// Lower distance = more similar
-	err := r.db.WithContext(ctx).
-		Table("document_chunks").
-		Select("id, embedding <=> ? as distance", vec).
-		Where("embedding IS NOT NULL").
-		Order("distance").
-		Limit(limit).
-		Find(&results).Error
+	search := func(db *gorm.DB) error {
+		return searchableChunks(db, botIDs).
+			Select("document_chunks.id, document_chunks.embedding <=> ? as distance", vec).
+			Where("document_chunks.embedding IS NOT NULL").
+			Order("distance").
+			Limit(limit).
+			Find(&results).Error
+	}
 
+	// Index search parameters are set for this query only, which SET LOCAL scopes to a transaction
+	var err error
+	if settings := r.index.searchSettings(limit); len(settings) > 0 {
+		err = r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+			for _, setting := range settings {
+				if err := tx.Exec(setting).Error; err != nil {
+					return err
+				}
+			}
+			return search(tx)
+		})
+	} else {
+		err = search(r.db.WithContext(ctx))
+	}
 	if err != nil {
 		return nil, fmt.Errorf("failed to execute similarity search: %w", err)
 	}
@@ -146,6 +173,90 @@ func (r *VectorRepository) SearchSimilar(ctx context.Context, embedding []float3
 	return matches, nil
 }
 
+/*
+ * SearchKeyword performs full-text search over a bot's searchable chunks, including chunks without embeddings
+ * Any query term may match; chunks matching more terms, closer together, rank first
+ */
+func (r *VectorRepository) SearchKeyword(ctx context.Context, query string, botID string, limit int) ([]KeywordMatch, error) {
+	// plainto_tsquery ANDs the terms; OR them so natural questions still match
+	tsQuery := "replace(plainto_tsquery('english', ?)::text, '&', '|')::tsquery"
+
+	var results []struct {
+		ID   string
+		Rank float32
+	}
+
+	err := searchableChunks(r.db.WithContext(ctx), []string{botID}).
+		Select("document_chunks.id, ts_rank_cd(document_chunks.search_vector, "+tsQuery+") AS rank", query).
+		Where("document_chunks.search_vector @@ "+tsQuery, query).
+		Order("rank DESC").
+		Limit(limit).
+		Find(&results).Error
+
+	if err != nil {
+		return nil, fmt.Errorf("failed to execute keyword search: %w", err)
+	}
+
+	matches := make([]KeywordMatch, len(results))
+	for i, r := range results {
+		matches[i] = KeywordMatch{
+			ChunkID: r.ID,
+			Rank:    r.Rank,
+		}
+	}
+
+	return matches, nil
+}
+
+/*
+ * searchableChunks scopes a query to the chunks of the bots' completed, non-deleted sources
+ * Filtering in SQL, before ranking, returns a full page to every tenant of a shared table; the
+ * sources (bot_id, status) partial index finds the bot's sources and document_chunks(source_id) their chunks
+ */
+func searchableChunks(db *gorm.DB, botIDs []string) *gorm.DB {
+	query := db.Table("document_chunks").
+		Joins("JOIN sources ON sources.id = document_chunks.source_id").
+		Where("sources.deleted_at IS NULL AND sources.status = ?", models.SourceStatusCompleted)
+	if len(botIDs) > 0 {
+		query = query.Where("sources.bot_id IN ?", botIDs)
+	}
+	return query
+}
+
+/*
+ * Distances returns the cosine distance between an embedding and each of the given chunks
+ * Chunks without an embedding are left out
+ */
+func (r *VectorRepository) Distances(ctx context.Context, embedding []float32, chunkIDs []string) (map[string]float32, error) {
+	distances := make(map[string]float32, len(chunkIDs))
+	if len(chunkIDs) == 0 {
+		return distances, nil
+	}
+
+	vec := pgvector.NewVector(embedding)
+
+	var results []struct {
+		ID       string
+		Distance float32
+	}
+
+	err := r.db.WithContext(ctx).
+		Table("document_chunks").
+		Select("id, embedding <=> ? as distance", vec).
+		Where("id IN ? AND embedding IS NOT NULL", chunkIDs).
+		Find(&results).Error
+
+	if err != nil {
+		return nil, fmt.Errorf("failed to compute chunk distances: %w", err)
+	}
+
+	for _, r := range results {
+		distances[r.ID] = r.Distance
+	}
+
+	return distances, nil
+}
+
 /*
  * DeleteByChunkID deletes an embedding by setting it to NULL
  * The chunk record itself remains for potential re-embedding
@@ -2,6 +2,7 @@ package vector
 
 import (
 	"context"
+	"fmt"
 	"testing"
 
 	"github.com/souravsspace/texly.chat/internal/models"
@@ -152,7 +153,7 @@ func TestSearchSimilar(t *testing.T) {
 	require.NoError(t, err)
 
 	// Create parent records
-	_, sourceID := setupTestBotAndSource(t, gormDB)
+	botID, sourceID := setupTestBotAndSource(t, gormDB)
 
 	// Create and insert test data
 	chunks := []models.DocumentChunk{
@@ -174,7 +175,7 @@ func TestSearchSimilar(t *testing.T) {
 
 	// Search
 	queryEmbedding := generateTestEmbedding(0.85) // Similar to chunk-1
-	matches, err := repo.SearchSimilar(ctx, queryEmbedding, 5)
+	matches, err := repo.SearchSimilar(ctx, queryEmbedding, botID, 5)
 
 	require.NoError(t, err)
 	assert.NotEmpty(t, matches)
@@ -265,3 +266,148 @@ func TestExists(t *testing.T) {
 	require.NoError(t, err)
 	assert.True(t, exists)
 }
+
+/*
+ * TestSearchKeyword tests full-text search scoped to a bot
+ */
+func TestSearchKeyword(t *testing.T) {
+	gormDB := shared.SetupTestDB()
+	repo := NewVectorRepository(gormDB)
+	ctx := context.Background()
+
+	botID, sourceID := setupTestBotAndSource(t, gormDB)
+	require.NoError(t, gormDB.Create(&models.Bot{ID: "test-bot-2", Name: "Other Bot"}).Error)
+	require.NoError(t, gormDB.Create(&models.Source{ID: "test-source-2", BotID: "test-bot-2", Status: models.SourceStatusCompleted}).Error)
+
+	// No embeddings: full-text search does not need them
+	chunks := []models.DocumentChunk{
+		{ID: "chunk-sku", SourceID: sourceID, Content: "Replacement filter SKU-4471 fits every model"},
+		{ID: "chunk-error", SourceID: sourceID, Content: "Error E1234 means the printer is offline"},
+		{ID: "chunk-refund", SourceID: sourceID, Content: "Refunds are processed within five days"},
+		{ID: "chunk-other", SourceID: "test-source-2", Content: "Error E1234 on the other bot"},
+	}
+	for _, chunk := range chunks {
+		require.NoError(t, gormDB.Create(&chunk).Error)
+	}
+
+	matches, err := repo.SearchKeyword(ctx, "What does error E1234 mean?", botID, 10)
+	require.NoError(t, err)
+	require.Len(t, matches, 1)
+	assert.Equal(t, "chunk-error", matches[0].ChunkID)
+	assert.Greater(t, matches[0].Rank, float32(0))
+
+	matches, err = repo.SearchKeyword(ctx, "sku-4471", botID, 10)
+	require.NoError(t, err)
+	require.NotEmpty(t, matches)
+	assert.Equal(t, "chunk-sku", matches[0].ChunkID)
+
+	// Any term may match
+	matches, err = repo.SearchKeyword(ctx, "refund or printer", botID, 10)
+	require.NoError(t, err)
+	assert.Len(t, matches, 2)
+}
+
+/*
+ * TestDistances tests distance lookup for specific chunks
+ */
+func TestDistances(t *testing.T) {
+	gormDB := shared.SetupTestDB()
+	repo := NewVectorRepository(gormDB)
+	ctx := context.Background()
+
+	_, sourceID := setupTestBotAndSource(t, gormDB)
+	require.NoError(t, gormDB.Create(&models.DocumentChunk{ID: "chunk-1", SourceID: sourceID, Content: "Embedded"}).Error)
+	require.NoError(t, gormDB.Create(&models.DocumentChunk{ID: "chunk-2", SourceID: sourceID, Content: "Not embedded"}).Error)
+	require.NoError(t, repo.InsertEmbedding(ctx, "chunk-1", generateTestEmbedding(0.5)))
+
+	distances, err := repo.Distances(ctx, generateTestEmbedding(0.5), []string{"chunk-1", "chunk-2"})
+	require.NoError(t, err)
+	require.Len(t, distances, 1)
+	assert.InDelta(t, 0, distances["chunk-1"], 0.001)
+}
+
+// Helper to create a bot with a completed source of embedded chunks
+func setupTenant(t *testing.T, gormDB *gorm.DB, botID string, chunks int, seed float32) {
+	require.NoError(t, gormDB.Create(&models.Bot{ID: botID, Name: botID}).Error)
+	sourceID := botID + "-source"
+	require.NoError(t, gormDB.Create(&models.Source{ID: sourceID, BotID: botID, Status: models.SourceStatusCompleted}).Error)
+
+	repo := NewVectorRepository(gormDB)
+	for i := 0; i < chunks; i++ {
+		chunkID := fmt.Sprintf("%s-chunk-%d", botID, i)
+		require.NoError(t, gormDB.Create(&models.DocumentChunk{ID: chunkID, SourceID: sourceID, Content: "Shared content"}).Error)
+		require.NoError(t, repo.InsertEmbedding(context.Background(), chunkID, generateTestEmbedding(seed)))
+	}
+}
+
+/*
+ * TestSearchSimilar_MultiTenant tests that a bot gets its own chunks when other tenants' chunks are closer
+ */
+func TestSearchSimilar_MultiTenant(t *testing.T) {
+	gormDB := shared.SetupTestDB()
+	repo := NewVectorRepository(gormDB)
+	ctx := context.Background()
+
+	// A large tenant whose chunks all sit right next to the query
+	setupTenant(t, gormDB, "big-bot", 30, 0.5)
+	// A small tenant with a few chunks farther away
+	setupTenant(t, gormDB, "small-bot", 3, 0.9)
+
+	query := generateTestEmbedding(0.5)
+
+	matches, err := repo.SearchSimilar(ctx, query, "small-bot", 5)
+	require.NoError(t, err)
+	require.Len(t, matches, 3)
+	for _, match := range matches {
+		assert.Contains(t, match.ChunkID, "small-bot-chunk-")
+	}
+
+	matches, err = repo.SearchSimilar(ctx, query, "big-bot", 5)
+	require.NoError(t, err)
+	require.Len(t, matches, 5)
+	for _, match := range matches {
+		assert.Contains(t, match.ChunkID, "big-bot-chunk-")
+	}
+
+	matches, err = repo.SearchSimilarForBots(ctx, query, []string{"small-bot", "big-bot"}, 40)
+	require.NoError(t, err)
+	assert.Len(t, matches, 33)
+
+	matches, err = repo.SearchSimilar(ctx, query, "unknown-bot", 5)
+	require.NoError(t, err)
+	assert.Empty(t, matches)
+}
+
+/*
+ * TestSearchSimilar_SearchableSources tests that deleted and unfinished sources are not searched
+ */
+func TestSearchSimilar_SearchableSources(t *testing.T) {
+	gormDB := shared.SetupTestDB()
+	repo := NewVectorRepository(gormDB)
+	ctx := context.Background()
+
+	setupTenant(t, gormDB, "bot-1", 1, 0.5)
+
+	sources := []models.Source{
+		{ID: "source-processing", BotID: "bot-1", Status: models.SourceStatusProcessing},
+		{ID: "source-failed", BotID: "bot-1", Status: models.SourceStatusFailed},
+		{ID: "source-deleted", BotID: "bot-1", Status: models.SourceStatusCompleted},
+	}
+	for _, source := range sources {
+		require.NoError(t, gormDB.Create(&source).Error)
+		chunkID := source.ID + "-chunk"
+		require.NoError(t, gormDB.Create(&models.DocumentChunk{ID: chunkID, SourceID: source.ID, Content: "Shared content"}).Error)
+		require.NoError(t, repo.InsertEmbedding(ctx, chunkID, generateTestEmbedding(0.5)))
+	}
+	require.NoError(t, gormDB.Delete(&models.Source{}, "id = ?", "source-deleted").Error)
+
+	matches, err := repo.SearchSimilar(ctx, generateTestEmbedding(0.5), "bot-1", 10)
+	require.NoError(t, err)
+	require.Len(t, matches, 1)
+	assert.Equal(t, "bot-1-chunk-0", matches[0].ChunkID)
+
+	keywordMatches, err := repo.SearchKeyword(ctx, "shared content", "bot-1", 10)
+	require.NoError(t, err)
+	require.Len(t, keywordMatches, 1)
+	assert.Equal(t, "bot-1-chunk-0", keywordMatches[0].ChunkID)
+}
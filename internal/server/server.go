@@ -5,31 +5,49 @@ import (
 	"fmt"
 	"os"
 	"os/signal"
+	"strings"
 	"syscall"
 
 	"github.com/gin-gonic/gin"
 	"github.com/souravsspace/texly.chat/configs"
 	"github.com/souravsspace/texly.chat/internal/db"
+	actionHandlerPkg "github.com/souravsspace/texly.chat/internal/handlers/action"
 	analyticsHandlerPkg "github.com/souravsspace/texly.chat/internal/handlers/analytics"
+	apiKeyHandlerPkg "github.com/souravsspace/texly.chat/internal/handlers/apikey"
 	"github.com/souravsspace/texly.chat/internal/handlers/auth"
 	billingHandlerPkg "github.com/souravsspace/texly.chat/internal/handlers/billing"
 	botHandlerPkg "github.com/souravsspace/texly.chat/internal/handlers/bot"
 	chatHandlerPkg "github.com/souravsspace/texly.chat/internal/handlers/chat"
+	completionsHandlerPkg "github.com/souravsspace/texly.chat/internal/handlers/completions"
+	feedbackHandlerPkg "github.com/souravsspace/texly.chat/internal/handlers/feedback"
+	goldenHandlerPkg "github.com/souravsspace/texly.chat/internal/handlers/golden"
+	handoffHandlerPkg "github.com/souravsspace/texly.chat/internal/handlers/handoff"
 	healthHandlerPkg "github.com/souravsspace/texly.chat/internal/handlers/health"
+	promptHandlerPkg "github.com/souravsspace/texly.chat/internal/handlers/prompt"
 	publicHandlerPkg "github.com/souravsspace/texly.chat/internal/handlers/public"
+	searchHandlerPkg "github.com/souravsspace/texly.chat/internal/handlers/search"
 	sourceHandlerPkg "github.com/souravsspace/texly.chat/internal/handlers/source"
 	userHandlerPkg "github.com/souravsspace/texly.chat/internal/handlers/user"
+	apiKeyMiddleware "github.com/souravsspace/texly.chat/internal/middleware/apikey"
 	authMiddleware "github.com/souravsspace/texly.chat/internal/middleware/auth"
 	corsMiddleware "github.com/souravsspace/texly.chat/internal/middleware/cors"
 	middleware "github.com/souravsspace/texly.chat/internal/middleware/entitlement"
 	rateLimitMiddleware "github.com/souravsspace/texly.chat/internal/middleware/rate_limit"
 	"github.com/souravsspace/texly.chat/internal/queue"
+	actionRepoPkg "github.com/souravsspace/texly.chat/internal/repo/action"
+	apiKeyRepoPkg "github.com/souravsspace/texly.chat/internal/repo/apikey"
 	botRepoPkg "github.com/souravsspace/texly.chat/internal/repo/bot"
+	feedbackRepoPkg "github.com/souravsspace/texly.chat/internal/repo/feedback"
+	goldenRepoPkg "github.com/souravsspace/texly.chat/internal/repo/golden"
 	messageRepoPkg "github.com/souravsspace/texly.chat/internal/repo/message"
+	promptRepoPkg "github.com/souravsspace/texly.chat/internal/repo/prompt"
 	sourceRepoPkg "github.com/souravsspace/texly.chat/internal/repo/source"
 	userRepoPkg "github.com/souravsspace/texly.chat/internal/repo/user"
 	vectorRepoPkg "github.com/souravsspace/texly.chat/internal/repo/vector"
+	"github.com/souravsspace/texly.chat/internal/services/action"
 	"github.com/souravsspace/texly.chat/internal/services/analytics"
+	"github.com/souravsspace/texly.chat/internal/services/answercache"
+	apiKeySvc "github.com/souravsspace/texly.chat/internal/services/apikey"
 	billing "github.com/souravsspace/texly.chat/internal/services/billing/core"
 	credits "github.com/souravsspace/texly.chat/internal/services/billing/credits"
 	polar "github.com/souravsspace/texly.chat/internal/services/billing/polar"
@@ -37,9 +55,14 @@ import (
 	"github.com/souravsspace/texly.chat/internal/services/cache"
 	"github.com/souravsspace/texly.chat/internal/services/chat"
 	"github.com/souravsspace/texly.chat/internal/services/embedding"
+	feedbackSvc "github.com/souravsspace/texly.chat/internal/services/feedback"
+	"github.com/souravsspace/texly.chat/internal/services/handoff"
+	"github.com/souravsspace/texly.chat/internal/services/llm"
 	"github.com/souravsspace/texly.chat/internal/services/oauth"
+	promptSvc "github.com/souravsspace/texly.chat/internal/services/prompt"
 	"github.com/souravsspace/texly.chat/internal/services/session"
 	"github.com/souravsspace/texly.chat/internal/services/storage"
+	"github.com/souravsspace/texly.chat/internal/services/stream"
 	"github.com/souravsspace/texly.chat/internal/services/vector"
 	"github.com/souravsspace/texly.chat/internal/worker"
 	"github.com/souravsspace/texly.chat/ui"
@@ -82,6 +105,7 @@ func (s *Server) Run() error {
 	 */
 	redisClient := db.GetRedisClient()
 	cacheService := cache.NewCacheService(redisClient)
+	answerCacheService := answercache.NewAnswerCacheService(cacheService, s.cfg.SemanticCacheThreshold)
 
 	/*
 	* Billing Services
@@ -100,7 +124,13 @@ func (s *Server) Run() error {
 	userRepo := userRepoPkg.NewUserRepo(s.db, cacheService)
 	botRepo := botRepoPkg.NewBotRepo(s.db, cacheService)
 	sourceRepo := sourceRepoPkg.NewSourceRepo(s.db, cacheService)
+	botRepo.SetAnswerCache(answerCacheService)
+	sourceRepo.SetAnswerCache(answerCacheService)
 	messageRepo := messageRepoPkg.New(s.db)
+	actionRepo := actionRepoPkg.New(s.db)
+	apiKeyRepo := apiKeyRepoPkg.New(s.db)
+	apiKeyService := apiKeySvc.NewAPIKeyService(apiKeyRepo)
+	promptService := promptSvc.NewPromptService(promptRepoPkg.New(s.db), botRepo)
 
 	/*
 	* Queue and Worker
@@ -140,6 +170,16 @@ func (s *Server) Run() error {
 			s.cfg.EmbeddingDimension,
 		)
 		vectorRepo = vectorRepoPkg.NewVectorRepository(s.db)
+		vectorRepo.SetIndexOptions(VectorIndexOptions(s.cfg))
+		if s.cfg.VectorIndexOnStartup {
+			// Building an index over many chunks takes a while; it is built concurrently so serving can start
+			// Only a missing index is created here, under an advisory lock; rebuilds are left to cmd/vector-index
+			go func() {
+				if err := vectorRepo.Initialize(ctx, s.cfg.EmbeddingDimension); err != nil {
+					fmt.Printf("Warning: Failed to initialize vector index: %v\n", err)
+				}
+			}()
+		}
 		searchService = vector.NewSearchService(s.db, vectorRepo, embeddingService)
 		chatService = chat.NewChatService(
 			embeddingService,
@@ -149,7 +189,15 @@ func (s *Server) Run() error {
 			s.cfg.ChatTemperature,
 			s.cfg.MaxContextChunks,
 			s.cfg.OpenAIAPIKey,
+			s.cfg.HistoryTokenBudget,
 		)
+		RegisterChatProviders(s.cfg, chatService)
+		chatService.SetQueryRewriteModel(s.cfg.QueryRewriteModel)
+		actionService := action.NewActionService(actionRepo)
+		actionService.SetAllowPrivateNetworks(s.cfg.ActionsAllowPrivateNetworks)
+		chatService.SetActionService(actionService)
+		chatService.SetUsageService(usageService)
+		chatService.SetAnswerCache(answerCacheService)
 		fmt.Println("✅ Embedding service initialized")
 		fmt.Println("✅ Vector search service initialized")
 		fmt.Println("✅ Chat service initialized")
@@ -185,7 +233,13 @@ func (s *Server) Run() error {
 	userHandler := userHandlerPkg.NewUserHandler(userRepo)
 	sourceHandler := sourceHandlerPkg.NewSourceHandler(sourceRepo, botRepo, jobQueue, storageService, usageService, s.cfg.MaxUploadSizeMB)
 	analyticsService := analytics.NewAnalyticsService(messageRepo)
+	analyticsService.SetAnswerCache(answerCacheService)
 	analyticsHandler := analyticsHandlerPkg.NewAnalyticsHandler(analyticsService)
+	sessionService := session.NewSessionService()
+	handoffService := handoff.NewHandoffService(sessionService, messageRepo)
+	streamBuffer := stream.NewStreamBuffer(cacheService)
+	feedbackService := feedbackSvc.NewFeedbackService(feedbackRepoPkg.New(s.db), messageRepo)
+	feedbackHandler := feedbackHandlerPkg.NewFeedbackHandler(feedbackService, sessionService, botRepo)
 
 	/*
 	* Billing Handlers
@@ -244,7 +298,11 @@ func (s *Server) Run() error {
 		/*
 		* Bot routes
 		 */
-		botHandler := botHandlerPkg.NewBotHandler(botRepo)
+		botHandler := botHandlerPkg.NewBotHandler(botRepo, userRepo)
+		botHandler.SetPromptService(promptService)
+		if chatService != nil {
+			botHandler.SetChatProviders(chatService.Providers())
+		}
 		apiGroup.POST("/bots", authMiddleware.Auth(s.cfg), entitlementMiddleware.EnforceLimit(middleware.LimitBotCreation), botHandler.CreateBot)
 		apiGroup.GET("/bots", authMiddleware.Auth(s.cfg), botHandler.ListBots)
 		apiGroup.GET("/bots/:id", authMiddleware.Auth(s.cfg), botHandler.GetBot)
@@ -262,17 +320,79 @@ func (s *Server) Run() error {
 		apiGroup.GET("/bots/:id/sources/:sourceId", authMiddleware.Auth(s.cfg), sourceHandler.GetSource)
 		apiGroup.DELETE("/bots/:id/sources/:sourceId", authMiddleware.Auth(s.cfg), sourceHandler.DeleteSource)
 
+		/*
+		* Action routes (nested under bots)
+		 */
+		actionHandler := actionHandlerPkg.NewActionHandler(actionRepo, botRepo)
+		actionHandler.SetAllowPrivateNetworks(s.cfg.ActionsAllowPrivateNetworks)
+		apiGroup.POST("/bots/:id/actions", authMiddleware.Auth(s.cfg), actionHandler.CreateAction)
+		apiGroup.GET("/bots/:id/actions", authMiddleware.Auth(s.cfg), actionHandler.ListActions)
+		apiGroup.PUT("/bots/:id/actions/:actionId", authMiddleware.Auth(s.cfg), actionHandler.UpdateAction)
+		apiGroup.DELETE("/bots/:id/actions/:actionId", authMiddleware.Auth(s.cfg), actionHandler.DeleteAction)
+
+		/*
+		* Prompt version and experiment routes (nested under bots)
+		 */
+		promptHandler := promptHandlerPkg.NewPromptHandler(promptService, botRepo)
+		apiGroup.GET("/bots/:id/prompts", authMiddleware.Auth(s.cfg), promptHandler.ListVersions)
+		apiGroup.GET("/bots/:id/prompts/diff", authMiddleware.Auth(s.cfg), promptHandler.DiffVersions)
+		apiGroup.POST("/bots/:id/prompts/rollback", authMiddleware.Auth(s.cfg), promptHandler.Rollback)
+		apiGroup.GET("/bots/:id/prompts/experiments", authMiddleware.Auth(s.cfg), promptHandler.ListExperiments)
+		apiGroup.POST("/bots/:id/prompts/experiments", authMiddleware.Auth(s.cfg), promptHandler.CreateExperiment)
+		apiGroup.POST("/bots/:id/prompts/experiments/:experimentId/stop", authMiddleware.Auth(s.cfg), promptHandler.StopExperiment)
+
+		/*
+		* Golden set routes for offline evaluation (nested under bots)
+		 */
+		goldenHandler := goldenHandlerPkg.NewGoldenHandler(goldenRepoPkg.New(s.db), botRepo)
+		apiGroup.POST("/bots/:id/golden-sets", authMiddleware.Auth(s.cfg), goldenHandler.CreateSet)
+		apiGroup.GET("/bots/:id/golden-sets", authMiddleware.Auth(s.cfg), goldenHandler.ListSets)
+		apiGroup.GET("/bots/:id/golden-sets/:setId", authMiddleware.Auth(s.cfg), goldenHandler.GetSet)
+		apiGroup.DELETE("/bots/:id/golden-sets/:setId", authMiddleware.Auth(s.cfg), goldenHandler.DeleteSet)
+
+		/*
+		* API key routes (nested under bots)
+		 */
+		apiKeyHandler := apiKeyHandlerPkg.NewAPIKeyHandler(apiKeyService, botRepo)
+		apiGroup.POST("/bots/:id/api-keys", authMiddleware.Auth(s.cfg), apiKeyHandler.CreateAPIKey)
+		apiGroup.GET("/bots/:id/api-keys", authMiddleware.Auth(s.cfg), apiKeyHandler.ListAPIKeys)
+		apiGroup.DELETE("/bots/:id/api-keys/:keyId", authMiddleware.Auth(s.cfg), apiKeyHandler.RevokeAPIKey)
+
+		/*
+		* Handoff routes (operator inbox, nested under bots)
+		 */
+		handoffHandler := handoffHandlerPkg.NewHandoffHandler(handoffService, sessionService, botRepo)
+		apiGroup.GET("/bots/:id/handoffs", authMiddleware.Auth(s.cfg), handoffHandler.ListHandoffs)
+		apiGroup.GET("/bots/:id/handoffs/events", authMiddleware.Auth(s.cfg), handoffHandler.StreamEvents)
+		apiGroup.POST("/bots/:id/handoffs/:session_id/messages", authMiddleware.Auth(s.cfg), handoffHandler.Reply)
+		apiGroup.POST("/bots/:id/handoffs/:session_id/typing", authMiddleware.Auth(s.cfg), handoffHandler.Typing)
+		apiGroup.POST("/bots/:id/handoffs/:session_id/close", authMiddleware.Auth(s.cfg), handoffHandler.CloseHandoff)
+
+		/*
+		* Feedback routes (nested under bots)
+		 */
+		apiGroup.POST("/bots/:id/messages/:message_id/feedback", authMiddleware.Auth(s.cfg), feedbackHandler.SubmitFeedback)
+
 		/*
 		* Chat routes
 		 */
-		chatHandler := chatHandlerPkg.NewChatHandler(botRepo, chatService, usageService)
+		chatHandler := chatHandlerPkg.NewChatHandler(botRepo, chatService, userRepo)
 		apiGroup.POST("/bots/:id/chat", authMiddleware.Auth(s.cfg), entitlementMiddleware.EnforceLimit(middleware.LimitMessageSend), chatHandler.StreamChat)
 
+		/*
+		* Retrieval playground routes
+		 */
+		searchHandler := searchHandlerPkg.NewSearchHandler(botRepo, chatService, userRepo)
+		apiGroup.POST("/bots/:id/search/explain", authMiddleware.Auth(s.cfg), searchHandler.Explain)
+
 		/*
 		* Analytics routes
 		 */
 		apiGroup.GET("/analytics/bots/:id", authMiddleware.Auth(s.cfg), analyticsHandler.GetBotAnalytics)
 		apiGroup.GET("/analytics/bots/:id/daily", authMiddleware.Auth(s.cfg), analyticsHandler.GetBotDailyStats)
+		apiGroup.GET("/analytics/bots/:id/unanswered", authMiddleware.Auth(s.cfg), analyticsHandler.GetUnansweredQuestions)
+		apiGroup.GET("/analytics/bots/:id/answer-cache", authMiddleware.Auth(s.cfg), analyticsHandler.GetAnswerCacheStats)
+		apiGroup.GET("/analytics/bots/:id/prompts", authMiddleware.Auth(s.cfg), analyticsHandler.GetPromptVersionStats)
 		apiGroup.GET("/analytics/user", authMiddleware.Auth(s.cfg), analyticsHandler.GetUserAnalytics)
 		apiGroup.GET("/analytics/sessions/:id/messages", authMiddleware.Auth(s.cfg), analyticsHandler.GetSessionMessages)
 
@@ -287,17 +407,29 @@ func (s *Server) Run() error {
 		apiGroup.POST("/billing/webhook", webhookHandler.HandleWebhook)
 	}
 
+	/*
+	* OpenAI-compatible API routes (authenticated by bot API keys)
+	 */
+	completionsHandler := completionsHandlerPkg.NewCompletionsHandler(botRepo, chatService, userRepo)
+	v1Group := s.engine.Group("/v1")
+	v1Group.Use(apiKeyMiddleware.Auth(apiKeyService))
+	{
+		v1Group.GET("/models", completionsHandler.ListModels)
+		v1Group.POST("/chat/completions", entitlementMiddleware.EnforceLimit(middleware.LimitMessageSend), completionsHandler.ChatCompletions)
+	}
+
 	/*
 	* Public API routes for widget
 	 */
-	sessionService := session.NewSessionService()
-	publicHandler := publicHandlerPkg.NewPublicHandler(botRepo, sessionService, chatService)
+	publicHandler := publicHandlerPkg.NewPublicHandler(botRepo, sessionService, chatService, userRepo, handoffService, streamBuffer)
+	publicHandler.SetPromptService(promptService)
 
 	publicGroup := s.engine.Group("/api/public")
 	publicGroup.Use(corsMiddleware.WidgetCORS(botRepo))
 	// Apply rate limiting to public endpoints
 	if rateLimiter != nil {
 		publicGroup.Use(rateLimiter.PublicRateLimitMiddleware())
+		publicHandler.SetRateLimiter(rateLimiter)
 	}
 	{
 		// Widget configuration
@@ -308,6 +440,13 @@ func (s *Server) Run() error {
 
 		// Chat streaming
 		publicGroup.POST("/chats/:session_id/messages", publicHandler.StreamChatPublic)
+		publicGroup.GET("/chats/:session_id/stream", publicHandler.ResumeStream)
+		publicGroup.GET("/chats/:session_id/ws", publicHandler.ChatSocket)
+		publicGroup.POST("/chats/:session_id/messages/:message_id/feedback", feedbackHandler.SubmitPublicFeedback)
+
+		// Human handoff
+		publicGroup.POST("/chats/:session_id/handoff", publicHandler.RequestHandoff)
+		publicGroup.GET("/chats/:session_id/events", publicHandler.StreamEvents)
 	}
 
 	/*
@@ -327,3 +466,54 @@ func (s *Server) Run() error {
 	addr := fmt.Sprintf(":%s", s.cfg.Port)
 	return s.engine.Run(addr)
 }
+
+/*
+* VectorIndexOptions builds the vector index options from the configuration
+ */
+func VectorIndexOptions(cfg configs.Config) vectorRepoPkg.IndexOptions {
+	return vectorRepoPkg.IndexOptions{
+		Type:               strings.ToLower(strings.TrimSpace(cfg.VectorIndexType)),
+		HNSWM:              cfg.HNSWM,
+		HNSWEfConstruction: cfg.HNSWEfConstruction,
+		HNSWEfSearch:       cfg.HNSWEfSearch,
+		IVFFlatLists:       cfg.IVFFlatLists,
+		IVFFlatProbes:      cfg.IVFFlatProbes,
+		IterativeScan:      cfg.VectorIterativeScan,
+	}
+}
+
+/*
+* RegisterChatProviders registers the optional chat providers configured via environment
+* Shared with the command line tools that build their own chat service
+ */
+func RegisterChatProviders(cfg configs.Config, chatService *chat.ChatService) {
+	if cfg.OpenAICompatibleBaseURL != "" {
+		chatService.RegisterProvider(llm.ProviderOpenAICompatible, llm.NewOpenAICompatibleProvider(llm.CompatibleConfig{
+			BaseURL:      cfg.OpenAICompatibleBaseURL,
+			APIKey:       cfg.OpenAICompatibleAPIKey,
+			DefaultModel: cfg.OpenAICompatibleModel,
+
+			LegacyMaxTokens: cfg.OpenAICompatibleLegacyMaxTokens,
+			NoStreamUsage:   !cfg.OpenAICompatibleStreamUsage,
+		}))
+	}
+
+	if cfg.AzureOpenAIBaseURL != "" && cfg.AzureOpenAIAPIKey != "" {
+		chatService.RegisterProvider(llm.ProviderAzure, llm.NewOpenAICompatibleProvider(llm.CompatibleConfig{
+			BaseURL:      cfg.AzureOpenAIBaseURL,
+			APIKey:       cfg.AzureOpenAIAPIKey,
+			APIKeyHeader: "api-key",
+			DefaultModel: cfg.AzureOpenAIModel,
+		}))
+	}
+
+	if cfg.AnthropicAPIKey != "" {
+		chatService.RegisterProvider(llm.ProviderAnthropic, llm.NewOpenAICompatibleProvider(llm.CompatibleConfig{
+			BaseURL:      cfg.AnthropicBaseURL,
+			APIKey:       cfg.AnthropicAPIKey,
+			DefaultModel: cfg.AnthropicModel,
+		}))
+	}
+
+	fmt.Printf("✅ Chat providers available: %v\n", chatService.Providers())
+}
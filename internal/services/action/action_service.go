@@ -0,0 +1,362 @@
+package action
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/url"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/souravsspace/texly.chat/internal/models"
+	actionRepo "github.com/souravsspace/texly.chat/internal/repo/action"
+	"github.com/souravsspace/texly.chat/internal/services/llm"
+)
+
+const (
+	// DefaultTimeout applies to actions without their own timeout
+	DefaultTimeout = 10 * time.Second
+
+	// MaxTimeoutSeconds caps owner-configured action timeouts
+	MaxTimeoutSeconds = 30
+
+	// maxResponseBytes limits how much of an action response is fed back to the model
+	maxResponseBytes = 16 * 1024
+
+	// maxRedirects limits how many redirects an action call follows
+	maxRedirects = 5
+)
+
+// ErrBlockedAddress is returned when an action URL points at a loopback, private or internal address
+var ErrBlockedAddress = errors.New("action url must not point to a private or internal address")
+
+var (
+	actionNamePattern = regexp.MustCompile(`^[a-zA-Z0-9_-]{1,64}$`)
+	placeholderRegex  = regexp.MustCompile(`\{([a-zA-Z0-9_]+)\}`)
+
+	// blockedNetworks are not covered by the net.IP classification helpers
+	blockedNetworks = []*net.IPNet{
+		mustParseCIDR("100.64.0.0/10"), // carrier-grade NAT, used by some cloud metadata services
+		mustParseCIDR("192.0.0.0/24"),  // IETF protocol assignments
+		mustParseCIDR("198.18.0.0/15"), // benchmarking
+	}
+
+	allowedMethods = map[string]bool{
+		http.MethodGet:    true,
+		http.MethodPost:   true,
+		http.MethodPut:    true,
+		http.MethodPatch:  true,
+		http.MethodDelete: true,
+	}
+)
+
+/*
+ * ActionService loads bot actions and executes them as HTTP calls on behalf of the model
+ */
+type ActionService struct {
+	repo                 *actionRepo.ActionRepository
+	client               *http.Client
+	allowPrivateNetworks bool
+}
+
+/*
+ * NewActionService creates a new action service instance
+ * Action calls may only reach public addresses: every connection is checked after DNS resolution,
+ * so redirects and DNS rebinding cannot reach loopback, private or cloud metadata addresses
+ */
+func NewActionService(repo *actionRepo.ActionRepository) *ActionService {
+	s := &ActionService{repo: repo}
+
+	dialer := &net.Dialer{Timeout: DefaultTimeout}
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.Proxy = nil
+	transport.DialContext = func(ctx context.Context, network, address string) (net.Conn, error) {
+		return s.dialPublic(ctx, dialer, network, address)
+	}
+
+	s.client = &http.Client{
+		Transport: transport,
+		CheckRedirect: func(req *http.Request, via []*http.Request) error {
+			if len(via) >= maxRedirects {
+				return fmt.Errorf("stopped after %d redirects", maxRedirects)
+			}
+			return s.checkURL(req.URL)
+		},
+	}
+	return s
+}
+
+/*
+ * SetAllowPrivateNetworks lets actions call loopback and private addresses,
+ * for self-hosted deployments whose actions target internal services
+ */
+func (s *ActionService) SetAllowPrivateNetworks(allow bool) {
+	s.allowPrivateNetworks = allow
+}
+
+/*
+ * ListForBot returns the actions configured for a bot
+ */
+func (s *ActionService) ListForBot(ctx context.Context, botID string) ([]models.BotAction, error) {
+	if s.repo == nil {
+		return nil, nil
+	}
+	return s.repo.ListByBotID(ctx, botID)
+}
+
+/*
+ * Tools converts bot actions into tool definitions for the model
+ */
+func Tools(actions []models.BotAction) []llm.Tool {
+	tools := make([]llm.Tool, 0, len(actions))
+	for _, action := range actions {
+		parameters := map[string]any{}
+		if action.Parameters != "" {
+			if err := json.Unmarshal([]byte(action.Parameters), &parameters); err != nil {
+				// Skip actions with a broken schema rather than failing the chat
+				continue
+			}
+		}
+		if len(parameters) == 0 {
+			parameters = map[string]any{"type": "object", "properties": map[string]any{}}
+		}
+
+		tools = append(tools, llm.Tool{
+			Name:        action.Name,
+			Description: action.Description,
+			Parameters:  parameters,
+		})
+	}
+	return tools
+}
+
+/*
+ * Validate checks an action definition before it is saved
+ * URLs naming a loopback, private or internal host are rejected unless allowPrivateNetworks is set
+ */
+func Validate(action *models.BotAction, allowPrivateNetworks bool) error {
+	if !actionNamePattern.MatchString(action.Name) {
+		return errors.New("name must be 1-64 letters, digits, underscores or dashes")
+	}
+	if !allowedMethods[action.Method] {
+		return fmt.Errorf("unsupported method %q", action.Method)
+	}
+
+	parsed, err := url.Parse(action.URL)
+	if err != nil || (parsed.Scheme != "http" && parsed.Scheme != "https") || parsed.Host == "" {
+		return errors.New("url must be an absolute http(s) URL")
+	}
+	if !allowPrivateNetworks && isBlockedHost(parsed.Hostname()) {
+		return ErrBlockedAddress
+	}
+
+	if action.Parameters != "" {
+		var schema map[string]any
+		if err := json.Unmarshal([]byte(action.Parameters), &schema); err != nil {
+			return errors.New("parameters must be a JSON schema object")
+		}
+		if schemaType, ok := schema["type"]; ok && schemaType != "object" {
+			return errors.New(`parameters schema must have type "object"`)
+		}
+	}
+
+	if action.TimeoutSeconds < 0 || action.TimeoutSeconds > MaxTimeoutSeconds {
+		return fmt.Errorf("timeout_seconds must be between 0 and %d", MaxTimeoutSeconds)
+	}
+	return nil
+}
+
+/*
+ * Execute calls the action endpoint with the model-provided JSON arguments
+ * URL placeholders like {order_id} are filled from the arguments; remaining arguments
+ * are sent as query parameters for GET/DELETE and as a JSON body otherwise
+ * Returns the (truncated) response body
+ */
+func (s *ActionService) Execute(ctx context.Context, action *models.BotAction, arguments string) (string, error) {
+	args := map[string]any{}
+	if strings.TrimSpace(arguments) != "" {
+		if err := json.Unmarshal([]byte(arguments), &args); err != nil {
+			return "", fmt.Errorf("invalid arguments: %w", err)
+		}
+	}
+
+	timeout := DefaultTimeout
+	if action.TimeoutSeconds > 0 {
+		timeout = time.Duration(action.TimeoutSeconds) * time.Second
+	}
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	req, err := buildRequest(ctx, action, args)
+	if err != nil {
+		return "", err
+	}
+
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return "", fmt.Errorf("action request failed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(io.LimitReader(resp.Body, maxResponseBytes))
+	if err != nil {
+		return "", fmt.Errorf("failed to read action response: %w", err)
+	}
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return "", fmt.Errorf("action returned status %d: %s", resp.StatusCode, string(body))
+	}
+
+	return string(body), nil
+}
+
+/*
+ * buildRequest creates the HTTP request for an action call
+ */
+func buildRequest(ctx context.Context, action *models.BotAction, args map[string]any) (*http.Request, error) {
+	// Fill URL placeholders and remove the consumed arguments
+	endpoint := placeholderRegex.ReplaceAllStringFunc(action.URL, func(match string) string {
+		key := match[1 : len(match)-1]
+		value, ok := args[key]
+		if !ok {
+			return match
+		}
+		delete(args, key)
+		return url.PathEscape(fmt.Sprint(value))
+	})
+
+	var body io.Reader
+	if action.Method == http.MethodGet || action.Method == http.MethodDelete {
+		if len(args) > 0 {
+			parsed, err := url.Parse(endpoint)
+			if err != nil {
+				return nil, fmt.Errorf("invalid action url: %w", err)
+			}
+			query := parsed.Query()
+			for key, value := range args {
+				query.Set(key, fmt.Sprint(value))
+			}
+			parsed.RawQuery = query.Encode()
+			endpoint = parsed.String()
+		}
+	} else {
+		payload, err := json.Marshal(args)
+		if err != nil {
+			return nil, fmt.Errorf("failed to encode arguments: %w", err)
+		}
+		body = bytes.NewReader(payload)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, action.Method, endpoint, body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create action request: %w", err)
+	}
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	req.Header.Set("Accept", "application/json")
+
+	if action.Headers != "" {
+		var headers map[string]string
+		if err := json.Unmarshal([]byte(action.Headers), &headers); err != nil {
+			return nil, fmt.Errorf("invalid action headers: %w", err)
+		}
+		for key, value := range headers {
+			req.Header.Set(key, value)
+		}
+	}
+
+	return req, nil
+}
+
+/*
+ * checkURL rejects redirect targets that are not http(s) or name a blocked host
+ * Hostnames are resolved and checked again when the connection is dialled
+ */
+func (s *ActionService) checkURL(target *url.URL) error {
+	if target.Scheme != "http" && target.Scheme != "https" {
+		return fmt.Errorf("action redirected to unsupported scheme %q", target.Scheme)
+	}
+	if !s.allowPrivateNetworks && isBlockedHost(target.Hostname()) {
+		return ErrBlockedAddress
+	}
+	return nil
+}
+
+/*
+ * dialPublic resolves the address and connects to the first public IP it resolves to
+ * Dialling the checked IP rather than the hostname keeps a second DNS answer from changing the target
+ */
+func (s *ActionService) dialPublic(ctx context.Context, dialer *net.Dialer, network, address string) (net.Conn, error) {
+	if s.allowPrivateNetworks {
+		return dialer.DialContext(ctx, network, address)
+	}
+
+	host, port, err := net.SplitHostPort(address)
+	if err != nil {
+		return nil, err
+	}
+	addrs, err := net.DefaultResolver.LookupIPAddr(ctx, host)
+	if err != nil {
+		return nil, err
+	}
+
+	var lastErr error = ErrBlockedAddress
+	for _, addr := range addrs {
+		if isBlockedIP(addr.IP) {
+			continue
+		}
+		conn, err := dialer.DialContext(ctx, network, net.JoinHostPort(addr.IP.String(), port))
+		if err == nil {
+			return conn, nil
+		}
+		lastErr = err
+	}
+	return nil, lastErr
+}
+
+/*
+ * isBlockedHost reports whether a URL host is a blocked IP literal or a local hostname
+ */
+func isBlockedHost(host string) bool {
+	host = strings.TrimSuffix(strings.ToLower(host), ".")
+	if host == "localhost" || strings.HasSuffix(host, ".localhost") {
+		return true
+	}
+	if ip := net.ParseIP(host); ip != nil {
+		return isBlockedIP(ip)
+	}
+	return false
+}
+
+/*
+ * isBlockedIP reports whether an IP is loopback, private, link-local (including the
+ * 169.254.169.254 metadata endpoint), multicast, unspecified or otherwise not public
+ */
+func isBlockedIP(ip net.IP) bool {
+	if ip.IsLoopback() || ip.IsPrivate() || ip.IsUnspecified() ||
+		ip.IsLinkLocalUnicast() || ip.IsLinkLocalMulticast() ||
+		ip.IsInterfaceLocalMulticast() || ip.IsMulticast() {
+		return true
+	}
+	for _, network := range blockedNetworks {
+		if network.Contains(ip) {
+			return true
+		}
+	}
+	return false
+}
+
+func mustParseCIDR(cidr string) *net.IPNet {
+	_, network, err := net.ParseCIDR(cidr)
+	if err != nil {
+		panic(err)
+	}
+	return network
+}
@@ -0,0 +1,190 @@
+package action
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/souravsspace/texly.chat/internal/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+/*
+ * Test Execute fills URL placeholders, sends query params and auth headers
+ */
+func TestExecute_GetWithPlaceholders(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, http.MethodGet, r.Method)
+		assert.Equal(t, "/orders/A%2F42", r.URL.EscapedPath())
+		assert.Equal(t, "true", r.URL.Query().Get("verbose"))
+		assert.Equal(t, "Bearer secret", r.Header.Get("Authorization"))
+		w.Write([]byte(`{"status":"shipped"}`))
+	}))
+	defer server.Close()
+
+	service := NewActionService(nil)
+	service.SetAllowPrivateNetworks(true)
+	botAction := &models.BotAction{
+		Name:    "lookup_order_status",
+		Method:  http.MethodGet,
+		URL:     server.URL + "/orders/{order_id}",
+		Headers: `{"Authorization":"Bearer secret"}`,
+	}
+
+	result, err := service.Execute(context.Background(), botAction, `{"order_id":"A/42","verbose":true}`)
+	require.NoError(t, err)
+	assert.Equal(t, `{"status":"shipped"}`, result)
+}
+
+/*
+ * Test Execute sends remaining arguments as a JSON body for POST
+ */
+func TestExecute_PostBody(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, http.MethodPost, r.Method)
+		assert.Equal(t, "application/json", r.Header.Get("Content-Type"))
+
+		body, _ := io.ReadAll(r.Body)
+		var payload map[string]any
+		require.NoError(t, json.Unmarshal(body, &payload))
+		assert.Equal(t, "jane@example.com", payload["email"])
+		w.Write([]byte(`ok`))
+	}))
+	defer server.Close()
+
+	service := NewActionService(nil)
+	service.SetAllowPrivateNetworks(true)
+	botAction := &models.BotAction{Name: "subscribe", Method: http.MethodPost, URL: server.URL}
+
+	result, err := service.Execute(context.Background(), botAction, `{"email":"jane@example.com"}`)
+	require.NoError(t, err)
+	assert.Equal(t, "ok", result)
+}
+
+/*
+ * Test Execute reports non-2xx responses, invalid arguments and timeouts as errors
+ */
+func TestExecute_Errors(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/slow" {
+			time.Sleep(1500 * time.Millisecond)
+		}
+		w.WriteHeader(http.StatusNotFound)
+		w.Write([]byte(`order not found`))
+	}))
+	defer server.Close()
+
+	service := NewActionService(nil)
+	service.SetAllowPrivateNetworks(true)
+
+	_, err := service.Execute(context.Background(), &models.BotAction{Method: http.MethodGet, URL: server.URL}, "")
+	assert.ErrorContains(t, err, "status 404")
+
+	_, err = service.Execute(context.Background(), &models.BotAction{Method: http.MethodGet, URL: server.URL}, "not json")
+	assert.ErrorContains(t, err, "invalid arguments")
+
+	slow := &models.BotAction{Method: http.MethodGet, URL: server.URL + "/slow", TimeoutSeconds: 1}
+	_, err = service.Execute(context.Background(), slow, "")
+	assert.ErrorIs(t, err, context.DeadlineExceeded)
+}
+
+/*
+ * Test Validate rejects malformed action definitions
+ */
+func TestValidate(t *testing.T) {
+	valid := models.BotAction{
+		Name:       "lookup_order_status",
+		Method:     http.MethodGet,
+		URL:        "https://api.example.com/orders/{order_id}",
+		Parameters: `{"type":"object","properties":{"order_id":{"type":"string"}}}`,
+	}
+	require.NoError(t, Validate(&valid, false))
+
+	testCases := []struct {
+		name   string
+		mutate func(a *models.BotAction)
+	}{
+		{"Invalid name", func(a *models.BotAction) { a.Name = "look up order" }},
+		{"Invalid method", func(a *models.BotAction) { a.Method = "TRACE" }},
+		{"Relative URL", func(a *models.BotAction) { a.URL = "/orders" }},
+		{"Non-HTTP URL", func(a *models.BotAction) { a.URL = "file:///etc/passwd" }},
+		{"Non-object schema", func(a *models.BotAction) { a.Parameters = `{"type":"string"}` }},
+		{"Timeout too long", func(a *models.BotAction) { a.TimeoutSeconds = MaxTimeoutSeconds + 1 }},
+		{"Localhost URL", func(a *models.BotAction) { a.URL = "http://localhost:8080/admin" }},
+		{"Loopback URL", func(a *models.BotAction) { a.URL = "http://127.0.0.1/admin" }},
+		{"Private URL", func(a *models.BotAction) { a.URL = "http://10.0.0.5/internal" }},
+		{"Metadata URL", func(a *models.BotAction) { a.URL = "http://169.254.169.254/latest/meta-data/" }},
+		{"IPv6 loopback URL", func(a *models.BotAction) { a.URL = "http://[::1]/admin" }},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			botAction := valid
+			tc.mutate(&botAction)
+			assert.Error(t, Validate(&botAction, false))
+		})
+	}
+}
+
+/*
+ * Test Execute refuses to connect to loopback addresses, directly or through a redirect
+ */
+func TestExecute_BlocksPrivateAddresses(t *testing.T) {
+	internal := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`secret`))
+	}))
+	defer internal.Close()
+
+	service := NewActionService(nil)
+
+	_, err := service.Execute(context.Background(), &models.BotAction{Method: http.MethodGet, URL: internal.URL}, "")
+	assert.ErrorIs(t, err, ErrBlockedAddress)
+
+	// A public-looking host that resolves to loopback is blocked when dialled
+	localhostURL := strings.Replace(internal.URL, "127.0.0.1", "localhost", 1)
+	_, err = service.Execute(context.Background(), &models.BotAction{Method: http.MethodGet, URL: localhostURL}, "")
+	assert.ErrorIs(t, err, ErrBlockedAddress)
+
+	// Redirect targets are checked on every hop
+	redirect := &http.Request{URL: &url.URL{Scheme: "http", Host: "169.254.169.254", Path: "/latest/meta-data/"}}
+	assert.ErrorIs(t, service.client.CheckRedirect(redirect, []*http.Request{{}}), ErrBlockedAddress)
+	redirect.URL = &url.URL{Scheme: "file", Path: "/etc/passwd"}
+	assert.Error(t, service.client.CheckRedirect(redirect, []*http.Request{{}}))
+}
+
+/*
+ * Test isBlockedIP classifies internal and public addresses
+ */
+func TestIsBlockedIP(t *testing.T) {
+	for _, ip := range []string{"127.0.0.1", "10.1.2.3", "172.16.0.1", "192.168.1.1", "169.254.169.254", "100.100.100.200", "0.0.0.0", "::1", "fe80::1", "fd00:ec2::254", "::ffff:127.0.0.1"} {
+		assert.True(t, isBlockedIP(net.ParseIP(ip)), ip)
+	}
+	for _, ip := range []string{"93.184.216.34", "8.8.8.8", "2606:4700::1111"} {
+		assert.False(t, isBlockedIP(net.ParseIP(ip)), ip)
+	}
+}
+
+/*
+ * Test Tools converts actions into tool definitions
+ */
+func TestTools(t *testing.T) {
+	tools := Tools([]models.BotAction{
+		{Name: "lookup_order_status", Description: "Look up an order", Parameters: `{"type":"object","properties":{"order_id":{"type":"string"}}}`},
+		{Name: "ping"},
+		{Name: "broken", Parameters: `{not json`},
+	})
+
+	require.Len(t, tools, 2)
+	assert.Equal(t, "lookup_order_status", tools[0].Name)
+	assert.Equal(t, "Look up an order", tools[0].Description)
+	assert.Contains(t, tools[0].Parameters, "properties")
+	assert.Equal(t, "object", tools[1].Parameters["type"])
+}
@@ -6,6 +6,7 @@ import (
 
 	"github.com/souravsspace/texly.chat/internal/models"
 	messageRepo "github.com/souravsspace/texly.chat/internal/repo/message"
+	"github.com/souravsspace/texly.chat/internal/services/answercache"
 )
 
 /*
@@ -13,6 +14,7 @@ import (
  */
 type AnalyticsService struct {
 	messageRepo *messageRepo.MessageRepository
+	answerCache *answercache.AnswerCacheService // Optional: semantic answer cache metrics
 }
 
 /*
@@ -31,6 +33,23 @@ func (s *AnalyticsService) GetBotAnalytics(ctx context.Context, botID string) (*
 	return s.messageRepo.GetBotAnalytics(ctx, botID)
 }
 
+/*
+ * SetAnswerCache enables reporting semantic answer cache metrics
+ */
+func (s *AnalyticsService) SetAnswerCache(answerCache *answercache.AnswerCacheService) {
+	s.answerCache = answerCache
+}
+
+/*
+ * GetAnswerCacheStats retrieves the semantic answer cache hit rate of a bot
+ */
+func (s *AnalyticsService) GetAnswerCacheStats(ctx context.Context, botID string) (*models.AnswerCacheStats, error) {
+	if s.answerCache == nil {
+		return &models.AnswerCacheStats{BotID: botID}, nil
+	}
+	return s.answerCache.Stats(ctx, botID)
+}
+
 /*
  * GetBotDailyStats retrieves daily message statistics for a bot
  */
@@ -49,8 +68,37 @@ func (s *AnalyticsService) GetUserAnalytics(ctx context.Context, userID string)
 }
 
 /*
- * GetSessionMessages retrieves all messages for a specific session
+ * GetSessionMessages retrieves all messages for a specific session with the rolling summary
+ * of its older turns (nil when the conversation has not been summarized)
+ */
+func (s *AnalyticsService) GetSessionMessages(ctx context.Context, sessionID string) (*models.SessionTranscript, error) {
+	messages, err := s.messageRepo.GetBySessionID(ctx, sessionID)
+	if err != nil {
+		return nil, err
+	}
+
+	summary, err := s.messageRepo.GetSessionSummary(ctx, sessionID)
+	if err != nil {
+		return nil, err
+	}
+
+	return &models.SessionTranscript{
+		SessionID: sessionID,
+		Summary:   summary,
+		Messages:  messages,
+	}, nil
+}
+
+/*
+ * GetUnansweredQuestions retrieves recent questions a bot answered with its fallback
+ */
+func (s *AnalyticsService) GetUnansweredQuestions(ctx context.Context, botID string, limit int) ([]models.Message, error) {
+	return s.messageRepo.GetUnansweredByBotID(ctx, botID, limit)
+}
+
+/*
+ * GetPromptVersionStats compares fallback rates and feedback across a bot's prompt versions
  */
-func (s *AnalyticsService) GetSessionMessages(ctx context.Context, sessionID string) ([]models.Message, error) {
-	return s.messageRepo.GetBySessionID(ctx, sessionID)
+func (s *AnalyticsService) GetPromptVersionStats(ctx context.Context, botID string) ([]models.PromptVersionStats, error) {
+	return s.messageRepo.GetPromptVersionStats(ctx, botID)
 }
@@ -226,14 +226,32 @@ func TestGetSessionMessages(t *testing.T) {
 	}
 
 	// Get session messages
-	retrieved, err := service.GetSessionMessages(ctx, sessionID)
+	transcript, err := service.GetSessionMessages(ctx, sessionID)
 	require.NoError(t, err)
+	retrieved := transcript.Messages
 	assert.GreaterOrEqual(t, len(retrieved), 3)
+	assert.Nil(t, transcript.Summary)
 
 	// Verify order
 	assert.Equal(t, "First message", retrieved[0].Content)
 	assert.Equal(t, "First response", retrieved[1].Content)
 	assert.Equal(t, "Second message", retrieved[2].Content)
+
+	// The rolling summary of older turns is included once it exists
+	err = msgRepo.SaveSessionSummary(ctx, &models.SessionSummary{
+		SessionID:     sessionID,
+		BotID:         botID,
+		Content:       "The visitor said hello twice.",
+		LastMessageID: messages[1].ID,
+		MessageCount:  2,
+	})
+	require.NoError(t, err)
+
+	transcript, err = service.GetSessionMessages(ctx, sessionID)
+	require.NoError(t, err)
+	require.NotNil(t, transcript.Summary)
+	assert.Equal(t, "The visitor said hello twice.", transcript.Summary.Content)
+	assert.Equal(t, messages[1].ID, transcript.Summary.LastMessageID)
 }
 
 /*
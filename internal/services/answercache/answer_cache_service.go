@@ -0,0 +1,252 @@
+package answercache
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/souravsspace/texly.chat/internal/models"
+	"github.com/souravsspace/texly.chat/internal/services/cache"
+	"github.com/souravsspace/texly.chat/internal/services/vector"
+)
+
+/*
+ * maxEntriesPerBot bounds how many answers are kept (and compared on lookup) per bot
+ */
+const maxEntriesPerBot = 200
+
+/*
+ * Entry is a cached answer to a question, with the question's embedding for similarity lookups
+ */
+type Entry struct {
+	ID        string            `json:"id"`
+	Question  string            `json:"question"`
+	Embedding []float32         `json:"embedding"`
+	Answer    string            `json:"answer"`
+	Citations []models.Citation `json:"citations,omitempty"`
+	CreatedAt time.Time         `json:"created_at"`
+}
+
+/*
+ * AnswerCacheService stores bot answers in Redis and replays them for semantically similar questions
+ * Each answer is its own key, listed in a per-bot index so stores from several instances never overwrite each other
+ * Entries are dropped through Invalidate when the bot or its sources change (see the bot and source repos)
+ */
+type AnswerCacheService struct {
+	cache     *cache.CacheService
+	threshold float64 // Minimum cosine similarity for a hit; 0 disables the cache
+}
+
+/*
+ * NewAnswerCacheService creates a semantic answer cache
+ * The cache is disabled when Redis is not configured or threshold is 0
+ */
+func NewAnswerCacheService(cacheService *cache.CacheService, threshold float64) *AnswerCacheService {
+	return &AnswerCacheService{
+		cache:     cacheService,
+		threshold: threshold,
+	}
+}
+
+/*
+ * Enabled reports whether answers are cached
+ */
+func (s *AnswerCacheService) Enabled() bool {
+	return s != nil && s.threshold > 0 && s.cache.IsEnabled()
+}
+
+/*
+ * GetQueryEmbedding returns the cached embedding of a question, if any
+ */
+func (s *AnswerCacheService) GetQueryEmbedding(ctx context.Context, botID, question string) ([]float32, bool) {
+	var embedding []float32
+	if err := s.cache.GetJSON(ctx, queryEmbeddingKey(botID, question), &embedding); err != nil {
+		return nil, false
+	}
+	return embedding, true
+}
+
+/*
+ * SetQueryEmbedding caches the embedding of a question so repeats are not re-embedded
+ */
+func (s *AnswerCacheService) SetQueryEmbedding(ctx context.Context, botID, question string, embedding []float32) error {
+	return s.cache.SetJSON(ctx, queryEmbeddingKey(botID, question), embedding, cache.VectorSearchCacheTTL)
+}
+
+/*
+ * Lookup returns the cached answer closest to the question embedding, or nil when none is
+ * similar enough. Every lookup is counted as a hit or miss
+ */
+func (s *AnswerCacheService) Lookup(ctx context.Context, botID string, embedding []float32) (*Entry, error) {
+	entries, err := s.entries(ctx, botID)
+	if err != nil {
+		return nil, err
+	}
+
+	var best *Entry
+	bestSimilarity := s.threshold
+	for i := range entries {
+		similarity := vector.CosineSimilarity(embedding, entries[i].Embedding)
+		if similarity >= bestSimilarity {
+			best, bestSimilarity = &entries[i], similarity
+		}
+	}
+
+	outcome := "misses"
+	if best != nil {
+		outcome = "hits"
+	}
+	_, _ = s.cache.Incr(ctx, fmt.Sprintf(cache.AnswerCacheStatsKey, botID, outcome))
+
+	return best, nil
+}
+
+/*
+ * Store caches an answer, evicting the oldest entries beyond maxEntriesPerBot
+ * The entry is written before it is listed, so the index never names a missing answer
+ */
+func (s *AnswerCacheService) Store(ctx context.Context, botID string, entry Entry) error {
+	if entry.ID == "" {
+		entry.ID = uuid.New().String()
+	}
+	if entry.CreatedAt.IsZero() {
+		entry.CreatedAt = time.Now()
+	}
+
+	if err := s.cache.SetJSON(ctx, entryKey(botID, entry.ID), entry, cache.AnswerCacheTTL); err != nil {
+		return fmt.Errorf("failed to cache answer: %w", err)
+	}
+	indexKey := fmt.Sprintf(cache.AnswerCacheKey, botID)
+	if err := s.cache.Append(ctx, indexKey, entry.ID, cache.AnswerCacheTTL); err != nil {
+		return fmt.Errorf("failed to index cached answer: %w", err)
+	}
+
+	evicted, err := s.cache.TrimList(ctx, indexKey, maxEntriesPerBot)
+	if err != nil {
+		return fmt.Errorf("failed to evict cached answers: %w", err)
+	}
+	for _, id := range evicted {
+		_ = s.cache.Delete(ctx, entryKey(botID, id))
+	}
+	return nil
+}
+
+/*
+ * Invalidate drops every cached answer of a bot
+ * Runs whenever Redis is configured, so answers cached before the cache was disabled are dropped too
+ */
+func (s *AnswerCacheService) Invalidate(ctx context.Context, botID string) error {
+	if s == nil || !s.cache.IsEnabled() {
+		return nil
+	}
+
+	indexKey := fmt.Sprintf(cache.AnswerCacheKey, botID)
+	ids, err := s.cache.Range(ctx, indexKey, 0)
+	if err != nil {
+		return fmt.Errorf("failed to list cached answers: %w", err)
+	}
+	if err := s.cache.Delete(ctx, indexKey); err != nil {
+		return err
+	}
+	for _, id := range ids {
+		_ = s.cache.Delete(ctx, entryKey(botID, id))
+	}
+	return nil
+}
+
+/*
+ * Stats returns the hit and miss counts of a bot's answer cache
+ */
+func (s *AnswerCacheService) Stats(ctx context.Context, botID string) (*models.AnswerCacheStats, error) {
+	stats := &models.AnswerCacheStats{BotID: botID, Enabled: s.Enabled()}
+	if !stats.Enabled {
+		return stats, nil
+	}
+
+	var err error
+	if stats.Hits, err = s.counter(ctx, botID, "hits"); err != nil {
+		return nil, err
+	}
+	if stats.Misses, err = s.counter(ctx, botID, "misses"); err != nil {
+		return nil, err
+	}
+	if lookups := stats.Hits + stats.Misses; lookups > 0 {
+		stats.HitRate = float64(stats.Hits) / float64(lookups)
+	}
+
+	entries, err := s.entries(ctx, botID)
+	if err != nil {
+		return nil, err
+	}
+	stats.Entries = len(entries)
+
+	return stats, nil
+}
+
+/*
+ * entries loads the cached answers of a bot, skipping listed entries that already expired
+ */
+func (s *AnswerCacheService) entries(ctx context.Context, botID string) ([]Entry, error) {
+	ids, err := s.cache.Range(ctx, fmt.Sprintf(cache.AnswerCacheKey, botID), 0)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list cached answers: %w", err)
+	}
+
+	keys := make([]string, len(ids))
+	for i, id := range ids {
+		keys[i] = entryKey(botID, id)
+	}
+	values, err := s.cache.GetMany(ctx, keys)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load cached answers: %w", err)
+	}
+
+	entries := make([]Entry, 0, len(values))
+	for _, value := range values {
+		if value == "" {
+			continue
+		}
+		var entry Entry
+		if err := json.Unmarshal([]byte(value), &entry); err != nil {
+			continue
+		}
+		entries = append(entries, entry)
+	}
+	return entries, nil
+}
+
+/*
+ * entryKey returns the cache key of one cached answer
+ */
+func entryKey(botID, entryID string) string {
+	return fmt.Sprintf(cache.AnswerCacheEntryKey, botID, entryID)
+}
+
+/*
+ * counter reads a hit or miss counter, treating a missing key as zero
+ */
+func (s *AnswerCacheService) counter(ctx context.Context, botID, outcome string) (int64, error) {
+	value, err := s.cache.Get(ctx, fmt.Sprintf(cache.AnswerCacheStatsKey, botID, outcome))
+	if errors.Is(err, cache.ErrCacheMiss) {
+		return 0, nil
+	}
+	if err != nil {
+		return 0, fmt.Errorf("failed to read answer cache stats: %w", err)
+	}
+	return strconv.ParseInt(value, 10, 64)
+}
+
+/*
+ * queryEmbeddingKey hashes the question, ignoring case and surrounding whitespace
+ */
+func queryEmbeddingKey(botID, question string) string {
+	sum := sha256.Sum256([]byte(strings.ToLower(strings.TrimSpace(question))))
+	return fmt.Sprintf(cache.VectorSearchCacheKey, botID, hex.EncodeToString(sum[:]))
+}
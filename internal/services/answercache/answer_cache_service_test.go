@@ -0,0 +1,187 @@
+package answercache_test
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"testing"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/redis/go-redis/v9"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/souravsspace/texly.chat/internal/models"
+	"github.com/souravsspace/texly.chat/internal/services/answercache"
+	"github.com/souravsspace/texly.chat/internal/services/cache"
+)
+
+func setupAnswerCache(t *testing.T, threshold float64) *answercache.AnswerCacheService {
+	service, _ := setupAnswerCacheWithRedis(t, threshold)
+	return service
+}
+
+func setupAnswerCacheWithRedis(t *testing.T, threshold float64) (*answercache.AnswerCacheService, *miniredis.Miniredis) {
+	mr, err := miniredis.Run()
+	require.NoError(t, err)
+	t.Cleanup(mr.Close)
+
+	client := redis.NewClient(&redis.Options{Addr: mr.Addr()})
+	return answercache.NewAnswerCacheService(cache.NewCacheService(client), threshold), mr
+}
+
+func TestAnswerCache_LookupAndStore(t *testing.T) {
+	ctx := context.Background()
+	service := setupAnswerCache(t, 0.95)
+	require.True(t, service.Enabled())
+
+	// Nothing cached yet
+	entry, err := service.Lookup(ctx, "bot-1", []float32{1, 0, 0})
+	require.NoError(t, err)
+	assert.Nil(t, entry)
+
+	err = service.Store(ctx, "bot-1", answercache.Entry{
+		Question:  "Do you ship abroad?",
+		Embedding: []float32{1, 0, 0},
+		Answer:    "Yes, worldwide.",
+		Citations: []models.Citation{{ChunkID: "chunk-1", SourceID: "source-1"}},
+	})
+	require.NoError(t, err)
+
+	// A nearly identical question hits
+	entry, err = service.Lookup(ctx, "bot-1", []float32{0.99, 0.05, 0})
+	require.NoError(t, err)
+	require.NotNil(t, entry)
+	assert.Equal(t, "Yes, worldwide.", entry.Answer)
+	require.Len(t, entry.Citations, 1)
+	assert.False(t, entry.CreatedAt.IsZero())
+
+	// A different question misses
+	entry, err = service.Lookup(ctx, "bot-1", []float32{0, 1, 0})
+	require.NoError(t, err)
+	assert.Nil(t, entry)
+
+	// Entries are per bot
+	entry, err = service.Lookup(ctx, "bot-2", []float32{1, 0, 0})
+	require.NoError(t, err)
+	assert.Nil(t, entry)
+
+	stats, err := service.Stats(ctx, "bot-1")
+	require.NoError(t, err)
+	assert.True(t, stats.Enabled)
+	assert.Equal(t, int64(1), stats.Hits)
+	assert.Equal(t, int64(2), stats.Misses)
+	assert.InDelta(t, 1.0/3.0, stats.HitRate, 0.0001)
+	assert.Equal(t, 1, stats.Entries)
+}
+
+func TestAnswerCache_Invalidate(t *testing.T) {
+	ctx := context.Background()
+	service, mr := setupAnswerCacheWithRedis(t, 0.95)
+
+	require.NoError(t, service.Store(ctx, "bot-1", answercache.Entry{Embedding: []float32{1, 0}, Answer: "Old answer"}))
+	require.NoError(t, service.Invalidate(ctx, "bot-1"))
+
+	// The index and the entries it listed are both gone
+	for _, key := range mr.Keys() {
+		assert.NotContains(t, key, "answers:bot-1")
+	}
+
+	// Repos without an answer cache can invalidate safely
+	var missing *answercache.AnswerCacheService
+	assert.NoError(t, missing.Invalidate(ctx, "bot-1"))
+
+	entry, err := service.Lookup(ctx, "bot-1", []float32{1, 0})
+	require.NoError(t, err)
+	assert.Nil(t, entry)
+
+	// Metrics survive invalidation
+	stats, err := service.Stats(ctx, "bot-1")
+	require.NoError(t, err)
+	assert.Equal(t, int64(1), stats.Misses)
+	assert.Equal(t, 0, stats.Entries)
+}
+
+func TestAnswerCache_EvictsOldestEntries(t *testing.T) {
+	ctx := context.Background()
+	service, mr := setupAnswerCacheWithRedis(t, 0.95)
+
+	for i := 0; i < 205; i++ {
+		embedding := make([]float32, 205)
+		embedding[i] = 1
+		require.NoError(t, service.Store(ctx, "bot-1", answercache.Entry{Embedding: embedding, Answer: fmt.Sprint(i)}))
+	}
+
+	stats, err := service.Stats(ctx, "bot-1")
+	require.NoError(t, err)
+	assert.Equal(t, 200, stats.Entries)
+
+	// Evicted answers are deleted, not just unlisted
+	assert.Len(t, mr.Keys(), 201)
+
+	oldest := make([]float32, 205)
+	oldest[0] = 1
+	entry, err := service.Lookup(ctx, "bot-1", oldest)
+	require.NoError(t, err)
+	assert.Nil(t, entry)
+
+	newest := make([]float32, 205)
+	newest[204] = 1
+	entry, err = service.Lookup(ctx, "bot-1", newest)
+	require.NoError(t, err)
+	require.NotNil(t, entry)
+	assert.Equal(t, "204", entry.Answer)
+}
+
+func TestAnswerCache_ConcurrentStores(t *testing.T) {
+	ctx := context.Background()
+	service := setupAnswerCache(t, 0.95)
+
+	// Stores from several instances don't overwrite each other
+	var wg sync.WaitGroup
+	for i := 0; i < 20; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			assert.NoError(t, service.Store(ctx, "bot-1", answercache.Entry{Embedding: []float32{1, 0}, Answer: fmt.Sprint(i)}))
+		}()
+	}
+	wg.Wait()
+
+	stats, err := service.Stats(ctx, "bot-1")
+	require.NoError(t, err)
+	assert.Equal(t, 20, stats.Entries)
+}
+
+func TestAnswerCache_QueryEmbedding(t *testing.T) {
+	ctx := context.Background()
+	service := setupAnswerCache(t, 0.95)
+
+	_, ok := service.GetQueryEmbedding(ctx, "bot-1", "Do you ship abroad?")
+	assert.False(t, ok)
+
+	require.NoError(t, service.SetQueryEmbedding(ctx, "bot-1", "Do you ship abroad?", []float32{0.1, 0.2}))
+
+	// Case and surrounding whitespace are ignored
+	embedding, ok := service.GetQueryEmbedding(ctx, "bot-1", "  do you ship abroad?")
+	assert.True(t, ok)
+	assert.Equal(t, []float32{0.1, 0.2}, embedding)
+}
+
+func TestAnswerCache_Disabled(t *testing.T) {
+	ctx := context.Background()
+
+	// Zero threshold disables the cache
+	assert.False(t, setupAnswerCache(t, 0).Enabled())
+
+	// So does a missing Redis client
+	service := answercache.NewAnswerCacheService(cache.NewCacheService(nil), 0.95)
+	assert.False(t, service.Enabled())
+
+	stats, err := service.Stats(ctx, "bot-1")
+	require.NoError(t, err)
+	assert.False(t, stats.Enabled)
+
+	var nilService *answercache.AnswerCacheService
+	assert.False(t, nilService.Enabled())
+}
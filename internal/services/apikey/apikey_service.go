@@ -0,0 +1,107 @@
+package apikey
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/souravsspace/texly.chat/internal/models"
+	apiKeyRepo "github.com/souravsspace/texly.chat/internal/repo/apikey"
+)
+
+const (
+	// KeyPrefix marks Texly bot API keys
+	KeyPrefix = "txk_"
+
+	// displayPrefixLength is how much of the key is kept in clear text for identification
+	displayPrefixLength = 12
+)
+
+var ErrInvalidKey = errors.New("invalid api key")
+
+/*
+ * APIKeyService issues and verifies bot-scoped API keys
+ */
+type APIKeyService struct {
+	repo *apiKeyRepo.APIKeyRepository
+}
+
+/*
+ * NewAPIKeyService creates a new API key service instance
+ */
+func NewAPIKeyService(repo *apiKeyRepo.APIKeyRepository) *APIKeyService {
+	return &APIKeyService{repo: repo}
+}
+
+/*
+ * Create issues a new key for the bot and returns it with its plaintext secret
+ * The secret cannot be recovered later
+ */
+func (s *APIKeyService) Create(ctx context.Context, bot *models.Bot, name string) (*models.BotAPIKey, string, error) {
+	secret := make([]byte, 24)
+	if _, err := rand.Read(secret); err != nil {
+		return nil, "", fmt.Errorf("failed to generate api key: %w", err)
+	}
+	key := KeyPrefix + hex.EncodeToString(secret)
+
+	apiKey := &models.BotAPIKey{
+		BotID:   bot.ID,
+		UserID:  bot.UserID,
+		Name:    name,
+		Prefix:  key[:displayPrefixLength],
+		KeyHash: HashKey(key),
+	}
+	if err := s.repo.Create(ctx, apiKey); err != nil {
+		return nil, "", err
+	}
+
+	return apiKey, key, nil
+}
+
+/*
+ * Authenticate resolves a plaintext key to its record
+ * Returns ErrInvalidKey for unknown or revoked keys
+ */
+func (s *APIKeyService) Authenticate(ctx context.Context, key string) (*models.BotAPIKey, error) {
+	if !strings.HasPrefix(key, KeyPrefix) {
+		return nil, ErrInvalidKey
+	}
+
+	apiKey, err := s.repo.GetByHash(ctx, HashKey(key))
+	if err != nil {
+		return nil, err
+	}
+	if apiKey == nil {
+		return nil, ErrInvalidKey
+	}
+
+	// Best effort; a failed timestamp update should not reject the request
+	_ = s.repo.TouchLastUsed(ctx, apiKey.ID)
+	return apiKey, nil
+}
+
+/*
+ * List returns the keys issued for a bot
+ */
+func (s *APIKeyService) List(ctx context.Context, botID string) ([]models.BotAPIKey, error) {
+	return s.repo.ListByBotID(ctx, botID)
+}
+
+/*
+ * Revoke deletes a bot's key
+ */
+func (s *APIKeyService) Revoke(ctx context.Context, id string, botID string) error {
+	return s.repo.Delete(ctx, id, botID)
+}
+
+/*
+ * HashKey returns the SHA-256 hex digest stored for a key
+ */
+func HashKey(key string) string {
+	sum := sha256.Sum256([]byte(key))
+	return hex.EncodeToString(sum[:])
+}
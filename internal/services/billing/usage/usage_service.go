@@ -23,6 +23,16 @@ func (s *UsageService) TrackChatMessage(userID, botID string) error {
 	return s.trackUsage(userID, botID, models.UsageTypeChatMessage, 1, cost)
 }
 
+// TrackChatTokens records usage for one chat answer, priced by the tokens the provider reported
+func (s *UsageService) TrackChatTokens(userID, botID, model string, promptTokens, completionTokens int) error {
+	return s.trackTokens(userID, botID, models.UsageTypeChatTokens, model, promptTokens, completionTokens)
+}
+
+// TrackHelperTokens records usage for a completion supporting an answer; it is billed but not counted as a message
+func (s *UsageService) TrackHelperTokens(userID, botID, model string, promptTokens, completionTokens int) error {
+	return s.trackTokens(userID, botID, models.UsageTypeHelperTokens, model, promptTokens, completionTokens)
+}
+
 // TrackEmbedding records usage for embedding tokens
 func (s *UsageService) TrackEmbedding(userID string, tokens int) error {
 	cost := configs.CalculateEmbeddingCost(tokens)
@@ -35,20 +45,38 @@ func (s *UsageService) TrackStorage(userID string, sizeGB float64) error {
 	return s.trackUsage(userID, "", models.UsageTypeStorage, sizeGB, cost)
 }
 
+// trackTokens saves a token usage record priced by the model's prompt and completion tokens
+func (s *UsageService) trackTokens(userID, botID, usageType, model string, promptTokens, completionTokens int) error {
+	cost := configs.CalculateChatTokenCost(model, promptTokens, completionTokens)
+	return s.trackUsageRecord(models.UsageRecord{
+		UserID:   userID,
+		BotID:    botID,
+		Type:     usageType,
+		Model:    model,
+		Quantity: float64(promptTokens + completionTokens),
+		Cost:     cost,
+	})
+}
+
 // trackUsage is the internal helper to save the record and update user balance
 func (s *UsageService) trackUsage(userID, botID, usageType string, quantity, cost float64) error {
+	return s.trackUsageRecord(models.UsageRecord{
+		UserID:   userID,
+		BotID:    botID,
+		Type:     usageType,
+		Quantity: quantity,
+		Cost:     cost,
+	})
+}
+
+// trackUsageRecord saves the record and charges its cost to the user
+func (s *UsageService) trackUsageRecord(record models.UsageRecord) error {
+	userID, cost := record.UserID, record.Cost
 	return s.db.Transaction(func(tx *gorm.DB) error {
 		// 1. Create usage record
-		record := models.UsageRecord{
-			ID:        uuid.New().String(),
-			UserID:    userID,
-			BotID:     botID,
-			Type:      usageType,
-			Quantity:  quantity,
-			Cost:      cost,
-			BilledAt:  time.Time{}, // Zero time means not yet billed/invoiced
-			CreatedAt: time.Now(),
-		}
+		record.ID = uuid.New().String()
+		record.BilledAt = time.Time{} // Zero time means not yet billed/invoiced
+		record.CreatedAt = time.Now()
 		if err := tx.Create(&record).Error; err != nil {
 			return err
 		}
@@ -43,6 +43,37 @@ func TestTrackChatMessage(t *testing.T) {
 	assert.InDelta(t, expectedBalance, updatedUser.CreditsBalance, 0.0001)
 }
 
+func TestTrackChatTokens(t *testing.T) {
+	db := shared.SetupTestDB()
+	service := usage.NewUsageService(db)
+
+	user := models.User{
+		ID:             "user_tokens",
+		CreditsBalance: 10.0,
+		Tier:           configs.TierPro,
+	}
+	db.Create(&user)
+
+	// Track one answer with a long RAG prompt
+	err := service.TrackChatTokens(user.ID, "bot_1", "gpt-4o-mini", 3000, 200)
+	assert.NoError(t, err)
+
+	var record models.UsageRecord
+	err = db.First(&record, "user_id = ?", user.ID).Error
+	assert.NoError(t, err)
+	assert.Equal(t, models.UsageTypeChatTokens, record.Type)
+	assert.Equal(t, "gpt-4o-mini", record.Model)
+	assert.Equal(t, 3200.0, record.Quantity)
+
+	pricing := configs.GetChatModelPricing("gpt-4o-mini")
+	expectedCost := 3*pricing.PricePerInput1KTokens + 0.2*pricing.PricePerOutput1KTokens
+	assert.InDelta(t, expectedCost, record.Cost, 0.000001)
+
+	var updatedUser models.User
+	db.First(&updatedUser, "id = ?", user.ID)
+	assert.InDelta(t, 10.0-expectedCost, updatedUser.CreditsBalance, 0.000001)
+}
+
 func TestTrackEmbedding(t *testing.T) {
 	db := shared.SetupTestDB()
 	service := usage.NewUsageService(db)
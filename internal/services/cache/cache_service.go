@@ -127,6 +127,82 @@ func (c *CacheService) DeletePattern(ctx context.Context, pattern string) error
 	return nil
 }
 
+/*
+* Incr atomically increments a counter and returns its new value.
+ */
+func (c *CacheService) Incr(ctx context.Context, key string) (int64, error) {
+	if c == nil || !c.enabled {
+		return 0, nil
+	}
+	return c.client.Incr(ctx, key).Result()
+}
+
+/*
+* Append adds a value to the end of a list and refreshes the list's TTL.
+ */
+func (c *CacheService) Append(ctx context.Context, key string, value string, ttl time.Duration) error {
+	if c == nil || !c.enabled {
+		return nil
+	}
+	_, err := c.client.TxPipelined(ctx, func(pipe redis.Pipeliner) error {
+		pipe.RPush(ctx, key, value)
+		pipe.Expire(ctx, key, ttl)
+		return nil
+	})
+	return err
+}
+
+/*
+* Range returns the values of a list from index start to the end.
+ */
+func (c *CacheService) Range(ctx context.Context, key string, start int64) ([]string, error) {
+	if c == nil || !c.enabled {
+		return nil, nil
+	}
+	return c.client.LRange(ctx, key, start, -1).Result()
+}
+
+/*
+* TrimList keeps the newest keep values of a list and returns the values it removed.
+* Reading and trimming happen in one transaction, so concurrent callers never return the same value.
+ */
+func (c *CacheService) TrimList(ctx context.Context, key string, keep int64) ([]string, error) {
+	if c == nil || !c.enabled {
+		return nil, nil
+	}
+	var removed *redis.StringSliceCmd
+	_, err := c.client.TxPipelined(ctx, func(pipe redis.Pipeliner) error {
+		removed = pipe.LRange(ctx, key, 0, -keep-1)
+		pipe.LTrim(ctx, key, -keep, -1)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return removed.Val(), nil
+}
+
+/*
+* GetMany retrieves several string values at once.
+* Missing keys are returned as empty strings.
+ */
+func (c *CacheService) GetMany(ctx context.Context, keys []string) ([]string, error) {
+	if c == nil || !c.enabled || len(keys) == 0 {
+		return make([]string, len(keys)), nil
+	}
+	vals, err := c.client.MGet(ctx, keys...).Result()
+	if err != nil {
+		return nil, fmt.Errorf("cache mget error: %w", err)
+	}
+	values := make([]string, len(vals))
+	for i, val := range vals {
+		if s, ok := val.(string); ok {
+			values[i] = s
+		}
+	}
+	return values, nil
+}
+
 /*
 * Exists checks whether a key exists in cache.
  */
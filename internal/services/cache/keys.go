@@ -29,10 +29,31 @@ const (
 	// Format: sources:bot:{bot_id}
 	SourceListCacheKey = "sources:bot:%s"
 
-	// VectorSearchCacheKey caches vector search results.
+	// VectorSearchCacheKey caches the embedding of a search query.
 	// Format: vector:{bot_id}:{query_hash}
 	VectorSearchCacheKey = "vector:%s:%s"
 
+	// AnswerCacheKey lists the IDs of a bot's cached answers, oldest first.
+	// Deleting it invalidates every answer of the bot; the entries then expire unreachable.
+	// Format: answers:{bot_id}
+	AnswerCacheKey = "answers:%s"
+
+	// AnswerCacheEntryKey caches one answered question with its embedding.
+	// Format: answers:{bot_id}:entry:{entry_id}
+	AnswerCacheEntryKey = "answers:%s:entry:%s"
+
+	// AnswerCacheStatsKey counts answer cache lookups for a bot.
+	// Format: answers:stats:{bot_id}:{hits|misses}
+	AnswerCacheStatsKey = "answers:stats:%s:%s"
+
+	// StreamBufferKey buffers the SSE events of an in-flight answer so clients can resume.
+	// Format: stream:{session_id}:{message_id}
+	StreamBufferKey = "stream:%s:%s"
+
+	// StreamLatestKey points to the most recent answer stream of a session.
+	// Format: stream:{session_id}:latest
+	StreamLatestKey = "stream:%s:latest"
+
 	// SessionCacheKey caches an active chat session.
 	// Format: session:{session_id}
 	SessionCacheKey = "session:%s"
@@ -45,6 +66,8 @@ const (
 	UserCacheTTL         = 1 * time.Hour
 	SourceCacheTTL       = 30 * time.Minute
 	SourceListCacheTTL   = 15 * time.Minute
-	VectorSearchCacheTTL = 5 * time.Minute
+	VectorSearchCacheTTL = 24 * time.Hour
+	AnswerCacheTTL       = 24 * time.Hour
+	StreamBufferTTL      = 10 * time.Minute
 	SessionCacheTTL      = 24 * time.Hour
 )
@@ -0,0 +1,107 @@
+package chat
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/souravsspace/texly.chat/internal/models"
+	"github.com/souravsspace/texly.chat/internal/services/answercache"
+)
+
+/*
+ * SetAnswerCache enables replaying cached answers to repeated questions
+ */
+func (s *ChatService) SetAnswerCache(answerCache *answercache.AnswerCacheService) {
+	s.answerCache = answerCache
+}
+
+/*
+ * canCacheAnswer reports whether an answer may be served from or stored in the cache
+ * Follow-ups depend on the conversation and actions on live data, so only opening
+ * questions to bots without actions are cached
+ */
+func (s *ChatService) canCacheAnswer(history []models.Message, actions []models.BotAction) bool {
+	return s.answerCache.Enabled() && s.embeddingService != nil && s.searchService != nil &&
+		len(history) == 0 && len(actions) == 0
+}
+
+/*
+ * embedQuery embeds a question, reusing the embedding of an identical earlier question
+ */
+func (s *ChatService) embedQuery(ctx context.Context, botID string, query string) ([]float32, error) {
+	if embedding, ok := s.answerCache.GetQueryEmbedding(ctx, botID, query); ok {
+		return embedding, nil
+	}
+
+	embedding, _, err := s.embeddingService.GenerateEmbedding(ctx, query)
+	if err != nil {
+		return nil, fmt.Errorf("failed to generate query embedding: %w", err)
+	}
+	if err := s.answerCache.SetQueryEmbedding(ctx, botID, query, embedding); err != nil {
+		// Log error but continue with the fresh embedding
+		fmt.Printf("Warning: failed to cache query embedding: %v\n", err)
+	}
+	return embedding, nil
+}
+
+/*
+ * lookupAnswer returns a cached answer to a similar question, or nil
+ */
+func (s *ChatService) lookupAnswer(ctx context.Context, botID string, embedding []float32) *answercache.Entry {
+	entry, err := s.answerCache.Lookup(ctx, botID, embedding)
+	if err != nil {
+		// Log error and answer normally
+		fmt.Printf("Warning: failed to look up cached answer: %v\n", err)
+		return nil
+	}
+	return entry
+}
+
+/*
+ * storeAnswer caches an answer for similar future questions
+ */
+func (s *ChatService) storeAnswer(
+	ctx context.Context,
+	botID string,
+	question string,
+	embedding []float32,
+	answer string,
+	citations []models.Citation,
+) {
+	if strings.TrimSpace(answer) == "" {
+		return
+	}
+
+	err := s.answerCache.Store(ctx, botID, answercache.Entry{
+		Question:  question,
+		Embedding: embedding,
+		Answer:    answer,
+		Citations: citations,
+	})
+	if err != nil {
+		// Log error but don't fail the request
+		fmt.Printf("Warning: failed to cache answer: %v\n", err)
+	}
+}
+
+/*
+ * replayAnswer streams a cached answer to the client like a generated one
+ */
+func replayAnswer(ctx context.Context, eventChan chan<- models.ChatTokenResponse, entry *answercache.Entry) error {
+	if len(entry.Citations) > 0 {
+		if err := sendEvent(ctx, eventChan, models.ChatTokenResponse{Type: "sources", Sources: entry.Citations}); err != nil {
+			return err
+		}
+	}
+
+	for _, token := range strings.SplitAfter(entry.Answer, " ") {
+		if token == "" {
+			continue
+		}
+		if err := sendEvent(ctx, eventChan, models.ChatTokenResponse{Type: "token", Content: token}); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -2,14 +2,20 @@ package chat
 
 import (
 	"context"
+	"encoding/json"
 	"fmt"
 	"strings"
+	"sync"
 
-	"github.com/openai/openai-go/v3"
-	"github.com/openai/openai-go/v3/option"
+	"github.com/google/uuid"
+	"github.com/souravsspace/texly.chat/configs"
 	"github.com/souravsspace/texly.chat/internal/models"
 	messageRepo "github.com/souravsspace/texly.chat/internal/repo/message"
+	"github.com/souravsspace/texly.chat/internal/services/action"
+	"github.com/souravsspace/texly.chat/internal/services/answercache"
+	"github.com/souravsspace/texly.chat/internal/services/billing/usage"
 	"github.com/souravsspace/texly.chat/internal/services/embedding"
+	"github.com/souravsspace/texly.chat/internal/services/llm"
 	"github.com/souravsspace/texly.chat/internal/services/vector"
 	"github.com/tiktoken-go/tokenizer"
 )
@@ -21,12 +27,38 @@ type ChatService struct {
 	embeddingService *embedding.EmbeddingService
 	searchService    *vector.SearchService
 	messageRepo      *messageRepo.MessageRepository
-	chatModel        openai.ChatModel
+	chatModel        string
 	temperature      float64
 	maxContextChunks int
-	baseURL          string
-	apiKey           string // Store to re-init client
-	client           openai.Client
+	historyBudget    int    // Max tokens of prior conversation turns sent to the model
+	apiKey           string // Store to re-init the default provider
+	providers        *llm.Registry
+	actionService    *action.ActionService           // Optional: bot actions exposed as tools
+	rewriteModel     string                          // Small model for query rewriting and follow-up suggestions
+	usageService     *usage.UsageService             // Optional: bills bot owners for the tokens each answer used
+	answerCache      *answercache.AnswerCacheService // Optional: replays answers to repeated questions
+	summarizing      sync.Map                        // Session IDs with a summary in progress
+}
+
+/*
+ * maxToolRounds bounds how many times the model may call actions before it must answer
+ */
+const maxToolRounds = 3
+
+/*
+ * GenerationSettings are the effective per-request model and retrieval settings
+ */
+type GenerationSettings struct {
+	SystemPrompt      string
+	PromptVersionID   string // Prompt version recorded on the session's messages ("" = unversioned)
+	Model             string
+	Temperature       float64
+	MaxOutputTokens   int // 0 = provider default
+	MaxContextChunks  int
+	DistanceThreshold float64 // 0 = no threshold
+	Reranker          string  // Reorders retrieved chunks before the context is built ("" = retrieval order)
+	RetrievalMode     string  // "" = most similar chunks, "mmr" = relevant and diverse chunks
+	MMRLambda         float64 // MMR relevance/diversity balance (1 = relevance only)
 }
 
 /*
@@ -40,194 +72,754 @@ func NewChatService(
 	temperature float64,
 	maxContextChunks int,
 	apiKey string,
+	historyBudget int,
 ) *ChatService {
+	providers := llm.NewRegistry()
+	providers.Register(llm.DefaultProvider, llm.NewOpenAIProvider(apiKey, chatModel))
+
 	return &ChatService{
 		embeddingService: embeddingService,
 		searchService:    searchService,
 		messageRepo:      messageRepo,
-		chatModel:        openai.ChatModel(chatModel),
+		chatModel:        chatModel,
 		temperature:      temperature,
 		maxContextChunks: maxContextChunks,
+		historyBudget:    historyBudget,
 		apiKey:           apiKey,
-		client:           openai.NewClient(option.WithAPIKey(apiKey)),
+		providers:        providers,
 	}
 }
 
 /*
-* SetBaseURL sets a custom API base URL for the OpenAI client (useful for testing)
+* SetBaseURL points the default OpenAI provider at a custom API base URL (useful for testing)
  */
 func (s *ChatService) SetBaseURL(url string) {
-	s.baseURL = url
-	s.client = openai.NewClient(
-		option.WithAPIKey(s.apiKey),
-		option.WithBaseURL(url),
-	)
+	s.providers.Register(llm.DefaultProvider, llm.NewOpenAICompatibleProvider(llm.CompatibleConfig{
+		BaseURL:      url,
+		APIKey:       s.apiKey,
+		DefaultModel: s.chatModel,
+	}))
+}
+
+/*
+* RegisterProvider makes an additional chat provider available to bots under name
+ */
+func (s *ChatService) RegisterProvider(name string, provider llm.ChatProvider) {
+	s.providers.Register(name, provider)
+}
+
+/*
+* SetActionService enables owner-defined bot actions as model tools
+ */
+func (s *ChatService) SetActionService(actionService *action.ActionService) {
+	s.actionService = actionService
+}
+
+/*
+* SetUsageService enables token-based billing of answers to the bot owner
+ */
+func (s *ChatService) SetUsageService(usageService *usage.UsageService) {
+	s.usageService = usageService
 }
 
 /*
- * StreamChat performs RAG and streams LLM response via channels
- * Returns a token channel and error channel
+* Providers returns the names of all registered chat providers
+ */
+func (s *ChatService) Providers() []string {
+	return s.providers.Names()
+}
+
+/*
+ * isDefaultProvider reports whether a bot's provider name selects the default OpenAI provider
+ */
+func isDefaultProvider(name string) bool {
+	return name == "" || name == llm.DefaultProvider
+}
+
+/*
+ * ResolveSettings merges a bot's generation settings over the service defaults
+ * Values the owner's tier no longer permits (e.g. after a downgrade) fall back or are clamped
+ */
+func (s *ChatService) ResolveSettings(bot *models.Bot, limits configs.TierLimits) GenerationSettings {
+	settings := GenerationSettings{
+		SystemPrompt:      bot.SystemPrompt,
+		PromptVersionID:   bot.PromptVersionID,
+		Temperature:       s.temperature,
+		MaxContextChunks:  s.maxContextChunks,
+		MaxOutputTokens:   bot.MaxOutputTokens,
+		DistanceThreshold: bot.DistanceThreshold,
+		Reranker:          bot.Reranker,
+		RetrievalMode:     bot.RetrievalMode,
+		MMRLambda:         vector.DefaultMMRLambda,
+	}
+
+	// The service's chat model is an OpenAI model; other providers fall back to their own configured default
+	if isDefaultProvider(bot.Provider) {
+		settings.Model = s.chatModel
+	}
+	if bot.Model != "" && limits.AllowsChatModel(bot.Provider, bot.Model) {
+		settings.Model = bot.Model
+	}
+	if bot.Temperature != nil {
+		settings.Temperature = *bot.Temperature
+	}
+	if bot.MaxContextChunks > 0 {
+		settings.MaxContextChunks = bot.MaxContextChunks
+	}
+	if bot.MMRLambda != nil {
+		settings.MMRLambda = *bot.MMRLambda
+	}
+
+	// Clamp to the tier limits
+	if limits.MaxContextChunks != -1 && settings.MaxContextChunks > limits.MaxContextChunks {
+		settings.MaxContextChunks = limits.MaxContextChunks
+	}
+	if limits.MaxOutputTokens != -1 && (settings.MaxOutputTokens <= 0 || settings.MaxOutputTokens > limits.MaxOutputTokens) {
+		settings.MaxOutputTokens = limits.MaxOutputTokens
+	}
+
+	return settings
+}
+
+/*
+ * StreamChat performs RAG and streams LLM response via channels using the bot's chat provider
+ * Returns an event channel (sources, tokens) and error channel
  * Optionally saves messages to database if sessionID and userID are provided
  */
 func (s *ChatService) StreamChat(
 	ctx context.Context,
-	botID string,
-	systemPrompt string,
+	bot *models.Bot,
+	settings GenerationSettings,
+	userMessage string,
+	sessionID string,
+	userID *string,
+) (<-chan models.ChatTokenResponse, <-chan error) {
+	return s.streamChat(ctx, bot, settings, userMessage, sessionID, userID, nil)
+}
+
+/*
+ * StreamChatWithHistory runs the same RAG pipeline with a caller-supplied conversation
+ * Used by stateless API clients; nothing is persisted
+ */
+func (s *ChatService) StreamChatWithHistory(
+	ctx context.Context,
+	bot *models.Bot,
+	settings GenerationSettings,
+	history []models.Message,
+	userMessage string,
+) (<-chan models.ChatTokenResponse, <-chan error) {
+	return s.streamChat(ctx, bot, settings, userMessage, "", nil, trimHistory(history, s.historyBudget))
+}
+
+/*
+ * streamChat is the shared pipeline behind StreamChat and StreamChatWithHistory
+ * History is loaded from the session when not supplied
+ */
+func (s *ChatService) streamChat(
+	ctx context.Context,
+	bot *models.Bot,
+	settings GenerationSettings,
 	userMessage string,
 	sessionID string,
 	userID *string,
-) (<-chan string, <-chan error) {
-	tokenChan := make(chan string)
+	history []models.Message,
+) (<-chan models.ChatTokenResponse, <-chan error) {
+	eventChan := make(chan models.ChatTokenResponse)
 	errChan := make(chan error, 1)
 
 	go func() {
-		defer close(tokenChan)
+		defer close(eventChan)
 		defer close(errChan)
 
-		// Step 1: Save user message to database
+		botID := bot.ID
+
+		// Step 0: Resolve the bot's chat provider
+		provider, err := s.providers.Get(bot.Provider)
+		if err != nil {
+			errChan <- err
+			return
+		}
+		// Helper calls (rewrite, reranking, summaries, suggestions) are billed like the answer
+		helper := s.billedProvider(provider, bot)
+
+		// Step 1: Load prior conversation turns and the summary of older ones (before the new message is saved)
+		var summary *models.SessionSummary
+		if history == nil {
+			summary, history = s.loadHistory(ctx, sessionID)
+		}
+
+		// Step 2: Rewrite follow-ups into a standalone search query (if enabled for the bot)
+		searchQuery := userMessage
+		rewrittenQuery := s.rewriteQuery(ctx, helper, bot, settings, history, userMessage)
+		if rewrittenQuery != "" {
+			searchQuery = rewrittenQuery
+		}
+
+		// Step 3: Save user message to database
+		var userMsg *models.Message
 		if s.messageRepo != nil && sessionID != "" {
-			userMsg := &models.Message{
-				SessionID:  sessionID,
-				BotID:      botID,
-				UserID:     userID,
-				Role:       "user",
-				Content:    userMessage,
-				TokenCount: countTokens(userMessage),
+			userMsg = &models.Message{
+				SessionID:       sessionID,
+				BotID:           botID,
+				UserID:          userID,
+				Role:            "user",
+				Content:         userMessage,
+				TokenCount:      countTokens(userMessage),
+				RewrittenQuery:  rewrittenQuery,
+				PromptVersionID: settings.PromptVersionID,
 			}
 			if err := s.messageRepo.Create(ctx, userMsg); err != nil {
 				// Log error but don't fail the request
 				fmt.Printf("Warning: failed to save user message: %v\n", err)
+				userMsg = nil
+			}
+		}
+
+		// Announce the ID the answer will be stored under, so clients can rate it
+		var assistantMsgID string
+		if s.messageRepo != nil && sessionID != "" {
+			assistantMsgID = uuid.New().String()
+			if err := sendEvent(ctx, eventChan, models.ChatTokenResponse{Type: "start", MessageID: assistantMsgID}); err != nil {
+				errChan <- err
+				return
+			}
+		}
+
+		// Step 4: Replay the cached answer to a similar opening question
+		actions := s.loadActions(ctx, botID)
+		var queryEmbedding []float32
+		// Experiment variants don't share the cache of the bot's current prompt
+		if settings.SystemPrompt == bot.SystemPrompt && s.canCacheAnswer(history, actions) {
+			queryEmbedding, err = s.embedQuery(ctx, botID, searchQuery)
+			if err != nil {
+				errChan <- err
+				return
+			}
+			if entry := s.lookupAnswer(ctx, botID, queryEmbedding); entry != nil {
+				if err := replayAnswer(ctx, eventChan, entry); err != nil {
+					errChan <- err
+					return
+				}
+				s.saveAssistantMessage(ctx, assistantMsgID, sessionID, botID, userID, settings.PromptVersionID, entry.Answer, entry.Citations, nil, nil)
+				return
 			}
 		}
 
-		// Step 2: Perform RAG - retrieve relevant context
-		contextChunks, err := s.searchService.SearchSimilar(ctx, userMessage, botID, s.maxContextChunks)
+		// Step 5: Perform RAG - retrieve relevant context in the bot's retrieval mode, reranking a deeper pool of candidates if enabled
+		contextChunks, err := s.retrieveContext(ctx, helper, bot, settings, searchQuery, queryEmbedding)
 		if err != nil {
-			errChan <- fmt.Errorf("failed to search context: %w", err)
+			errChan <- err
+			return
+		}
+
+		// Step 6: Reply with the bot's fallback instead of guessing when nothing relevant was found
+		if reply, ok := fallbackReply(bot, contextChunks, s.searchService != nil); ok {
+			if err := sendFallback(ctx, eventChan, bot.FallbackMode, reply); err != nil {
+				errChan <- err
+				return
+			}
+			s.markUnanswered(ctx, userMsg)
+			s.saveAssistantMessage(ctx, assistantMsgID, sessionID, botID, userID, settings.PromptVersionID, reply, nil, nil, nil)
 			return
 		}
 
-		// Step 3: Build messages with context
-		messages := s.buildMessages(systemPrompt, contextChunks, userMessage)
+		// Step 7: Keep the chunks that fit in the model's context window
+		contextChunks, breakdown := fitContext(settings, settings.SystemPrompt, summary, history, userMessage, contextChunks)
 
-		// Step 4: Stream from OpenAI and collect response
+		// Step 8: Tell the client which documents the answer is grounded in
+		citations := buildCitations(contextChunks)
+		if len(citations) > 0 {
+			if err := sendEvent(ctx, eventChan, models.ChatTokenResponse{Type: "sources", Sources: citations}); err != nil {
+				errChan <- err
+				return
+			}
+		}
+
+		// Step 9: Build messages with context, the conversation summary and recent history
+		messages := s.buildMessages(settings.SystemPrompt, contextChunks, summary, history, userMessage)
+
+		// Step 10: Stream from the provider, running any bot actions the model calls
 		var fullResponse strings.Builder
-		if err := s.streamFromOpenAI(ctx, messages, tokenChan, &fullResponse); err != nil {
+		tokenUsage, err := s.runCompletion(ctx, provider, settings, messages, actions, eventChan, &fullResponse)
+		s.trackUsage(bot, settings.Model, tokenUsage)
+		if err != nil {
+			errChan <- err
+			return
+		}
+		if err := sendEvent(ctx, eventChan, models.ChatTokenResponse{Type: "usage", Usage: &models.TokenUsage{
+			PromptTokens:     tokenUsage.PromptTokens,
+			CompletionTokens: tokenUsage.CompletionTokens,
+			TotalTokens:      tokenUsage.PromptTokens + tokenUsage.CompletionTokens,
+		}}); err != nil {
 			errChan <- err
 			return
 		}
 
-		// Step 5: Save assistant message to database
-		if s.messageRepo != nil && sessionID != "" {
-			assistantMsg := &models.Message{
-				SessionID:  sessionID,
-				BotID:      botID,
-				UserID:     userID,
-				Role:       "assistant",
-				Content:    fullResponse.String(),
-				TokenCount: countTokens(fullResponse.String()),
-			}
-			if err := s.messageRepo.Create(ctx, assistantMsg); err != nil {
-				// Log error but don't fail the request
-				fmt.Printf("Warning: failed to save assistant message: %v\n", err)
+		// Step 11: Save assistant message to database
+		breakdown.Answer = countTokens(fullResponse.String())
+		s.saveAssistantMessage(ctx, assistantMsgID, sessionID, botID, userID, settings.PromptVersionID, fullResponse.String(), citations, &breakdown, &tokenUsage)
+
+		// Step 12: Condense older turns in the background once the conversation outgrows the history budget
+		go s.summarizeSession(context.WithoutCancel(ctx), helper, bot, settings, sessionID)
+
+		// Step 13: Offer follow-up questions the knowledge base can answer
+		if suggestions := s.suggestFollowUps(ctx, helper, bot, settings, contextChunks, userMessage, fullResponse.String()); len(suggestions) > 0 {
+			if err := sendEvent(ctx, eventChan, models.ChatTokenResponse{Type: "suggestions", Suggestions: suggestions}); err != nil {
+				errChan <- err
+				return
 			}
 		}
+
+		// Step 14: Remember the answer for similar questions
+		if queryEmbedding != nil {
+			s.storeAnswer(ctx, botID, userMessage, queryEmbedding, fullResponse.String(), citations)
+		}
 	}()
 
-	return tokenChan, errChan
+	return eventChan, errChan
 }
 
 /*
- * buildMessages constructs the complete message array with system, context, and user message
+ * saveAssistantMessage persists the bot's reply when the conversation belongs to a session
+ */
+func (s *ChatService) saveAssistantMessage(
+	ctx context.Context,
+	messageID string,
+	sessionID string,
+	botID string,
+	userID *string,
+	promptVersionID string,
+	content string,
+	citations []models.Citation,
+	breakdown *models.TokenBreakdown,
+	tokenUsage *llm.Usage,
+) {
+	if s.messageRepo == nil || sessionID == "" {
+		return
+	}
+
+	assistantMsg := &models.Message{
+		ID:              messageID,
+		SessionID:       sessionID,
+		BotID:           botID,
+		UserID:          userID,
+		Role:            "assistant",
+		Content:         content,
+		TokenCount:      countTokens(content),
+		PromptVersionID: promptVersionID,
+	}
+	if len(citations) > 0 {
+		if citationsJSON, err := json.Marshal(citations); err == nil {
+			assistantMsg.Citations = string(citationsJSON)
+		}
+	}
+	if tokenUsage != nil {
+		assistantMsg.PromptTokens = tokenUsage.PromptTokens
+		assistantMsg.CompletionTokens = tokenUsage.CompletionTokens
+	}
+	if breakdown != nil {
+		if breakdownJSON, err := json.Marshal(breakdown); err == nil {
+			assistantMsg.TokenBreakdown = string(breakdownJSON)
+		}
+	}
+	if err := s.messageRepo.Create(ctx, assistantMsg); err != nil {
+		// Log error but don't fail the request
+		fmt.Printf("Warning: failed to save assistant message: %v\n", err)
+	}
+}
+
+/*
+ * markUnanswered records that the user's question could not be answered from the knowledge base
+ */
+func (s *ChatService) markUnanswered(ctx context.Context, userMsg *models.Message) {
+	if s.messageRepo == nil || userMsg == nil {
+		return
+	}
+	if err := s.messageRepo.MarkUnanswered(ctx, userMsg.ID); err != nil {
+		// Log error but don't fail the request
+		fmt.Printf("Warning: failed to mark question unanswered: %v\n", err)
+	}
+}
+
+/*
+ * buildCitations converts retrieved chunks into citations for the client and message log
+ */
+func buildCitations(contextChunks []vector.SearchResult) []models.Citation {
+	citations := make([]models.Citation, 0, len(contextChunks))
+	for _, chunk := range contextChunks {
+		citations = append(citations, models.Citation{
+			ChunkID:  chunk.ChunkID,
+			SourceID: chunk.SourceID,
+			URL:      chunk.URL,
+			Filename: chunk.OriginalFilename,
+			Distance: chunk.Distance,
+		})
+	}
+	return citations
+}
+
+/*
+ * filterByDistance drops chunks farther than threshold from the query (0 = keep all)
+ * Full-text matches are kept: an exact term is relevant even when the embeddings disagree
+ * Chunks without a distance cannot be measured, so only full-text matches among them are kept
+ */
+func filterByDistance(contextChunks []vector.SearchResult, threshold float64) []vector.SearchResult {
+	if threshold <= 0 {
+		return contextChunks
+	}
+
+	filtered := make([]vector.SearchResult, 0, len(contextChunks))
+	for _, chunk := range contextChunks {
+		switch {
+		case chunk.KeywordRank > 0:
+			filtered = append(filtered, chunk)
+		case chunk.Distance != nil && float64(*chunk.Distance) <= threshold:
+			filtered = append(filtered, chunk)
+		}
+	}
+	return filtered
+}
+
+/*
+ * sourceLabel returns a human-readable reference for a chunk's source
+ */
+func sourceLabel(chunk vector.SearchResult) string {
+	if chunk.URL != "" {
+		return chunk.URL
+	}
+	return chunk.OriginalFilename
+}
+
+/*
+ * trimHistory keeps the most recent messages whose combined token count fits within budget
+ * Messages are returned in chronological order
+ */
+func trimHistory(messages []models.Message, budget int) []models.Message {
+	used := 0
+	start := len(messages)
+
+	// Walk backwards from the newest message until the budget is exhausted
+	for i := len(messages) - 1; i >= 0; i-- {
+		tokens := messages[i].TokenCount
+		if tokens == 0 {
+			tokens = countTokens(messages[i].Content)
+		}
+		if used+tokens > budget {
+			break
+		}
+		used += tokens
+		start = i
+	}
+
+	// Never open the history with a dangling assistant reply
+	for start < len(messages) && messages[start].Role != "user" {
+		start++
+	}
+
+	return messages[start:]
+}
+
+/*
+ * buildMessages constructs the complete message array with system, context, summary, history, and user message
  */
 func (s *ChatService) buildMessages(
 	systemPrompt string,
 	contextChunks []vector.SearchResult,
+	summary *models.SessionSummary,
+	history []models.Message,
 	userMessage string,
-) []openai.ChatCompletionMessageParamUnion {
-	messages := []openai.ChatCompletionMessageParamUnion{}
+) []llm.Message {
+	messages := []llm.Message{}
 
 	// System message
 	if systemPrompt != "" {
-		messages = append(messages, openai.SystemMessage(systemPrompt))
+		messages = append(messages, llm.Message{Role: llm.RoleSystem, Content: systemPrompt})
 	}
 
 	// Add context from RAG if available
 	if len(contextChunks) > 0 {
 		var contextBuilder strings.Builder
-		contextBuilder.WriteString("Here is relevant information from the knowledge base:\n\n")
+		contextBuilder.WriteString(knowledgeHeader)
 
 		for i, chunk := range contextChunks {
-			contextBuilder.WriteString(fmt.Sprintf("--- Context %d ---\n", i+1))
-			contextBuilder.WriteString(chunk.Content)
-			contextBuilder.WriteString(fmt.Sprintf("\nSource: %s\n\n", chunk.URL))
+			contextBuilder.WriteString(formatChunk(i, chunk))
 		}
 
-		contextBuilder.WriteString("Please use this information to answer the user's question accurately.")
+		contextBuilder.WriteString(knowledgeFooter)
 
-		messages = append(messages, openai.SystemMessage(contextBuilder.String()))
+		messages = append(messages, llm.Message{Role: llm.RoleSystem, Content: contextBuilder.String()})
+	}
+
+	// Older turns condensed into the session summary
+	if summary != nil && summary.Content != "" {
+		messages = append(messages, llm.Message{Role: llm.RoleSystem, Content: summaryHeader + summary.Content})
+	}
+
+	// Prior conversation turns
+	for _, msg := range history {
+		if msg.Content == "" {
+			continue
+		}
+		switch msg.Role {
+		case "user":
+			messages = append(messages, llm.Message{Role: llm.RoleUser, Content: msg.Content})
+		case "assistant", "operator":
+			// Human operator replies are part of the support side of the conversation
+			messages = append(messages, llm.Message{Role: llm.RoleAssistant, Content: msg.Content})
+		}
 	}
 
 	// User message
-	messages = append(messages, openai.UserMessage(userMessage))
+	messages = append(messages, llm.Message{Role: llm.RoleUser, Content: userMessage})
 
 	return messages
 }
 
 /*
- * streamFromOpenAI makes streaming request to OpenAI Chat Completion API using official SDK
- * Also collects the full response in responseBuilder for persistence
+ * loadActions fetches the bot's actions, or nil when actions are disabled or unavailable
  */
-func (s *ChatService) streamFromOpenAI(
-	ctx context.Context,
-	messages []openai.ChatCompletionMessageParamUnion,
-	tokenChan chan<- string,
-	responseBuilder *strings.Builder,
-) error {
-	// Create streaming chat completion params
-	params := openai.ChatCompletionNewParams{
-		Messages: messages,
-		Model:    s.chatModel,
+func (s *ChatService) loadActions(ctx context.Context, botID string) []models.BotAction {
+	if s.actionService == nil {
+		return nil
 	}
 
-	// Only set temperature if it's not the default (1.0)
-	// Some models (like o1) don't support custom temperature
-	if s.temperature != 1.0 {
-		params.Temperature = openai.Float(s.temperature)
+	actions, err := s.actionService.ListForBot(ctx, botID)
+	if err != nil {
+		// Log error but answer without actions
+		fmt.Printf("Warning: failed to load bot actions: %v\n", err)
+		return nil
 	}
+	return actions
+}
 
-	stream := s.client.Chat.Completions.NewStreaming(ctx, params)
+/*
+ * trackUsage bills the bot owner for the tokens an answer used
+ */
+func (s *ChatService) trackUsage(bot *models.Bot, model string, tokenUsage llm.Usage) {
+	if s.usageService == nil || tokenUsage.PromptTokens+tokenUsage.CompletionTokens == 0 {
+		return
+	}
+	if err := s.usageService.TrackChatTokens(bot.UserID, bot.ID, model, tokenUsage.PromptTokens, tokenUsage.CompletionTokens); err != nil {
+		// Log error but don't fail the request
+		fmt.Printf("Warning: failed to track chat usage: %v\n", err)
+	}
+}
 
-	// Process the stream
-	for stream.Next() {
-		chunk := stream.Current()
+/*
+ * trackHelperUsage bills the bot owner for a supporting completion without counting it as a message
+ */
+func (s *ChatService) trackHelperUsage(bot *models.Bot, model string, tokenUsage llm.Usage) {
+	if s.usageService == nil || tokenUsage.PromptTokens+tokenUsage.CompletionTokens == 0 {
+		return
+	}
+	if err := s.usageService.TrackHelperTokens(bot.UserID, bot.ID, model, tokenUsage.PromptTokens, tokenUsage.CompletionTokens); err != nil {
+		// Log error but don't fail the request
+		fmt.Printf("Warning: failed to track helper usage: %v\n", err)
+	}
+}
 
-		// Extract content delta from the first choice
-		if len(chunk.Choices) > 0 && chunk.Choices[0].Delta.Content != "" {
-			token := chunk.Choices[0].Delta.Content
+/*
+ * billedProvider wraps a provider so the bot owner is billed for every completion it runs
+ * Completions are recorded as helper usage, which does not count towards the message limit
+ * Returns the provider unchanged when usage is not tracked
+ */
+func (s *ChatService) billedProvider(provider llm.ChatProvider, bot *models.Bot) llm.ChatProvider {
+	if s.usageService == nil {
+		return provider
+	}
+	return &meteredProvider{ChatProvider: provider, service: s, bot: bot}
+}
 
-			// Collect full response
-			if responseBuilder != nil {
-				responseBuilder.WriteString(token)
-			}
+/*
+ * meteredProvider tracks the token usage of each completion of the wrapped provider
+ */
+type meteredProvider struct {
+	llm.ChatProvider
+	service *ChatService
+	bot     *models.Bot
+}
+
+func (p *meteredProvider) StreamCompletion(
+	ctx context.Context,
+	req llm.CompletionRequest,
+	onToken func(token string) error,
+) (*llm.Completion, error) {
+	completion, err := p.ChatProvider.StreamCompletion(ctx, req, onToken)
+	if err != nil {
+		return nil, err
+	}
+	p.service.trackHelperUsage(p.bot, req.Model, completionUsage(req.Messages, completion))
+	return completion, nil
+}
 
-			// Stream token to channel
-			select {
-			case tokenChan <- token:
-			case <-ctx.Done():
-				return ctx.Err()
+/*
+ * completionUsage returns the usage reported by the provider
+ * Providers that do not report usage are estimated with the local tokenizer
+ */
+func completionUsage(messages []llm.Message, completion *llm.Completion) llm.Usage {
+	if completion.Usage != nil {
+		return *completion.Usage
+	}
+
+	estimate := llm.Usage{CompletionTokens: countTokens(completion.Content)}
+	for _, msg := range messages {
+		estimate.PromptTokens += countTokens(msg.Content) + messageOverheadTokens
+	}
+	for _, call := range completion.ToolCalls {
+		estimate.CompletionTokens += countTokens(call.Name) + countTokens(call.Arguments)
+	}
+	return estimate
+}
+
+/*
+ * runCompletion streams the answer, executing requested actions and feeding their
+ * results back to the model until it produces a final answer
+ * Returns the tokens used across all rounds, including rounds before a failure
+ */
+func (s *ChatService) runCompletion(
+	ctx context.Context,
+	provider llm.ChatProvider,
+	settings GenerationSettings,
+	messages []llm.Message,
+	actions []models.BotAction,
+	eventChan chan<- models.ChatTokenResponse,
+	responseBuilder *strings.Builder,
+) (llm.Usage, error) {
+	tools := action.Tools(actions)
+
+	var total llm.Usage
+	for round := 0; ; round++ {
+		// Withhold tools on the last round so the model has to answer
+		roundTools := tools
+		if round >= maxToolRounds {
+			roundTools = nil
+		}
+
+		completion, err := s.streamCompletion(ctx, provider, settings, messages, roundTools, eventChan, responseBuilder)
+		if err != nil {
+			return total, err
+		}
+		total.Add(completionUsage(messages, completion))
+		if len(completion.ToolCalls) == 0 {
+			return total, nil
+		}
+
+		messages = append(messages, llm.Message{
+			Role:      llm.RoleAssistant,
+			Content:   completion.Content,
+			ToolCalls: completion.ToolCalls,
+		})
+		for _, call := range completion.ToolCalls {
+			result, err := s.runAction(ctx, actions, call, eventChan)
+			if err != nil {
+				return total, err
 			}
+			messages = append(messages, llm.Message{
+				Role:       llm.RoleTool,
+				Content:    result,
+				ToolCallID: call.ID,
+			})
 		}
 	}
+}
+
+/*
+ * runAction executes a single tool call and reports its progress to the client
+ * Action failures are returned to the model as the tool result; only stream errors are returned
+ */
+func (s *ChatService) runAction(
+	ctx context.Context,
+	actions []models.BotAction,
+	call llm.ToolCall,
+	eventChan chan<- models.ChatTokenResponse,
+) (string, error) {
+	progress := &models.ToolCall{ID: call.ID, Name: call.Name, Status: "running"}
+	if err := sendEvent(ctx, eventChan, models.ChatTokenResponse{Type: "tool_call", ToolCall: progress}); err != nil {
+		return "", err
+	}
+
+	var result string
+	var execErr error
+	if botAction := findAction(actions, call.Name); botAction == nil {
+		execErr = fmt.Errorf("unknown action %q", call.Name)
+	} else {
+		result, execErr = s.actionService.Execute(ctx, botAction, call.Arguments)
+	}
 
-	// Check for streaming errors
-	if err := stream.Err(); err != nil {
-		return fmt.Errorf("streaming error: %w", err)
+	done := &models.ToolCall{ID: call.ID, Name: call.Name, Status: "completed"}
+	if execErr != nil {
+		done.Status = "failed"
+		result = "Error: " + execErr.Error()
+	}
+	if err := sendEvent(ctx, eventChan, models.ChatTokenResponse{Type: "tool_call", ToolCall: done}); err != nil {
+		return "", err
 	}
 
+	return result, nil
+}
+
+/*
+ * findAction returns the action with the given tool name
+ */
+func findAction(actions []models.BotAction, name string) *models.BotAction {
+	for i := range actions {
+		if actions[i].Name == name {
+			return &actions[i]
+		}
+	}
 	return nil
 }
 
+/*
+ * streamCompletion streams one chat completion from the provider, forwarding tokens as events
+ * Also collects the streamed content in responseBuilder for persistence
+ */
+func (s *ChatService) streamCompletion(
+	ctx context.Context,
+	provider llm.ChatProvider,
+	settings GenerationSettings,
+	messages []llm.Message,
+	tools []llm.Tool,
+	eventChan chan<- models.ChatTokenResponse,
+	responseBuilder *strings.Builder,
+) (*llm.Completion, error) {
+	req := llm.CompletionRequest{
+		Model:     settings.Model,
+		Messages:  messages,
+		Tools:     tools,
+		MaxTokens: settings.MaxOutputTokens,
+	}
+
+	// Only set temperature if it's not the default (1.0)
+	// Some models (like o1) don't support custom temperature
+	if settings.Temperature != 1.0 {
+		temperature := settings.Temperature
+		req.Temperature = &temperature
+	}
+
+	return provider.StreamCompletion(ctx, req, func(token string) error {
+		// Collect full response
+		if responseBuilder != nil {
+			responseBuilder.WriteString(token)
+		}
+
+		// Stream token to channel
+		return sendEvent(ctx, eventChan, models.ChatTokenResponse{Type: "token", Content: token})
+	})
+}
+
+/*
+ * sendEvent delivers an event to the client unless the request was cancelled
+ */
+func sendEvent(ctx context.Context, eventChan chan<- models.ChatTokenResponse, event models.ChatTokenResponse) error {
+	select {
+	case eventChan <- event:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
 /*
  * countTokens estimates token count for a string
  * Uses a simple approximation: 1 token ≈ 4 characters
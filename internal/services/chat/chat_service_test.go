@@ -2,11 +2,29 @@ package chat
 
 import (
 	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
 	"testing"
+	"time"
 
+	"github.com/alicebob/miniredis/v2"
+	"github.com/redis/go-redis/v9"
+	"github.com/souravsspace/texly.chat/configs"
+	"github.com/souravsspace/texly.chat/internal/models"
+	messageRepo "github.com/souravsspace/texly.chat/internal/repo/message"
+	"github.com/souravsspace/texly.chat/internal/services/action"
+	"github.com/souravsspace/texly.chat/internal/services/answercache"
+	"github.com/souravsspace/texly.chat/internal/services/billing/usage"
+	"github.com/souravsspace/texly.chat/internal/services/cache"
+	"github.com/souravsspace/texly.chat/internal/services/embedding"
+	"github.com/souravsspace/texly.chat/internal/services/llm"
 	"github.com/souravsspace/texly.chat/internal/services/vector"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
 )
 
 /*
@@ -21,24 +39,28 @@ func TestNewChatService(t *testing.T) {
 		0.7,
 		5,
 		"test-api-key",
+		2000,
 	)
 
 	assert.NotNil(t, service)
-	assert.NotNil(t, service.client)
-	assert.Equal(t, "gpt-4o-mini", string(service.chatModel))
+	assert.Equal(t, []string{llm.DefaultProvider}, service.Providers())
+	assert.Equal(t, "gpt-4o-mini", service.chatModel)
 	assert.Equal(t, 0.7, service.temperature)
 	assert.Equal(t, 5, service.maxContextChunks)
+	assert.Equal(t, 2000, service.historyBudget)
 }
 
 /*
  * Test buildMessages with no context
  */
 func TestBuildMessages_NoContext(t *testing.T) {
-	service := NewChatService(nil, nil, nil, "gpt-4o-mini", 0.7, 5, "test-key")
+	service := NewChatService(nil, nil, nil, "gpt-4o-mini", 0.7, 5, "test-key", 2000)
 
 	messages := service.buildMessages(
 		"You are a helpful assistant",
 		[]vector.SearchResult{},
+		nil,
+		nil,
 		"Hello, how are you?",
 	)
 
@@ -50,21 +72,21 @@ func TestBuildMessages_NoContext(t *testing.T) {
  * Test buildMessages with context
  */
 func TestBuildMessages_WithContext(t *testing.T) {
-	service := NewChatService(nil, nil, nil, "gpt-4o-mini", 0.7, 5, "test-key")
+	service := NewChatService(nil, nil, nil, "gpt-4o-mini", 0.7, 5, "test-key", 2000)
 
 	contextChunks := []vector.SearchResult{
 		{
 			ChunkID:    "chunk1",
 			Content:    "This is relevant context",
 			URL:        "https://example.com/doc1",
-			Distance:   0.1,
+			Distance:   distance(0.1),
 			ChunkIndex: 0,
 		},
 		{
 			ChunkID:    "chunk2",
 			Content:    "More relevant information",
 			URL:        "https://example.com/doc2",
-			Distance:   0.2,
+			Distance:   distance(0.2),
 			ChunkIndex: 1,
 		},
 	}
@@ -72,6 +94,8 @@ func TestBuildMessages_WithContext(t *testing.T) {
 	messages := service.buildMessages(
 		"You are a helpful assistant",
 		contextChunks,
+		nil,
+		nil,
 		"Tell me about the docs",
 	)
 
@@ -83,11 +107,13 @@ func TestBuildMessages_WithContext(t *testing.T) {
  * Test buildMessages without system prompt
  */
 func TestBuildMessages_NoSystemPrompt(t *testing.T) {
-	service := NewChatService(nil, nil, nil, "gpt-4o-mini", 0.7, 5, "test-key")
+	service := NewChatService(nil, nil, nil, "gpt-4o-mini", 0.7, 5, "test-key", 2000)
 
 	messages := service.buildMessages(
 		"", // No system prompt
 		[]vector.SearchResult{},
+		nil,
+		nil,
 		"Hello!",
 	)
 
@@ -103,15 +129,16 @@ func TestStreamChat_ContextCancellation(t *testing.T) {
 	// This is an integration test that requires actual API access
 	t.Skip("Skipping integration test - requires OpenAI API key")
 
-	service := NewChatService(nil, nil, nil, "gpt-4o-mini", 0.7, 5, "test-key")
+	service := NewChatService(nil, nil, nil, "gpt-4o-mini", 0.7, 5, "test-key", 2000)
 
 	ctx, cancel := context.WithCancel(context.Background())
 	cancel() // Cancel immediately
 
+	bot := &models.Bot{ID: "bot-123", SystemPrompt: "You are helpful"}
 	tokenChan, errChan := service.StreamChat(
 		ctx,
-		"bot-123",
-		"You are helpful",
+		bot,
+		service.ResolveSettings(bot, configs.GetTierLimits(configs.TierFree)),
 		"Hello",
 		"session-123",
 		nil,
@@ -130,7 +157,7 @@ func TestStreamChat_ContextCancellation(t *testing.T) {
  * Test buildMessages formatting with multiple context chunks
  */
 func TestBuildMessages_ContextFormatting(t *testing.T) {
-	service := NewChatService(nil, nil, nil, "gpt-4o-mini", 0.7, 5, "test-key")
+	service := NewChatService(nil, nil, nil, "gpt-4o-mini", 0.7, 5, "test-key", 2000)
 
 	contextChunks := []vector.SearchResult{
 		{
@@ -150,6 +177,8 @@ func TestBuildMessages_ContextFormatting(t *testing.T) {
 	messages := service.buildMessages(
 		"System prompt",
 		contextChunks,
+		nil,
+		nil,
 		"User question",
 	)
 
@@ -181,6 +210,7 @@ func TestChatService_TemperatureConfiguration(t *testing.T) {
 				tc.temperature,
 				5,
 				"test-key",
+				2000,
 			)
 
 			assert.Equal(t, tc.temperature, service.temperature)
@@ -212,9 +242,874 @@ func TestChatService_MaxContextChunks(t *testing.T) {
 				0.7,
 				tc.maxContextChunks,
 				"test-key",
+				2000,
 			)
 
 			assert.Equal(t, tc.maxContextChunks, service.maxContextChunks)
 		})
 	}
 }
+
+/*
+ * Test buildMessages includes prior conversation turns before the new message
+ */
+func TestBuildMessages_WithHistory(t *testing.T) {
+	service := NewChatService(nil, nil, nil, "gpt-4o-mini", 0.7, 5, "test-key", 2000)
+
+	history := []models.Message{
+		{Role: "user", Content: "What plans do you offer?"},
+		{Role: "assistant", Content: "We offer Free and Pro plans."},
+	}
+
+	messages := service.buildMessages(
+		"System prompt",
+		[]vector.SearchResult{},
+		nil,
+		history,
+		"And how much does that cost?",
+	)
+
+	// system + 2 history turns + user = 4 messages
+	require.Len(t, messages, 4)
+	assert.Equal(t, llm.RoleUser, messages[1].Role)
+	assert.Equal(t, llm.RoleAssistant, messages[2].Role)
+	assert.Equal(t, llm.RoleUser, messages[3].Role)
+}
+
+/*
+ * Test trimHistory keeps the newest turns that fit the token budget
+ */
+func TestTrimHistory_Budget(t *testing.T) {
+	history := []models.Message{
+		{Role: "user", Content: "first question", TokenCount: 50},
+		{Role: "assistant", Content: "first answer", TokenCount: 50},
+		{Role: "user", Content: "second question", TokenCount: 30},
+		{Role: "assistant", Content: "second answer", TokenCount: 30},
+	}
+
+	// Everything fits
+	assert.Len(t, trimHistory(history, 1000), 4)
+
+	// Only the latest exchange fits
+	trimmed := trimHistory(history, 100)
+	require.Len(t, trimmed, 2)
+	assert.Equal(t, "second question", trimmed[0].Content)
+
+	// Nothing fits
+	assert.Empty(t, trimHistory(history, 10))
+}
+
+/*
+ * Test trimHistory never starts with an assistant reply
+ */
+func TestTrimHistory_DropsLeadingAssistant(t *testing.T) {
+	history := []models.Message{
+		{Role: "user", Content: "question", TokenCount: 50},
+		{Role: "assistant", Content: "answer", TokenCount: 20},
+		{Role: "user", Content: "follow-up", TokenCount: 20},
+		{Role: "assistant", Content: "follow-up answer", TokenCount: 20},
+	}
+
+	// Budget admits the last three messages, but the leading assistant reply is dropped
+	trimmed := trimHistory(history, 60)
+	require.Len(t, trimmed, 2)
+	assert.Equal(t, "user", trimmed[0].Role)
+	assert.Equal(t, "follow-up", trimmed[0].Content)
+}
+
+/*
+ * Test buildCitations maps search results to citations
+ */
+func TestBuildCitations(t *testing.T) {
+	contextChunks := []vector.SearchResult{
+		{ChunkID: "chunk1", SourceID: "source1", URL: "https://example.com/pricing", Distance: distance(0.12)},
+		{ChunkID: "chunk2", SourceID: "source2", OriginalFilename: "handbook.pdf", Distance: distance(0.3)},
+	}
+
+	citations := buildCitations(contextChunks)
+
+	require.Len(t, citations, 2)
+	assert.Equal(t, "chunk1", citations[0].ChunkID)
+	assert.Equal(t, "source1", citations[0].SourceID)
+	assert.Equal(t, "https://example.com/pricing", citations[0].URL)
+	assert.Equal(t, distance(0.12), citations[0].Distance)
+	assert.Equal(t, "handbook.pdf", citations[1].Filename)
+	assert.Empty(t, citations[1].URL)
+
+	assert.Empty(t, buildCitations(nil))
+}
+
+/*
+ * Test sourceLabel falls back to the original filename for file sources
+ */
+func TestSourceLabel(t *testing.T) {
+	assert.Equal(t, "https://example.com", sourceLabel(vector.SearchResult{URL: "https://example.com", OriginalFilename: "ignored.pdf"}))
+	assert.Equal(t, "notes.txt", sourceLabel(vector.SearchResult{OriginalFilename: "notes.txt"}))
+}
+
+/*
+ * Test StreamChat streams tokens from the bot's selected provider
+ */
+func TestStreamChat_UsesBotProvider(t *testing.T) {
+	service := NewChatService(nil, nil, nil, "gpt-4o-mini", 0.7, 5, "test-key", 2000)
+	fake := llm.NewFakeProvider("Hello from the fake provider")
+	service.RegisterProvider("fake", fake)
+
+	bot := &models.Bot{ID: "bot-123", SystemPrompt: "You are helpful", Provider: "fake"}
+	eventChan, errChan := service.StreamChat(
+		context.Background(),
+		bot,
+		service.ResolveSettings(bot, configs.GetTierLimits(configs.TierEnterprise)),
+		"Hi there",
+		"",
+		nil,
+	)
+
+	var response string
+	var usage *models.TokenUsage
+	for event := range eventChan {
+		switch event.Type {
+		case "token":
+			response += event.Content
+		case "usage":
+			usage = event.Usage
+		default:
+			t.Errorf("unexpected event %q", event.Type)
+		}
+	}
+	require.NoError(t, <-errChan)
+
+	assert.Equal(t, "Hello from the fake provider", response)
+
+	// The fake provider reports no usage, so the answer's tokens are estimated
+	require.NotNil(t, usage)
+	assert.Positive(t, usage.PromptTokens)
+	assert.Positive(t, usage.CompletionTokens)
+	assert.Equal(t, usage.PromptTokens+usage.CompletionTokens, usage.TotalTokens)
+
+	// The service's OpenAI model is not sent to other providers; they use their own default
+	req := fake.LastRequest()
+	assert.Empty(t, req.Model)
+	require.NotNil(t, req.Temperature)
+	assert.Equal(t, 0.7, *req.Temperature)
+	require.Len(t, req.Messages, 2)
+	assert.Equal(t, "Hi there", req.Messages[1].Content)
+}
+
+/*
+ * Test StreamChat fails for bots configured with an unregistered provider
+ */
+func TestStreamChat_UnknownProvider(t *testing.T) {
+	service := NewChatService(nil, nil, nil, "gpt-4o-mini", 0.7, 5, "test-key", 2000)
+
+	bot := &models.Bot{ID: "bot-123", Provider: "missing"}
+	eventChan, errChan := service.StreamChat(
+		context.Background(),
+		bot,
+		GenerationSettings{},
+		"Hi there",
+		"",
+		nil,
+	)
+
+	for range eventChan {
+		t.Fatal("Expected no events for an unknown provider")
+	}
+	assert.ErrorIs(t, <-errChan, llm.ErrUnknownProvider)
+}
+
+/*
+ * Test ResolveSettings prefers bot settings and falls back to service defaults
+ */
+func TestResolveSettings(t *testing.T) {
+	service := NewChatService(nil, nil, nil, "gpt-4o-mini", 0.7, 5, "test-key", 2000)
+
+	// No bot overrides: service defaults with tier output cap
+	settings := service.ResolveSettings(&models.Bot{}, configs.GetTierLimits(configs.TierFree))
+	assert.Equal(t, "gpt-4o-mini", settings.Model)
+	assert.Equal(t, 0.7, settings.Temperature)
+	assert.Equal(t, 5, settings.MaxContextChunks)
+	assert.Equal(t, 1024, settings.MaxOutputTokens)
+	assert.Equal(t, vector.DefaultMMRLambda, settings.MMRLambda)
+
+	// Bot overrides within tier limits
+	temperature := 0.0
+	lambda := 0.7
+	bot := &models.Bot{
+		Model:             "gpt-4o",
+		Temperature:       &temperature,
+		MaxOutputTokens:   800,
+		MaxContextChunks:  10,
+		DistanceThreshold: 0.5,
+		RetrievalMode:     models.RetrievalModeMMR,
+		MMRLambda:         &lambda,
+	}
+	settings = service.ResolveSettings(bot, configs.GetTierLimits(configs.TierPro))
+	assert.Equal(t, "gpt-4o", settings.Model)
+	assert.Equal(t, 0.0, settings.Temperature)
+	assert.Equal(t, 800, settings.MaxOutputTokens)
+	assert.Equal(t, 10, settings.MaxContextChunks)
+	assert.Equal(t, 0.5, settings.DistanceThreshold)
+	assert.Equal(t, models.RetrievalModeMMR, settings.RetrievalMode)
+	assert.Equal(t, 0.7, settings.MMRLambda)
+
+	// Same bot after a downgrade: model falls back, limits are clamped
+	settings = service.ResolveSettings(bot, configs.GetTierLimits(configs.TierFree))
+	assert.Equal(t, "gpt-4o-mini", settings.Model)
+	assert.Equal(t, 800, settings.MaxOutputTokens)
+	assert.Equal(t, 5, settings.MaxContextChunks)
+
+	// Other providers get the bot's model or, without one, their own default
+	settings = service.ResolveSettings(&models.Bot{Provider: llm.ProviderAnthropic}, configs.GetTierLimits(configs.TierEnterprise))
+	assert.Empty(t, settings.Model)
+	settings = service.ResolveSettings(&models.Bot{Provider: llm.ProviderAnthropic, Model: "claude-sonnet-4-5"}, configs.GetTierLimits(configs.TierEnterprise))
+	assert.Equal(t, "claude-sonnet-4-5", settings.Model)
+}
+
+// Helper to set the distance of a search result
+func distance(d float32) *float32 {
+	return &d
+}
+
+/*
+ * Test filterByDistance drops chunks beyond the threshold
+ */
+func TestFilterByDistance(t *testing.T) {
+	chunks := []vector.SearchResult{
+		{ChunkID: "near", Distance: distance(0.2)},
+		{ChunkID: "far", Distance: distance(0.8)},
+		{ChunkID: "far-keyword", Distance: distance(0.8), KeywordRank: 1},
+		{ChunkID: "unembedded-keyword", KeywordRank: 2},
+		{ChunkID: "unembedded"},
+	}
+
+	assert.Len(t, filterByDistance(chunks, 0), 5)
+
+	// Full-text matches are kept with or without a distance; other chunks need one within the threshold
+	filtered := filterByDistance(chunks, 0.5)
+	ids := make([]string, len(filtered))
+	for i, chunk := range filtered {
+		ids[i] = chunk.ChunkID
+	}
+	assert.Equal(t, []string{"near", "far-keyword", "unembedded-keyword"}, ids)
+}
+
+/*
+ * Test runCompletion executes requested actions and feeds results back to the model
+ */
+func TestRunCompletion_ToolCalls(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/orders/42", r.URL.Path)
+		w.Write([]byte(`{"status":"shipped"}`))
+	}))
+	defer server.Close()
+
+	service := NewChatService(nil, nil, nil, "gpt-4o-mini", 0.7, 5, "test-key", 2000)
+	actionService := action.NewActionService(nil)
+	actionService.SetAllowPrivateNetworks(true)
+	service.SetActionService(actionService)
+
+	fake := llm.NewFakeProvider("", "Your order has shipped.")
+	fake.ToolCalls = [][]llm.ToolCall{
+		{{ID: "call_1", Name: "lookup_order_status", Arguments: `{"order_id":"42"}`}},
+	}
+	fake.Usage = &llm.Usage{PromptTokens: 100, CompletionTokens: 20}
+
+	actions := []models.BotAction{
+		{Name: "lookup_order_status", Method: http.MethodGet, URL: server.URL + "/orders/{order_id}"},
+	}
+	messages := []llm.Message{{Role: llm.RoleUser, Content: "Where is order 42?"}}
+
+	eventChan := make(chan models.ChatTokenResponse, 16)
+	var response strings.Builder
+	tokenUsage, err := service.runCompletion(context.Background(), fake, GenerationSettings{Model: "gpt-4o-mini"}, messages, actions, eventChan, &response)
+	require.NoError(t, err)
+	close(eventChan)
+
+	// Usage is summed across both rounds
+	assert.Equal(t, llm.Usage{PromptTokens: 200, CompletionTokens: 40}, tokenUsage)
+
+	var toolEvents []models.ToolCall
+	for event := range eventChan {
+		if event.Type == "tool_call" {
+			toolEvents = append(toolEvents, *event.ToolCall)
+		}
+	}
+	require.Len(t, toolEvents, 2)
+	assert.Equal(t, "running", toolEvents[0].Status)
+	assert.Equal(t, "completed", toolEvents[1].Status)
+	assert.Equal(t, "Your order has shipped.", response.String())
+
+	// First call offered the tool, second call received its result
+	requests := fake.Requests()
+	require.Len(t, requests, 2)
+	require.Len(t, requests[0].Tools, 1)
+	followUp := requests[1].Messages
+	require.Len(t, followUp, 3)
+	assert.Equal(t, llm.RoleAssistant, followUp[1].Role)
+	assert.Equal(t, llm.RoleTool, followUp[2].Role)
+	assert.Equal(t, "call_1", followUp[2].ToolCallID)
+	assert.Equal(t, `{"status":"shipped"}`, followUp[2].Content)
+}
+
+/*
+ * Test runCompletion stops offering tools after maxToolRounds
+ */
+func TestRunCompletion_MaxToolRounds(t *testing.T) {
+	service := NewChatService(nil, nil, nil, "gpt-4o-mini", 0.7, 5, "test-key", 2000)
+	actionService := action.NewActionService(nil)
+	actionService.SetAllowPrivateNetworks(true)
+	service.SetActionService(actionService)
+
+	// The model keeps asking for an unknown action
+	call := []llm.ToolCall{{ID: "call", Name: "missing_action", Arguments: `{}`}}
+	fake := llm.NewFakeProvider("", "", "", "Giving up.")
+	fake.ToolCalls = [][]llm.ToolCall{call, call, call}
+
+	eventChan := make(chan models.ChatTokenResponse, 32)
+	var response strings.Builder
+	_, err := service.runCompletion(context.Background(), fake, GenerationSettings{}, nil, []models.BotAction{{Name: "other"}}, eventChan, &response)
+	require.NoError(t, err)
+
+	requests := fake.Requests()
+	require.Len(t, requests, maxToolRounds+1)
+	assert.Empty(t, requests[maxToolRounds].Tools)
+	assert.Contains(t, requests[1].Messages[1].Content, "unknown action")
+	assert.Equal(t, "Giving up.", response.String())
+}
+
+/*
+ * Test completionUsage prefers provider-reported usage and estimates otherwise
+ */
+func TestCompletionUsage(t *testing.T) {
+	messages := []llm.Message{
+		{Role: llm.RoleSystem, Content: "You are helpful"},
+		{Role: llm.RoleUser, Content: "Do you ship abroad?"},
+	}
+
+	reported := completionUsage(messages, &llm.Completion{
+		Content: "Yes, worldwide.",
+		Usage:   &llm.Usage{PromptTokens: 1200, CompletionTokens: 7},
+	})
+	assert.Equal(t, llm.Usage{PromptTokens: 1200, CompletionTokens: 7}, reported)
+
+	estimated := completionUsage(messages, &llm.Completion{Content: "Yes, worldwide."})
+	assert.Equal(t, countTokens("You are helpful")+countTokens("Do you ship abroad?")+2*messageOverheadTokens, estimated.PromptTokens)
+	assert.Equal(t, countTokens("Yes, worldwide."), estimated.CompletionTokens)
+}
+
+/*
+ * Test billedProvider bills the bot owner for helper completions at the requested model's price
+ */
+func TestBilledProvider(t *testing.T) {
+	db, err := gorm.Open(sqlite.Open(":memory:"), &gorm.Config{})
+	require.NoError(t, err)
+	require.NoError(t, db.AutoMigrate(&models.User{}, &models.UsageRecord{}))
+	require.NoError(t, db.Create(&models.User{ID: "user-1", Email: "owner@example.com"}).Error)
+
+	service := NewChatService(nil, nil, nil, "gpt-4o", 0.7, 5, "test-key", 2000)
+	fake := llm.NewFakeProvider(`"Pro plan pricing"`)
+	fake.Usage = &llm.Usage{PromptTokens: 120, CompletionTokens: 8}
+	bot := &models.Bot{ID: "bot-1", UserID: "user-1", QueryRewrite: true}
+	history := []models.Message{{Role: "user", Content: "Tell me about the Pro plan"}}
+
+	// Without a usage service the provider is used as is
+	assert.Same(t, llm.ChatProvider(fake), service.billedProvider(fake, bot))
+
+	service.SetUsageService(usage.NewUsageService(db))
+	service.SetQueryRewriteModel("gpt-4o-mini")
+	rewritten := service.rewriteQuery(context.Background(), service.billedProvider(fake, bot), bot, GenerationSettings{Model: "gpt-4o"}, history, "and pricing?")
+	assert.Equal(t, "Pro plan pricing", rewritten)
+
+	var records []models.UsageRecord
+	require.NoError(t, db.Find(&records).Error)
+	require.Len(t, records, 1)
+	assert.Equal(t, models.UsageTypeHelperTokens, records[0].Type)
+	assert.Equal(t, "gpt-4o-mini", records[0].Model)
+	assert.Equal(t, "bot-1", records[0].BotID)
+	assert.Equal(t, float64(128), records[0].Quantity)
+}
+
+/*
+ * Test rewriteQuery only runs for opted-in bots with prior conversation
+ */
+func TestRewriteQuery(t *testing.T) {
+	service := NewChatService(nil, nil, nil, "gpt-4o", 0.7, 5, "test-key", 2000)
+	service.SetQueryRewriteModel("gpt-4o-mini")
+	settings := GenerationSettings{Model: "gpt-4o"}
+	history := []models.Message{
+		{Role: "user", Content: "Tell me about the Pro plan"},
+		{Role: "assistant", Content: "The Pro plan includes 10 bots."},
+	}
+
+	// Disabled for the bot
+	fake := llm.NewFakeProvider(`"Pro plan pricing"`)
+	rewritten := service.rewriteQuery(context.Background(), fake, &models.Bot{}, settings, history, "what about pricing?")
+	assert.Empty(t, rewritten)
+	assert.Empty(t, fake.Requests())
+
+	// First message of the conversation
+	bot := &models.Bot{QueryRewrite: true}
+	rewritten = service.rewriteQuery(context.Background(), fake, bot, settings, nil, "what about pricing?")
+	assert.Empty(t, rewritten)
+	assert.Empty(t, fake.Requests())
+
+	// Follow-up is rewritten with the rewrite model
+	rewritten = service.rewriteQuery(context.Background(), fake, bot, settings, history, "what about pricing?")
+	assert.Equal(t, "Pro plan pricing", rewritten)
+	req := fake.LastRequest()
+	assert.Equal(t, "gpt-4o-mini", req.Model)
+	require.Len(t, req.Messages, 2)
+	assert.Contains(t, req.Messages[1].Content, "User: Tell me about the Pro plan")
+	assert.Contains(t, req.Messages[1].Content, "Latest message: what about pricing?")
+
+	// Bots on other providers keep their own model, or the provider's default when they have none
+	rewritten = service.rewriteQuery(context.Background(), fake, &models.Bot{QueryRewrite: true, Provider: llm.ProviderAnthropic, Model: "gpt-4o"}, settings, history, "and pricing?")
+	assert.Equal(t, "Pro plan pricing", rewritten)
+	assert.Equal(t, "gpt-4o", fake.LastRequest().Model)
+
+	service.rewriteQuery(context.Background(), fake, &models.Bot{QueryRewrite: true, Provider: llm.ProviderAnthropic}, settings, history, "and pricing?")
+	assert.Empty(t, fake.LastRequest().Model)
+
+	// Failures fall back to the original message
+	fake.Err = assert.AnError
+	assert.Empty(t, service.rewriteQuery(context.Background(), fake, bot, settings, history, "what about pricing?"))
+}
+
+/*
+ * Test fallbackReply only triggers when retrieval found nothing and a mode is set
+ */
+func TestFallbackReply(t *testing.T) {
+	chunks := []vector.SearchResult{{ChunkID: "chunk1", Distance: distance(0.2)}}
+
+	testCases := []struct {
+		name     string
+		bot      models.Bot
+		chunks   []vector.SearchResult
+		searched bool
+		reply    string
+		ok       bool
+	}{
+		{"Disabled", models.Bot{}, nil, true, "", false},
+		{"Chunks found", models.Bot{FallbackMode: models.FallbackModeMessage}, chunks, true, "", false},
+		{"No retrieval", models.Bot{FallbackMode: models.FallbackModeMessage}, nil, false, "", false},
+		{"Default message", models.Bot{FallbackMode: models.FallbackModeMessage}, nil, true, defaultFallbackMessage, true},
+		{"Custom message", models.Bot{FallbackMode: models.FallbackModeMessage, FallbackMessage: "Ask support."}, nil, true, "Ask support.", true},
+		{"Human handoff", models.Bot{FallbackMode: models.FallbackModeHuman}, nil, true, defaultHandoffMessage, true},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			reply, ok := fallbackReply(&tc.bot, tc.chunks, tc.searched)
+			assert.Equal(t, tc.ok, ok)
+			assert.Equal(t, tc.reply, reply)
+		})
+	}
+}
+
+/*
+ * Test sendFallback emits the fallback event followed by the reply as a token
+ */
+func TestSendFallback(t *testing.T) {
+	eventChan := make(chan models.ChatTokenResponse, 2)
+	require.NoError(t, sendFallback(context.Background(), eventChan, models.FallbackModeHuman, "Talk to us?"))
+	close(eventChan)
+
+	var events []models.ChatTokenResponse
+	for event := range eventChan {
+		events = append(events, event)
+	}
+	require.Len(t, events, 2)
+	assert.Equal(t, "fallback", events[0].Type)
+	assert.Equal(t, models.FallbackModeHuman, events[0].Fallback)
+	assert.Equal(t, "token", events[1].Type)
+	assert.Equal(t, "Talk to us?", events[1].Content)
+}
+
+/*
+ * Test fitContext keeps chunks within the model's context window
+ */
+func TestFitContext(t *testing.T) {
+	bigChunk := strings.Repeat("shipping ", 3000)
+	chunks := []vector.SearchResult{
+		{ChunkID: "chunk1", Content: bigChunk, URL: "https://example.com/1"},
+		{ChunkID: "chunk2", Content: bigChunk, URL: "https://example.com/2"},
+		{ChunkID: "chunk3", Content: bigChunk, URL: "https://example.com/3"},
+		{ChunkID: "chunk4", Content: bigChunk, URL: "https://example.com/4"},
+	}
+	history := []models.Message{{Role: "user", Content: "Hi"}, {Role: "assistant", Content: "Hello!"}}
+
+	// gpt-4 has an 8192 token window
+	settings := GenerationSettings{Model: "gpt-4", MaxOutputTokens: 1000}
+	fitted, breakdown := fitContext(settings, "You are helpful", nil, history, "Do you ship abroad?", chunks)
+
+	require.Len(t, fitted, 3)
+	assert.Equal(t, bigChunk, fitted[1].Content)
+	assert.Less(t, len(fitted[2].Content), len(bigChunk))
+	assert.Equal(t, bigChunk, chunks[2].Content, "input chunks are not modified")
+
+	assert.Equal(t, 8192, breakdown.ContextWindow)
+	assert.Equal(t, 1000, breakdown.ReservedAnswer)
+	assert.Equal(t, 3, breakdown.ChunksUsed)
+	assert.Equal(t, 1, breakdown.ChunksTruncated)
+	assert.Equal(t, 1, breakdown.ChunksDropped)
+	assert.Greater(t, breakdown.History, 0)
+	total := breakdown.System + breakdown.Knowledge + breakdown.History + breakdown.Question + breakdown.ReservedAnswer
+	assert.LessOrEqual(t, total, breakdown.ContextWindow)
+
+	// Large windows keep every chunk intact
+	fitted, breakdown = fitContext(GenerationSettings{Model: "gpt-4o-mini"}, "", nil, nil, "Do you ship abroad?", chunks)
+	assert.Len(t, fitted, 4)
+	assert.Equal(t, defaultAnswerReserve, breakdown.ReservedAnswer)
+	assert.Zero(t, breakdown.ChunksTruncated)
+	assert.Zero(t, breakdown.ChunksDropped)
+}
+
+/*
+ * Test replayAnswer streams a cached answer with its sources
+ */
+func TestReplayAnswer(t *testing.T) {
+	eventChan := make(chan models.ChatTokenResponse, 16)
+	entry := &answercache.Entry{
+		Answer:    "Yes, we ship worldwide.",
+		Citations: []models.Citation{{ChunkID: "chunk-1", SourceID: "source-1"}},
+	}
+
+	require.NoError(t, replayAnswer(context.Background(), eventChan, entry))
+	close(eventChan)
+
+	var events []models.ChatTokenResponse
+	for event := range eventChan {
+		events = append(events, event)
+	}
+	require.Len(t, events, 5)
+	assert.Equal(t, "sources", events[0].Type)
+	assert.Equal(t, entry.Citations, events[0].Sources)
+
+	var content strings.Builder
+	for _, event := range events[1:] {
+		assert.Equal(t, "token", event.Type)
+		content.WriteString(event.Content)
+	}
+	assert.Equal(t, entry.Answer, content.String())
+}
+
+/*
+ * Test canCacheAnswer only caches opening questions to bots without actions
+ */
+func TestCanCacheAnswer(t *testing.T) {
+	mr, err := miniredis.Run()
+	require.NoError(t, err)
+	defer mr.Close()
+
+	cacheService := cache.NewCacheService(redis.NewClient(&redis.Options{Addr: mr.Addr()}))
+	service := NewChatService(embedding.NewEmbeddingService("test-key", "text-embedding-3-small", 1536), &vector.SearchService{}, nil, "gpt-4o-mini", 0.7, 5, "test-key", 2000)
+
+	// Disabled until a cache is set
+	assert.False(t, service.canCacheAnswer(nil, nil))
+
+	service.SetAnswerCache(answercache.NewAnswerCacheService(cacheService, 0.95))
+	assert.True(t, service.canCacheAnswer(nil, nil))
+	assert.False(t, service.canCacheAnswer([]models.Message{{Role: "user", Content: "Hi"}}, nil))
+	assert.False(t, service.canCacheAnswer(nil, []models.BotAction{{Name: "lookup_order_status"}}))
+}
+
+/*
+ * Test parseSuggestions strips list markers, duplicates and the original question
+ */
+func TestParseSuggestions(t *testing.T) {
+	content := "1. How long does shipping take?\n- \"Do you ship abroad?\"\n\n* Can I return an item?\n2) how long does shipping take?\n• What payment methods do you accept?"
+
+	suggestions := parseSuggestions(content, "Do you ship abroad?")
+	assert.Equal(t, []string{
+		"How long does shipping take?",
+		"Can I return an item?",
+		"What payment methods do you accept?",
+	}, suggestions)
+
+	assert.Empty(t, parseSuggestions("", "Hi"))
+}
+
+/*
+ * Test suggestFollowUps only runs for opted-in bots with retrieved context
+ */
+func TestSuggestFollowUps(t *testing.T) {
+	service := NewChatService(nil, nil, nil, "gpt-4o", 0.7, 5, "test-key", 2000)
+	service.SetQueryRewriteModel("gpt-4o-mini")
+	settings := GenerationSettings{Model: "gpt-4o"}
+	chunks := []vector.SearchResult{{ChunkID: "chunk1", Content: "We ship to 40 countries within 5 days.", URL: "https://example.com/shipping"}}
+	fake := llm.NewFakeProvider("How much does shipping cost?\nWhich countries do you ship to?")
+
+	// Disabled for the bot
+	bot := &models.Bot{SuggestFollowUps: false}
+	assert.Nil(t, service.suggestFollowUps(context.Background(), fake, bot, settings, chunks, "Do you ship abroad?", "Yes."))
+
+	// Nothing retrieved, so nothing the knowledge base is known to answer
+	bot.SuggestFollowUps = true
+	assert.Nil(t, service.suggestFollowUps(context.Background(), fake, bot, settings, nil, "Do you ship abroad?", "Yes."))
+	assert.Empty(t, fake.Requests())
+
+	suggestions := service.suggestFollowUps(context.Background(), fake, bot, settings, chunks, "Do you ship abroad?", "Yes.")
+	assert.Equal(t, []string{"How much does shipping cost?", "Which countries do you ship to?"}, suggestions)
+
+	request := fake.LastRequest()
+	assert.Equal(t, "gpt-4o-mini", request.Model)
+	require.Len(t, request.Messages, 2)
+	assert.Contains(t, request.Messages[1].Content, "We ship to 40 countries")
+	assert.Contains(t, request.Messages[1].Content, "Question: Do you ship abroad?")
+
+	// Failures finish the answer without suggestions
+	fake.Err = assert.AnError
+	assert.Nil(t, service.suggestFollowUps(context.Background(), fake, bot, settings, chunks, "Do you ship abroad?", "Yes."))
+}
+
+/*
+ * Test buildMessages puts the session summary before the recent turns
+ */
+func TestBuildMessages_WithSummary(t *testing.T) {
+	service := NewChatService(nil, nil, nil, "gpt-4o-mini", 0.7, 5, "test-key", 2000)
+
+	summary := &models.SessionSummary{Content: "The visitor runs a bakery and asked about the Pro plan."}
+	history := []models.Message{
+		{Role: "user", Content: "Does it include analytics?"},
+		{Role: "assistant", Content: "Yes, Pro includes analytics."},
+	}
+
+	messages := service.buildMessages("System prompt", nil, summary, history, "And exports?")
+
+	// system + summary + 2 history turns + user = 5 messages
+	require.Len(t, messages, 5)
+	assert.Equal(t, llm.RoleSystem, messages[1].Role)
+	assert.Equal(t, summaryHeader+summary.Content, messages[1].Content)
+	assert.Equal(t, "Does it include analytics?", messages[2].Content)
+
+	// The summary takes room from the knowledge base
+	_, breakdown := fitContext(GenerationSettings{Model: "gpt-4o"}, "System prompt", summary, history, "And exports?", nil)
+	assert.Equal(t, countTokens(summaryHeader+summary.Content)+messageOverheadTokens, breakdown.Summary)
+}
+
+/*
+ * Test summarizeSession condenses older turns once the history budget is exceeded
+ * and loadHistory then returns the summary with the turns it does not cover
+ */
+func TestSummarizeSession(t *testing.T) {
+	db, err := gorm.Open(sqlite.Open(":memory:"), &gorm.Config{})
+	require.NoError(t, err)
+	require.NoError(t, db.AutoMigrate(&models.Message{}, &models.SessionSummary{}))
+
+	ctx := context.Background()
+	repo := messageRepo.New(db)
+	service := NewChatService(nil, nil, repo, "gpt-4o", 0.7, 5, "test-key", 100)
+	service.SetQueryRewriteModel("gpt-4o-mini")
+	bot := &models.Bot{ID: "bot-1"}
+	settings := GenerationSettings{Model: "gpt-4o"}
+
+	var messages []models.Message
+	addTurns := func(count int) {
+		for i := 0; i < count; i++ {
+			role := "user"
+			if i%2 == 1 {
+				role = "assistant"
+			}
+			msg := models.Message{
+				SessionID:  "session-1",
+				BotID:      bot.ID,
+				Role:       role,
+				Content:    fmt.Sprintf("message %d", len(messages)),
+				TokenCount: 20,
+				CreatedAt:  time.Now().Add(time.Duration(len(messages)) * time.Second),
+			}
+			require.NoError(t, repo.Create(ctx, &msg))
+			messages = append(messages, msg)
+		}
+	}
+
+	// Within budget: nothing to summarize
+	addTurns(4)
+	fake := llm.NewFakeProvider("The visitor asked four things.", "The visitor asked eight things.")
+	service.summarizeSession(ctx, fake, bot, settings, "session-1")
+	assert.Empty(t, fake.Requests())
+
+	// Over budget: older turns are condensed, the newest half of the budget is kept
+	addTurns(4)
+	service.summarizeSession(ctx, fake, bot, settings, "session-1")
+	require.Len(t, fake.Requests(), 1)
+	assert.Equal(t, "gpt-4o-mini", fake.LastRequest().Model)
+	assert.Contains(t, fake.LastRequest().Messages[1].Content, "Visitor: message 0")
+	assert.NotContains(t, fake.LastRequest().Messages[1].Content, "message 6")
+
+	summary, history := service.loadHistory(ctx, "session-1")
+	require.NotNil(t, summary)
+	assert.Equal(t, "The visitor asked four things.", summary.Content)
+	assert.Equal(t, messages[5].ID, summary.LastMessageID)
+	assert.Equal(t, 6, summary.MessageCount)
+	require.Len(t, history, 2)
+	assert.Equal(t, "message 6", history[0].Content)
+
+	// The next summary builds on the previous one
+	addTurns(6)
+	service.summarizeSession(ctx, fake, bot, settings, "session-1")
+	require.Len(t, fake.Requests(), 2)
+	assert.Contains(t, fake.LastRequest().Messages[1].Content, "Existing summary:\nThe visitor asked four things.")
+	assert.NotContains(t, fake.LastRequest().Messages[1].Content, "message 5\n")
+
+	summary, _ = service.loadHistory(ctx, "session-1")
+	assert.Equal(t, "The visitor asked eight things.", summary.Content)
+	assert.Equal(t, 12, summary.MessageCount)
+}
+
+/*
+ * Test StreamChat answers with the prompt in the settings and records its version on both messages
+ */
+func TestStreamChat_PromptVersion(t *testing.T) {
+	db, err := gorm.Open(sqlite.Open(":memory:"), &gorm.Config{})
+	require.NoError(t, err)
+	require.NoError(t, db.AutoMigrate(&models.Message{}, &models.SessionSummary{}))
+
+	repo := messageRepo.New(db)
+	service := NewChatService(nil, nil, repo, "gpt-4o-mini", 0.7, 5, "test-key", 2000)
+	fake := llm.NewFakeProvider("Bonjour")
+	service.RegisterProvider("fake", fake)
+
+	bot := &models.Bot{ID: "bot-123", SystemPrompt: "Be brief.", PromptVersionID: "version-1", Provider: "fake"}
+	settings := service.ResolveSettings(bot, configs.GetTierLimits(configs.TierEnterprise))
+	assert.Equal(t, "Be brief.", settings.SystemPrompt)
+	assert.Equal(t, "version-1", settings.PromptVersionID)
+
+	// An experiment variant replaces the prompt for this session
+	settings.SystemPrompt = "Answer in French."
+	settings.PromptVersionID = "version-2"
+	eventChan, errChan := service.StreamChat(context.Background(), bot, settings, "Hi there", "session-1", nil)
+	for range eventChan {
+	}
+	require.NoError(t, <-errChan)
+
+	assert.Equal(t, "Answer in French.", fake.LastRequest().Messages[0].Content)
+
+	messages, err := repo.GetBySessionID(context.Background(), "session-1")
+	require.NoError(t, err)
+	require.Len(t, messages, 2)
+	for _, msg := range messages {
+		assert.Equal(t, "version-2", msg.PromptVersionID)
+	}
+}
+
+/*
+ * Test explain reports what becomes of each retrieved chunk and the prompt that would be sent
+ */
+func TestExplain(t *testing.T) {
+	service := NewChatService(nil, nil, nil, "gpt-4", 0.7, 5, "test-key", 2000)
+	bigChunk := strings.Repeat("shipping ", 3000)
+	retrieved := []vector.SearchResult{
+		{ChunkID: "chunk1", SourceID: "source1", Content: bigChunk, Distance: distance(0.1), ChunkIndex: 4, URL: "https://example.com/1"},
+		{ChunkID: "chunk2", SourceID: "source2", Content: "Far away", Distance: distance(0.9), OriginalFilename: "faq.pdf"},
+		{ChunkID: "chunk2b", SourceID: "source2", Content: bigChunk, Distance: distance(0.2), URL: "https://example.com/2"},
+		{ChunkID: "chunk3", SourceID: "source3", Content: bigChunk, Distance: distance(0.3), URL: "https://example.com/3"},
+		{ChunkID: "chunk4", SourceID: "source4", Content: bigChunk, Distance: distance(0.4), URL: "https://example.com/4"},
+	}
+	bot := &models.Bot{ID: "bot-123"}
+	settings := GenerationSettings{SystemPrompt: "You are helpful", Model: "gpt-4", MaxOutputTokens: 1000, MaxContextChunks: 5, DistanceThreshold: 0.5}
+
+	explanation := service.explain(context.Background(), nil, bot, settings, "Do you ship abroad?", "Do you ship abroad?", retrieved)
+
+	statuses := make([]string, len(explanation.Chunks))
+	for i, chunk := range explanation.Chunks {
+		statuses[i] = chunk.Status
+	}
+	assert.Equal(t, []string{
+		models.ChunkStatusUsed,
+		models.ChunkStatusFiltered,
+		models.ChunkStatusUsed,
+		models.ChunkStatusTruncated,
+		models.ChunkStatusDropped,
+	}, statuses)
+
+	first := explanation.Chunks[0]
+	assert.Equal(t, 1, first.Rank)
+	assert.Equal(t, 4, first.ChunkIndex)
+	assert.Equal(t, countTokens(bigChunk), first.TokenCount)
+	assert.Equal(t, "faq.pdf", explanation.Chunks[1].Filename)
+
+	// The prompt is exactly what buildMessages produces for the chunks that fit
+	require.Len(t, explanation.Prompt, 3)
+	assert.Equal(t, models.PromptMessage{Role: llm.RoleSystem, Content: "You are helpful"}, explanation.Prompt[0])
+	assert.Contains(t, explanation.Prompt[1].Content, "https://example.com/3")
+	assert.NotContains(t, explanation.Prompt[1].Content, "https://example.com/4")
+	assert.Equal(t, models.PromptMessage{Role: llm.RoleUser, Content: "Do you ship abroad?"}, explanation.Prompt[2])
+	assert.Equal(t, 3, explanation.Breakdown.ChunksUsed)
+	assert.Equal(t, 0.5, explanation.DistanceThreshold)
+	assert.Empty(t, explanation.Fallback)
+}
+
+/*
+ * Test explain shows the fallback reply when nothing clears the threshold
+ */
+func TestExplain_Fallback(t *testing.T) {
+	service := NewChatService(nil, nil, nil, "gpt-4o-mini", 0.7, 5, "test-key", 2000)
+	bot := &models.Bot{ID: "bot-123", FallbackMode: models.FallbackModeMessage, FallbackMessage: "Ask support."}
+	retrieved := []vector.SearchResult{{ChunkID: "chunk1", Content: "Unrelated", Distance: distance(0.8)}}
+
+	explanation := service.explain(context.Background(), nil, bot, GenerationSettings{DistanceThreshold: 0.5}, "Do you ship abroad?", "Do you ship abroad?", retrieved)
+
+	assert.Equal(t, "Ask support.", explanation.Fallback)
+	assert.Empty(t, explanation.Prompt)
+	require.Len(t, explanation.Chunks, 1)
+	assert.Equal(t, models.ChunkStatusFiltered, explanation.Chunks[0].Status)
+}
+
+/*
+ * Test explain reports rerank positions and scores and the chunks pushed below the limit
+ */
+func TestExplain_Rerank(t *testing.T) {
+	service := NewChatService(nil, nil, nil, "gpt-4o-mini", 0.7, 5, "test-key", 2000)
+	retrieved := []vector.SearchResult{
+		{ChunkID: "hours", Content: "Our store opens at 9am.", Distance: distance(0.2), Score: 0.03},
+		{ChunkID: "far", Content: "Customs duties explained.", Distance: distance(0.9), Score: 0.02},
+		{ChunkID: "returns", Content: "Returns are free within 30 days.", Distance: distance(0.3), Score: 0.02},
+		{ChunkID: "customs", Content: "Customs duties on international orders are paid by the buyer.", Distance: distance(0.4), Score: 0.01},
+	}
+	settings := GenerationSettings{SystemPrompt: "You are helpful", Model: "gpt-4o-mini", MaxOutputTokens: 500, MaxContextChunks: 1, DistanceThreshold: 0.5, Reranker: models.RerankerBM25}
+
+	explanation := service.explain(context.Background(), nil, &models.Bot{ID: "bot-123"}, settings, "Who pays customs duties?", "Who pays customs duties?", retrieved)
+
+	assert.Equal(t, models.RerankerBM25, explanation.Reranker)
+	assert.Empty(t, explanation.RerankError)
+
+	byID := map[string]models.ExplainedChunk{}
+	for _, chunk := range explanation.Chunks {
+		byID[chunk.ChunkID] = chunk
+	}
+	assert.Equal(t, models.ChunkStatusUsed, byID["customs"].Status)
+	assert.Equal(t, 4, byID["customs"].Rank)
+	assert.Equal(t, 1, byID["customs"].RerankRank)
+	assert.Greater(t, byID["customs"].RerankScore, 0.0)
+	assert.Equal(t, models.ChunkStatusOutranked, byID["hours"].Status)
+	assert.Equal(t, models.ChunkStatusOutranked, byID["returns"].Status)
+	assert.Equal(t, models.ChunkStatusFiltered, byID["far"].Status)
+	assert.Zero(t, byID["far"].RerankRank)
+	assert.Contains(t, explanation.Prompt[1].Content, "paid by the buyer")
+
+	// Without a reranker the retrieval order decides
+	settings.Reranker = models.RerankerNone
+	explanation = service.explain(context.Background(), nil, &models.Bot{ID: "bot-123"}, settings, "Who pays customs duties?", "Who pays customs duties?", retrieved)
+	assert.Equal(t, models.ChunkStatusUsed, explanation.Chunks[0].Status)
+	assert.Zero(t, explanation.Chunks[0].RerankRank)
+}
+
+/*
+ * Test candidateLimit retrieves a deeper pool for reranking
+ */
+func TestCandidateLimit(t *testing.T) {
+	assert.Equal(t, 5, candidateLimit(GenerationSettings{MaxContextChunks: 5}))
+	assert.Equal(t, rerankCandidates, candidateLimit(GenerationSettings{MaxContextChunks: 5, Reranker: models.RerankerLLM}))
+	assert.Equal(t, 30, candidateLimit(GenerationSettings{MaxContextChunks: 30, Reranker: models.RerankerBM25}))
+}
+
+/*
+ * Test ExplainRetrieval requires the search service
+ */
+func TestExplainRetrieval_NoSearchService(t *testing.T) {
+	service := NewChatService(nil, nil, nil, "gpt-4o-mini", 0.7, 5, "test-key", 2000)
+	_, err := service.ExplainRetrieval(context.Background(), &models.Bot{ID: "bot-123"}, GenerationSettings{}, models.ExplainSearchRequest{Query: "Hi"})
+	assert.ErrorIs(t, err, ErrSearchUnavailable)
+}
@@ -0,0 +1,131 @@
+package chat
+
+import (
+	"fmt"
+
+	"github.com/souravsspace/texly.chat/internal/models"
+	"github.com/souravsspace/texly.chat/internal/services/llm"
+	"github.com/souravsspace/texly.chat/internal/services/vector"
+	"github.com/tiktoken-go/tokenizer"
+)
+
+const (
+	// defaultAnswerReserve is kept free for the answer when the bot sets no output limit
+	defaultAnswerReserve = 1024
+	// messageOverheadTokens approximates the per-message formatting tokens of chat models
+	messageOverheadTokens = 4
+	// minTruncatedChunkTokens is the smallest useful remainder of a truncated chunk
+	minTruncatedChunkTokens = 100
+)
+
+const (
+	knowledgeHeader = "Here is relevant information from the knowledge base:\n\n"
+	knowledgeFooter = "Please use this information to answer the user's question accurately."
+)
+
+/*
+ * fitContext keeps the retrieved chunks that fit in the model's context window
+ * Room is reserved for the system prompt, summary, history, question and answer first; chunks are
+ * taken in rank order, the first one that does not fit is truncated and the rest are dropped
+ */
+func fitContext(
+	settings GenerationSettings,
+	systemPrompt string,
+	summary *models.SessionSummary,
+	history []models.Message,
+	userMessage string,
+	contextChunks []vector.SearchResult,
+) ([]vector.SearchResult, models.TokenBreakdown) {
+	breakdown := models.TokenBreakdown{
+		ContextWindow:  llm.ContextWindow(settings.Model),
+		Question:       countTokens(userMessage) + messageOverheadTokens,
+		ReservedAnswer: settings.MaxOutputTokens,
+	}
+	if breakdown.ReservedAnswer <= 0 {
+		breakdown.ReservedAnswer = defaultAnswerReserve
+	}
+	if systemPrompt != "" {
+		breakdown.System = countTokens(systemPrompt) + messageOverheadTokens
+	}
+	if summary != nil && summary.Content != "" {
+		breakdown.Summary = countTokens(summaryHeader+summary.Content) + messageOverheadTokens
+	}
+	for _, msg := range history {
+		if msg.Content != "" {
+			breakdown.History += countTokens(msg.Content) + messageOverheadTokens
+		}
+	}
+
+	if len(contextChunks) == 0 {
+		return contextChunks, breakdown
+	}
+
+	budget := breakdown.ContextWindow - breakdown.System - breakdown.Summary - breakdown.History - breakdown.Question - breakdown.ReservedAnswer
+	budget -= countTokens(knowledgeHeader) + countTokens(knowledgeFooter) + messageOverheadTokens
+
+	fitted := make([]vector.SearchResult, 0, len(contextChunks))
+	used := 0
+	for i, chunk := range contextChunks {
+		tokens := countTokens(formatChunk(i, chunk))
+		if used+tokens <= budget {
+			fitted = append(fitted, chunk)
+			used += tokens
+			continue
+		}
+
+		// Cut the first chunk that does not fit, if enough room is left to be useful
+		remaining := budget - used - (tokens - countTokens(chunk.Content))
+		for remaining >= minTruncatedChunkTokens {
+			chunk.Content = truncateTokens(chunk.Content, remaining)
+			tokens = countTokens(formatChunk(i, chunk))
+			if used+tokens <= budget {
+				fitted = append(fitted, chunk)
+				used += tokens
+				breakdown.ChunksTruncated++
+				break
+			}
+			// Re-encoding the cut text can merge tokens differently; shrink by the overflow
+			remaining -= used + tokens - budget
+		}
+		break
+	}
+
+	breakdown.ChunksUsed = len(fitted)
+	breakdown.ChunksDropped = len(contextChunks) - len(fitted)
+	if len(fitted) > 0 {
+		breakdown.Knowledge = used + countTokens(knowledgeHeader) + countTokens(knowledgeFooter) + messageOverheadTokens
+	}
+	return fitted, breakdown
+}
+
+/*
+ * formatChunk renders a chunk as it appears in the knowledge base system message
+ */
+func formatChunk(index int, chunk vector.SearchResult) string {
+	return fmt.Sprintf("--- Context %d ---\n%s\nSource: %s\n\n", index+1, chunk.Content, sourceLabel(chunk))
+}
+
+/*
+ * truncateTokens cuts text to at most maxTokens tokens
+ */
+func truncateTokens(text string, maxTokens int) string {
+	codec, err := tokenizer.Get(tokenizer.Cl100kBase)
+	if err != nil {
+		// Fallback to simple approximation
+		if len(text) > maxTokens*4 {
+			return text[:maxTokens*4]
+		}
+		return text
+	}
+
+	ids, _, err := codec.Encode(text)
+	if err != nil || len(ids) <= maxTokens {
+		return text
+	}
+
+	truncated, err := codec.Decode(ids[:maxTokens])
+	if err != nil {
+		return text
+	}
+	return truncated
+}
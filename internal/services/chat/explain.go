@@ -0,0 +1,166 @@
+package chat
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/souravsspace/texly.chat/internal/models"
+	"github.com/souravsspace/texly.chat/internal/services/llm"
+	"github.com/souravsspace/texly.chat/internal/services/vector"
+)
+
+var ErrSearchUnavailable = errors.New("search service not available")
+
+/*
+ * ExplainRetrieval runs the retrieval half of the chat pipeline for a question without answering it
+ * Returns the chunks retrieved, what became of each and the exact prompt the model would receive
+ */
+func (s *ChatService) ExplainRetrieval(
+	ctx context.Context,
+	bot *models.Bot,
+	settings GenerationSettings,
+	req models.ExplainSearchRequest,
+) (*models.SearchExplanation, error) {
+	if s.searchService == nil {
+		return nil, ErrSearchUnavailable
+	}
+
+	if req.Limit != nil {
+		settings.MaxContextChunks = *req.Limit
+	}
+	if req.DistanceThreshold != nil {
+		settings.DistanceThreshold = *req.DistanceThreshold
+	}
+	if req.Reranker != nil {
+		settings.Reranker = *req.Reranker
+		if settings.Reranker == "none" {
+			settings.Reranker = models.RerankerNone
+		}
+	}
+
+	if req.RetrievalMode != nil {
+		settings.RetrievalMode = *req.RetrievalMode
+		if settings.RetrievalMode == "similarity" {
+			settings.RetrievalMode = models.RetrievalModeSimilarity
+		}
+	}
+	if req.MMRLambda != nil {
+		settings.MMRLambda = *req.MMRLambda
+	}
+
+	provider, err := s.providers.Get(bot.Provider)
+	if err != nil {
+		return nil, err
+	}
+
+	searchQuery := req.Query
+	if req.RewrittenQuery != "" {
+		searchQuery = req.RewrittenQuery
+	}
+
+	retrieved, err := s.retrieve(ctx, bot.ID, settings, searchQuery, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to search context: %w", err)
+	}
+
+	return s.explain(ctx, s.billedProvider(provider, bot), bot, settings, req.Query, searchQuery, retrieved), nil
+}
+
+/*
+ * explain applies the distance threshold, reranking, fallback and context fitting of streamChat to retrieved chunks
+ */
+func (s *ChatService) explain(
+	ctx context.Context,
+	provider llm.ChatProvider,
+	bot *models.Bot,
+	settings GenerationSettings,
+	question string,
+	searchQuery string,
+	retrieved []vector.SearchResult,
+) *models.SearchExplanation {
+	explanation := &models.SearchExplanation{
+		Query:             question,
+		SearchQuery:       searchQuery,
+		Limit:             settings.MaxContextChunks,
+		DistanceThreshold: settings.DistanceThreshold,
+		Model:             settings.Model,
+		Reranker:          settings.Reranker,
+		RetrievalMode:     settings.RetrievalMode,
+		MMRLambda:         settings.MMRLambda,
+		Chunks:            make([]models.ExplainedChunk, 0, len(retrieved)),
+		Prompt:            []models.PromptMessage{},
+	}
+
+	relevant := filterByDistance(retrieved, settings.DistanceThreshold)
+	ranked, err := s.rerankChunks(ctx, provider, bot, settings, searchQuery, relevant)
+	if err != nil {
+		explanation.RerankError = err.Error()
+	}
+	selected := limitChunks(ranked, settings.MaxContextChunks)
+
+	var fitted []vector.SearchResult
+	if reply, ok := fallbackReply(bot, selected, true); ok {
+		explanation.Fallback = reply
+	} else {
+		fitted, explanation.Breakdown = fitContext(settings, settings.SystemPrompt, nil, nil, question, selected)
+		for _, msg := range s.buildMessages(settings.SystemPrompt, fitted, nil, nil, question) {
+			explanation.Prompt = append(explanation.Prompt, models.PromptMessage{Role: msg.Role, Content: msg.Content})
+		}
+	}
+
+	// Positions after reranking; fitting keeps a prefix of the selected chunks
+	reranked := settings.Reranker != models.RerankerNone && err == nil
+	relevantIDs := make(map[string]bool, len(relevant))
+	for _, chunk := range relevant {
+		relevantIDs[chunk.ChunkID] = true
+	}
+	positions := make(map[string]int, len(ranked))
+	for i, chunk := range ranked {
+		positions[chunk.ChunkID] = i
+	}
+
+	for i, chunk := range retrieved {
+		status := models.ChunkStatusFiltered
+		var rerankRank int
+		var rerankScore float64
+		if relevantIDs[chunk.ChunkID] {
+			position := positions[chunk.ChunkID]
+			switch {
+			case position >= len(selected):
+				status = models.ChunkStatusOutranked
+			case position >= len(fitted):
+				status = models.ChunkStatusDropped
+			case fitted[position].Content != chunk.Content:
+				status = models.ChunkStatusTruncated
+			default:
+				status = models.ChunkStatusUsed
+			}
+			if reranked {
+				rerankRank = position + 1
+				rerankScore = ranked[position].RerankScore
+			}
+		}
+
+		explanation.Chunks = append(explanation.Chunks, models.ExplainedChunk{
+			Rank:         i + 1,
+			ChunkID:      chunk.ChunkID,
+			SourceID:     chunk.SourceID,
+			ChunkIndex:   chunk.ChunkIndex,
+			Content:      chunk.Content,
+			TokenCount:   countTokens(chunk.Content),
+			Distance:     chunk.Distance,
+			Score:        chunk.Score,
+			VectorRank:   chunk.VectorRank,
+			KeywordRank:  chunk.KeywordRank,
+			RerankRank:   rerankRank,
+			RerankScore:  rerankScore,
+			URL:          chunk.URL,
+			Filename:     chunk.OriginalFilename,
+			SourceStatus: chunk.SourceStatus,
+			Status:       status,
+		})
+	}
+
+	return explanation
+}
@@ -0,0 +1,49 @@
+package chat
+
+import (
+	"context"
+
+	"github.com/souravsspace/texly.chat/internal/models"
+	"github.com/souravsspace/texly.chat/internal/services/vector"
+)
+
+const (
+	defaultFallbackMessage = "I'm sorry, I don't have enough information to answer that question."
+	defaultHandoffMessage  = "I'm sorry, I couldn't find an answer to that. Would you like to talk to someone from our team?"
+)
+
+/*
+ * fallbackReply decides whether to answer with the bot's fallback instead of the model
+ * Applies only when retrieval ran and no chunk cleared the distance threshold
+ */
+func fallbackReply(bot *models.Bot, contextChunks []vector.SearchResult, searched bool) (string, bool) {
+	if !searched || len(contextChunks) > 0 {
+		return "", false
+	}
+
+	switch bot.FallbackMode {
+	case models.FallbackModeMessage:
+		if bot.FallbackMessage != "" {
+			return bot.FallbackMessage, true
+		}
+		return defaultFallbackMessage, true
+	case models.FallbackModeHuman:
+		if bot.FallbackMessage != "" {
+			return bot.FallbackMessage, true
+		}
+		return defaultHandoffMessage, true
+	default:
+		return "", false
+	}
+}
+
+/*
+ * sendFallback announces the fallback to the client and streams the reply as a token
+ * Clients that only render tokens (e.g. the completions API) still show the reply
+ */
+func sendFallback(ctx context.Context, eventChan chan<- models.ChatTokenResponse, mode string, reply string) error {
+	if err := sendEvent(ctx, eventChan, models.ChatTokenResponse{Type: "fallback", Fallback: mode, Content: reply}); err != nil {
+		return err
+	}
+	return sendEvent(ctx, eventChan, models.ChatTokenResponse{Type: "token", Content: reply})
+}
@@ -0,0 +1,113 @@
+package chat
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/souravsspace/texly.chat/internal/models"
+	"github.com/souravsspace/texly.chat/internal/services/llm"
+)
+
+const (
+	// rewriteHistoryMessages is how many recent turns are shown to the rewrite model
+	rewriteHistoryMessages = 6
+	// rewriteMaxTokens bounds the length of a rewritten query
+	rewriteMaxTokens = 100
+)
+
+const rewriteSystemPrompt = `You rewrite the user's latest message into a standalone search query for a knowledge base.
+Resolve pronouns and references using the conversation so the query makes sense on its own.
+Keep the user's language and key terms. Do not answer the question.
+Reply with the search query only, without quotes or explanations.`
+
+/*
+ * SetQueryRewriteModel sets the small model used to rewrite follow-up questions and suggest new ones
+ * Only applies to bots on the default provider; other bots use their own model
+ */
+func (s *ChatService) SetQueryRewriteModel(model string) {
+	s.rewriteModel = model
+}
+
+/*
+ * smallModel returns the model used for auxiliary calls such as rewriting and suggestions
+ * Other providers get the bot's own model, or their configured default when it has none
+ */
+func (s *ChatService) smallModel(bot *models.Bot, settings GenerationSettings) string {
+	if !isDefaultProvider(bot.Provider) {
+		if bot.Model == "" {
+			return ""
+		}
+		return settings.Model
+	}
+	if s.rewriteModel != "" {
+		return s.rewriteModel
+	}
+	return settings.Model
+}
+
+/*
+ * rewriteQuery turns a follow-up question into a self-contained search query
+ * Returns an empty string when rewriting is disabled, not needed, or fails
+ */
+func (s *ChatService) rewriteQuery(
+	ctx context.Context,
+	provider llm.ChatProvider,
+	bot *models.Bot,
+	settings GenerationSettings,
+	history []models.Message,
+	userMessage string,
+) string {
+	// The first message of a conversation is already standalone
+	if !bot.QueryRewrite || len(history) == 0 {
+		return ""
+	}
+
+	temperature := 0.0
+	completion, err := llm.Complete(ctx, provider, llm.CompletionRequest{
+		Model: s.smallModel(bot, settings),
+		Messages: []llm.Message{
+			{Role: llm.RoleSystem, Content: rewriteSystemPrompt},
+			{Role: llm.RoleUser, Content: buildRewritePrompt(history, userMessage)},
+		},
+		Temperature: &temperature,
+		MaxTokens:   rewriteMaxTokens,
+	})
+	if err != nil {
+		// Log error but retrieve with the original message
+		fmt.Printf("Warning: failed to rewrite query: %v\n", err)
+		return ""
+	}
+
+	return strings.Trim(strings.TrimSpace(completion.Content), `"`)
+}
+
+/*
+ * buildRewritePrompt renders the recent conversation and the new message as plain text
+ */
+func buildRewritePrompt(history []models.Message, userMessage string) string {
+	if len(history) > rewriteHistoryMessages {
+		history = history[len(history)-rewriteHistoryMessages:]
+	}
+
+	var builder strings.Builder
+	builder.WriteString("Conversation:\n")
+	for _, msg := range history {
+		if msg.Content == "" {
+			continue
+		}
+		switch msg.Role {
+		case "user":
+			builder.WriteString("User: ")
+		case "assistant", "operator":
+			builder.WriteString("Assistant: ")
+		default:
+			continue
+		}
+		builder.WriteString(msg.Content)
+		builder.WriteString("\n")
+	}
+	builder.WriteString("\nLatest message: ")
+	builder.WriteString(userMessage)
+	return builder.String()
+}
@@ -0,0 +1,62 @@
+package chat
+
+import (
+	"context"
+
+	"github.com/souravsspace/texly.chat/internal/models"
+	"github.com/souravsspace/texly.chat/internal/services/llm"
+	"github.com/souravsspace/texly.chat/internal/services/rerank"
+	"github.com/souravsspace/texly.chat/internal/services/vector"
+)
+
+/*
+ * rerankCandidates is how many chunks are retrieved for the reranker to choose the context from
+ */
+const rerankCandidates = 20
+
+/*
+ * candidateLimit returns how many chunks to retrieve: a deeper pool when the bot reranks them
+ */
+func candidateLimit(settings GenerationSettings) int {
+	if settings.Reranker == models.RerankerNone {
+		return settings.MaxContextChunks
+	}
+	return max(settings.MaxContextChunks, rerankCandidates)
+}
+
+/*
+ * rerankChunks reorders retrieved chunks with the bot's reranker
+ * Returns the chunks in retrieval order when reranking is disabled or fails
+ */
+func (s *ChatService) rerankChunks(
+	ctx context.Context,
+	provider llm.ChatProvider,
+	bot *models.Bot,
+	settings GenerationSettings,
+	query string,
+	chunks []vector.SearchResult,
+) ([]vector.SearchResult, error) {
+	if settings.Reranker == models.RerankerNone || len(chunks) == 0 {
+		return chunks, nil
+	}
+
+	reranker, err := rerank.New(settings.Reranker, provider, s.smallModel(bot, settings))
+	if err != nil {
+		return chunks, err
+	}
+	ranked, err := reranker.Rerank(ctx, query, chunks)
+	if err != nil {
+		return chunks, err
+	}
+	return ranked, nil
+}
+
+/*
+ * limitChunks keeps the first limit chunks
+ */
+func limitChunks(chunks []vector.SearchResult, limit int) []vector.SearchResult {
+	if limit > 0 && len(chunks) > limit {
+		return chunks[:limit]
+	}
+	return chunks
+}
@@ -0,0 +1,84 @@
+package chat
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/souravsspace/texly.chat/internal/models"
+	"github.com/souravsspace/texly.chat/internal/services/llm"
+	"github.com/souravsspace/texly.chat/internal/services/vector"
+)
+
+/*
+ * RetrieveContext returns the chunks a bot would answer a standalone question from
+ * It runs the same retrieval, distance threshold, reranking and context limit as a chat message
+ */
+func (s *ChatService) RetrieveContext(
+	ctx context.Context,
+	bot *models.Bot,
+	settings GenerationSettings,
+	query string,
+) ([]vector.SearchResult, error) {
+	provider, err := s.providers.Get(bot.Provider)
+	if err != nil {
+		return nil, err
+	}
+	return s.retrieveContext(ctx, s.billedProvider(provider, bot), bot, settings, query, nil)
+}
+
+/*
+ * retrieveContext retrieves candidates in the bot's retrieval mode, drops those beyond the distance threshold,
+ * reranks the rest and keeps the bot's number of context chunks
+ * A failed rerank is logged and the retrieval order kept; no chunks are returned without a search service
+ */
+func (s *ChatService) retrieveContext(
+	ctx context.Context,
+	provider llm.ChatProvider,
+	bot *models.Bot,
+	settings GenerationSettings,
+	query string,
+	queryEmbedding []float32,
+) ([]vector.SearchResult, error) {
+	if s.searchService == nil {
+		return nil, nil
+	}
+
+	chunks, err := s.retrieve(ctx, bot.ID, settings, query, queryEmbedding)
+	if err != nil {
+		return nil, fmt.Errorf("failed to search context: %w", err)
+	}
+	chunks = filterByDistance(chunks, settings.DistanceThreshold)
+
+	chunks, err = s.rerankChunks(ctx, provider, bot, settings, query, chunks)
+	if err != nil {
+		// Log error but answer from the retrieval order
+		fmt.Printf("Warning: failed to rerank chunks: %v\n", err)
+	}
+	return limitChunks(chunks, settings.MaxContextChunks), nil
+}
+
+/*
+ * retrieve searches the bot's knowledge base for the candidate chunks of an answer in its retrieval mode
+ * A query embedding computed earlier (e.g. for the answer cache) is reused when given
+ */
+func (s *ChatService) retrieve(
+	ctx context.Context,
+	botID string,
+	settings GenerationSettings,
+	query string,
+	queryEmbedding []float32,
+) ([]vector.SearchResult, error) {
+	limit := candidateLimit(settings)
+	mmr := settings.RetrievalMode == models.RetrievalModeMMR
+
+	switch {
+	case queryEmbedding != nil && mmr:
+		return s.searchService.SearchHybridMMR(ctx, query, queryEmbedding, botID, limit, settings.MMRLambda)
+	case queryEmbedding != nil:
+		return s.searchService.SearchHybrid(ctx, query, queryEmbedding, botID, limit)
+	case mmr:
+		return s.searchService.SearchSimilarMMR(ctx, query, botID, limit, settings.MMRLambda)
+	default:
+		return s.searchService.SearchSimilar(ctx, query, botID, limit)
+	}
+}
@@ -0,0 +1,101 @@
+package chat
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/souravsspace/texly.chat/internal/models"
+	"github.com/souravsspace/texly.chat/internal/services/llm"
+	"github.com/souravsspace/texly.chat/internal/services/vector"
+)
+
+const (
+	// maxSuggestions is how many follow-up questions are offered after an answer
+	maxSuggestions = 3
+	// suggestionsMaxTokens bounds the length of the suggestion list
+	suggestionsMaxTokens = 150
+	// suggestionChunkTokens is how much of each retrieved chunk is shown to the suggestion model
+	suggestionChunkTokens = 200
+)
+
+const suggestionsSystemPrompt = `You suggest follow-up questions a visitor might ask next.
+Only suggest questions that the knowledge base excerpts can answer, and do not repeat the question already asked.
+Write at most 3 short questions in the language of the conversation, one per line, without numbering or quotes.`
+
+/*
+ * suggestFollowUps proposes questions the knowledge base can answer after an answer
+ * Returns nil when suggestions are disabled for the bot, nothing was retrieved, or generation fails
+ */
+func (s *ChatService) suggestFollowUps(
+	ctx context.Context,
+	provider llm.ChatProvider,
+	bot *models.Bot,
+	settings GenerationSettings,
+	contextChunks []vector.SearchResult,
+	question string,
+	answer string,
+) []string {
+	if !bot.SuggestFollowUps || len(contextChunks) == 0 || strings.TrimSpace(answer) == "" {
+		return nil
+	}
+
+	temperature := 0.3
+	completion, err := llm.Complete(ctx, provider, llm.CompletionRequest{
+		Model: s.smallModel(bot, settings),
+		Messages: []llm.Message{
+			{Role: llm.RoleSystem, Content: suggestionsSystemPrompt},
+			{Role: llm.RoleUser, Content: buildSuggestionsPrompt(contextChunks, question, answer)},
+		},
+		Temperature: &temperature,
+		MaxTokens:   suggestionsMaxTokens,
+	})
+	if err != nil {
+		// Log error but finish the answer without suggestions
+		fmt.Printf("Warning: failed to suggest follow-up questions: %v\n", err)
+		return nil
+	}
+
+	return parseSuggestions(completion.Content, question)
+}
+
+/*
+ * buildSuggestionsPrompt shows the start of each retrieved chunk with the exchange so far
+ */
+func buildSuggestionsPrompt(contextChunks []vector.SearchResult, question string, answer string) string {
+	var builder strings.Builder
+	builder.WriteString("Knowledge base excerpts:\n\n")
+	for i, chunk := range contextChunks {
+		chunk.Content = truncateTokens(chunk.Content, suggestionChunkTokens)
+		builder.WriteString(formatChunk(i, chunk))
+	}
+	builder.WriteString("Question: ")
+	builder.WriteString(question)
+	builder.WriteString("\nAnswer: ")
+	builder.WriteString(answer)
+	return builder.String()
+}
+
+/*
+ * parseSuggestions extracts up to maxSuggestions distinct questions, one per line
+ * List markers and quotes the model adds anyway are stripped
+ */
+func parseSuggestions(content string, question string) []string {
+	seen := map[string]bool{strings.ToLower(strings.TrimSpace(question)): true}
+	var suggestions []string
+	for _, line := range strings.Split(content, "\n") {
+		line = strings.TrimSpace(line)
+		line = strings.TrimLeft(line, "-*•0123456789.) ")
+		line = strings.Trim(line, `"' `)
+		key := strings.ToLower(line)
+		if line == "" || seen[key] {
+			continue
+		}
+		seen[key] = true
+		suggestions = append(suggestions, line)
+		if len(suggestions) == maxSuggestions {
+			break
+		}
+	}
+	return suggestions
+}
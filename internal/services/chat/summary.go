@@ -0,0 +1,187 @@
+package chat
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/souravsspace/texly.chat/internal/models"
+	"github.com/souravsspace/texly.chat/internal/services/llm"
+)
+
+/*
+ * summaryMaxTokens bounds the length of a session summary
+ */
+const summaryMaxTokens = 300
+
+const summarySystemPrompt = `You maintain a running summary of a support conversation between a visitor and an assistant.
+Merge the existing summary (if any) with the new turns into one concise summary.
+Keep facts the assistant may need later: who the visitor is, what they asked, answers given, decisions and open questions.
+Write plain prose in the language of the conversation, without preamble.`
+
+/*
+ * summaryHeader introduces the session summary to the chat model
+ */
+const summaryHeader = "Summary of the earlier conversation:\n"
+
+/*
+ * loadHistory fetches the session summary and the prior messages it does not cover,
+ * trimmed to the history token budget
+ * Returns nil when persistence is disabled or the lookup fails
+ */
+func (s *ChatService) loadHistory(ctx context.Context, sessionID string) (*models.SessionSummary, []models.Message) {
+	if s.messageRepo == nil || sessionID == "" || s.historyBudget <= 0 {
+		return nil, nil
+	}
+
+	summary, err := s.messageRepo.GetSessionSummary(ctx, sessionID)
+	if err != nil {
+		// Log error but answer from the recent turns alone
+		fmt.Printf("Warning: failed to load session summary: %v\n", err)
+	}
+
+	messages, err := s.messageRepo.GetBySessionID(ctx, sessionID)
+	if err != nil {
+		// Log error but answer without history
+		fmt.Printf("Warning: failed to load conversation history: %v\n", err)
+		return summary, nil
+	}
+
+	return summary, trimHistory(unsummarized(messages, summary), s.historyBudget)
+}
+
+/*
+ * unsummarized returns the messages after the last one condensed into the summary
+ */
+func unsummarized(messages []models.Message, summary *models.SessionSummary) []models.Message {
+	if summary == nil {
+		return messages
+	}
+	for i, msg := range messages {
+		if msg.ID == summary.LastMessageID {
+			return messages[i+1:]
+		}
+	}
+	return messages
+}
+
+/*
+ * summarizeSession condenses the older turns of a session into its summary once the turns
+ * not yet summarized outgrow the history budget. The newest turns, up to half the budget,
+ * are kept verbatim so the next few answers don't trigger another summary
+ * Runs in the background after an answer; failures only delay summarization
+ */
+func (s *ChatService) summarizeSession(
+	ctx context.Context,
+	provider llm.ChatProvider,
+	bot *models.Bot,
+	settings GenerationSettings,
+	sessionID string,
+) {
+	if s.messageRepo == nil || sessionID == "" || s.historyBudget <= 0 {
+		return
+	}
+
+	// One summary per session at a time
+	if _, running := s.summarizing.LoadOrStore(sessionID, struct{}{}); running {
+		return
+	}
+	defer s.summarizing.Delete(sessionID)
+
+	summary, err := s.messageRepo.GetSessionSummary(ctx, sessionID)
+	if err != nil {
+		fmt.Printf("Warning: failed to load session summary: %v\n", err)
+		return
+	}
+	messages, err := s.messageRepo.GetBySessionID(ctx, sessionID)
+	if err != nil {
+		fmt.Printf("Warning: failed to load conversation history: %v\n", err)
+		return
+	}
+
+	messages = unsummarized(messages, summary)
+	if historyTokens(messages) <= s.historyBudget {
+		return
+	}
+	recent := trimHistory(messages, s.historyBudget/2)
+	older := messages[:len(messages)-len(recent)]
+	if len(older) == 0 {
+		return
+	}
+
+	temperature := 0.2
+	completion, err := llm.Complete(ctx, provider, llm.CompletionRequest{
+		Model: s.smallModel(bot, settings),
+		Messages: []llm.Message{
+			{Role: llm.RoleSystem, Content: summarySystemPrompt},
+			{Role: llm.RoleUser, Content: buildSummaryPrompt(summary, older)},
+		},
+		Temperature: &temperature,
+		MaxTokens:   summaryMaxTokens,
+	})
+	if err != nil {
+		fmt.Printf("Warning: failed to summarize conversation: %v\n", err)
+		return
+	}
+	content := strings.TrimSpace(completion.Content)
+	if content == "" {
+		return
+	}
+
+	if summary == nil {
+		summary = &models.SessionSummary{SessionID: sessionID, BotID: bot.ID}
+	}
+	summary.Content = content
+	summary.LastMessageID = older[len(older)-1].ID
+	summary.MessageCount += len(older)
+	summary.TokenCount = countTokens(content)
+	if err := s.messageRepo.SaveSessionSummary(ctx, summary); err != nil {
+		fmt.Printf("Warning: failed to save session summary: %v\n", err)
+	}
+}
+
+/*
+ * buildSummaryPrompt renders the existing summary and the turns to fold into it as plain text
+ */
+func buildSummaryPrompt(summary *models.SessionSummary, turns []models.Message) string {
+	var builder strings.Builder
+	if summary != nil && summary.Content != "" {
+		builder.WriteString("Existing summary:\n")
+		builder.WriteString(summary.Content)
+		builder.WriteString("\n\n")
+	}
+	builder.WriteString("New turns:\n")
+	for _, msg := range turns {
+		if msg.Content == "" {
+			continue
+		}
+		switch msg.Role {
+		case "user":
+			builder.WriteString("Visitor: ")
+		case "assistant":
+			builder.WriteString("Assistant: ")
+		case "operator":
+			builder.WriteString("Operator: ")
+		default:
+			continue
+		}
+		builder.WriteString(msg.Content)
+		builder.WriteString("\n")
+	}
+	return builder.String()
+}
+
+/*
+ * historyTokens counts the tokens of a list of messages
+ */
+func historyTokens(messages []models.Message) int {
+	total := 0
+	for _, msg := range messages {
+		if msg.TokenCount > 0 {
+			total += msg.TokenCount
+		} else {
+			total += countTokens(msg.Content)
+		}
+	}
+	return total
+}
@@ -0,0 +1,301 @@
+package eval
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/souravsspace/texly.chat/internal/models"
+	"github.com/souravsspace/texly.chat/internal/services/chat"
+	"github.com/souravsspace/texly.chat/internal/services/llm"
+	"github.com/souravsspace/texly.chat/internal/services/vector"
+)
+
+/*
+ * DefaultK is the number of chunks retrieved per question when none is given
+ */
+const DefaultK = 5
+
+/*
+ * graderMaxTokens bounds the grader's one-word verdict
+ */
+const graderMaxTokens = 10
+
+const graderSystemPrompt = `You grade a support bot's answer against a reference answer.
+Reply with a single word:
+CORRECT if the answer agrees with the reference on everything the question asks,
+PARTIAL if it is incomplete or partly wrong,
+INCORRECT if it is wrong, missing or off topic.`
+
+// Grader verdicts and the correctness they score
+const (
+	VerdictCorrect   = "correct"
+	VerdictPartial   = "partial"
+	VerdictIncorrect = "incorrect"
+)
+
+var verdictScores = map[string]float64{
+	VerdictCorrect:   1,
+	VerdictPartial:   0.5,
+	VerdictIncorrect: 0,
+}
+
+var ErrUnknownVerdict = errors.New("grader returned an unknown verdict")
+
+/*
+ * Retriever returns the chunks a bot would answer a question from
+ * Implemented by chat.ChatService, so retrieval is scored after the bot's reranking and distance threshold
+ */
+type Retriever interface {
+	RetrieveContext(ctx context.Context, bot *models.Bot, settings chat.GenerationSettings, query string) ([]vector.SearchResult, error)
+}
+
+/*
+ * EvalService scores a bot's retrieval and answers against a golden set
+ */
+type EvalService struct {
+	retriever   Retriever         // Optional: skips retrieval metrics when nil
+	chatService *chat.ChatService // Optional: skips answers when nil
+	grader      llm.ChatProvider  // Optional: skips answer grading when nil
+	graderModel string
+}
+
+/*
+ * NewEvalService creates a new evaluation service instance
+ */
+func NewEvalService(retriever Retriever, chatService *chat.ChatService, grader llm.ChatProvider, graderModel string) *EvalService {
+	return &EvalService{
+		retriever:   retriever,
+		chatService: chatService,
+		grader:      grader,
+		graderModel: graderModel,
+	}
+}
+
+/*
+ * Run evaluates every question of the golden set and aggregates recall@k, MRR and answer correctness
+ * A failing question is recorded in its result and left out of the averages
+ */
+func (s *EvalService) Run(
+	ctx context.Context,
+	bot *models.Bot,
+	settings chat.GenerationSettings,
+	set *models.GoldenSet,
+	k int,
+) (*models.EvalReport, error) {
+	if k <= 0 {
+		k = DefaultK
+	}
+
+	// Follow-up suggestions are not evaluated
+	evalBot := *bot
+	evalBot.SuggestFollowUps = false
+
+	report := &models.EvalReport{
+		BotID:         bot.ID,
+		GoldenSetID:   set.ID,
+		GoldenSetName: set.Name,
+		K:             k,
+		StartedAt:     time.Now(),
+		Results:       make([]models.EvalResult, 0, len(set.Questions)),
+	}
+
+	var recallSum, rrSum, correctnessSum float64
+	for _, question := range set.Questions {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+
+		result := s.evaluate(ctx, &evalBot, settings, question, k)
+		report.Results = append(report.Results, result)
+		report.Questions++
+
+		if result.Error != "" {
+			report.Errors++
+			continue
+		}
+		if result.Recall != nil {
+			report.RetrievalScored++
+			recallSum += *result.Recall
+			rrSum += *result.ReciprocalRank
+		}
+		if result.Correctness != nil {
+			report.AnswersGraded++
+			correctnessSum += *result.Correctness
+		}
+	}
+
+	if report.RetrievalScored > 0 {
+		report.RecallAtK = recallSum / float64(report.RetrievalScored)
+		report.MRR = rrSum / float64(report.RetrievalScored)
+	}
+	if report.AnswersGraded > 0 {
+		report.AnswerCorrectness = correctnessSum / float64(report.AnswersGraded)
+	}
+	report.DurationMs = time.Since(report.StartedAt).Milliseconds()
+
+	return report, nil
+}
+
+/*
+ * evaluate runs retrieval, answering and grading for one question
+ */
+func (s *EvalService) evaluate(
+	ctx context.Context,
+	bot *models.Bot,
+	settings chat.GenerationSettings,
+	question models.GoldenQuestion,
+	k int,
+) models.EvalResult {
+	result := models.EvalResult{Question: question.Question, ExpectedSourceIDs: []string{}}
+	if question.ExpectedSourceIDs != "" {
+		if err := json.Unmarshal([]byte(question.ExpectedSourceIDs), &result.ExpectedSourceIDs); err != nil {
+			result.Error = fmt.Sprintf("invalid expected source IDs: %v", err)
+			return result
+		}
+	}
+
+	// Step 1: Score retrieval against the expected sources, using the top k chunks of the context
+	if s.retriever != nil {
+		retrievalSettings := settings
+		retrievalSettings.MaxContextChunks = k
+		chunks, err := s.retriever.RetrieveContext(ctx, bot, retrievalSettings, question.Question)
+		if err != nil {
+			result.Error = fmt.Sprintf("retrieval failed: %v", err)
+			return result
+		}
+		result.RetrievedSourceIDs = uniqueSources(chunks)
+		if len(result.ExpectedSourceIDs) > 0 {
+			recall, reciprocalRank := scoreRetrieval(chunks, result.ExpectedSourceIDs)
+			result.Recall = &recall
+			result.ReciprocalRank = &reciprocalRank
+		}
+	}
+
+	// Step 2: Answer through the full chat pipeline
+	if s.chatService == nil {
+		return result
+	}
+	answer, err := s.answer(ctx, bot, settings, question.Question)
+	if err != nil {
+		result.Error = fmt.Sprintf("answer failed: %v", err)
+		return result
+	}
+	result.Answer = answer
+
+	// Step 3: Grade the answer against the reference
+	if s.grader == nil || strings.TrimSpace(question.ReferenceAnswer) == "" {
+		return result
+	}
+	verdict, err := s.grade(ctx, question, answer)
+	if err != nil {
+		result.Error = fmt.Sprintf("grading failed: %v", err)
+		return result
+	}
+	correctness := verdictScores[verdict]
+	result.GraderVerdict = verdict
+	result.Correctness = &correctness
+
+	return result
+}
+
+/*
+ * answer collects the bot's streamed answer to a question asked without history
+ * Nothing is persisted
+ */
+func (s *EvalService) answer(ctx context.Context, bot *models.Bot, settings chat.GenerationSettings, question string) (string, error) {
+	eventChan, errChan := s.chatService.StreamChatWithHistory(ctx, bot, settings, nil, question)
+
+	var answer strings.Builder
+	for event := range eventChan {
+		if event.Type == "token" {
+			answer.WriteString(event.Content)
+		}
+	}
+	if err := <-errChan; err != nil {
+		return "", err
+	}
+	return answer.String(), nil
+}
+
+/*
+ * grade asks the grader model whether the answer matches the reference answer
+ */
+func (s *EvalService) grade(ctx context.Context, question models.GoldenQuestion, answer string) (string, error) {
+	temperature := 0.0
+	completion, err := llm.Complete(ctx, s.grader, llm.CompletionRequest{
+		Model: s.graderModel,
+		Messages: []llm.Message{
+			{Role: llm.RoleSystem, Content: graderSystemPrompt},
+			{Role: llm.RoleUser, Content: fmt.Sprintf(
+				"Question: %s\n\nReference answer: %s\n\nBot answer: %s",
+				question.Question, question.ReferenceAnswer, answer,
+			)},
+		},
+		Temperature: &temperature,
+		MaxTokens:   graderMaxTokens,
+	})
+	if err != nil {
+		return "", err
+	}
+	return ParseVerdict(completion.Content)
+}
+
+/*
+ * ParseVerdict reads the grader's verdict from the first word of its reply
+ */
+func ParseVerdict(content string) (string, error) {
+	fields := strings.Fields(content)
+	if len(fields) == 0 {
+		return "", ErrUnknownVerdict
+	}
+	verdict := strings.ToLower(strings.Trim(fields[0], ".,:;!*\"'`"))
+	if _, ok := verdictScores[verdict]; !ok {
+		return "", fmt.Errorf("%w: %q", ErrUnknownVerdict, fields[0])
+	}
+	return verdict, nil
+}
+
+/*
+ * scoreRetrieval returns the share of expected sources found in the chunks and the reciprocal
+ * rank of the first chunk from an expected source (0 when none was retrieved)
+ */
+func scoreRetrieval(chunks []vector.SearchResult, expected []string) (float64, float64) {
+	expectedSet := make(map[string]bool, len(expected))
+	for _, sourceID := range expected {
+		expectedSet[sourceID] = true
+	}
+
+	found := make(map[string]bool)
+	reciprocalRank := 0.0
+	for rank, chunk := range chunks {
+		if !expectedSet[chunk.SourceID] {
+			continue
+		}
+		if reciprocalRank == 0 {
+			reciprocalRank = 1 / float64(rank+1)
+		}
+		found[chunk.SourceID] = true
+	}
+
+	return float64(len(found)) / float64(len(expectedSet)), reciprocalRank
+}
+
+/*
+ * uniqueSources lists the sources of the chunks in rank order without repeats
+ */
+func uniqueSources(chunks []vector.SearchResult) []string {
+	seen := make(map[string]bool, len(chunks))
+	sources := make([]string, 0, len(chunks))
+	for _, chunk := range chunks {
+		if seen[chunk.SourceID] {
+			continue
+		}
+		seen[chunk.SourceID] = true
+		sources = append(sources, chunk.SourceID)
+	}
+	return sources
+}
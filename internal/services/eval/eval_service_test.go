@@ -0,0 +1,179 @@
+package eval
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/souravsspace/texly.chat/configs"
+	"github.com/souravsspace/texly.chat/internal/models"
+	"github.com/souravsspace/texly.chat/internal/services/chat"
+	"github.com/souravsspace/texly.chat/internal/services/llm"
+	"github.com/souravsspace/texly.chat/internal/services/vector"
+)
+
+/*
+ * fakeRetriever returns canned chunks per query
+ */
+type fakeRetriever struct {
+	results map[string][]vector.SearchResult
+	err     error
+	limits  []int
+}
+
+func (f *fakeRetriever) RetrieveContext(ctx context.Context, bot *models.Bot, settings chat.GenerationSettings, query string) ([]vector.SearchResult, error) {
+	f.limits = append(f.limits, settings.MaxContextChunks)
+	if f.err != nil {
+		return nil, f.err
+	}
+	return f.results[query], nil
+}
+
+func chunks(sourceIDs ...string) []vector.SearchResult {
+	results := make([]vector.SearchResult, len(sourceIDs))
+	for i, sourceID := range sourceIDs {
+		results[i] = vector.SearchResult{ChunkID: sourceID + "-chunk", SourceID: sourceID}
+	}
+	return results
+}
+
+func goldenSet(questions ...models.GoldenQuestionRequest) *models.GoldenSet {
+	return models.NewGoldenSet("bot-1", models.CreateGoldenSetRequest{Name: "Support FAQ", Questions: questions})
+}
+
+func TestRun_RetrievalMetrics(t *testing.T) {
+	retriever := &fakeRetriever{results: map[string][]vector.SearchResult{
+		"refunds?":  chunks("billing", "refunds", "refunds"),
+		"shipping?": chunks("faq", "billing"),
+		"hours?":    chunks("hours"),
+	}}
+	service := NewEvalService(retriever, nil, nil, "")
+
+	set := goldenSet(
+		models.GoldenQuestionRequest{Question: "refunds?", ExpectedSourceIDs: []string{"refunds", "policy"}},
+		models.GoldenQuestionRequest{Question: "shipping?", ExpectedSourceIDs: []string{"shipping"}},
+		models.GoldenQuestionRequest{Question: "hours?", ExpectedSourceIDs: []string{"hours"}},
+		models.GoldenQuestionRequest{Question: "unscored?"},
+	)
+
+	report, err := service.Run(context.Background(), &models.Bot{ID: "bot-1"}, chat.GenerationSettings{}, set, 3)
+	require.NoError(t, err)
+
+	assert.Equal(t, []int{3, 3, 3, 3}, retriever.limits)
+	assert.Equal(t, 4, report.Questions)
+	assert.Equal(t, 3, report.RetrievalScored)
+	assert.InDelta(t, (0.5+0+1)/3, report.RecallAtK, 1e-9)
+	assert.InDelta(t, (0.5+0+1)/3, report.MRR, 1e-9)
+	assert.Equal(t, 0, report.AnswersGraded)
+
+	first := report.Results[0]
+	assert.Equal(t, []string{"billing", "refunds"}, first.RetrievedSourceIDs)
+	require.NotNil(t, first.Recall)
+	assert.Equal(t, 0.5, *first.Recall)
+	assert.Equal(t, 0.5, *first.ReciprocalRank)
+
+	assert.Nil(t, report.Results[3].Recall)
+}
+
+func TestRun_GradesAnswers(t *testing.T) {
+	chatService := chat.NewChatService(nil, nil, nil, "gpt-4o-mini", 0.7, 5, "test-key", 2000)
+	bot := &models.Bot{ID: "bot-1", SystemPrompt: "Be brief.", Provider: "fake", SuggestFollowUps: true}
+	answers := llm.NewFakeProvider("Refunds take 5 days.", "We ship worldwide.", "Open 24/7.")
+	chatService.RegisterProvider("fake", answers)
+	grader := llm.NewFakeProvider("CORRECT", "Partial.", "verdict: unsure")
+
+	service := NewEvalService(nil, chatService, grader, "grader-model")
+	set := goldenSet(
+		models.GoldenQuestionRequest{Question: "How long do refunds take?", ReferenceAnswer: "Five business days."},
+		models.GoldenQuestionRequest{Question: "Do you ship abroad?", ReferenceAnswer: "Only to the EU."},
+		models.GoldenQuestionRequest{Question: "When are you open?", ReferenceAnswer: "9 to 5."},
+	)
+	settings := chatService.ResolveSettings(bot, configs.GetTierLimits(configs.TierEnterprise))
+
+	report, err := service.Run(context.Background(), bot, settings, set, 0)
+	require.NoError(t, err)
+
+	assert.Equal(t, DefaultK, report.K)
+	assert.Equal(t, 2, report.AnswersGraded)
+	assert.Equal(t, 1, report.Errors)
+	assert.InDelta(t, 0.75, report.AnswerCorrectness, 1e-9)
+
+	assert.Equal(t, "Refunds take 5 days.", report.Results[0].Answer)
+	assert.Equal(t, VerdictCorrect, report.Results[0].GraderVerdict)
+	assert.Equal(t, VerdictPartial, report.Results[1].GraderVerdict)
+	assert.Contains(t, report.Results[2].Error, "grading failed")
+
+	// Follow-ups are not requested, so each question costs one answer call
+	assert.Len(t, answers.Requests(), 3)
+
+	req := grader.Requests()[0]
+	assert.Equal(t, "grader-model", req.Model)
+	assert.Contains(t, req.Messages[1].Content, "Five business days.")
+	assert.Contains(t, req.Messages[1].Content, "Refunds take 5 days.")
+}
+
+func TestRun_RecordsErrors(t *testing.T) {
+	service := NewEvalService(&fakeRetriever{err: errors.New("db down")}, nil, nil, "")
+	set := goldenSet(models.GoldenQuestionRequest{Question: "refunds?", ExpectedSourceIDs: []string{"refunds"}})
+
+	report, err := service.Run(context.Background(), &models.Bot{ID: "bot-1"}, chat.GenerationSettings{}, set, 5)
+	require.NoError(t, err)
+	assert.Equal(t, 1, report.Errors)
+	assert.Equal(t, 0, report.RetrievalScored)
+	assert.Contains(t, report.Results[0].Error, "db down")
+}
+
+func TestParseVerdict(t *testing.T) {
+	for content, want := range map[string]string{
+		"CORRECT":                   VerdictCorrect,
+		"  partial. It misses X":    VerdictPartial,
+		"**Incorrect**":             VerdictIncorrect,
+		"Correct, the answer is ok": VerdictCorrect,
+	} {
+		verdict, err := ParseVerdict(content)
+		require.NoError(t, err, content)
+		assert.Equal(t, want, verdict, content)
+	}
+
+	_, err := ParseVerdict("")
+	assert.ErrorIs(t, err, ErrUnknownVerdict)
+	_, err = ParseVerdict("Maybe")
+	assert.ErrorIs(t, err, ErrUnknownVerdict)
+}
+
+func TestFormatMarkdown(t *testing.T) {
+	recall := 1.0
+	report := &models.EvalReport{
+		BotID:         "bot-1",
+		GoldenSetName: "Support FAQ",
+		K:             5,
+		Questions:     2,
+		RecallAtK:     1,
+		MRR:           1,
+		Results: []models.EvalResult{
+			{Question: "Refunds | returns?", Recall: &recall, ReciprocalRank: &recall, GraderVerdict: VerdictCorrect},
+			{Question: "Multi\nline", Error: "answer failed"},
+		},
+	}
+
+	markdown := FormatMarkdown(report)
+	assert.Contains(t, markdown, "# Evaluation: Support FAQ")
+	assert.Contains(t, markdown, "| Recall@5 | 1.000 | 0 |")
+	assert.Contains(t, markdown, "| 1 | Refunds \\| returns? | 1.00 | 1.00 | correct | - |")
+	assert.Contains(t, markdown, "| 2 | Multi line | - | - | - | answer failed |")
+}
+
+func TestReportJSON(t *testing.T) {
+	set := goldenSet(models.GoldenQuestionRequest{Question: "hours?"})
+	report, err := NewEvalService(nil, nil, nil, "").Run(context.Background(), &models.Bot{ID: "bot-1"}, chat.GenerationSettings{}, set, 5)
+	require.NoError(t, err)
+
+	data, err := json.Marshal(report)
+	require.NoError(t, err)
+	assert.Contains(t, string(data), `"expected_source_ids":[]`)
+	assert.Contains(t, string(data), `"recall":null`)
+}
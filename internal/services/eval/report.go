@@ -0,0 +1,62 @@
+package eval
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/souravsspace/texly.chat/internal/models"
+)
+
+/*
+ * FormatMarkdown renders an evaluation report as a Markdown summary followed by per-question results
+ */
+func FormatMarkdown(report *models.EvalReport) string {
+	var b strings.Builder
+
+	fmt.Fprintf(&b, "# Evaluation: %s\n\n", report.GoldenSetName)
+	fmt.Fprintf(&b, "Bot `%s`, %d questions, %d errors, %dms\n\n", report.BotID, report.Questions, report.Errors, report.DurationMs)
+
+	b.WriteString("| Metric | Value | Questions |\n")
+	b.WriteString("|---|---|---|\n")
+	fmt.Fprintf(&b, "| Recall@%d | %.3f | %d |\n", report.K, report.RecallAtK, report.RetrievalScored)
+	fmt.Fprintf(&b, "| MRR | %.3f | %d |\n", report.MRR, report.RetrievalScored)
+	fmt.Fprintf(&b, "| Answer correctness | %.3f | %d |\n", report.AnswerCorrectness, report.AnswersGraded)
+
+	b.WriteString("\n## Questions\n\n")
+	b.WriteString("| # | Question | Recall | RR | Verdict | Error |\n")
+	b.WriteString("|---|---|---|---|---|---|\n")
+	for i, result := range report.Results {
+		fmt.Fprintf(&b, "| %d | %s | %s | %s | %s | %s |\n",
+			i+1,
+			escapeCell(result.Question),
+			formatScore(result.Recall),
+			formatScore(result.ReciprocalRank),
+			orDash(result.GraderVerdict),
+			escapeCell(orDash(result.Error)),
+		)
+	}
+
+	return b.String()
+}
+
+func formatScore(score *float64) string {
+	if score == nil {
+		return "-"
+	}
+	return fmt.Sprintf("%.2f", *score)
+}
+
+func orDash(s string) string {
+	if s == "" {
+		return "-"
+	}
+	return s
+}
+
+/*
+ * escapeCell keeps a value on one table row
+ */
+func escapeCell(s string) string {
+	s = strings.ReplaceAll(s, "|", "\\|")
+	return strings.Join(strings.Fields(s), " ")
+}
@@ -0,0 +1,116 @@
+package feedback
+
+import (
+	"context"
+	"errors"
+
+	"github.com/souravsspace/texly.chat/internal/models"
+	feedbackRepo "github.com/souravsspace/texly.chat/internal/repo/feedback"
+	messageRepo "github.com/souravsspace/texly.chat/internal/repo/message"
+)
+
+var (
+	ErrMessageNotFound = errors.New("message not found")
+	ErrNotRatable      = errors.New("only assistant messages can be rated")
+)
+
+/*
+ * FeedbackService records visitor and owner ratings of assistant messages
+ */
+type FeedbackService struct {
+	feedbackRepo *feedbackRepo.FeedbackRepository
+	messageRepo  *messageRepo.MessageRepository
+}
+
+/*
+ * NewFeedbackService creates a new feedback service instance
+ */
+func NewFeedbackService(feedbackRepo *feedbackRepo.FeedbackRepository, messageRepo *messageRepo.MessageRepository) *FeedbackService {
+	return &FeedbackService{
+		feedbackRepo: feedbackRepo,
+		messageRepo:  messageRepo,
+	}
+}
+
+/*
+ * SubmitVisitorFeedback rates a message of the visitor's own widget session
+ */
+func (s *FeedbackService) SubmitVisitorFeedback(
+	ctx context.Context,
+	sessionID string,
+	messageID string,
+	req models.MessageFeedbackRequest,
+) (*models.MessageFeedback, error) {
+	message, err := s.ratableMessage(ctx, messageID)
+	if err != nil {
+		return nil, err
+	}
+	if message.SessionID != sessionID {
+		return nil, ErrMessageNotFound
+	}
+
+	return s.save(ctx, message, models.FeedbackSourceVisitor, nil, req)
+}
+
+/*
+ * SubmitOwnerFeedback rates any message of a bot the owner has access to
+ */
+func (s *FeedbackService) SubmitOwnerFeedback(
+	ctx context.Context,
+	botID string,
+	userID string,
+	messageID string,
+	req models.MessageFeedbackRequest,
+) (*models.MessageFeedback, error) {
+	message, err := s.ratableMessage(ctx, messageID)
+	if err != nil {
+		return nil, err
+	}
+	if message.BotID != botID {
+		return nil, ErrMessageNotFound
+	}
+
+	return s.save(ctx, message, models.FeedbackSourceOwner, &userID, req)
+}
+
+/*
+ * ratableMessage loads a message and checks it is an assistant answer
+ */
+func (s *FeedbackService) ratableMessage(ctx context.Context, messageID string) (*models.Message, error) {
+	message, err := s.messageRepo.GetByID(ctx, messageID)
+	if err != nil {
+		return nil, err
+	}
+	if message == nil {
+		return nil, ErrMessageNotFound
+	}
+	if message.Role != "assistant" {
+		return nil, ErrNotRatable
+	}
+	return message, nil
+}
+
+/*
+ * save stores the rating for the message
+ */
+func (s *FeedbackService) save(
+	ctx context.Context,
+	message *models.Message,
+	source string,
+	userID *string,
+	req models.MessageFeedbackRequest,
+) (*models.MessageFeedback, error) {
+	feedback := &models.MessageFeedback{
+		MessageID: message.ID,
+		Source:    source,
+		SessionID: message.SessionID,
+		BotID:     message.BotID,
+		UserID:    userID,
+		Rating:    req.Rating,
+		Comment:   req.Comment,
+	}
+	if err := s.feedbackRepo.Upsert(ctx, feedback); err != nil {
+		return nil, err
+	}
+	return feedback, nil
+}
@@ -0,0 +1,236 @@
+package handoff
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/souravsspace/texly.chat/internal/models"
+	messageRepo "github.com/souravsspace/texly.chat/internal/repo/message"
+	"github.com/souravsspace/texly.chat/internal/services/session"
+)
+
+/*
+ * HandoffService moves widget conversations between the bot and human operators
+ */
+type HandoffService struct {
+	sessionService *session.SessionService
+	messageRepo    *messageRepo.MessageRepository // Optional: persists messages exchanged during handoff
+	hub            *Hub
+}
+
+/*
+ * NewHandoffService creates a new handoff service instance
+ */
+func NewHandoffService(sessionService *session.SessionService, messageRepo *messageRepo.MessageRepository) *HandoffService {
+	return &HandoffService{
+		sessionService: sessionService,
+		messageRepo:    messageRepo,
+		hub:            NewHub(),
+	}
+}
+
+/*
+ * Request hands a session to a human and notifies the bot's operators
+ * Requesting a handoff for a session already in handoff is a no-op
+ */
+func (s *HandoffService) Request(chatSession *models.ChatSession, reason string) error {
+	started, err := s.sessionService.StartHandoff(chatSession.ID, reason)
+	if err != nil {
+		return err
+	}
+	if !started {
+		return nil
+	}
+
+	event := models.HandoffEvent{
+		Type:      "handoff_requested",
+		SessionID: chatSession.ID,
+		BotID:     chatSession.BotID,
+		Reason:    reason,
+	}
+	s.hub.Publish(chatSession.BotID, event)
+	s.hub.Publish(chatSession.ID, event)
+	return nil
+}
+
+/*
+ * RecordVisitorMessage stores a visitor message sent during handoff and forwards it to operators
+ */
+func (s *HandoffService) RecordVisitorMessage(ctx context.Context, chatSession *models.ChatSession, content string) (*models.Message, error) {
+	message := &models.Message{
+		SessionID: chatSession.ID,
+		BotID:     chatSession.BotID,
+		Role:      "user",
+		Content:   content,
+	}
+	if err := s.saveMessage(ctx, message); err != nil {
+		return nil, err
+	}
+
+	s.hub.Publish(chatSession.BotID, models.HandoffEvent{
+		Type:      "visitor_message",
+		SessionID: chatSession.ID,
+		BotID:     chatSession.BotID,
+		Message:   message,
+	})
+	return message, nil
+}
+
+/*
+ * Reply stores an operator's reply and delivers it to the visitor
+ * Replying to a conversation the bot is still handling takes it over
+ */
+func (s *HandoffService) Reply(ctx context.Context, chatSession *models.ChatSession, operatorID string, content string) (*models.Message, error) {
+	if err := s.Request(chatSession, models.HandoffReasonOperator); err != nil {
+		return nil, err
+	}
+	if err := s.sessionService.ActivateHandoff(chatSession.ID); err != nil {
+		return nil, err
+	}
+
+	message := &models.Message{
+		SessionID: chatSession.ID,
+		BotID:     chatSession.BotID,
+		UserID:    &operatorID,
+		Role:      "operator",
+		Content:   content,
+	}
+	if err := s.saveMessage(ctx, message); err != nil {
+		return nil, err
+	}
+
+	event := models.HandoffEvent{
+		Type:      "operator_message",
+		SessionID: chatSession.ID,
+		BotID:     chatSession.BotID,
+		Message:   message,
+	}
+	s.hub.Publish(chatSession.ID, event)
+	s.hub.Publish(chatSession.BotID, event)
+	return message, nil
+}
+
+/*
+ * Close returns the conversation to the bot and notifies both sides
+ */
+func (s *HandoffService) Close(chatSession *models.ChatSession) error {
+	if err := s.sessionService.EndHandoff(chatSession.ID); err != nil {
+		return err
+	}
+
+	event := models.HandoffEvent{
+		Type:      "handoff_closed",
+		SessionID: chatSession.ID,
+		BotID:     chatSession.BotID,
+	}
+	s.hub.Publish(chatSession.ID, event)
+	s.hub.Publish(chatSession.BotID, event)
+	return nil
+}
+
+/*
+ * SetVisitorPresence tells operators that the visitor connected or disconnected
+ */
+func (s *HandoffService) SetVisitorPresence(chatSession *models.ChatSession, presence string) {
+	s.hub.Publish(chatSession.BotID, models.HandoffEvent{
+		Type:      "visitor_presence",
+		SessionID: chatSession.ID,
+		BotID:     chatSession.BotID,
+		Presence:  presence,
+	})
+}
+
+/*
+ * VisitorTyping tells operators whether the visitor is typing
+ */
+func (s *HandoffService) VisitorTyping(chatSession *models.ChatSession, typing bool) {
+	s.hub.Publish(chatSession.BotID, models.HandoffEvent{
+		Type:      "visitor_typing",
+		SessionID: chatSession.ID,
+		BotID:     chatSession.BotID,
+		Typing:    &typing,
+	})
+}
+
+/*
+ * OperatorTyping tells the visitor whether an operator is typing
+ */
+func (s *HandoffService) OperatorTyping(chatSession *models.ChatSession, typing bool) {
+	s.hub.Publish(chatSession.ID, models.HandoffEvent{
+		Type:      "operator_typing",
+		SessionID: chatSession.ID,
+		BotID:     chatSession.BotID,
+		Typing:    &typing,
+	})
+}
+
+/*
+ * OperatorsOnline reports whether any operator is watching the bot's conversations
+ */
+func (s *HandoffService) OperatorsOnline(botID string) bool {
+	return s.hub.Subscribers(botID) > 0
+}
+
+/*
+ * List returns the bot's conversations currently handled by a human
+ */
+func (s *HandoffService) List(botID string) []models.ChatSession {
+	return s.sessionService.ListHandoffs(botID)
+}
+
+/*
+ * SubscribeSession streams handoff events for a visitor's session
+ */
+func (s *HandoffService) SubscribeSession(sessionID string) (<-chan models.HandoffEvent, func()) {
+	return s.hub.Subscribe(sessionID)
+}
+
+/*
+ * SubscribeBot streams handoff events for all conversations of a bot
+ */
+func (s *HandoffService) SubscribeBot(botID string) (<-chan models.HandoffEvent, func()) {
+	return s.hub.Subscribe(botID)
+}
+
+/*
+ * saveMessage persists a handoff message when a message repository is configured
+ */
+func (s *HandoffService) saveMessage(ctx context.Context, message *models.Message) error {
+	if s.messageRepo == nil {
+		message.ID = uuid.New().String()
+		message.CreatedAt = time.Now()
+		return nil
+	}
+	if err := s.messageRepo.Create(ctx, message); err != nil {
+		return fmt.Errorf("failed to save handoff message: %w", err)
+	}
+	return nil
+}
+
+/*
+ * MatchesRule reports whether a message contains one of the bot's handoff keywords
+ */
+func MatchesRule(bot *models.Bot, content string) bool {
+	if bot.HandoffKeywords == "" {
+		return false
+	}
+
+	var keywords []string
+	if err := json.Unmarshal([]byte(bot.HandoffKeywords), &keywords); err != nil {
+		return false
+	}
+
+	content = strings.ToLower(content)
+	for _, keyword := range keywords {
+		keyword = strings.ToLower(strings.TrimSpace(keyword))
+		if keyword != "" && strings.Contains(content, keyword) {
+			return true
+		}
+	}
+	return false
+}
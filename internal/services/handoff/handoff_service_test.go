@@ -0,0 +1,137 @@
+package handoff
+
+import (
+	"context"
+	"testing"
+
+	"github.com/souravsspace/texly.chat/internal/models"
+	"github.com/souravsspace/texly.chat/internal/services/session"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+/*
+ * Test a handoff from request to operator reply to close notifies both sides
+ */
+func TestHandoffLifecycle(t *testing.T) {
+	sessionService := session.NewSessionService()
+	service := NewHandoffService(sessionService, nil)
+	chatSession := sessionService.CreateSession("bot-1")
+
+	visitorEvents, unsubscribeVisitor := service.SubscribeSession(chatSession.ID)
+	defer unsubscribeVisitor()
+	operatorEvents, unsubscribeOperator := service.SubscribeBot("bot-1")
+	defer unsubscribeOperator()
+
+	require.NoError(t, service.Request(chatSession, models.HandoffReasonVisitor))
+	assert.Equal(t, "handoff_requested", (<-operatorEvents).Type)
+	assert.Equal(t, "handoff_requested", (<-visitorEvents).Type)
+	assert.Len(t, service.List("bot-1"), 1)
+
+	// Visitor messages during handoff only reach operators
+	_, err := service.RecordVisitorMessage(context.Background(), chatSession, "Is anyone there?")
+	require.NoError(t, err)
+	event := <-operatorEvents
+	assert.Equal(t, "visitor_message", event.Type)
+	assert.Equal(t, "Is anyone there?", event.Message.Content)
+
+	reply, err := service.Reply(context.Background(), chatSession, "owner-1", "Hi, I'm here to help.")
+	require.NoError(t, err)
+	assert.Equal(t, "operator", reply.Role)
+	assert.NotEmpty(t, reply.ID)
+	assert.Equal(t, models.HandoffStatusActive, chatSession.HandoffStatus)
+	event = <-visitorEvents
+	assert.Equal(t, "operator_message", event.Type)
+	assert.Equal(t, "Hi, I'm here to help.", event.Message.Content)
+	assert.Equal(t, "operator_message", (<-operatorEvents).Type)
+
+	require.NoError(t, service.Close(chatSession))
+	assert.Equal(t, "handoff_closed", (<-visitorEvents).Type)
+	assert.False(t, chatSession.InHandoff())
+	assert.Empty(t, service.List("bot-1"))
+
+	assert.ErrorIs(t, service.Close(chatSession), session.ErrNotInHandoff)
+}
+
+/*
+ * Test an operator reply takes over a conversation the bot is handling
+ */
+func TestReply_TakesOver(t *testing.T) {
+	sessionService := session.NewSessionService()
+	service := NewHandoffService(sessionService, nil)
+	chatSession := sessionService.CreateSession("bot-1")
+
+	_, err := service.Reply(context.Background(), chatSession, "owner-1", "Let me help you with that.")
+	require.NoError(t, err)
+	assert.Equal(t, models.HandoffStatusActive, chatSession.HandoffStatus)
+	assert.Equal(t, models.HandoffReasonOperator, chatSession.HandoffReason)
+}
+
+/*
+ * Test MatchesRule checks the bot's handoff keywords case-insensitively
+ */
+/*
+ * Test presence and typing events reach the other side only
+ */
+func TestPresenceAndTyping(t *testing.T) {
+	sessionService := session.NewSessionService()
+	service := NewHandoffService(sessionService, nil)
+	chatSession := sessionService.CreateSession("bot-1")
+
+	assert.False(t, service.OperatorsOnline("bot-1"))
+
+	visitorEvents, unsubscribeVisitor := service.SubscribeSession(chatSession.ID)
+	defer unsubscribeVisitor()
+	operatorEvents, unsubscribeOperator := service.SubscribeBot("bot-1")
+	defer unsubscribeOperator()
+
+	assert.True(t, service.OperatorsOnline("bot-1"))
+
+	service.SetVisitorPresence(chatSession, models.PresenceOnline)
+	event := <-operatorEvents
+	assert.Equal(t, "visitor_presence", event.Type)
+	assert.Equal(t, models.PresenceOnline, event.Presence)
+
+	service.VisitorTyping(chatSession, true)
+	event = <-operatorEvents
+	assert.Equal(t, "visitor_typing", event.Type)
+	require.NotNil(t, event.Typing)
+	assert.True(t, *event.Typing)
+
+	service.OperatorTyping(chatSession, false)
+	event = <-visitorEvents
+	assert.Equal(t, "operator_typing", event.Type)
+	require.NotNil(t, event.Typing)
+	assert.False(t, *event.Typing)
+
+	// Nothing is echoed back to the sender
+	assert.Empty(t, visitorEvents)
+	assert.Empty(t, operatorEvents)
+}
+
+func TestMatchesRule(t *testing.T) {
+	bot := &models.Bot{HandoffKeywords: `["talk to a human","refund"]`}
+
+	assert.True(t, MatchesRule(bot, "Can I TALK TO A HUMAN please?"))
+	assert.True(t, MatchesRule(bot, "I want a refund"))
+	assert.False(t, MatchesRule(bot, "What are your opening hours?"))
+	assert.False(t, MatchesRule(&models.Bot{}, "talk to a human"))
+	assert.False(t, MatchesRule(&models.Bot{HandoffKeywords: `not json`}, "talk to a human"))
+}
+
+/*
+ * Test Hub drops events for slow subscribers instead of blocking
+ */
+func TestHub_SlowSubscriber(t *testing.T) {
+	hub := NewHub()
+	events, unsubscribe := hub.Subscribe("topic")
+
+	for i := 0; i < subscriberBuffer+5; i++ {
+		hub.Publish("topic", models.HandoffEvent{Type: "visitor_message"})
+	}
+	assert.Len(t, events, subscriberBuffer)
+
+	unsubscribe()
+	unsubscribe()
+	hub.Publish("topic", models.HandoffEvent{Type: "visitor_message"})
+}
@@ -0,0 +1,86 @@
+package handoff
+
+import (
+	"sync"
+
+	"github.com/souravsspace/texly.chat/internal/models"
+)
+
+/*
+ * subscriberBuffer is how many events a slow subscriber may lag behind before events are dropped
+ */
+const subscriberBuffer = 16
+
+/*
+ * Hub fans handoff events out to in-process subscribers, keyed by topic
+ * Topics are a session ID (the visitor) or a bot ID (its operators)
+ */
+type Hub struct {
+	subscribers map[string]map[chan models.HandoffEvent]struct{}
+	mu          sync.RWMutex
+}
+
+/*
+ * NewHub creates an empty event hub
+ */
+func NewHub() *Hub {
+	return &Hub{
+		subscribers: make(map[string]map[chan models.HandoffEvent]struct{}),
+	}
+}
+
+/*
+ * Subscribe registers a listener for a topic
+ * The returned function unsubscribes and closes the channel
+ */
+func (h *Hub) Subscribe(topic string) (<-chan models.HandoffEvent, func()) {
+	ch := make(chan models.HandoffEvent, subscriberBuffer)
+
+	h.mu.Lock()
+	if h.subscribers[topic] == nil {
+		h.subscribers[topic] = make(map[chan models.HandoffEvent]struct{})
+	}
+	h.subscribers[topic][ch] = struct{}{}
+	h.mu.Unlock()
+
+	var once sync.Once
+	unsubscribe := func() {
+		once.Do(func() {
+			h.mu.Lock()
+			delete(h.subscribers[topic], ch)
+			if len(h.subscribers[topic]) == 0 {
+				delete(h.subscribers, topic)
+			}
+			h.mu.Unlock()
+			close(ch)
+		})
+	}
+
+	return ch, unsubscribe
+}
+
+/*
+ * Publish delivers an event to every subscriber of a topic without blocking
+ * Subscribers whose buffer is full miss the event
+ */
+func (h *Hub) Publish(topic string, event models.HandoffEvent) {
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+
+	for ch := range h.subscribers[topic] {
+		select {
+		case ch <- event:
+		default:
+		}
+	}
+}
+
+/*
+ * Subscribers returns how many listeners a topic has
+ */
+func (h *Hub) Subscribers(topic string) int {
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+
+	return len(h.subscribers[topic])
+}
@@ -0,0 +1,42 @@
+package llm
+
+import "strings"
+
+/*
+ * DefaultContextWindow is assumed for models missing from contextWindows
+ */
+const DefaultContextWindow = 128000
+
+/*
+ * contextWindows maps model name prefixes to their context window in tokens
+ * Longer prefixes win, so dated snapshots (e.g. gpt-4o-2024-08-06) resolve to their family
+ */
+var contextWindows = map[string]int{
+	"gpt-4o":        128000,
+	"gpt-4o-mini":   128000,
+	"gpt-4.1":       1047576,
+	"gpt-4.1-mini":  1047576,
+	"gpt-4.1-nano":  1047576,
+	"gpt-4-turbo":   128000,
+	"gpt-4":         8192,
+	"gpt-3.5-turbo": 16385,
+	"o1":            200000,
+	"o3":            200000,
+	"o4-mini":       200000,
+	"claude":        200000,
+}
+
+/*
+ * ContextWindow returns the context window of a model in tokens
+ */
+func ContextWindow(model string) int {
+	bestPrefix := ""
+	window := DefaultContextWindow
+	for prefix, size := range contextWindows {
+		if strings.HasPrefix(model, prefix) && len(prefix) > len(bestPrefix) {
+			bestPrefix = prefix
+			window = size
+		}
+	}
+	return window
+}
@@ -0,0 +1,92 @@
+package llm
+
+import (
+	"context"
+	"strings"
+	"sync"
+)
+
+/*
+ * FakeProvider is an in-process ChatProvider for tests and offline tooling
+ * It replays canned responses in order (the last one repeats) and records every request
+ */
+type FakeProvider struct {
+	Responses []string
+	ToolCalls [][]ToolCall // Optional: tool calls returned by the call at the same index
+	Err       error        // Returned instead of a completion when set
+	Usage     *Usage       // Optional: usage reported with every completion
+
+	mu       sync.Mutex
+	requests []CompletionRequest
+}
+
+/*
+ * NewFakeProvider creates a fake provider that answers with the given responses
+ */
+func NewFakeProvider(responses ...string) *FakeProvider {
+	return &FakeProvider{Responses: responses}
+}
+
+/*
+ * StreamCompletion streams the next canned response word by word
+ */
+func (f *FakeProvider) StreamCompletion(
+	ctx context.Context,
+	req CompletionRequest,
+	onToken func(token string) error,
+) (*Completion, error) {
+	f.mu.Lock()
+	f.requests = append(f.requests, req)
+	callIndex := len(f.requests) - 1
+	f.mu.Unlock()
+
+	if f.Err != nil {
+		return nil, f.Err
+	}
+
+	response := ""
+	if len(f.Responses) > 0 {
+		response = f.Responses[min(callIndex, len(f.Responses)-1)]
+	}
+
+	if onToken != nil {
+		for _, token := range strings.SplitAfter(response, " ") {
+			if token == "" {
+				continue
+			}
+			if err := ctx.Err(); err != nil {
+				return nil, err
+			}
+			if err := onToken(token); err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	completion := &Completion{Content: response, Usage: f.Usage}
+	if callIndex < len(f.ToolCalls) {
+		completion.ToolCalls = f.ToolCalls[callIndex]
+	}
+	return completion, nil
+}
+
+/*
+ * Requests returns a copy of every request received so far
+ */
+func (f *FakeProvider) Requests() []CompletionRequest {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return append([]CompletionRequest(nil), f.requests...)
+}
+
+/*
+ * LastRequest returns the most recent request, or an empty request if none was made
+ */
+func (f *FakeProvider) LastRequest() CompletionRequest {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if len(f.requests) == 0 {
+		return CompletionRequest{}
+	}
+	return f.requests[len(f.requests)-1]
+}
@@ -0,0 +1,213 @@
+package llm
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/openai/openai-go/v3"
+	"github.com/openai/openai-go/v3/option"
+	"github.com/openai/openai-go/v3/shared"
+)
+
+/*
+ * CompatibleConfig configures a provider that speaks the OpenAI Chat Completions protocol
+ * This covers Azure OpenAI, Anthropic's compatibility endpoint, Ollama, vLLM and similar servers
+ */
+type CompatibleConfig struct {
+	BaseURL      string // e.g. "http://localhost:11434/v1"
+	APIKey       string
+	APIKeyHeader string // Optional header carrying the key instead of "Authorization: Bearer" (Azure uses "api-key")
+	DefaultModel string // Model used when a request does not specify one
+
+	// Not every server accepts the newer request fields
+	LegacyMaxTokens bool // Send "max_tokens" instead of "max_completion_tokens"
+	NoStreamUsage   bool // Don't send "stream_options.include_usage"; usage is then estimated locally
+}
+
+/*
+ * OpenAIProvider implements ChatProvider using the official OpenAI SDK
+ */
+type OpenAIProvider struct {
+	client          openai.Client
+	defaultModel    string
+	legacyMaxTokens bool
+	noStreamUsage   bool
+}
+
+/*
+ * NewOpenAIProvider creates a provider for the OpenAI API
+ */
+func NewOpenAIProvider(apiKey string, defaultModel string) *OpenAIProvider {
+	return &OpenAIProvider{
+		client:       openai.NewClient(option.WithAPIKey(apiKey)),
+		defaultModel: defaultModel,
+	}
+}
+
+/*
+ * NewOpenAICompatibleProvider creates a provider for any OpenAI-compatible base URL
+ */
+func NewOpenAICompatibleProvider(cfg CompatibleConfig) *OpenAIProvider {
+	opts := []option.RequestOption{
+		option.WithBaseURL(cfg.BaseURL),
+	}
+	if cfg.APIKeyHeader != "" {
+		opts = append(opts, option.WithHeader(cfg.APIKeyHeader, cfg.APIKey))
+	} else {
+		opts = append(opts, option.WithAPIKey(cfg.APIKey))
+	}
+
+	return &OpenAIProvider{
+		client:          openai.NewClient(opts...),
+		defaultModel:    cfg.DefaultModel,
+		legacyMaxTokens: cfg.LegacyMaxTokens,
+		noStreamUsage:   cfg.NoStreamUsage,
+	}
+}
+
+/*
+ * StreamCompletion streams a chat completion and returns the aggregated response
+ */
+func (p *OpenAIProvider) StreamCompletion(
+	ctx context.Context,
+	req CompletionRequest,
+	onToken func(token string) error,
+) (*Completion, error) {
+	model := req.Model
+	if model == "" {
+		model = p.defaultModel
+	}
+
+	params := openai.ChatCompletionNewParams{
+		Messages: toOpenAIMessages(req.Messages),
+		Model:    openai.ChatModel(model),
+	}
+	if !p.noStreamUsage {
+		// Ask for the real token counts in a final chunk
+		params.StreamOptions = openai.ChatCompletionStreamOptionsParam{
+			IncludeUsage: openai.Bool(true),
+		}
+	}
+	if req.Temperature != nil {
+		params.Temperature = openai.Float(*req.Temperature)
+	}
+	if req.MaxTokens > 0 {
+		if p.legacyMaxTokens {
+			params.MaxTokens = openai.Int(int64(req.MaxTokens))
+		} else {
+			params.MaxCompletionTokens = openai.Int(int64(req.MaxTokens))
+		}
+	}
+	if len(req.Tools) > 0 {
+		params.Tools = toOpenAITools(req.Tools)
+	}
+
+	stream := p.client.Chat.Completions.NewStreaming(ctx, params)
+	defer stream.Close()
+
+	// The accumulator reassembles tool call arguments split across chunks
+	acc := openai.ChatCompletionAccumulator{}
+	var content strings.Builder
+	var usage *Usage
+	for stream.Next() {
+		chunk := stream.Current()
+		acc.AddChunk(chunk)
+
+		// The usage chunk comes last and has no choices
+		if chunk.Usage.TotalTokens > 0 {
+			usage = &Usage{
+				PromptTokens:     int(chunk.Usage.PromptTokens),
+				CompletionTokens: int(chunk.Usage.CompletionTokens),
+			}
+		}
+
+		// Extract content delta from the first choice
+		if len(chunk.Choices) == 0 || chunk.Choices[0].Delta.Content == "" {
+			continue
+		}
+
+		token := chunk.Choices[0].Delta.Content
+		content.WriteString(token)
+
+		if onToken != nil {
+			if err := onToken(token); err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	if err := stream.Err(); err != nil {
+		return nil, fmt.Errorf("streaming error: %w", err)
+	}
+
+	completion := &Completion{Content: content.String(), Usage: usage}
+	if len(acc.Choices) > 0 {
+		for _, call := range acc.Choices[0].Message.ToolCalls {
+			completion.ToolCalls = append(completion.ToolCalls, ToolCall{
+				ID:        call.ID,
+				Name:      call.Function.Name,
+				Arguments: call.Function.Arguments,
+			})
+		}
+	}
+
+	return completion, nil
+}
+
+/*
+ * toOpenAITools converts provider-neutral tools to SDK function tools
+ */
+func toOpenAITools(tools []Tool) []openai.ChatCompletionToolUnionParam {
+	params := make([]openai.ChatCompletionToolUnionParam, 0, len(tools))
+	for _, tool := range tools {
+		function := shared.FunctionDefinitionParam{
+			Name:       tool.Name,
+			Parameters: shared.FunctionParameters(tool.Parameters),
+		}
+		if tool.Description != "" {
+			function.Description = openai.String(tool.Description)
+		}
+		params = append(params, openai.ChatCompletionFunctionTool(function))
+	}
+	return params
+}
+
+/*
+ * toOpenAIMessages converts provider-neutral messages to SDK message params
+ */
+func toOpenAIMessages(messages []Message) []openai.ChatCompletionMessageParamUnion {
+	params := make([]openai.ChatCompletionMessageParamUnion, 0, len(messages))
+	for _, msg := range messages {
+		switch msg.Role {
+		case RoleSystem:
+			params = append(params, openai.SystemMessage(msg.Content))
+		case RoleAssistant:
+			if len(msg.ToolCalls) == 0 {
+				params = append(params, openai.AssistantMessage(msg.Content))
+				continue
+			}
+			assistant := openai.ChatCompletionAssistantMessageParam{}
+			if msg.Content != "" {
+				assistant.Content.OfString = openai.String(msg.Content)
+			}
+			for _, call := range msg.ToolCalls {
+				assistant.ToolCalls = append(assistant.ToolCalls, openai.ChatCompletionMessageToolCallUnionParam{
+					OfFunction: &openai.ChatCompletionMessageFunctionToolCallParam{
+						ID: call.ID,
+						Function: openai.ChatCompletionMessageFunctionToolCallFunctionParam{
+							Name:      call.Name,
+							Arguments: call.Arguments,
+						},
+					},
+				})
+			}
+			params = append(params, openai.ChatCompletionMessageParamUnion{OfAssistant: &assistant})
+		case RoleTool:
+			params = append(params, openai.ToolMessage(msg.Content, msg.ToolCallID))
+		default:
+			params = append(params, openai.UserMessage(msg.Content))
+		}
+	}
+	return params
+}
@@ -0,0 +1,176 @@
+package llm
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"sync"
+)
+
+/*
+ * Message roles understood by every provider
+ */
+const (
+	RoleSystem    = "system"
+	RoleUser      = "user"
+	RoleAssistant = "assistant"
+	RoleTool      = "tool"
+)
+
+/*
+ * DefaultProvider is the provider name used when a bot does not select one
+ */
+const DefaultProvider = "openai"
+
+/*
+ * Names of the optional providers wired from configuration
+ */
+const (
+	ProviderOpenAICompatible = "openai_compatible"
+	ProviderAzure            = "azure"
+	ProviderAnthropic        = "anthropic"
+)
+
+var ErrUnknownProvider = errors.New("unknown chat provider")
+
+/*
+ * Message is a provider-neutral chat message
+ */
+type Message struct {
+	Role       string
+	Content    string
+	ToolCalls  []ToolCall // Tools requested by an assistant message
+	ToolCallID string     // Call answered by a tool message
+}
+
+/*
+ * Tool is a function the model may call, described by a JSON schema
+ */
+type Tool struct {
+	Name        string
+	Description string
+	Parameters  map[string]any
+}
+
+/*
+ * ToolCall is a function invocation requested by the model
+ */
+type ToolCall struct {
+	ID        string
+	Name      string
+	Arguments string // JSON-encoded arguments
+}
+
+/*
+ * CompletionRequest describes a single chat completion call
+ */
+type CompletionRequest struct {
+	Model       string // Empty = provider default model
+	Messages    []Message
+	Tools       []Tool
+	Temperature *float64 // Nil = provider default (some models reject custom values)
+	MaxTokens   int      // 0 = provider default
+}
+
+/*
+ * Completion is the aggregated result of a streamed completion
+ * When ToolCalls is non-empty the model is waiting for tool results
+ */
+type Completion struct {
+	Content   string
+	ToolCalls []ToolCall
+	Usage     *Usage // Nil when the provider does not report token usage
+}
+
+/*
+ * Usage is the number of tokens a provider billed for a completion
+ */
+type Usage struct {
+	PromptTokens     int
+	CompletionTokens int
+}
+
+/*
+ * Add accumulates the usage of another completion (e.g. a tool call round)
+ */
+func (u *Usage) Add(other Usage) {
+	u.PromptTokens += other.PromptTokens
+	u.CompletionTokens += other.CompletionTokens
+}
+
+/*
+ * ChatProvider streams chat completions from an LLM backend
+ * onToken is invoked for every content delta and may be nil when streaming is not needed
+ * Returning an error from onToken aborts the stream
+ */
+type ChatProvider interface {
+	StreamCompletion(ctx context.Context, req CompletionRequest, onToken func(token string) error) (*Completion, error)
+}
+
+/*
+ * Complete runs a completion without streaming tokens to a caller
+ */
+func Complete(ctx context.Context, provider ChatProvider, req CompletionRequest) (*Completion, error) {
+	return provider.StreamCompletion(ctx, req, nil)
+}
+
+/*
+ * Registry holds the chat providers available to bots, keyed by name
+ */
+type Registry struct {
+	providers map[string]ChatProvider
+	mu        sync.RWMutex
+}
+
+/*
+ * NewRegistry creates an empty provider registry
+ */
+func NewRegistry() *Registry {
+	return &Registry{
+		providers: make(map[string]ChatProvider),
+	}
+}
+
+/*
+ * Register adds or replaces a provider under the given name
+ */
+func (r *Registry) Register(name string, provider ChatProvider) {
+	r.mu.Lock()
+	r.providers[name] = provider
+	r.mu.Unlock()
+}
+
+/*
+ * Get returns the provider registered under name
+ * An empty name resolves to DefaultProvider
+ */
+func (r *Registry) Get(name string) (ChatProvider, error) {
+	if name == "" {
+		name = DefaultProvider
+	}
+
+	r.mu.RLock()
+	provider, exists := r.providers[name]
+	r.mu.RUnlock()
+
+	if !exists {
+		return nil, fmt.Errorf("%w: %s", ErrUnknownProvider, name)
+	}
+	return provider, nil
+}
+
+/*
+ * Names returns the registered provider names in sorted order
+ */
+func (r *Registry) Names() []string {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	names := make([]string, 0, len(r.providers))
+	for name := range r.providers {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
@@ -0,0 +1,178 @@
+package llm
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+/*
+ * Test Registry resolves names and falls back to the default provider
+ */
+func TestRegistry_Get(t *testing.T) {
+	registry := NewRegistry()
+	defaultProvider := NewFakeProvider("default")
+	localProvider := NewFakeProvider("local")
+
+	registry.Register(DefaultProvider, defaultProvider)
+	registry.Register(ProviderOpenAICompatible, localProvider)
+
+	provider, err := registry.Get("")
+	require.NoError(t, err)
+	assert.Same(t, defaultProvider, provider)
+
+	provider, err = registry.Get(ProviderOpenAICompatible)
+	require.NoError(t, err)
+	assert.Same(t, localProvider, provider)
+
+	_, err = registry.Get("missing")
+	assert.ErrorIs(t, err, ErrUnknownProvider)
+
+	assert.Equal(t, []string{DefaultProvider, ProviderOpenAICompatible}, registry.Names())
+}
+
+/*
+ * Test FakeProvider streams canned responses and records requests
+ */
+func TestFakeProvider_StreamCompletion(t *testing.T) {
+	fake := NewFakeProvider("first answer", "second answer")
+
+	var tokens []string
+	completion, err := fake.StreamCompletion(context.Background(), CompletionRequest{Model: "test"}, func(token string) error {
+		tokens = append(tokens, token)
+		return nil
+	})
+	require.NoError(t, err)
+	assert.Equal(t, "first answer", completion.Content)
+	assert.Equal(t, []string{"first ", "answer"}, tokens)
+
+	// Subsequent calls advance, and the last response repeats
+	completion, err = Complete(context.Background(), fake, CompletionRequest{})
+	require.NoError(t, err)
+	assert.Equal(t, "second answer", completion.Content)
+
+	completion, err = Complete(context.Background(), fake, CompletionRequest{})
+	require.NoError(t, err)
+	assert.Equal(t, "second answer", completion.Content)
+
+	assert.Len(t, fake.Requests(), 3)
+	assert.Equal(t, "test", fake.Requests()[0].Model)
+}
+
+/*
+ * Test FakeProvider surfaces configured errors and aborts on callback errors
+ */
+func TestFakeProvider_Errors(t *testing.T) {
+	fake := NewFakeProvider("unused")
+	fake.Err = errors.New("provider down")
+
+	_, err := Complete(context.Background(), fake, CompletionRequest{})
+	assert.EqualError(t, err, "provider down")
+
+	stop := errors.New("stop")
+	fake = NewFakeProvider("one two three")
+	_, err = fake.StreamCompletion(context.Background(), CompletionRequest{}, func(token string) error {
+		return stop
+	})
+	assert.ErrorIs(t, err, stop)
+}
+
+/*
+ * Test the OpenAI-compatible provider streams from a custom base URL
+ */
+func TestOpenAICompatibleProvider_StreamCompletion(t *testing.T) {
+	var apiKeyHeader, authHeader string
+	var body map[string]any
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		apiKeyHeader = r.Header.Get("api-key")
+		authHeader = r.Header.Get("Authorization")
+		json.NewDecoder(r.Body).Decode(&body)
+
+		w.Header().Set("Content-Type", "text/event-stream")
+		w.WriteHeader(http.StatusOK)
+		w.Write([]byte(`data: {"id":"1","object":"chat.completion.chunk","created":1,"model":"local","choices":[{"index":0,"delta":{"content":"Hello"}}]}` + "\n\n"))
+		w.Write([]byte(`data: {"id":"1","object":"chat.completion.chunk","created":1,"model":"local","choices":[{"index":0,"delta":{"content":" world"}}]}` + "\n\n"))
+		w.Write([]byte(`data: {"id":"1","object":"chat.completion.chunk","created":1,"model":"local","choices":[],"usage":{"prompt_tokens":9,"completion_tokens":2,"total_tokens":11}}` + "\n\n"))
+		w.Write([]byte("data: [DONE]\n\n"))
+	}))
+	defer server.Close()
+
+	provider := NewOpenAICompatibleProvider(CompatibleConfig{
+		BaseURL:      server.URL,
+		APIKey:       "secret",
+		APIKeyHeader: "api-key",
+		DefaultModel: "local",
+	})
+
+	var tokens []string
+	completion, err := provider.StreamCompletion(context.Background(), CompletionRequest{
+		Messages: []Message{{Role: RoleUser, Content: "Hi"}},
+	}, func(token string) error {
+		tokens = append(tokens, token)
+		return nil
+	})
+
+	require.NoError(t, err)
+	assert.Equal(t, "Hello world", completion.Content)
+	assert.Equal(t, []string{"Hello", " world"}, tokens)
+	assert.Equal(t, "secret", apiKeyHeader)
+	assert.Empty(t, authHeader)
+
+	// Usage is requested and read from the final chunk
+	assert.Equal(t, map[string]any{"include_usage": true}, body["stream_options"])
+	require.NotNil(t, completion.Usage)
+	assert.Equal(t, Usage{PromptTokens: 9, CompletionTokens: 2}, *completion.Usage)
+}
+
+/*
+ * Test servers limited to the older request fields get max_tokens and no stream options
+ */
+func TestOpenAICompatibleProvider_LegacyFields(t *testing.T) {
+	var body map[string]any
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		json.NewDecoder(r.Body).Decode(&body)
+
+		w.Header().Set("Content-Type", "text/event-stream")
+		w.WriteHeader(http.StatusOK)
+		w.Write([]byte(`data: {"id":"1","object":"chat.completion.chunk","created":1,"model":"local","choices":[{"index":0,"delta":{"content":"Hi"}}]}` + "\n\n"))
+		w.Write([]byte("data: [DONE]\n\n"))
+	}))
+	defer server.Close()
+
+	provider := NewOpenAICompatibleProvider(CompatibleConfig{
+		BaseURL:         server.URL,
+		DefaultModel:    "local",
+		LegacyMaxTokens: true,
+		NoStreamUsage:   true,
+	})
+
+	completion, err := provider.StreamCompletion(context.Background(), CompletionRequest{
+		Messages:  []Message{{Role: RoleUser, Content: "Hi"}},
+		MaxTokens: 100,
+	}, nil)
+	require.NoError(t, err)
+	assert.Equal(t, "Hi", completion.Content)
+	assert.Nil(t, completion.Usage)
+
+	assert.Equal(t, float64(100), body["max_tokens"])
+	assert.NotContains(t, body, "max_completion_tokens")
+	assert.NotContains(t, body, "stream_options")
+}
+
+/*
+ * Test ContextWindow resolves models by their longest known prefix
+ */
+func TestContextWindow(t *testing.T) {
+	assert.Equal(t, 128000, ContextWindow("gpt-4o-mini"))
+	assert.Equal(t, 128000, ContextWindow("gpt-4o-2024-08-06"))
+	assert.Equal(t, 1047576, ContextWindow("gpt-4.1-mini"))
+	assert.Equal(t, 8192, ContextWindow("gpt-4"))
+	assert.Equal(t, 200000, ContextWindow("claude-sonnet-4-5"))
+	assert.Equal(t, DefaultContextWindow, ContextWindow("llama3"))
+}
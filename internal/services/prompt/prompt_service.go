@@ -0,0 +1,333 @@
+package prompt
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"hash/fnv"
+	"strings"
+
+	"github.com/souravsspace/texly.chat/internal/models"
+	botRepo "github.com/souravsspace/texly.chat/internal/repo/bot"
+	promptRepo "github.com/souravsspace/texly.chat/internal/repo/prompt"
+)
+
+var (
+	ErrVersionNotFound    = errors.New("prompt version not found")
+	ErrExperimentNotFound = errors.New("prompt experiment not found")
+	ErrExperimentRunning  = errors.New("an experiment is already running for this bot")
+	ErrExperimentStopped  = errors.New("experiment is not running")
+	ErrInvalidVariants    = errors.New("invalid experiment variants")
+)
+
+/*
+ * PromptService keeps the history of bot system prompts and assigns sessions to experiment variants
+ */
+type PromptService struct {
+	repo    *promptRepo.PromptRepository
+	botRepo *botRepo.BotRepo
+}
+
+/*
+ * NewPromptService creates a new prompt service instance
+ */
+func NewPromptService(repo *promptRepo.PromptRepository, botRepo *botRepo.BotRepo) *PromptService {
+	return &PromptService{
+		repo:    repo,
+		botRepo: botRepo,
+	}
+}
+
+/*
+ * RecordVersion saves the bot's current system prompt as its newest version
+ * Sets bot.PromptVersionID; the caller saves the bot
+ */
+func (s *PromptService) RecordVersion(ctx context.Context, bot *models.Bot, userID string, note string) (*models.PromptVersion, error) {
+	return s.record(ctx, bot, &models.PromptVersion{Note: note}, userID)
+}
+
+/*
+ * record saves version with the bot's current system prompt and makes it current
+ */
+func (s *PromptService) record(ctx context.Context, bot *models.Bot, version *models.PromptVersion, userID string) (*models.PromptVersion, error) {
+	version.BotID = bot.ID
+	version.SystemPrompt = bot.SystemPrompt
+	if userID != "" {
+		version.UserID = &userID
+	}
+	if err := s.repo.CreateVersion(ctx, version); err != nil {
+		return nil, err
+	}
+
+	bot.PromptVersionID = version.ID
+	return version, nil
+}
+
+/*
+ * RecordChange replaces the bot's system prompt and records the new version
+ * Bots created before versioning first get their existing prompt recorded, so it can be restored
+ * The caller saves the bot
+ */
+func (s *PromptService) RecordChange(ctx context.Context, bot *models.Bot, systemPrompt string, userID string, note string) (*models.PromptVersion, error) {
+	if bot.PromptVersionID == "" {
+		if _, err := s.RecordVersion(ctx, bot, "", "Initial prompt"); err != nil {
+			return nil, err
+		}
+	}
+
+	bot.SystemPrompt = systemPrompt
+	return s.RecordVersion(ctx, bot, userID, note)
+}
+
+/*
+ * ListVersions returns the prompt history of a bot, newest first
+ */
+func (s *PromptService) ListVersions(ctx context.Context, botID string) ([]models.PromptVersion, error) {
+	return s.repo.ListVersions(ctx, botID)
+}
+
+/*
+ * Diff compares two prompt versions of a bot line by line
+ */
+func (s *PromptService) Diff(ctx context.Context, botID string, fromID string, toID string) (*models.PromptDiff, error) {
+	from, err := s.version(ctx, fromID, botID)
+	if err != nil {
+		return nil, err
+	}
+	to, err := s.version(ctx, toID, botID)
+	if err != nil {
+		return nil, err
+	}
+
+	return &models.PromptDiff{
+		From:  *from,
+		To:    *to,
+		Lines: DiffLines(from.SystemPrompt, to.SystemPrompt),
+	}, nil
+}
+
+/*
+ * Rollback makes an earlier version's prompt current again
+ * The restore is recorded as a new version, so the history is never rewritten
+ */
+func (s *PromptService) Rollback(ctx context.Context, bot *models.Bot, versionID string, userID string) (*models.PromptVersion, error) {
+	target, err := s.version(ctx, versionID, bot.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	bot.SystemPrompt = target.SystemPrompt
+	version, err := s.record(ctx, bot, &models.PromptVersion{
+		Note:         fmt.Sprintf("Rollback to version %d", target.Version),
+		RolledBackTo: &target.Version,
+	}, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.botRepo.Update(bot); err != nil {
+		return nil, fmt.Errorf("failed to update bot: %w", err)
+	}
+	return version, nil
+}
+
+/*
+ * version loads a prompt version of a bot or returns ErrVersionNotFound
+ */
+func (s *PromptService) version(ctx context.Context, id string, botID string) (*models.PromptVersion, error) {
+	version, err := s.repo.GetVersion(ctx, id, botID)
+	if err != nil {
+		return nil, err
+	}
+	if version == nil {
+		return nil, ErrVersionNotFound
+	}
+	return version, nil
+}
+
+/*
+ * CreateExperiment starts splitting a bot's widget sessions across prompt versions
+ * Only one experiment may run per bot at a time
+ */
+func (s *PromptService) CreateExperiment(ctx context.Context, botID string, req models.CreatePromptExperimentRequest) (*models.PromptExperiment, error) {
+	if err := s.validateVariants(ctx, botID, req.Variants); err != nil {
+		return nil, err
+	}
+
+	running, err := s.repo.GetRunningExperiment(ctx, botID)
+	if err != nil {
+		return nil, err
+	}
+	if running != nil {
+		return nil, ErrExperimentRunning
+	}
+
+	variantsJSON, err := json.Marshal(req.Variants)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidVariants, err)
+	}
+
+	experiment := &models.PromptExperiment{
+		BotID:    botID,
+		Name:     strings.TrimSpace(req.Name),
+		Status:   models.ExperimentStatusRunning,
+		Variants: string(variantsJSON),
+	}
+	if err := s.repo.CreateExperiment(ctx, experiment); err != nil {
+		return nil, err
+	}
+	return experiment, nil
+}
+
+/*
+ * validateVariants checks each variant points at a version of the bot and the shares fit in 100%
+ */
+func (s *PromptService) validateVariants(ctx context.Context, botID string, variants []models.PromptVariant) error {
+	if len(variants) == 0 {
+		return fmt.Errorf("%w: at least one variant is required", ErrInvalidVariants)
+	}
+
+	total := 0
+	seen := make(map[string]bool, len(variants))
+	for _, variant := range variants {
+		if variant.Percent < 1 || variant.Percent > 100 {
+			return fmt.Errorf("%w: percent must be between 1 and 100", ErrInvalidVariants)
+		}
+		if seen[variant.PromptVersionID] {
+			return fmt.Errorf("%w: version %s is used twice", ErrInvalidVariants, variant.PromptVersionID)
+		}
+		seen[variant.PromptVersionID] = true
+		total += variant.Percent
+
+		version, err := s.repo.GetVersion(ctx, variant.PromptVersionID, botID)
+		if err != nil {
+			return err
+		}
+		if version == nil {
+			return fmt.Errorf("%w: version %s not found", ErrInvalidVariants, variant.PromptVersionID)
+		}
+	}
+	if total > 100 {
+		return fmt.Errorf("%w: percentages add up to %d", ErrInvalidVariants, total)
+	}
+	return nil
+}
+
+/*
+ * ListExperiments returns the prompt experiments of a bot, newest first
+ */
+func (s *PromptService) ListExperiments(ctx context.Context, botID string) ([]models.PromptExperiment, error) {
+	return s.repo.ListExperiments(ctx, botID)
+}
+
+/*
+ * StopExperiment ends an experiment; new answers use the bot's current prompt again
+ */
+func (s *PromptService) StopExperiment(ctx context.Context, botID string, experimentID string) (*models.PromptExperiment, error) {
+	experiment, err := s.repo.GetExperiment(ctx, experimentID, botID)
+	if err != nil {
+		return nil, err
+	}
+	if experiment == nil {
+		return nil, ErrExperimentNotFound
+	}
+	if experiment.Status != models.ExperimentStatusRunning {
+		return nil, ErrExperimentStopped
+	}
+
+	if err := s.repo.StopExperiment(ctx, experiment); err != nil {
+		return nil, err
+	}
+	return experiment, nil
+}
+
+/*
+ * ResolveForSession returns the prompt version a widget session is assigned to by the bot's
+ * running experiment. Sessions always land in the same variant
+ * Returns nil when no experiment runs or the session falls outside every variant
+ */
+func (s *PromptService) ResolveForSession(ctx context.Context, botID string, sessionID string) (*models.PromptVersion, error) {
+	experiment, err := s.repo.GetRunningExperiment(ctx, botID)
+	if err != nil || experiment == nil {
+		return nil, err
+	}
+
+	var variants []models.PromptVariant
+	if err := json.Unmarshal([]byte(experiment.Variants), &variants); err != nil {
+		return nil, fmt.Errorf("failed to parse experiment variants: %w", err)
+	}
+
+	versionID := AssignVariant(experiment.ID, sessionID, variants)
+	if versionID == "" {
+		return nil, nil
+	}
+	return s.repo.GetVersion(ctx, versionID, botID)
+}
+
+/*
+ * AssignVariant hashes a session into one of 100 buckets and returns the prompt version
+ * whose share covers the bucket, or "" for the remaining sessions
+ * Hashing with the experiment ID reshuffles sessions between experiments
+ */
+func AssignVariant(experimentID string, sessionID string, variants []models.PromptVariant) string {
+	hash := fnv.New32a()
+	hash.Write([]byte(experimentID + ":" + sessionID))
+	bucket := int(hash.Sum32() % 100)
+
+	upper := 0
+	for _, variant := range variants {
+		upper += variant.Percent
+		if bucket < upper {
+			return variant.PromptVersionID
+		}
+	}
+	return ""
+}
+
+/*
+ * DiffLines computes a line diff of two prompts from their longest common subsequence
+ */
+func DiffLines(from string, to string) []models.PromptDiffLine {
+	a := strings.Split(from, "\n")
+	b := strings.Split(to, "\n")
+
+	// lcs[i][j] is the common subsequence length of a[i:] and b[j:]
+	lcs := make([][]int, len(a)+1)
+	for i := range lcs {
+		lcs[i] = make([]int, len(b)+1)
+	}
+	for i := len(a) - 1; i >= 0; i-- {
+		for j := len(b) - 1; j >= 0; j-- {
+			if a[i] == b[j] {
+				lcs[i][j] = lcs[i+1][j+1] + 1
+			} else {
+				lcs[i][j] = max(lcs[i+1][j], lcs[i][j+1])
+			}
+		}
+	}
+
+	lines := make([]models.PromptDiffLine, 0, len(a)+len(b))
+	i, j := 0, 0
+	for i < len(a) && j < len(b) {
+		switch {
+		case a[i] == b[j]:
+			lines = append(lines, models.PromptDiffLine{Op: models.DiffOpEqual, Text: a[i]})
+			i++
+			j++
+		case lcs[i+1][j] >= lcs[i][j+1]:
+			lines = append(lines, models.PromptDiffLine{Op: models.DiffOpRemove, Text: a[i]})
+			i++
+		default:
+			lines = append(lines, models.PromptDiffLine{Op: models.DiffOpAdd, Text: b[j]})
+			j++
+		}
+	}
+	for ; i < len(a); i++ {
+		lines = append(lines, models.PromptDiffLine{Op: models.DiffOpRemove, Text: a[i]})
+	}
+	for ; j < len(b); j++ {
+		lines = append(lines, models.PromptDiffLine{Op: models.DiffOpAdd, Text: b[j]})
+	}
+	return lines
+}
@@ -0,0 +1,159 @@
+package prompt_test
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+
+	"github.com/souravsspace/texly.chat/internal/models"
+	botRepo "github.com/souravsspace/texly.chat/internal/repo/bot"
+	promptRepo "github.com/souravsspace/texly.chat/internal/repo/prompt"
+	"github.com/souravsspace/texly.chat/internal/services/prompt"
+)
+
+func setupPromptService(t *testing.T) (*prompt.PromptService, *botRepo.BotRepo) {
+	db, err := gorm.Open(sqlite.Open(":memory:"), &gorm.Config{})
+	require.NoError(t, err)
+	require.NoError(t, db.AutoMigrate(&models.Bot{}, &models.PromptVersion{}, &models.PromptExperiment{}))
+
+	bots := botRepo.NewBotRepo(db, nil)
+	return prompt.NewPromptService(promptRepo.New(db), bots), bots
+}
+
+func TestRecordChangeAndRollback(t *testing.T) {
+	ctx := context.Background()
+	service, bots := setupPromptService(t)
+
+	// A bot created before versioning
+	bot := &models.Bot{UserID: "user-1", Name: "Support", SystemPrompt: "Be helpful."}
+	require.NoError(t, bots.Create(bot))
+
+	changed, err := service.RecordChange(ctx, bot, "Be helpful.\nAnswer in French.", "user-1", "French")
+	require.NoError(t, err)
+	assert.Equal(t, 2, changed.Version)
+	assert.Equal(t, changed.ID, bot.PromptVersionID)
+	require.NoError(t, bots.Update(bot))
+
+	versions, err := service.ListVersions(ctx, bot.ID)
+	require.NoError(t, err)
+	require.Len(t, versions, 2)
+	assert.Equal(t, "Be helpful.", versions[1].SystemPrompt)
+	assert.Equal(t, "Initial prompt", versions[1].Note)
+
+	diff, err := service.Diff(ctx, bot.ID, versions[1].ID, versions[0].ID)
+	require.NoError(t, err)
+	assert.Equal(t, []models.PromptDiffLine{
+		{Op: models.DiffOpEqual, Text: "Be helpful."},
+		{Op: models.DiffOpAdd, Text: "Answer in French."},
+	}, diff.Lines)
+
+	restored, err := service.Rollback(ctx, bot, versions[1].ID, "user-1")
+	require.NoError(t, err)
+	assert.Equal(t, 3, restored.Version)
+	require.NotNil(t, restored.RolledBackTo)
+	assert.Equal(t, 1, *restored.RolledBackTo)
+
+	saved, err := bots.GetByID(bot.ID, "user-1")
+	require.NoError(t, err)
+	assert.Equal(t, "Be helpful.", saved.SystemPrompt)
+	assert.Equal(t, restored.ID, saved.PromptVersionID)
+
+	_, err = service.Rollback(ctx, bot, "missing", "user-1")
+	assert.ErrorIs(t, err, prompt.ErrVersionNotFound)
+}
+
+func TestExperiments(t *testing.T) {
+	ctx := context.Background()
+	service, bots := setupPromptService(t)
+
+	bot := &models.Bot{UserID: "user-1", Name: "Support", SystemPrompt: "Be brief."}
+	require.NoError(t, bots.Create(bot))
+	control, err := service.RecordVersion(ctx, bot, "user-1", "")
+	require.NoError(t, err)
+	challenger, err := service.RecordChange(ctx, bot, "Be thorough.", "user-1", "")
+	require.NoError(t, err)
+
+	// Variants must exist and fit in 100%
+	for _, variants := range [][]models.PromptVariant{
+		nil,
+		{{PromptVersionID: "missing", Percent: 50}},
+		{{PromptVersionID: challenger.ID, Percent: 0}},
+		{{PromptVersionID: challenger.ID, Percent: 60}, {PromptVersionID: control.ID, Percent: 60}},
+		{{PromptVersionID: challenger.ID, Percent: 20}, {PromptVersionID: challenger.ID, Percent: 20}},
+	} {
+		_, err := service.CreateExperiment(ctx, bot.ID, models.CreatePromptExperimentRequest{Name: "Tone", Variants: variants})
+		assert.ErrorIs(t, err, prompt.ErrInvalidVariants)
+	}
+
+	experiment, err := service.CreateExperiment(ctx, bot.ID, models.CreatePromptExperimentRequest{
+		Name:     "Tone",
+		Variants: []models.PromptVariant{{PromptVersionID: control.ID, Percent: 50}},
+	})
+	require.NoError(t, err)
+	assert.Equal(t, models.ExperimentStatusRunning, experiment.Status)
+
+	_, err = service.CreateExperiment(ctx, bot.ID, models.CreatePromptExperimentRequest{
+		Name:     "Another",
+		Variants: []models.PromptVariant{{PromptVersionID: control.ID, Percent: 10}},
+	})
+	assert.ErrorIs(t, err, prompt.ErrExperimentRunning)
+
+	// Half the sessions get the old prompt, the others keep the current one; assignments stick
+	assigned := 0
+	for i := 0; i < 200; i++ {
+		sessionID := fmt.Sprintf("session-%d", i)
+		version, err := service.ResolveForSession(ctx, bot.ID, sessionID)
+		require.NoError(t, err)
+		again, err := service.ResolveForSession(ctx, bot.ID, sessionID)
+		require.NoError(t, err)
+		assert.Equal(t, version, again)
+		if version != nil {
+			assert.Equal(t, "Be brief.", version.SystemPrompt)
+			assigned++
+		}
+	}
+	assert.InDelta(t, 100, assigned, 30)
+
+	stopped, err := service.StopExperiment(ctx, bot.ID, experiment.ID)
+	require.NoError(t, err)
+	assert.Equal(t, models.ExperimentStatusStopped, stopped.Status)
+	assert.NotNil(t, stopped.StoppedAt)
+
+	_, err = service.StopExperiment(ctx, bot.ID, experiment.ID)
+	assert.ErrorIs(t, err, prompt.ErrExperimentStopped)
+
+	version, err := service.ResolveForSession(ctx, bot.ID, "session-1")
+	require.NoError(t, err)
+	assert.Nil(t, version)
+}
+
+func TestAssignVariant(t *testing.T) {
+	variants := []models.PromptVariant{{PromptVersionID: "a", Percent: 30}, {PromptVersionID: "b", Percent: 30}}
+
+	counts := map[string]int{}
+	for i := 0; i < 3000; i++ {
+		counts[prompt.AssignVariant("exp-1", fmt.Sprintf("session-%d", i), variants)]++
+	}
+	assert.InDelta(t, 900, counts["a"], 150)
+	assert.InDelta(t, 900, counts["b"], 150)
+	assert.InDelta(t, 1200, counts[""], 150)
+
+	assert.Equal(t, "", prompt.AssignVariant("exp-1", "session-1", nil))
+}
+
+func TestDiffLines(t *testing.T) {
+	lines := prompt.DiffLines("You are a bot.\nBe brief.\nUse English.", "You are a bot.\nBe thorough.\nUse English.")
+	assert.Equal(t, []models.PromptDiffLine{
+		{Op: models.DiffOpEqual, Text: "You are a bot."},
+		{Op: models.DiffOpRemove, Text: "Be brief."},
+		{Op: models.DiffOpAdd, Text: "Be thorough."},
+		{Op: models.DiffOpEqual, Text: "Use English."},
+	}, lines)
+
+	assert.Equal(t, []models.PromptDiffLine{{Op: models.DiffOpEqual, Text: "Same"}}, prompt.DiffLines("Same", "Same"))
+}
@@ -0,0 +1,129 @@
+package rerank
+
+import (
+	"context"
+	"math"
+	"strings"
+	"unicode"
+
+	"github.com/souravsspace/texly.chat/internal/services/vector"
+)
+
+const (
+	// bm25K1 controls how quickly repeated terms stop adding to a score
+	bm25K1 = 1.2
+	// bm25B controls how much long chunks are penalised
+	bm25B = 0.75
+)
+
+/*
+ * stopWords are question words too common to tell chunks apart
+ */
+var stopWords = map[string]bool{
+	"a": true, "an": true, "and": true, "are": true, "as": true, "at": true, "be": true, "by": true,
+	"can": true, "do": true, "does": true, "for": true, "from": true, "how": true, "i": true, "in": true,
+	"is": true, "it": true, "my": true, "of": true, "on": true, "or": true, "the": true, "to": true,
+	"what": true, "when": true, "where": true, "which": true, "who": true, "why": true, "with": true,
+	"you": true, "your": true,
+}
+
+/*
+ * BM25Reranker scores candidates with Okapi BM25 against the query terms
+ * Term statistics come from the candidates themselves, so it needs no index and no network calls
+ */
+type BM25Reranker struct{}
+
+/*
+ * NewBM25Reranker creates a new BM25 reranker instance
+ */
+func NewBM25Reranker() *BM25Reranker {
+	return &BM25Reranker{}
+}
+
+/*
+ * Rerank orders the candidates by their BM25 score for the query
+ */
+func (r *BM25Reranker) Rerank(ctx context.Context, query string, candidates []vector.SearchResult) ([]vector.SearchResult, error) {
+	return sortByScore(candidates, bm25Scores(tokenize(query), candidates)), nil
+}
+
+/*
+ * bm25Scores scores every candidate for the query terms
+ */
+func bm25Scores(queryTerms []string, candidates []vector.SearchResult) []float64 {
+	scores := make([]float64, len(candidates))
+	if len(candidates) == 0 || len(queryTerms) == 0 {
+		return scores
+	}
+
+	termCounts := make([]map[string]int, len(candidates))
+	lengths := make([]int, len(candidates))
+	documentFrequency := map[string]int{}
+	totalLength := 0
+	for i, candidate := range candidates {
+		terms := tokenize(candidate.Content)
+		counts := make(map[string]int, len(terms))
+		for _, term := range terms {
+			counts[term]++
+		}
+		for term := range counts {
+			documentFrequency[term]++
+		}
+		termCounts[i] = counts
+		lengths[i] = len(terms)
+		totalLength += len(terms)
+	}
+
+	averageLength := float64(totalLength) / float64(len(candidates))
+	if averageLength == 0 {
+		return scores
+	}
+
+	count := float64(len(candidates))
+	queryTerms = uniqueTerms(queryTerms)
+	for i := range candidates {
+		for _, term := range queryTerms {
+			frequency := float64(termCounts[i][term])
+			if frequency == 0 {
+				continue
+			}
+			df := float64(documentFrequency[term])
+			idf := math.Log(1 + (count-df+0.5)/(df+0.5))
+			norm := bm25K1 * (1 - bm25B + bm25B*float64(lengths[i])/averageLength)
+			scores[i] += idf * frequency * (bm25K1 + 1) / (frequency + norm)
+		}
+	}
+	return scores
+}
+
+/*
+ * tokenize lowercases text and splits it into letter and digit runs, leaving out stop words
+ */
+func tokenize(text string) []string {
+	fields := strings.FieldsFunc(strings.ToLower(text), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+
+	terms := fields[:0]
+	for _, field := range fields {
+		if !stopWords[field] {
+			terms = append(terms, field)
+		}
+	}
+	return terms
+}
+
+/*
+ * uniqueTerms drops repeated query terms so each counts once
+ */
+func uniqueTerms(terms []string) []string {
+	seen := make(map[string]bool, len(terms))
+	unique := make([]string, 0, len(terms))
+	for _, term := range terms {
+		if !seen[term] {
+			seen[term] = true
+			unique = append(unique, term)
+		}
+	}
+	return unique
+}
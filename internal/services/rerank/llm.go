@@ -0,0 +1,114 @@
+package rerank
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/souravsspace/texly.chat/internal/services/llm"
+	"github.com/souravsspace/texly.chat/internal/services/vector"
+)
+
+const (
+	// maxPassageRunes is how much of each candidate is shown to the scoring model
+	maxPassageRunes = 1000
+	// maxLLMScore is the top of the scale the model scores passages on
+	maxLLMScore = 10
+)
+
+const llmSystemPrompt = `You judge how relevant knowledge base passages are to a search query.
+Score every passage from 0 (irrelevant) to 10 (directly answers the query).
+Reply with a JSON array of the scores in passage order, e.g. [7, 0, 10], and nothing else.`
+
+var ErrInvalidScores = errors.New("reranker returned invalid scores")
+
+/*
+ * LLMReranker asks a chat model to score every candidate against the query in a single call
+ */
+type LLMReranker struct {
+	provider llm.ChatProvider
+	model    string
+}
+
+/*
+ * NewLLMReranker creates a new LLM reranker instance
+ */
+func NewLLMReranker(provider llm.ChatProvider, model string) *LLMReranker {
+	return &LLMReranker{
+		provider: provider,
+		model:    model,
+	}
+}
+
+/*
+ * Rerank orders the candidates by the model's relevance scores, scaled to 0-1
+ */
+func (r *LLMReranker) Rerank(ctx context.Context, query string, candidates []vector.SearchResult) ([]vector.SearchResult, error) {
+	if len(candidates) == 0 {
+		return candidates, nil
+	}
+
+	temperature := 0.0
+	completion, err := llm.Complete(ctx, r.provider, llm.CompletionRequest{
+		Model: r.model,
+		Messages: []llm.Message{
+			{Role: llm.RoleSystem, Content: llmSystemPrompt},
+			{Role: llm.RoleUser, Content: buildLLMPrompt(query, candidates)},
+		},
+		Temperature: &temperature,
+		MaxTokens:   20 + 4*len(candidates),
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to score candidates: %w", err)
+	}
+
+	scores, err := parseScores(completion.Content, len(candidates))
+	if err != nil {
+		return nil, err
+	}
+	return sortByScore(candidates, scores), nil
+}
+
+/*
+ * buildLLMPrompt numbers the start of each candidate under the query
+ */
+func buildLLMPrompt(query string, candidates []vector.SearchResult) string {
+	var builder strings.Builder
+	builder.WriteString("Query: ")
+	builder.WriteString(query)
+	builder.WriteString("\n\nPassages:\n")
+	for i, candidate := range candidates {
+		content := []rune(strings.TrimSpace(candidate.Content))
+		if len(content) > maxPassageRunes {
+			content = append(content[:maxPassageRunes], '…')
+		}
+		fmt.Fprintf(&builder, "\n[%d] %s\n", i+1, string(content))
+	}
+	return builder.String()
+}
+
+/*
+ * parseScores reads the JSON array of scores, tolerating text or code fences around it
+ */
+func parseScores(content string, count int) ([]float64, error) {
+	start := strings.Index(content, "[")
+	end := strings.LastIndex(content, "]")
+	if start < 0 || end < start {
+		return nil, fmt.Errorf("%w: %q", ErrInvalidScores, content)
+	}
+
+	var scores []float64
+	if err := json.Unmarshal([]byte(content[start:end+1]), &scores); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidScores, err)
+	}
+	if len(scores) != count {
+		return nil, fmt.Errorf("%w: got %d scores for %d passages", ErrInvalidScores, len(scores), count)
+	}
+
+	for i, score := range scores {
+		scores[i] = min(max(score, 0), maxLLMScore) / maxLLMScore
+	}
+	return scores, nil
+}
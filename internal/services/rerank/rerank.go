@@ -0,0 +1,59 @@
+package rerank
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+
+	"github.com/souravsspace/texly.chat/internal/models"
+	"github.com/souravsspace/texly.chat/internal/services/llm"
+	"github.com/souravsspace/texly.chat/internal/services/vector"
+)
+
+var ErrUnknownReranker = errors.New("unknown reranker")
+
+/*
+ * Reranker reorders retrieved chunks by their relevance to the query
+ * Implementations set RerankScore on every candidate and return them most relevant first
+ */
+type Reranker interface {
+	Rerank(ctx context.Context, query string, candidates []vector.SearchResult) ([]vector.SearchResult, error)
+}
+
+/*
+ * New returns the reranker with the given name, or nil when reranking is disabled
+ * The LLM reranker scores candidates with the given provider and model
+ */
+func New(name string, provider llm.ChatProvider, model string) (Reranker, error) {
+	switch name {
+	case models.RerankerNone:
+		return nil, nil
+	case models.RerankerBM25:
+		return NewBM25Reranker(), nil
+	case models.RerankerLLM:
+		if provider == nil {
+			return nil, fmt.Errorf("%w: %s requires a chat provider", ErrUnknownReranker, name)
+		}
+		return NewLLMReranker(provider, model), nil
+	default:
+		return nil, fmt.Errorf("%w: %s", ErrUnknownReranker, name)
+	}
+}
+
+/*
+ * sortByScore returns a copy of the candidates carrying their scores, highest first
+ * Ties keep the retrieval order
+ */
+func sortByScore(candidates []vector.SearchResult, scores []float64) []vector.SearchResult {
+	ranked := make([]vector.SearchResult, len(candidates))
+	copy(ranked, candidates)
+	for i := range ranked {
+		ranked[i].RerankScore = scores[i]
+	}
+
+	sort.SliceStable(ranked, func(i, j int) bool {
+		return ranked[i].RerankScore > ranked[j].RerankScore
+	})
+	return ranked
+}
@@ -0,0 +1,128 @@
+package rerank
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/souravsspace/texly.chat/internal/models"
+	"github.com/souravsspace/texly.chat/internal/services/llm"
+	"github.com/souravsspace/texly.chat/internal/services/vector"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func chunkIDs(results []vector.SearchResult) []string {
+	ids := make([]string, len(results))
+	for i, result := range results {
+		ids[i] = result.ChunkID
+	}
+	return ids
+}
+
+/*
+ * TestNew tests resolving rerankers by name
+ */
+func TestNew(t *testing.T) {
+	reranker, err := New(models.RerankerNone, nil, "")
+	require.NoError(t, err)
+	assert.Nil(t, reranker)
+
+	reranker, err = New(models.RerankerBM25, nil, "")
+	require.NoError(t, err)
+	assert.IsType(t, &BM25Reranker{}, reranker)
+
+	reranker, err = New(models.RerankerLLM, llm.NewFakeProvider(), "gpt-4o-mini")
+	require.NoError(t, err)
+	assert.IsType(t, &LLMReranker{}, reranker)
+
+	_, err = New("cohere", nil, "")
+	assert.ErrorIs(t, err, ErrUnknownReranker)
+}
+
+/*
+ * TestBM25Reranker tests that chunks matching rarer query terms rise above closer embeddings
+ */
+func TestBM25Reranker(t *testing.T) {
+	candidates := []vector.SearchResult{
+		{ChunkID: "general", Content: "We ship orders to most countries. Orders usually arrive within a week."},
+		{ChunkID: "refunds", Content: "Refunds are issued within 14 days of receiving the returned item."},
+		{ChunkID: "customs", Content: "International orders may be charged customs duties by the destination country. Customs fees are paid by the buyer."},
+	}
+
+	ranked, err := NewBM25Reranker().Rerank(context.Background(), "Who pays customs duties on international orders?", candidates)
+	require.NoError(t, err)
+
+	assert.Equal(t, []string{"customs", "general", "refunds"}, chunkIDs(ranked))
+	assert.Greater(t, ranked[0].RerankScore, ranked[1].RerankScore)
+	assert.Zero(t, ranked[2].RerankScore)
+
+	// The candidates are not modified
+	assert.Equal(t, "general", candidates[0].ChunkID)
+	assert.Zero(t, candidates[0].RerankScore)
+}
+
+/*
+ * TestBM25Reranker_NoMatches tests that the retrieval order is kept when no term matches
+ */
+func TestBM25Reranker_NoMatches(t *testing.T) {
+	candidates := []vector.SearchResult{
+		{ChunkID: "first", Content: "Opening hours"},
+		{ChunkID: "second", Content: "Store locations"},
+	}
+
+	ranked, err := NewBM25Reranker().Rerank(context.Background(), "What is the?", candidates)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"first", "second"}, chunkIDs(ranked))
+}
+
+/*
+ * TestLLMReranker tests ordering candidates by the model's scores
+ */
+func TestLLMReranker(t *testing.T) {
+	fake := llm.NewFakeProvider("```json\n[3, 10, 7]\n```")
+	candidates := []vector.SearchResult{
+		{ChunkID: "a", Content: "Opening hours"},
+		{ChunkID: "b", Content: "Shipping abroad"},
+		{ChunkID: "c", Content: "Shipping costs"},
+	}
+
+	ranked, err := NewLLMReranker(fake, "gpt-4o-mini").Rerank(context.Background(), "Do you ship abroad?", candidates)
+	require.NoError(t, err)
+
+	assert.Equal(t, []string{"b", "c", "a"}, chunkIDs(ranked))
+	assert.InDelta(t, 1.0, ranked[0].RerankScore, 1e-9)
+	assert.InDelta(t, 0.3, ranked[2].RerankScore, 1e-9)
+
+	request := fake.LastRequest()
+	assert.Equal(t, "gpt-4o-mini", request.Model)
+	assert.Contains(t, request.Messages[1].Content, "Query: Do you ship abroad?")
+	assert.Contains(t, request.Messages[1].Content, "[2] Shipping abroad")
+}
+
+/*
+ * TestLLMReranker_Errors tests that provider failures and malformed scores are reported
+ */
+func TestLLMReranker_Errors(t *testing.T) {
+	candidates := []vector.SearchResult{{ChunkID: "a"}, {ChunkID: "b"}}
+
+	fake := llm.NewFakeProvider()
+	fake.Err = errors.New("rate limited")
+	_, err := NewLLMReranker(fake, "").Rerank(context.Background(), "query", candidates)
+	assert.Error(t, err)
+
+	_, err = NewLLMReranker(llm.NewFakeProvider("[5]"), "").Rerank(context.Background(), "query", candidates)
+	assert.ErrorIs(t, err, ErrInvalidScores)
+
+	_, err = NewLLMReranker(llm.NewFakeProvider("Both are relevant."), "").Rerank(context.Background(), "query", candidates)
+	assert.ErrorIs(t, err, ErrInvalidScores)
+}
+
+/*
+ * TestParseScores tests scaling and clamping of model scores
+ */
+func TestParseScores(t *testing.T) {
+	scores, err := parseScores("Scores: [0, 12, -1, 5]", 4)
+	require.NoError(t, err)
+	assert.Equal(t, []float64{0, 1, 0, 0.5}, scores)
+}
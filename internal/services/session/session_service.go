@@ -2,6 +2,7 @@ package session
 
 import (
 	"errors"
+	"sort"
 	"sync"
 	"time"
 
@@ -11,6 +12,7 @@ import (
 var (
 	ErrSessionNotFound = errors.New("session not found")
 	ErrSessionExpired  = errors.New("session has expired")
+	ErrNotInHandoff    = errors.New("session is not in handoff")
 )
 
 /*
@@ -21,6 +23,22 @@ type SessionService struct {
 	mu       sync.RWMutex
 }
 
+/*
+* HandoffState is a snapshot of a session's handoff, safe to read outside the session lock
+ */
+type HandoffState struct {
+	Status string     // "" (bot answers), "pending" or "active"
+	Reason string     // What triggered the handoff
+	At     *time.Time // When the handoff started
+}
+
+/*
+* InHandoff reports whether a human had taken over the conversation when the snapshot was taken
+ */
+func (h HandoffState) InHandoff() bool {
+	return h.Status != ""
+}
+
 /*
 * NewSessionService creates a new session service instance
  */
@@ -122,3 +140,120 @@ func (s *SessionService) GetSessionCount() int {
 	defer s.mu.RUnlock()
 	return len(s.sessions)
 }
+
+/*
+* StartHandoff hands the session to a human operator
+* Returns false if the session was already in handoff
+ */
+func (s *SessionService) StartHandoff(sessionID string, reason string) (bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	session, err := s.activeSession(sessionID)
+	if err != nil {
+		return false, err
+	}
+	if session.InHandoff() {
+		return false, nil
+	}
+
+	now := time.Now()
+	session.HandoffStatus = models.HandoffStatusPending
+	session.HandoffReason = reason
+	session.HandoffAt = &now
+	return true, nil
+}
+
+/*
+* ActivateHandoff marks that an operator has joined the conversation
+ */
+func (s *SessionService) ActivateHandoff(sessionID string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	session, err := s.activeSession(sessionID)
+	if err != nil {
+		return err
+	}
+	if !session.InHandoff() {
+		return ErrNotInHandoff
+	}
+
+	session.HandoffStatus = models.HandoffStatusActive
+	return nil
+}
+
+/*
+* EndHandoff returns the conversation to the bot
+ */
+func (s *SessionService) EndHandoff(sessionID string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	session, err := s.activeSession(sessionID)
+	if err != nil {
+		return err
+	}
+	if !session.InHandoff() {
+		return ErrNotInHandoff
+	}
+
+	session.HandoffStatus = ""
+	session.HandoffReason = ""
+	session.HandoffAt = nil
+	return nil
+}
+
+/*
+* HandoffState returns a copy of the session's handoff status, reason and start time
+ */
+func (s *SessionService) HandoffState(sessionID string) (HandoffState, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	session, err := s.activeSession(sessionID)
+	if err != nil {
+		return HandoffState{}, err
+	}
+
+	state := HandoffState{Status: session.HandoffStatus, Reason: session.HandoffReason}
+	if session.HandoffAt != nil {
+		at := *session.HandoffAt
+		state.At = &at
+	}
+	return state, nil
+}
+
+/*
+* ListHandoffs returns copies of a bot's sessions currently handled by a human, oldest first
+ */
+func (s *SessionService) ListHandoffs(botID string) []models.ChatSession {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	handoffs := []models.ChatSession{}
+	for _, session := range s.sessions {
+		if session.BotID == botID && session.InHandoff() && !session.IsExpired() {
+			handoffs = append(handoffs, *session)
+		}
+	}
+
+	sort.Slice(handoffs, func(i, j int) bool {
+		return handoffs[i].HandoffAt.Before(*handoffs[j].HandoffAt)
+	})
+	return handoffs
+}
+
+/*
+* activeSession looks up a non-expired session; callers must hold the lock
+ */
+func (s *SessionService) activeSession(sessionID string) (*models.ChatSession, error) {
+	session, exists := s.sessions[sessionID]
+	if !exists {
+		return nil, ErrSessionNotFound
+	}
+	if session.IsExpired() {
+		return nil, ErrSessionExpired
+	}
+	return session, nil
+}
@@ -139,3 +139,55 @@ func TestSessionService_ConcurrentAccess(t *testing.T) {
 		t.Errorf("Expected 10 sessions, got %d", service.GetSessionCount())
 	}
 }
+
+func TestSessionService_Handoff(t *testing.T) {
+	service := NewSessionService()
+	session := service.CreateSession("test-bot-123")
+	service.CreateSession("test-bot-123")
+
+	if err := service.ActivateHandoff(session.ID); err != ErrNotInHandoff {
+		t.Errorf("Expected ErrNotInHandoff, got %v", err)
+	}
+
+	started, err := service.StartHandoff(session.ID, "visitor")
+	if err != nil || !started {
+		t.Fatalf("Expected handoff to start, got started=%v err=%v", started, err)
+	}
+
+	// A second request does not restart the handoff
+	started, _ = service.StartHandoff(session.ID, "rule")
+	if started {
+		t.Error("Expected second handoff request to be ignored")
+	}
+
+	handoffs := service.ListHandoffs("test-bot-123")
+	if len(handoffs) != 1 || handoffs[0].HandoffReason != "visitor" || handoffs[0].HandoffStatus != "pending" {
+		t.Fatalf("Expected one pending visitor handoff, got %+v", handoffs)
+	}
+
+	if err := service.ActivateHandoff(session.ID); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	state, err := service.HandoffState(session.ID)
+	if err != nil || state.Status != "active" || state.Reason != "visitor" || state.At == nil {
+		t.Errorf("Expected active visitor handoff, got %+v (err=%v)", state, err)
+	}
+
+	if err := service.EndHandoff(session.ID); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if session.InHandoff() || len(service.ListHandoffs("test-bot-123")) != 0 {
+		t.Error("Expected handoff to be closed")
+	}
+
+	// Snapshots taken earlier are unaffected by later changes
+	if !state.InHandoff() || state.At == nil {
+		t.Errorf("Expected earlier snapshot to keep its handoff, got %+v", state)
+	}
+	if state, _ := service.HandoffState(session.ID); state.InHandoff() {
+		t.Errorf("Expected no handoff, got %+v", state)
+	}
+	if _, err := service.HandoffState("missing"); err != ErrSessionNotFound {
+		t.Errorf("Expected ErrSessionNotFound, got %v", err)
+	}
+}
@@ -0,0 +1,136 @@
+package stream
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/souravsspace/texly.chat/internal/models"
+	"github.com/souravsspace/texly.chat/internal/services/cache"
+)
+
+// ErrInvalidEventID is returned when a Last-Event-ID is not of the form {message_id}:{seq}
+var ErrInvalidEventID = errors.New("invalid event ID")
+
+/*
+ * StreamBuffer keeps the SSE events of in-flight answers in Redis, keyed by session and message,
+ * so a client whose connection drops can replay what it missed
+ * Events are numbered from 1 in the order they were appended
+ */
+type StreamBuffer struct {
+	cache *cache.CacheService
+}
+
+/*
+ * NewStreamBuffer creates a stream buffer
+ * Buffering is disabled (and streams cannot be resumed) when Redis is not configured
+ */
+func NewStreamBuffer(cacheService *cache.CacheService) *StreamBuffer {
+	return &StreamBuffer{cache: cacheService}
+}
+
+/*
+ * Enabled reports whether events are buffered
+ */
+func (b *StreamBuffer) Enabled() bool {
+	return b != nil && b.cache.IsEnabled()
+}
+
+/*
+ * Start marks a message's stream as the latest of its session
+ */
+func (b *StreamBuffer) Start(ctx context.Context, sessionID, messageID string) error {
+	if !b.Enabled() {
+		return nil
+	}
+	return b.cache.Set(ctx, fmt.Sprintf(cache.StreamLatestKey, sessionID), messageID, cache.StreamBufferTTL)
+}
+
+/*
+ * Latest returns the message ID of the session's most recent stream
+ */
+func (b *StreamBuffer) Latest(ctx context.Context, sessionID string) (string, error) {
+	if !b.Enabled() {
+		return "", cache.ErrCacheMiss
+	}
+	return b.cache.Get(ctx, fmt.Sprintf(cache.StreamLatestKey, sessionID))
+}
+
+/*
+ * Append buffers the next event of a message's stream
+ */
+func (b *StreamBuffer) Append(ctx context.Context, sessionID, messageID string, event models.ChatTokenResponse) error {
+	if !b.Enabled() {
+		return nil
+	}
+	data, err := json.Marshal(event)
+	if err != nil {
+		return fmt.Errorf("failed to encode stream event: %w", err)
+	}
+	return b.cache.Append(ctx, fmt.Sprintf(cache.StreamBufferKey, sessionID, messageID), string(data), cache.StreamBufferTTL)
+}
+
+/*
+ * Since returns the buffered events after sequence number seq (0 for all of them)
+ */
+func (b *StreamBuffer) Since(ctx context.Context, sessionID, messageID string, seq int) ([]models.ChatTokenResponse, error) {
+	if !b.Enabled() {
+		return nil, nil
+	}
+	values, err := b.cache.Range(ctx, fmt.Sprintf(cache.StreamBufferKey, sessionID, messageID), int64(seq))
+	if err != nil {
+		return nil, fmt.Errorf("failed to load stream events: %w", err)
+	}
+
+	events := make([]models.ChatTokenResponse, 0, len(values))
+	for _, value := range values {
+		var event models.ChatTokenResponse
+		if err := json.Unmarshal([]byte(value), &event); err != nil {
+			return nil, fmt.Errorf("failed to decode stream event: %w", err)
+		}
+		events = append(events, event)
+	}
+	return events, nil
+}
+
+/*
+ * Exists reports whether a message's stream is still buffered
+ */
+func (b *StreamBuffer) Exists(ctx context.Context, sessionID, messageID string) (bool, error) {
+	if !b.Enabled() {
+		return false, nil
+	}
+	return b.cache.Exists(ctx, fmt.Sprintf(cache.StreamBufferKey, sessionID, messageID))
+}
+
+/*
+ * IsFinal reports whether an event ends a stream
+ */
+func IsFinal(event models.ChatTokenResponse) bool {
+	return event.Type == "done" || event.Type == "error"
+}
+
+/*
+ * EventID formats the SSE event ID of the seq-th event of a message's stream
+ */
+func EventID(messageID string, seq int) string {
+	return messageID + ":" + strconv.Itoa(seq)
+}
+
+/*
+ * ParseEventID splits an SSE event ID into the message ID and sequence number
+ */
+func ParseEventID(id string) (string, int, error) {
+	i := strings.LastIndex(id, ":")
+	if i <= 0 {
+		return "", 0, ErrInvalidEventID
+	}
+	seq, err := strconv.Atoi(id[i+1:])
+	if err != nil || seq < 0 {
+		return "", 0, ErrInvalidEventID
+	}
+	return id[:i], seq, nil
+}
@@ -0,0 +1,96 @@
+package stream_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/redis/go-redis/v9"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/souravsspace/texly.chat/internal/models"
+	"github.com/souravsspace/texly.chat/internal/services/cache"
+	"github.com/souravsspace/texly.chat/internal/services/stream"
+)
+
+func setupStreamBuffer(t *testing.T) *stream.StreamBuffer {
+	mr, err := miniredis.Run()
+	require.NoError(t, err)
+	t.Cleanup(mr.Close)
+
+	client := redis.NewClient(&redis.Options{Addr: mr.Addr()})
+	return stream.NewStreamBuffer(cache.NewCacheService(client))
+}
+
+func TestStreamBuffer_AppendAndSince(t *testing.T) {
+	ctx := context.Background()
+	buffer := setupStreamBuffer(t)
+	require.True(t, buffer.Enabled())
+
+	require.NoError(t, buffer.Start(ctx, "session-1", "msg-1"))
+	for _, event := range []models.ChatTokenResponse{
+		{Type: "start", MessageID: "msg-1"},
+		{Type: "token", Content: "Hello"},
+		{Type: "token", Content: " world"},
+	} {
+		require.NoError(t, buffer.Append(ctx, "session-1", "msg-1", event))
+	}
+
+	latest, err := buffer.Latest(ctx, "session-1")
+	require.NoError(t, err)
+	assert.Equal(t, "msg-1", latest)
+
+	exists, err := buffer.Exists(ctx, "session-1", "msg-1")
+	require.NoError(t, err)
+	assert.True(t, exists)
+
+	// All events
+	events, err := buffer.Since(ctx, "session-1", "msg-1", 0)
+	require.NoError(t, err)
+	require.Len(t, events, 3)
+	assert.Equal(t, "start", events[0].Type)
+
+	// Only the events after the second one
+	events, err = buffer.Since(ctx, "session-1", "msg-1", 2)
+	require.NoError(t, err)
+	require.Len(t, events, 1)
+	assert.Equal(t, " world", events[0].Content)
+
+	// Streams are per session
+	exists, err = buffer.Exists(ctx, "session-2", "msg-1")
+	require.NoError(t, err)
+	assert.False(t, exists)
+}
+
+func TestStreamBuffer_Disabled(t *testing.T) {
+	ctx := context.Background()
+	buffer := stream.NewStreamBuffer(cache.NewCacheService(nil))
+	assert.False(t, buffer.Enabled())
+
+	require.NoError(t, buffer.Append(ctx, "session-1", "msg-1", models.ChatTokenResponse{Type: "token"}))
+	events, err := buffer.Since(ctx, "session-1", "msg-1", 0)
+	require.NoError(t, err)
+	assert.Empty(t, events)
+
+	_, err = buffer.Latest(ctx, "session-1")
+	assert.ErrorIs(t, err, cache.ErrCacheMiss)
+
+	var nilBuffer *stream.StreamBuffer
+	assert.False(t, nilBuffer.Enabled())
+}
+
+func TestEventID(t *testing.T) {
+	id := stream.EventID("3f2a-msg", 12)
+	assert.Equal(t, "3f2a-msg:12", id)
+
+	messageID, seq, err := stream.ParseEventID(id)
+	require.NoError(t, err)
+	assert.Equal(t, "3f2a-msg", messageID)
+	assert.Equal(t, 12, seq)
+
+	for _, invalid := range []string{"", "msg-1", ":3", "msg-1:x", "msg-1:-1"} {
+		_, _, err := stream.ParseEventID(invalid)
+		assert.ErrorIs(t, err, stream.ErrInvalidEventID, invalid)
+	}
+}
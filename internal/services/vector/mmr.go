@@ -0,0 +1,104 @@
+package vector
+
+import (
+	"context"
+	"fmt"
+)
+
+const (
+	// DefaultMMRLambda weighs relevance and diversity equally
+	DefaultMMRLambda = 0.5
+	// mmrPoolFactor is how many candidates per selected chunk MMR chooses from
+	mmrPoolFactor = 4
+	// mmrMinPool is the smallest candidate pool, so small limits still have alternatives to pick
+	mmrMinPool = 20
+)
+
+/*
+* SearchSimilarMMR performs hybrid search for a text query and diversifies the results with MMR
+ */
+func (s *SearchService) SearchSimilarMMR(ctx context.Context, query string, botID string, limit int, lambda float64) ([]SearchResult, error) {
+	queryEmbedding, _, err := s.embeddingService.GenerateEmbedding(ctx, query)
+	if err != nil {
+		return nil, fmt.Errorf("failed to generate query embedding: %w", err)
+	}
+
+	return s.SearchHybridMMR(ctx, query, queryEmbedding, botID, limit, lambda)
+}
+
+/*
+* SearchHybridMMR retrieves a deeper pool of hybrid matches and selects limit of them with maximal marginal relevance
+* Near-identical chunks (headers, footers, boilerplate repeated across pages) then take one slot instead of all
+ */
+func (s *SearchService) SearchHybridMMR(
+	ctx context.Context,
+	query string,
+	embedding []float32,
+	botID string,
+	limit int,
+	lambda float64,
+) ([]SearchResult, error) {
+	candidates, err := s.SearchHybrid(ctx, query, embedding, botID, max(limit*mmrPoolFactor, mmrMinPool))
+	if err != nil {
+		return nil, err
+	}
+
+	return SelectMMR(embedding, candidates, limit, lambda), nil
+}
+
+/*
+* SelectMMR greedily picks up to limit candidates, each maximising
+*   lambda * sim(query, chunk) - (1 - lambda) * max sim(chunk, already picked)
+* using cosine similarity of the stored embeddings; lambda 1 keeps the relevance order, lower values favour diversity
+* Candidates without an embedding cannot be compared and fill any remaining slots in their retrieval order
+ */
+func SelectMMR(queryEmbedding []float32, candidates []SearchResult, limit int, lambda float64) []SearchResult {
+	if limit <= 0 || limit > len(candidates) {
+		limit = len(candidates)
+	}
+	lambda = min(max(lambda, 0), 1)
+
+	var pool, unembedded []int
+	relevance := make([]float64, len(candidates))
+	for i, candidate := range candidates {
+		if len(candidate.Embedding) == 0 {
+			unembedded = append(unembedded, i)
+			continue
+		}
+		pool = append(pool, i)
+		relevance[i] = CosineSimilarity(queryEmbedding, candidate.Embedding)
+	}
+
+	// redundancy[i] is the highest similarity of candidate i to a selected chunk
+	redundancy := make([]float64, len(candidates))
+	selected := make([]SearchResult, 0, limit)
+	for len(selected) < limit && len(pool) > 0 {
+		best := 0
+		bestScore := 0.0
+		for j, i := range pool {
+			score := lambda*relevance[i] - (1-lambda)*redundancy[i]
+			// Ties keep the retrieval order
+			if j == 0 || score > bestScore {
+				best, bestScore = j, score
+			}
+		}
+
+		chosen := pool[best]
+		selected = append(selected, candidates[chosen])
+		pool = append(pool[:best], pool[best+1:]...)
+
+		for _, i := range pool {
+			similarity := CosineSimilarity(candidates[i].Embedding, candidates[chosen].Embedding)
+			redundancy[i] = max(redundancy[i], similarity)
+		}
+	}
+
+	for _, i := range unembedded {
+		if len(selected) == limit {
+			break
+		}
+		selected = append(selected, candidates[i])
+	}
+
+	return selected
+}
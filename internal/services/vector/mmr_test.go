@@ -0,0 +1,113 @@
+package vector
+
+import (
+	"context"
+	"testing"
+
+	"github.com/souravsspace/texly.chat/internal/models"
+	vectorRepo "github.com/souravsspace/texly.chat/internal/repo/vector"
+	"github.com/souravsspace/texly.chat/internal/services/embedding"
+	"github.com/souravsspace/texly.chat/internal/shared"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// Helper to build a 1536-dimension embedding from its leading components
+func sparseEmbedding(values ...float32) []float32 {
+	embedding := make([]float32, 1536)
+	copy(embedding, values)
+	return embedding
+}
+
+func resultIDs(results []SearchResult) []string {
+	ids := make([]string, len(results))
+	for i, result := range results {
+		ids[i] = result.ChunkID
+	}
+	return ids
+}
+
+// Boilerplate repeated across pages is close to the query, and so are its copies
+var mmrCandidates = []SearchResult{
+	{ChunkID: "footer-1", Embedding: []float32{0.8, 0.6, 0}},
+	{ChunkID: "footer-2", Embedding: []float32{0.8, 0.6, 0.01}},
+	{ChunkID: "footer-3", Embedding: []float32{0.79, 0.61, 0}},
+	{ChunkID: "answer", Embedding: []float32{0.7, -0.7, 0.1}},
+}
+
+/*
+ * TestSelectMMR tests that near-duplicate chunks give way to a different relevant one
+ */
+func TestSelectMMR(t *testing.T) {
+	query := []float32{1, 0, 0}
+
+	selected := SelectMMR(query, mmrCandidates, 2, DefaultMMRLambda)
+	assert.Equal(t, []string{"footer-1", "answer"}, resultIDs(selected))
+
+	// Lambda 1 keeps the relevance order, duplicates included
+	selected = SelectMMR(query, mmrCandidates, 2, 1)
+	assert.Equal(t, []string{"footer-1", "footer-2"}, resultIDs(selected))
+
+	// A limit beyond the pool returns every candidate once
+	selected = SelectMMR(query, mmrCandidates, 10, DefaultMMRLambda)
+	assert.ElementsMatch(t, []string{"footer-1", "footer-2", "footer-3", "answer"}, resultIDs(selected))
+}
+
+/*
+ * TestSelectMMR_Unembedded tests that chunks without an embedding fill the remaining slots
+ */
+func TestSelectMMR_Unembedded(t *testing.T) {
+	candidates := []SearchResult{
+		{ChunkID: "keyword-only"},
+		{ChunkID: "close", Embedding: []float32{1, 0}},
+		{ChunkID: "far", Embedding: []float32{0, 1}},
+	}
+
+	selected := SelectMMR([]float32{1, 0}, candidates, 2, DefaultMMRLambda)
+	assert.Equal(t, []string{"close", "far"}, resultIDs(selected))
+
+	selected = SelectMMR([]float32{1, 0}, candidates, 3, DefaultMMRLambda)
+	assert.Equal(t, []string{"close", "far", "keyword-only"}, resultIDs(selected))
+
+	assert.Empty(t, SelectMMR([]float32{1, 0}, nil, 5, DefaultMMRLambda))
+}
+
+/*
+ * TestSearchHybridMMR tests diversifying search results with the stored embeddings
+ */
+func TestSearchHybridMMR(t *testing.T) {
+	gormDB := shared.SetupTestDB()
+	vRepo := vectorRepo.NewVectorRepository(gormDB)
+	embSvc := embedding.NewEmbeddingService("test-key", "test-model", 1536)
+	ctx := context.Background()
+
+	require.NoError(t, gormDB.Create(&models.Bot{ID: "bot-1", Name: "Test Bot"}).Error)
+	require.NoError(t, gormDB.Create(&models.Source{ID: "source-1", BotID: "bot-1", URL: "https://example.com", Status: models.SourceStatusCompleted}).Error)
+
+	embeddings := map[string][]float32{
+		"footer-1": sparseEmbedding(0.8, 0.6),
+		"footer-2": sparseEmbedding(0.8, 0.6),
+		"footer-3": sparseEmbedding(0.8, 0.6),
+		"answer":   sparseEmbedding(0.7, -0.7, 0.1),
+	}
+	var data []vectorRepo.VectorData
+	for id, vec := range embeddings {
+		require.NoError(t, gormDB.Create(&models.DocumentChunk{ID: id, SourceID: "source-1", Content: "Contact us"}).Error)
+		data = append(data, vectorRepo.VectorData{ChunkID: id, Embedding: vec})
+	}
+	require.NoError(t, vRepo.BulkInsertEmbeddings(ctx, data))
+
+	service := NewSearchService(gormDB, vRepo, embSvc)
+	query := sparseEmbedding(1)
+
+	// Similarity alone fills both slots with footers
+	results, err := service.SearchHybrid(ctx, "returns policy", query, "bot-1", 2)
+	require.NoError(t, err)
+	assert.NotContains(t, resultIDs(results), "answer")
+	assert.Len(t, results[0].Embedding, 1536)
+
+	results, err = service.SearchHybridMMR(ctx, "returns policy", query, "bot-1", 2, DefaultMMRLambda)
+	require.NoError(t, err)
+	require.Len(t, results, 2)
+	assert.Contains(t, resultIDs(results), "answer")
+}
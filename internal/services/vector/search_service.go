@@ -3,6 +3,7 @@ package vector
 import (
 	"context"
 	"fmt"
+	"sort"
 
 	"github.com/souravsspace/texly.chat/internal/models"
 	vectorRepo "github.com/souravsspace/texly.chat/internal/repo/vector"
@@ -34,22 +35,33 @@ func NewSearchService(
 	}
 }
 
+/*
+* rrfK dampens the weight of top ranks in reciprocal rank fusion (the value from the original paper)
+ */
+const rrfK = 60
+
 /*
 * SearchResult represents a single search result with metadata
  */
 type SearchResult struct {
-	ChunkID      string                 `json:"chunk_id"`
-	SourceID     string                 `json:"source_id"`
-	Content      string                 `json:"content"`
-	Distance     float32                `json:"distance"`
-	ChunkIndex   int                    `json:"chunk_index"`
-	URL          string                 `json:"url"`
-	SourceStatus models.SourceStatus    `json:"source_status"`
-	Metadata     map[string]interface{} `json:"metadata"`
+	ChunkID          string                 `json:"chunk_id"`
+	SourceID         string                 `json:"source_id"`
+	Content          string                 `json:"content"`
+	Distance         *float32               `json:"distance"` // Cosine distance to the query (nil when the chunk has no embedding)
+	ChunkIndex       int                    `json:"chunk_index"`
+	URL              string                 `json:"url"`
+	OriginalFilename string                 `json:"original_filename"`
+	SourceStatus     models.SourceStatus    `json:"source_status"`
+	Metadata         map[string]interface{} `json:"metadata"`
+	Score            float64                `json:"score"`        // Reciprocal rank fusion score (higher = more relevant)
+	VectorRank       int                    `json:"vector_rank"`  // Rank among the vector matches (0 = not matched)
+	KeywordRank      int                    `json:"keyword_rank"` // Rank among the full-text matches (0 = not matched)
+	RerankScore      float64                `json:"rerank_score"` // Relevance assigned by the bot's reranker (higher = more relevant)
+	Embedding        []float32              `json:"-"`            // Stored embedding of the chunk (nil when not embedded)
 }
 
 /*
-* SearchSimilar performs semantic search for a text query
+* SearchSimilar performs hybrid semantic and keyword search for a text query
  */
 func (s *SearchService) SearchSimilar(ctx context.Context, query string, botID string, limit int) ([]SearchResult, error) {
 	// Generate embedding for the query
@@ -58,15 +70,107 @@ func (s *SearchService) SearchSimilar(ctx context.Context, query string, botID s
 		return nil, fmt.Errorf("failed to generate query embedding: %w", err)
 	}
 
-	return s.SearchSimilarByEmbedding(ctx, queryEmbedding, botID, limit)
+	return s.SearchHybrid(ctx, query, queryEmbedding, botID, limit)
+}
+
+/*
+* SearchHybrid fuses vector search on a pre-computed embedding with full-text search on the query
+* Results are ordered by reciprocal rank fusion, so exact terms (SKUs, error codes) surface even when
+* their embeddings are not close, and chunks without embeddings can still be found
+ */
+func (s *SearchService) SearchHybrid(ctx context.Context, query string, embedding []float32, botID string, limit int) ([]SearchResult, error) {
+	candidates := limit * 2 // Deeper lists let chunks found by both searches rise
+
+	vectorMatches, err := s.vectorRepo.SearchSimilar(ctx, embedding, botID, candidates)
+	if err != nil {
+		return nil, fmt.Errorf("failed to search vectors: %w", err)
+	}
+
+	keywordMatches, err := s.vectorRepo.SearchKeyword(ctx, query, botID, candidates)
+	if err != nil {
+		return nil, fmt.Errorf("failed to search keywords: %w", err)
+	}
+
+	if len(vectorMatches) == 0 && len(keywordMatches) == 0 {
+		return []SearchResult{}, nil
+	}
+
+	chunkIDs := make([]string, 0, len(vectorMatches)+len(keywordMatches))
+	distanceMap := make(map[string]float32)
+	for _, match := range vectorMatches {
+		chunkIDs = append(chunkIDs, match.ChunkID)
+		distanceMap[match.ChunkID] = match.Distance
+	}
+	for _, match := range keywordMatches {
+		if _, ok := distanceMap[match.ChunkID]; !ok {
+			chunkIDs = append(chunkIDs, match.ChunkID)
+		}
+	}
+
+	chunks, err := s.loadChunks(ctx, chunkIDs, botID)
+	if err != nil {
+		return nil, err
+	}
+
+	// Rank the chunks still loadable, in the order each search returned them
+	vectorIDs := make([]string, 0, len(vectorMatches))
+	for _, match := range vectorMatches {
+		if _, ok := chunks[match.ChunkID]; ok {
+			vectorIDs = append(vectorIDs, match.ChunkID)
+		}
+	}
+	keywordIDs := make([]string, 0, len(keywordMatches))
+	for _, match := range keywordMatches {
+		if _, ok := chunks[match.ChunkID]; ok {
+			keywordIDs = append(keywordIDs, match.ChunkID)
+		}
+	}
+
+	fused := FuseRankings(vectorIDs, keywordIDs)
+	if len(fused) > limit {
+		fused = fused[:limit]
+	}
+
+	// Keyword-only matches have no distance yet
+	var missing []string
+	for _, match := range fused {
+		if match.VectorRank == 0 {
+			missing = append(missing, match.ChunkID)
+		}
+	}
+	if len(missing) > 0 {
+		distances, err := s.vectorRepo.Distances(ctx, embedding, missing)
+		if err != nil {
+			return nil, err
+		}
+		for chunkID, distance := range distances {
+			distanceMap[chunkID] = distance
+		}
+	}
+
+	// Chunks without an embedding keep a nil distance rather than a misleading 0
+	results := make([]SearchResult, 0, len(fused))
+	for _, match := range fused {
+		var distance *float32
+		if d, ok := distanceMap[match.ChunkID]; ok {
+			distance = &d
+		}
+		result := toSearchResult(chunks[match.ChunkID], distance)
+		result.Score = match.Score
+		result.VectorRank = match.VectorRank
+		result.KeywordRank = match.KeywordRank
+		results = append(results, result)
+	}
+
+	return results, nil
 }
 
 /*
-* SearchSimilarByEmbedding performs semantic search using a pre-computed embedding
+* SearchSimilarByEmbedding performs vector-only semantic search using a pre-computed embedding
  */
 func (s *SearchService) SearchSimilarByEmbedding(ctx context.Context, embedding []float32, botID string, limit int) ([]SearchResult, error) {
 	// Perform vector similarity search
-	matches, err := s.vectorRepo.SearchSimilar(ctx, embedding, limit*2) // Get more to filter by botID
+	matches, err := s.vectorRepo.SearchSimilar(ctx, embedding, botID, limit)
 	if err != nil {
 		return nil, fmt.Errorf("failed to search vectors: %w", err)
 	}
@@ -77,16 +181,97 @@ func (s *SearchService) SearchSimilarByEmbedding(ctx context.Context, embedding
 
 	// Extract chunk IDs
 	chunkIDs := make([]string, len(matches))
-	distanceMap := make(map[string]float32)
 	for i, match := range matches {
 		chunkIDs[i] = match.ChunkID
-		distanceMap[match.ChunkID] = match.Distance
 	}
 
+	chunks, err := s.loadChunks(ctx, chunkIDs, botID)
+	if err != nil {
+		return nil, err
+	}
+
+	// Build results maintaining original order by distance
+	results := make([]SearchResult, 0, len(chunks))
+	for _, match := range matches {
+		chunk, ok := chunks[match.ChunkID]
+		if !ok {
+			continue
+		}
+
+		distance := match.Distance
+		result := toSearchResult(chunk, &distance)
+		result.VectorRank = len(results) + 1
+		result.Score = 1 / float64(rrfK+result.VectorRank)
+		results = append(results, result)
+
+		// Stop if we have enough results
+		if len(results) >= limit {
+			break
+		}
+	}
+
+	return results, nil
+}
+
+/*
+* FusedMatch is a chunk ranked by reciprocal rank fusion
+ */
+type FusedMatch struct {
+	ChunkID     string
+	Score       float64
+	VectorRank  int // 0 = not in the vector results
+	KeywordRank int // 0 = not in the keyword results
+}
+
+/*
+* FuseRankings merges ranked chunk ID lists with reciprocal rank fusion
+* Each list contributes 1/(rrfK + rank); ties keep the vector order first
+ */
+func FuseRankings(vectorIDs []string, keywordIDs []string) []FusedMatch {
+	byID := make(map[string]*FusedMatch, len(vectorIDs)+len(keywordIDs))
+	order := make([]string, 0, len(vectorIDs)+len(keywordIDs))
+
+	match := func(chunkID string) *FusedMatch {
+		if m, ok := byID[chunkID]; ok {
+			return m
+		}
+		m := &FusedMatch{ChunkID: chunkID}
+		byID[chunkID] = m
+		order = append(order, chunkID)
+		return m
+	}
+
+	for i, chunkID := range vectorIDs {
+		m := match(chunkID)
+		m.VectorRank = i + 1
+		m.Score += 1 / float64(rrfK+i+1)
+	}
+	for i, chunkID := range keywordIDs {
+		m := match(chunkID)
+		m.KeywordRank = i + 1
+		m.Score += 1 / float64(rrfK+i+1)
+	}
+
+	fused := make([]FusedMatch, len(order))
+	for i, chunkID := range order {
+		fused[i] = *byID[chunkID]
+	}
+	sort.SliceStable(fused, func(i, j int) bool {
+		return fused[i].Score > fused[j].Score
+	})
+
+	return fused
+}
+
+/*
+* loadChunks fetches chunks with their sources, keeping those of the bot's live sources
+* The searches already scope to the bot; this drops sources deleted since they ran
+ */
+func (s *SearchService) loadChunks(ctx context.Context, chunkIDs []string, botID string) (map[string]models.DocumentChunk, error) {
 	// Fetch chunk metadata with source preloaded
 	var chunks []models.DocumentChunk
 
-	err = s.db.WithContext(ctx).
+	err := s.db.WithContext(ctx).
 		Preload("Source").
 		Where("id IN ?", chunkIDs).
 		Find(&chunks).Error
@@ -96,42 +281,40 @@ func (s *SearchService) SearchSimilarByEmbedding(ctx context.Context, embedding
 	}
 
 	// Filter by bot ID and deleted_at
-	var filteredChunks []models.DocumentChunk
+	filtered := make(map[string]models.DocumentChunk, len(chunks))
 	for _, chunk := range chunks {
 		if chunk.Source.BotID == botID && chunk.Source.DeletedAt.Time.IsZero() {
-			filteredChunks = append(filteredChunks, chunk)
+			filtered[chunk.ID] = chunk
 		}
 	}
 
-	// Build results maintaining original order by distance
-	results := make([]SearchResult, 0, len(filteredChunks))
-	for _, match := range matches {
-		for _, chunk := range filteredChunks {
-			if chunk.ID == match.ChunkID {
-				results = append(results, SearchResult{
-					ChunkID:      chunk.ID,
-					SourceID:     chunk.SourceID,
-					Content:      chunk.Content,
-					Distance:     distanceMap[chunk.ID],
-					ChunkIndex:   chunk.ChunkIndex,
-					URL:          chunk.Source.URL,
-					SourceStatus: chunk.Source.Status,
-					Metadata: map[string]interface{}{
-						"created_at": chunk.CreatedAt,
-						"source_url": chunk.Source.URL,
-					},
-				})
-				break
-			}
-		}
+	return filtered, nil
+}
 
-		// Stop if we have enough results
-		if len(results) >= limit {
-			break
-		}
+/*
+* toSearchResult converts a chunk with its source to a search result
+ */
+func toSearchResult(chunk models.DocumentChunk, distance *float32) SearchResult {
+	var embedding []float32
+	if chunk.Embedding != nil {
+		embedding = chunk.Embedding.Slice()
 	}
 
-	return results, nil
+	return SearchResult{
+		ChunkID:          chunk.ID,
+		SourceID:         chunk.SourceID,
+		Content:          chunk.Content,
+		Distance:         distance,
+		ChunkIndex:       chunk.ChunkIndex,
+		URL:              chunk.Source.URL,
+		OriginalFilename: chunk.Source.OriginalFilename,
+		SourceStatus:     chunk.Source.Status,
+		Metadata: map[string]interface{}{
+			"created_at": chunk.CreatedAt,
+			"source_url": chunk.Source.URL,
+		},
+		Embedding: embedding,
+	}
 }
 
 /*
@@ -145,7 +328,7 @@ func (s *SearchService) SearchMultipleBots(ctx context.Context, query string, bo
 	}
 
 	// Perform vector similarity search
-	matches, err := s.vectorRepo.SearchSimilar(ctx, queryEmbedding, limit*len(botIDs))
+	matches, err := s.vectorRepo.SearchSimilarForBots(ctx, queryEmbedding, botIDs, limit)
 	if err != nil {
 		return nil, fmt.Errorf("failed to search vectors: %w", err)
 	}
@@ -189,14 +372,16 @@ func (s *SearchService) SearchMultipleBots(ctx context.Context, query string, bo
 	for _, match := range matches {
 		for _, chunk := range chunks {
 			if chunk.ID == match.ChunkID {
+				distance := distanceMap[chunk.ID]
 				results = append(results, SearchResult{
-					ChunkID:      chunk.ID,
-					SourceID:     chunk.SourceID,
-					Content:      chunk.Content,
-					Distance:     distanceMap[chunk.ID],
-					ChunkIndex:   chunk.ChunkIndex,
-					URL:          chunk.Source.URL,
-					SourceStatus: chunk.Source.Status,
+					ChunkID:          chunk.ID,
+					SourceID:         chunk.SourceID,
+					Content:          chunk.Content,
+					Distance:         &distance,
+					ChunkIndex:       chunk.ChunkIndex,
+					URL:              chunk.Source.URL,
+					OriginalFilename: chunk.Source.OriginalFilename,
+					SourceStatus:     chunk.Source.Status,
 					Metadata: map[string]interface{}{
 						"created_at": chunk.CreatedAt,
 						"source_url": chunk.Source.URL,
@@ -2,6 +2,7 @@ package vector
 
 import (
 	"context"
+	"fmt"
 	"testing"
 
 	"github.com/souravsspace/texly.chat/internal/models"
@@ -189,3 +190,120 @@ func TestSearchMultipleBots(t *testing.T) {
 		assert.Equal(t, "bot-1", source.BotID)
 	}
 }
+
+/*
+ * TestSearchHybrid tests that keyword matches without embeddings are fused with vector matches
+ */
+func TestSearchHybrid(t *testing.T) {
+	gormDB := shared.SetupTestDB()
+	vRepo := vectorRepo.NewVectorRepository(gormDB)
+	embSvc := embedding.NewEmbeddingService("test-key", "test-model", 1536)
+	ctx := context.Background()
+
+	require.NoError(t, gormDB.Create(&models.Bot{ID: "bot-1", Name: "Test Bot"}).Error)
+	require.NoError(t, gormDB.Create(&models.Source{ID: "source-1", BotID: "bot-1", URL: "https://example.com", Status: models.SourceStatusCompleted}).Error)
+
+	chunks := []models.DocumentChunk{
+		{ID: "chunk-1", SourceID: "source-1", Content: "How to reset your password"},
+		{ID: "chunk-2", SourceID: "source-1", Content: "Shipping takes three days"},
+		{ID: "chunk-3", SourceID: "source-1", Content: "Error E1234 means the printer is offline"}, // Embedding failed
+	}
+	for _, chunk := range chunks {
+		require.NoError(t, gormDB.Create(&chunk).Error)
+	}
+	require.NoError(t, vRepo.BulkInsertEmbeddings(ctx, []vectorRepo.VectorData{
+		{ChunkID: "chunk-1", Embedding: generateTestEmbedding(0.9)},
+		{ChunkID: "chunk-2", Embedding: generateTestEmbedding(0.1)},
+	}))
+
+	service := NewSearchService(gormDB, vRepo, embSvc)
+	results, err := service.SearchHybrid(ctx, "printer error E1234", generateTestEmbedding(0.85), "bot-1", 5)
+	require.NoError(t, err)
+	require.Len(t, results, 3)
+
+	// The keyword match ranks first in one list, the vector matches in the other
+	byID := make(map[string]SearchResult)
+	for _, result := range results {
+		byID[result.ChunkID] = result
+	}
+	assert.Equal(t, 1, byID["chunk-3"].KeywordRank)
+	assert.Equal(t, 0, byID["chunk-3"].VectorRank)
+	assert.Equal(t, 1, byID["chunk-1"].VectorRank)
+	assert.Equal(t, 0, byID["chunk-1"].KeywordRank)
+	assert.Equal(t, "https://example.com", byID["chunk-3"].URL)
+
+	// Only embedded chunks have a distance
+	assert.Nil(t, byID["chunk-3"].Distance)
+	assert.NotNil(t, byID["chunk-1"].Distance)
+
+	results, err = service.SearchHybrid(ctx, "printer error E1234", generateTestEmbedding(0.85), "bot-1", 1)
+	require.NoError(t, err)
+	assert.Len(t, results, 1)
+}
+
+/*
+ * TestFuseRankings tests reciprocal rank fusion of vector and keyword rankings
+ */
+func TestFuseRankings(t *testing.T) {
+	fused := FuseRankings([]string{"a", "b", "c"}, []string{"c", "d"})
+	require.Len(t, fused, 4)
+
+	// c is found by both searches, so it outranks a, which only vector search found first
+	assert.Equal(t, "c", fused[0].ChunkID)
+	assert.Equal(t, 3, fused[0].VectorRank)
+	assert.Equal(t, 1, fused[0].KeywordRank)
+	assert.InDelta(t, 1.0/63+1.0/61, fused[0].Score, 1e-12)
+
+	// b and d tie at second place in their lists; the vector match comes first
+	assert.Equal(t, "a", fused[1].ChunkID)
+	assert.Equal(t, "b", fused[2].ChunkID)
+	assert.Equal(t, "d", fused[3].ChunkID)
+	assert.Equal(t, 0, fused[3].VectorRank)
+
+	assert.Empty(t, FuseRankings(nil, nil))
+}
+
+/*
+ * TestSearchSimilarByEmbedding_MultiTenant tests that a small bot fills its page when other tenants' chunks are closer
+ */
+func TestSearchSimilarByEmbedding_MultiTenant(t *testing.T) {
+	gormDB := shared.SetupTestDB()
+	vRepo := vectorRepo.NewVectorRepository(gormDB)
+	embSvc := embedding.NewEmbeddingService("test-key", "test-model", 1536)
+	ctx := context.Background()
+
+	for _, tenant := range []struct {
+		botID  string
+		chunks int
+		seed   float32
+	}{
+		{botID: "big-bot", chunks: 20, seed: 0.5},
+		{botID: "small-bot", chunks: 4, seed: 0.9},
+	} {
+		require.NoError(t, gormDB.Create(&models.Bot{ID: tenant.botID, Name: tenant.botID}).Error)
+		sourceID := tenant.botID + "-source"
+		require.NoError(t, gormDB.Create(&models.Source{ID: sourceID, BotID: tenant.botID, Status: models.SourceStatusCompleted}).Error)
+		for i := 0; i < tenant.chunks; i++ {
+			chunkID := fmt.Sprintf("%s-chunk-%d", tenant.botID, i)
+			require.NoError(t, gormDB.Create(&models.DocumentChunk{ID: chunkID, SourceID: sourceID, Content: "Opening hours"}).Error)
+			require.NoError(t, vRepo.InsertEmbedding(ctx, chunkID, generateTestEmbedding(tenant.seed)))
+		}
+	}
+
+	service := NewSearchService(gormDB, vRepo, embSvc)
+	query := generateTestEmbedding(0.5)
+
+	results, err := service.SearchSimilarByEmbedding(ctx, query, "small-bot", 3)
+	require.NoError(t, err)
+	require.Len(t, results, 3)
+	for _, result := range results {
+		assert.Equal(t, "small-bot-source", result.SourceID)
+	}
+
+	results, err = service.SearchHybrid(ctx, "opening hours", query, "small-bot", 10)
+	require.NoError(t, err)
+	require.Len(t, results, 4)
+	for _, result := range results {
+		assert.Equal(t, "small-bot-source", result.SourceID)
+	}
+}
@@ -0,0 +1,17 @@
+package vector
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+/*
+ * TestCosineSimilarity tests similarity of parallel, orthogonal, mismatched and zero vectors
+ */
+func TestCosineSimilarity(t *testing.T) {
+	assert.InDelta(t, 1.0, CosineSimilarity([]float32{1, 2}, []float32{2, 4}), 0.0001)
+	assert.InDelta(t, 0.0, CosineSimilarity([]float32{1, 0}, []float32{0, 1}), 0.0001)
+	assert.Zero(t, CosineSimilarity([]float32{1, 0}, []float32{1, 0, 0}))
+	assert.Zero(t, CosineSimilarity([]float32{0, 0}, []float32{1, 0}))
+}
@@ -7,6 +7,7 @@ import (
 
 	"github.com/gin-gonic/gin"
 	"github.com/souravsspace/texly.chat/configs"
+	database "github.com/souravsspace/texly.chat/internal/db"
 	"github.com/souravsspace/texly.chat/internal/models"
 	"gorm.io/driver/postgres"
 	"gorm.io/gorm"
@@ -54,7 +55,7 @@ func SetupTestDB() *gorm.DB {
 
 	// Drop tables in reverse dependency order to avoid foreign key issues
 	// document_chunks depends on sources, messages/sources depend on bots, bots depends on users
-	tables := []string{"document_chunks", "messages", "sources", "bots", "users"}
+	tables := []string{"document_chunks", "message_feedbacks", "session_summaries", "messages", "sources", "bot_actions", "bot_api_keys", "golden_questions", "golden_sets", "prompt_experiments", "prompt_versions", "bots", "users"}
 	for _, table := range tables {
 		if err := db.Exec(fmt.Sprintf("DROP TABLE IF EXISTS %s CASCADE", table)).Error; err != nil {
 			log.Fatalf("Failed to drop table %s: %v", table, err)
@@ -66,13 +67,24 @@ func SetupTestDB() *gorm.DB {
 	if err := db.AutoMigrate(
 		&models.User{},
 		&models.Bot{},
+		&models.BotAction{},
+		&models.BotAPIKey{},
+		&models.PromptVersion{},
+		&models.PromptExperiment{},
+		&models.GoldenSet{},
+		&models.GoldenQuestion{},
 		&models.Source{},
 		&models.Message{},
+		&models.MessageFeedback{},
+		&models.SessionSummary{},
 		&models.DocumentChunk{},
 		&models.UsageRecord{},
 	); err != nil {
 		log.Fatalf("Failed to migrate test database: %v", err)
 	}
+	if err := database.MigrateFullTextSearch(db); err != nil {
+		log.Fatalf("Failed to migrate test database: %v", err)
+	}
 
 	return db
 }
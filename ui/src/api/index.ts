@@ -6,11 +6,20 @@ import type {
   BotAnalytics,
   ChatTokenResponse,
   CreateBotRequest,
+  CreateGoldenSetRequest,
+  CreatePromptExperimentRequest,
   CreateSitemapSourceRequest,
   CreateSourceRequest,
   CreateTextSourceRequest,
-  Message,
+  ExplainSearchRequest,
+  GoldenSet,
   MessageStats,
+  PromptDiff,
+  PromptExperiment,
+  PromptVersion,
+  PromptVersionStats,
+  SearchExplanation,
+  SessionTranscript,
   SitemapResponse,
   Source,
   UpdateBotRequest,
@@ -83,6 +92,20 @@ class ApiClient {
       const payload: CreateBotRequest = {
         name,
         system_prompt: system_prompt || "",
+        provider: "",
+        model: "",
+        temperature: null,
+        max_output_tokens: null,
+        max_context_chunks: null,
+        distance_threshold: null,
+        query_rewrite: null,
+        suggest_follow_ups: null,
+        reranker: null,
+        retrieval_mode: null,
+        mmr_lambda: null,
+        fallback_mode: null,
+        fallback_message: null,
+        handoff_keywords: [],
         allowed_origins: [],
         widget_config: null,
       };
@@ -105,6 +128,65 @@ class ApiClient {
       }),
   };
 
+  prompts = {
+    list: (botId: string) =>
+      this.request<PromptVersion[]>(`/bots/${botId}/prompts`),
+
+    diff: (botId: string, from: string, to?: string) => {
+      const params = new URLSearchParams({ from });
+      if (to) params.set("to", to);
+      return this.request<PromptDiff>(`/bots/${botId}/prompts/diff?${params}`);
+    },
+
+    rollback: (botId: string, versionId: string) =>
+      this.request<Bot>(`/bots/${botId}/prompts/rollback`, {
+        method: "POST",
+        body: JSON.stringify({ version_id: versionId }),
+      }),
+
+    listExperiments: (botId: string) =>
+      this.request<PromptExperiment[]>(`/bots/${botId}/prompts/experiments`),
+
+    createExperiment: (botId: string, data: CreatePromptExperimentRequest) =>
+      this.request<PromptExperiment>(`/bots/${botId}/prompts/experiments`, {
+        method: "POST",
+        body: JSON.stringify(data),
+      }),
+
+    stopExperiment: (botId: string, experimentId: string) =>
+      this.request<PromptExperiment>(
+        `/bots/${botId}/prompts/experiments/${experimentId}/stop`,
+        { method: "POST" }
+      ),
+  };
+
+  search = {
+    explain: (botId: string, data: ExplainSearchRequest) =>
+      this.request<SearchExplanation>(`/bots/${botId}/search/explain`, {
+        method: "POST",
+        body: JSON.stringify(data),
+      }),
+  };
+
+  goldenSets = {
+    list: (botId: string) =>
+      this.request<GoldenSet[]>(`/bots/${botId}/golden-sets`),
+
+    get: (botId: string, setId: string) =>
+      this.request<GoldenSet>(`/bots/${botId}/golden-sets/${setId}`),
+
+    create: (botId: string, data: CreateGoldenSetRequest) =>
+      this.request<GoldenSet>(`/bots/${botId}/golden-sets`, {
+        method: "POST",
+        body: JSON.stringify(data),
+      }),
+
+    delete: (botId: string, setId: string) =>
+      this.request<void>(`/bots/${botId}/golden-sets/${setId}`, {
+        method: "DELETE",
+      }),
+  };
+
   sources = {
     list: (botId: string) => this.request<Source[]>(`/bots/${botId}/sources`),
 
@@ -267,10 +349,13 @@ class ApiClient {
         `/analytics/bots/${botId}/daily?days=${days}`
       ),
 
+    getPromptVersionStats: (botId: string) =>
+      this.request<PromptVersionStats[]>(`/analytics/bots/${botId}/prompts`),
+
     getUserAnalytics: () => this.request<BotAnalytics[]>("/analytics/user"),
 
     getSessionMessages: (sessionId: string) =>
-      this.request<Message[]>(`/analytics/sessions/${sessionId}/messages`),
+      this.request<SessionTranscript>(`/analytics/sessions/${sessionId}/messages`),
   };
 
   billing = {
@@ -2,6 +2,80 @@
  * This file is auto-generated. Do not edit directly.
  */
 
+/*
+ * BotAction is an owner-defined HTTP endpoint the bot can call as a tool
+ */
+export interface BotAction {
+  id: string;
+  bot_id: string;
+  name: string;
+  description: string;
+  method: string;
+  url: string;
+  parameters: string;
+  timeout_seconds: number;
+  created_at: string | Date;
+  updated_at: string | Date;
+  deleted_at: string | Date | null;
+}
+
+/*
+ * CreateBotActionRequest holds data for creating a new bot action
+ */
+export interface CreateBotActionRequest {
+  name: string;
+  description: string;
+  method: string;
+  url: string;
+  parameters: any;
+  headers: any;
+  timeout_seconds: number;
+}
+
+/*
+ * UpdateBotActionRequest holds data for updating an existing bot action
+ */
+export interface UpdateBotActionRequest {
+  name: string;
+  description: string;
+  method: string;
+  url: string;
+  parameters: any;
+  headers: any;
+  timeout_seconds: number;
+}
+
+/*
+ * BotAPIKey is a secret that lets backend services call a single bot through the
+* OpenAI-compatible API. Only a SHA-256 hash of the key is stored.
+ */
+export interface BotAPIKey {
+  id: string;
+  bot_id: string;
+  user_id: string;
+  name: string;
+  prefix: string;
+  last_used_at: string | Date | null;
+  created_at: string | Date;
+  updated_at: string | Date;
+  deleted_at: string | Date | null;
+}
+
+/*
+ * CreateBotAPIKeyRequest holds data for creating a bot API key
+ */
+export interface CreateBotAPIKeyRequest {
+  name: string;
+}
+
+/*
+ * CreateBotAPIKeyResponse returns the plaintext key; it is only shown once
+ */
+export interface CreateBotAPIKeyResponse {
+  api_key: BotAPIKey;
+  key: string;
+}
+
 /*
  * Subscription represents a localized view of a subscription
  */
@@ -29,6 +103,7 @@ export interface WidgetConfig {
   initial_message: string;
   position: string;
   bot_avatar: string;
+  starter_questions: string[];
 }
 
 /*
@@ -39,6 +114,21 @@ export interface Bot {
   user_id: string;
   name: string;
   system_prompt: string;
+  prompt_version_id: string;
+  provider: string;
+  model: string;
+  temperature: number | null;
+  max_output_tokens: number;
+  max_context_chunks: number;
+  distance_threshold: number;
+  query_rewrite: boolean;
+  suggest_follow_ups: boolean;
+  reranker: string;
+  retrieval_mode: string;
+  mmr_lambda: number | null;
+  fallback_mode: string;
+  fallback_message: string;
+  handoff_keywords: string;
   allowed_origins: string;
   widget_config: string;
   created_at: string | Date;
@@ -52,6 +142,20 @@ export interface Bot {
 export interface CreateBotRequest {
   name: string;
   system_prompt: string;
+  provider: string;
+  model: string;
+  temperature: number | null;
+  max_output_tokens: number | null;
+  max_context_chunks: number | null;
+  distance_threshold: number | null;
+  query_rewrite: boolean | null;
+  suggest_follow_ups: boolean | null;
+  reranker: string | null;
+  retrieval_mode: string | null;
+  mmr_lambda: number | null;
+  fallback_mode: string | null;
+  fallback_message: string | null;
+  handoff_keywords: string[];
   allowed_origins: string[];
   widget_config: WidgetConfig | null;
 }
@@ -61,7 +165,22 @@ export interface CreateBotRequest {
  */
 export interface UpdateBotRequest {
   name: string;
-  system_prompt: string;
+  system_prompt: string | null;
+  prompt_note: string;
+  provider: string;
+  model: string;
+  temperature: number | null;
+  max_output_tokens: number | null;
+  max_context_chunks: number | null;
+  distance_threshold: number | null;
+  query_rewrite: boolean | null;
+  suggest_follow_ups: boolean | null;
+  reranker: string | null;
+  retrieval_mode: string | null;
+  mmr_lambda: number | null;
+  fallback_mode: string | null;
+  fallback_message: string | null;
+  handoff_keywords: string[] | null;
   allowed_origins: string[];
   widget_config: WidgetConfig | null;
 }
@@ -78,10 +197,57 @@ export interface ChatRequest {
  */
 export interface ChatTokenResponse {
   type: string;
+  message_id: string;
   content: string;
+  sources: Citation[];
+  tool_call: ToolCall | null;
+  usage: TokenUsage | null;
+  fallback: string;
+  handoff: string;
+  suggestions: string[];
+  typing: boolean | null;
+  presence: string;
   error: string;
 }
 
+/*
+ * TokenUsage reports the prompt and completion tokens the provider billed for an answer
+ */
+export interface TokenUsage {
+  prompt_tokens: number;
+  completion_tokens: number;
+  total_tokens: number;
+}
+
+/*
+ * ChatSocketRequest is a frame sent by the widget over the chat WebSocket
+ */
+export interface ChatSocketRequest {
+  type: string;
+  message: string;
+  typing: boolean;
+}
+
+/*
+ * ToolCall reports the progress of a bot action invoked by the model
+ */
+export interface ToolCall {
+  id: string;
+  name: string;
+  status: string;
+}
+
+/*
+ * Citation references a knowledge base chunk used to answer a message
+ */
+export interface Citation {
+  chunk_id: string;
+  source_id: string;
+  url: string;
+  filename: string;
+  distance: number | null;
+}
+
 /*
  * DocumentChunk represents a chunk of text with its vector embedding
  */
@@ -94,6 +260,149 @@ export interface DocumentChunk {
   source: Source;
 }
 
+/*
+ * GoldenSet is a list of questions with known good sources and answers used to evaluate a bot
+ */
+export interface GoldenSet {
+  id: string;
+  bot_id: string;
+  name: string;
+  description: string;
+  questions: GoldenQuestion[];
+  created_at: string | Date;
+  updated_at: string | Date;
+}
+
+/*
+ * GoldenQuestion is a question of a golden set with the sources and/or answer expected for it
+ */
+export interface GoldenQuestion {
+  id: string;
+  golden_set_id: string;
+  position: number;
+  question: string;
+  expected_source_ids: string;
+  reference_answer: string;
+  created_at: string | Date;
+}
+
+/*
+ * GoldenQuestionRequest holds a question of a golden set
+ */
+export interface GoldenQuestionRequest {
+  question: string;
+  expected_source_ids: string[];
+  reference_answer: string;
+}
+
+/*
+ * CreateGoldenSetRequest holds data for creating a golden set
+ */
+export interface CreateGoldenSetRequest {
+  name: string;
+  description: string;
+  questions: GoldenQuestionRequest[];
+}
+
+/*
+ * EvalReport summarizes an evaluation of a bot against a golden set
+ */
+export interface EvalReport {
+  bot_id: string;
+  golden_set_id: string;
+  golden_set_name: string;
+  k: number;
+  questions: number;
+  retrieval_scored: number;
+  recall_at_k: number;
+  mrr: number;
+  answers_graded: number;
+  answer_correctness: number;
+  errors: number;
+  started_at: string | Date;
+  duration_ms: number;
+  results: EvalResult[];
+}
+
+/*
+ * EvalResult is the evaluation of a single golden question
+ */
+export interface EvalResult {
+  question: string;
+  expected_source_ids: string[];
+  retrieved_source_ids: string[];
+  recall: number | null;
+  reciprocal_rank: number | null;
+  answer: string;
+  correctness: number | null;
+  grader_verdict: string;
+  error: string;
+}
+
+/*
+ * MessageFeedback is a rating of an assistant message
+* Each message holds at most one rating per source; rating again replaces it
+ */
+export interface MessageFeedback {
+  id: string;
+  message_id: string;
+  source: string;
+  session_id: string;
+  bot_id: string;
+  user_id: string | null;
+  rating: string;
+  comment: string;
+  created_at: string | Date;
+  updated_at: string | Date;
+}
+
+/*
+ * MessageFeedbackRequest holds a rating for an assistant message
+ */
+export interface MessageFeedbackRequest {
+  rating: string;
+  comment: string;
+}
+
+/*
+ * RatedAnswer is a negatively rated assistant message with the question it answered
+ */
+export interface RatedAnswer {
+  message_id: string;
+  session_id: string;
+  question: string;
+  answer: string;
+  comment: string;
+  rated_at: string | Date;
+}
+
+/*
+ * HandoffEvent notifies operators and visitors about a human handoff
+ */
+export interface HandoffEvent {
+  type: string;
+  session_id: string;
+  bot_id: string;
+  reason: string;
+  message: Message | null;
+  presence: string;
+  typing: boolean | null;
+}
+
+/*
+ * OperatorReplyRequest holds an operator's reply to a visitor
+ */
+export interface OperatorReplyRequest {
+  content: string;
+}
+
+/*
+ * TypingRequest tells the visitor whether an operator is typing
+ */
+export interface TypingRequest {
+  typing: boolean;
+}
+
 /*
  * Message represents a chat message exchanged between user and bot
  */
@@ -105,10 +414,34 @@ export interface Message {
   role: string;
   content: string;
   token_count: number;
+  citations: string;
+  rewritten_query: string;
+  token_breakdown: string;
+  prompt_tokens: number;
+  completion_tokens: number;
+  prompt_version_id: string;
+  unanswered: boolean;
   created_at: string | Date;
   deleted_at: string | Date | null;
 }
 
+/*
+ * TokenBreakdown reports how the model's context window was spent on an answer
+ */
+export interface TokenBreakdown {
+  context_window: number;
+  system: number;
+  knowledge: number;
+  summary: number;
+  history: number;
+  question: number;
+  reserved_answer: number;
+  answer: number;
+  chunks_used: number;
+  chunks_truncated: number;
+  chunks_dropped: number;
+}
+
 /*
  * MessageStats represents aggregated statistics for a bot
  */
@@ -134,6 +467,170 @@ export interface BotAnalytics {
   avg_tokens_per_day: number;
   avg_messages_per_session: number;
   last_message_at: string | Date | null;
+  unanswered_count: number;
+  unanswered_rate: number;
+  positive_feedback: number;
+  negative_feedback: number;
+  satisfaction_rate: number;
+  negative_answers: RatedAnswer[];
+}
+
+/*
+ * AnswerCacheStats reports how often repeated questions were answered from the semantic cache
+ */
+export interface AnswerCacheStats {
+  bot_id: string;
+  enabled: boolean;
+  hits: number;
+  misses: number;
+  hit_rate: number;
+  entries: number;
+}
+
+/*
+ * PromptVersion is a saved revision of a bot's system prompt
+* Versions are never edited; rolling back records a new version with the old prompt
+ */
+export interface PromptVersion {
+  id: string;
+  bot_id: string;
+  version: number;
+  system_prompt: string;
+  note: string;
+  user_id: string | null;
+  rolled_back_to: number | null;
+  created_at: string | Date;
+}
+
+/*
+ * PromptExperiment splits widget sessions across prompt versions
+* Sessions outside every variant's share get the bot's current prompt
+ */
+export interface PromptExperiment {
+  id: string;
+  bot_id: string;
+  name: string;
+  status: string;
+  variants: string;
+  created_at: string | Date;
+  stopped_at: string | Date | null;
+}
+
+/*
+ * PromptVariant assigns a share of an experiment's sessions to a prompt version
+ */
+export interface PromptVariant {
+  prompt_version_id: string;
+  percent: number;
+}
+
+/*
+ * RollbackPromptRequest selects the version to restore
+ */
+export interface RollbackPromptRequest {
+  version_id: string;
+}
+
+/*
+ * CreatePromptExperimentRequest holds data for starting a prompt experiment
+ */
+export interface CreatePromptExperimentRequest {
+  name: string;
+  variants: PromptVariant[];
+}
+
+/*
+ * PromptDiffLine is one line of a diff between two prompt versions
+ */
+export interface PromptDiffLine {
+  op: string;
+  text: string;
+}
+
+/*
+ * PromptDiff compares two prompt versions line by line
+ */
+export interface PromptDiff {
+  from: PromptVersion;
+  to: PromptVersion;
+  lines: PromptDiffLine[];
+}
+
+/*
+ * PromptVersionStats compares the answers given with one prompt version
+ */
+export interface PromptVersionStats {
+  prompt_version_id: string;
+  version: number;
+  user_messages: number;
+  answers: number;
+  unanswered_count: number;
+  fallback_rate: number;
+  positive_feedback: number;
+  negative_feedback: number;
+  satisfaction_rate: number;
+}
+
+/*
+ * ExplainSearchRequest holds a test question and optional retrieval overrides
+ */
+export interface ExplainSearchRequest {
+  query: string;
+  rewritten_query: string;
+  limit: number | null;
+  distance_threshold: number | null;
+  reranker: string | null;
+  retrieval_mode: string | null;
+  mmr_lambda: number | null;
+}
+
+/*
+ * ExplainedChunk is a retrieved chunk and what the chat pipeline would do with it
+ */
+export interface ExplainedChunk {
+  rank: number;
+  chunk_id: string;
+  source_id: string;
+  chunk_index: number;
+  content: string;
+  token_count: number;
+  distance: number | null;
+  score: number;
+  vector_rank: number;
+  keyword_rank: number;
+  rerank_rank: number;
+  rerank_score: number;
+  url: string;
+  filename: string;
+  source_status: SourceStatus;
+  status: string;
+}
+
+/*
+ * PromptMessage is a message of the prompt sent to the model
+ */
+export interface PromptMessage {
+  role: string;
+  content: string;
+}
+
+/*
+ * SearchExplanation shows what a bot would retrieve for a question and the prompt it would send
+ */
+export interface SearchExplanation {
+  query: string;
+  search_query: string;
+  limit: number;
+  distance_threshold: number;
+  model: string;
+  reranker: string;
+  rerank_error: string;
+  retrieval_mode: string;
+  mmr_lambda: number;
+  chunks: ExplainedChunk[];
+  prompt: PromptMessage[];
+  fallback: string;
+  breakdown: TokenBreakdown;
 }
 
 /*
@@ -145,6 +642,9 @@ export interface ChatSession {
   created_at: string | Date;
   last_activity_at: string | Date;
   expires_at: string | Date;
+  handoff_status: string;
+  handoff_reason: string;
+  handoff_at: string | Date | null;
 }
 
 /*
@@ -163,6 +663,31 @@ export interface SessionResponse {
   expires_at: string | Date;
 }
 
+/*
+ * SessionSummary is a rolling LLM summary of the older turns of a chat session
+ * Messages up to and including LastMessageID are condensed into Content and no longer sent verbatim
+ */
+export interface SessionSummary {
+  id: string;
+  session_id: string;
+  bot_id: string;
+  content: string;
+  last_message_id: string;
+  message_count: number;
+  token_count: number;
+  created_at: string | Date;
+  updated_at: string | Date;
+}
+
+/*
+ * SessionTranscript is a session's messages with the summary of its older turns, if any
+ */
+export interface SessionTranscript {
+  session_id: string;
+  summary: SessionSummary | null;
+  messages: Message[];
+}
+
 /*
  * SourceType represents the type of data source
  */
@@ -234,6 +759,7 @@ export interface UsageRecord {
   bot_id: string;
   type: string;
   quantity: number;
+  model: string;
   cost: number;
   billed_at: string | Date;
   created_at: string | Date;
@@ -1,5 +1,11 @@
 import { useForm } from "@tanstack/react-form";
-import { Globe, MapPin, MessageSquare, Palette } from "lucide-react";
+import {
+  Globe,
+  Lightbulb,
+  MapPin,
+  MessageSquare,
+  Palette,
+} from "lucide-react";
 import type { WidgetConfig } from "@/api/index.types";
 import { Button } from "@/components/ui/button";
 import { Card, CardContent, CardHeader, CardTitle } from "@/components/ui/card";
@@ -36,6 +42,7 @@ export function WidgetConfigForm({
       initialMessage: initialConfig.initial_message,
       position: initialConfig.position,
       botAvatar: initialConfig.bot_avatar,
+      starterQuestions: (initialConfig.starter_questions ?? []).join("\n"),
       allowedOrigins: initialOrigins.join("\n"),
     },
     onSubmit: ({ value }) => {
@@ -44,12 +51,10 @@ export function WidgetConfigForm({
         initial_message: value.initialMessage,
         position: value.position,
         bot_avatar: value.botAvatar,
+        starter_questions: splitLines(value.starterQuestions),
       };
 
-      const originsArray = value.allowedOrigins
-        .split("\n")
-        .map((origin) => origin.trim())
-        .filter((origin) => origin.length > 0);
+      const originsArray = splitLines(value.allowedOrigins);
 
       onSubmit(widgetConfig, originsArray);
     },
@@ -68,6 +73,7 @@ export function WidgetConfigForm({
         initial_message: values.initialMessage,
         position: values.position,
         bot_avatar: values.botAvatar,
+        starter_questions: splitLines(form.state.values.starterQuestions),
       };
       onConfigChange(widgetConfig);
     }
@@ -227,6 +233,28 @@ export function WidgetConfigForm({
             </form.Field>
 
             {/* Allowed Origins */}
+            <form.Field name="starterQuestions">
+              {(field) => (
+                <Field>
+                  <Label htmlFor={field.name}>
+                    <Lightbulb className="mr-2 inline h-4 w-4" />
+                    Starter Questions
+                  </Label>
+                  <Textarea
+                    id={field.name}
+                    onChange={(e) => field.handleChange(e.target.value)}
+                    placeholder="What are your opening hours?&#10;Do you ship abroad?"
+                    rows={3}
+                    value={field.state.value}
+                  />
+                  <p className="mt-1 text-muted-foreground text-sm">
+                    One question per line (up to 5). Shown to visitors before
+                    they type.
+                  </p>
+                </Field>
+              )}
+            </form.Field>
+
             <form.Field name="allowedOrigins">
               {(field) => (
                 <Field>
@@ -265,3 +293,10 @@ export function WidgetConfigForm({
     </Card>
   );
 }
+
+function splitLines(value: string): string[] {
+  return value
+    .split("\n")
+    .map((line) => line.trim())
+    .filter((line) => line.length > 0);
+}
@@ -22,6 +22,7 @@ function WidgetPage() {
     initial_message: "Hi! How can I help you today?",
     position: "bottom-right",
     bot_avatar: "",
+    starter_questions: [],
   });
 
   const { data: bot } = useQuery({
@@ -64,6 +65,21 @@ function WidgetPage() {
     const updateData: UpdateBotRequest = {
       name: bot.name,
       system_prompt: bot.system_prompt,
+      prompt_note: "",
+      provider: bot.provider,
+      model: bot.model,
+      temperature: bot.temperature,
+      max_output_tokens: null,
+      max_context_chunks: null,
+      distance_threshold: null,
+      query_rewrite: null,
+      suggest_follow_ups: null,
+      reranker: null,
+      retrieval_mode: null,
+      mmr_lambda: null,
+      fallback_mode: null,
+      fallback_message: null,
+      handoff_keywords: null,
       widget_config: widgetConfig,
       allowed_origins: allowedOrigins,
     };
@@ -81,6 +97,7 @@ function WidgetPage() {
     initial_message: "Hi! How can I help you today?",
     position: "bottom-right",
     bot_avatar: "",
+    starter_questions: [],
   };
 
   let allowedOrigins: string[] = [];
@@ -34,6 +34,14 @@ const getApiBase = () => {
 
 const API_BASE = getApiBase();
 
+// How often a dropped answer stream is resumed before giving up
+const MAX_RESUME_ATTEMPTS = 3;
+
+type StreamOutcome =
+  | { type: "done" }
+  | { type: "error"; error: string }
+  | { type: "dropped"; error?: string };
+
 export class WidgetAPI {
   private botId: string;
 
@@ -117,61 +125,107 @@ export class WidgetAPI {
       throw new Error(`Failed to send message: ${response.statusText}`);
     }
 
+    let lastEventId = "";
+    let current = response;
+
+    for (let attempt = 0; ; attempt++) {
+      let outcome: StreamOutcome;
+      try {
+        outcome = await this.readEvents(current, onToken, (id) => {
+          lastEventId = id;
+        });
+      } catch (error) {
+        outcome = {
+          type: "dropped",
+          error: error instanceof Error ? error.message : "Unknown error",
+        };
+      }
+
+      if (outcome.type === "done") {
+        onComplete();
+        return;
+      }
+      if (outcome.type === "error") {
+        onError(outcome.error);
+        return;
+      }
+
+      // The connection dropped mid-answer: resume from the last event received
+      if (!lastEventId || attempt >= MAX_RESUME_ATTEMPTS) {
+        if (outcome.error) {
+          onError(outcome.error);
+        } else {
+          console.log("[Widget API] Stream ended without 'done' message, completing anyway");
+          onComplete();
+        }
+        return;
+      }
+
+      console.log("[Widget API] Resuming stream after event:", lastEventId);
+      try {
+        current = await fetch(`${API_BASE}/api/public/chats/${sessionId}/stream`, {
+          headers: { "Last-Event-ID": lastEventId },
+        });
+      } catch (error) {
+        onError(error instanceof Error ? error.message : "Unknown error");
+        return;
+      }
+      if (!current.ok) {
+        onComplete();
+        return;
+      }
+    }
+  }
+
+  /**
+   * Read SSE events until the stream ends, reporting tokens and event IDs
+   */
+  private async readEvents(
+    response: Response,
+    onToken: (token: string) => void,
+    onEventId: (id: string) => void,
+  ): Promise<StreamOutcome> {
     const reader = response.body?.getReader();
     if (!reader) {
       throw new Error("No response body");
     }
 
-    console.log("[Widget API] Starting to read SSE stream...");
-
     const decoder = new TextDecoder();
     let buffer = "";
-    let completedSuccessfully = false;
 
-    try {
-      while (true) {
-        const { done, value } = await reader.read();
-
-        if (done) break;
-
-        buffer += decoder.decode(value, { stream: true });
-        const lines = buffer.split("\n");
-        buffer = lines.pop() || "";
-
-        for (const line of lines) {
-          if (line.startsWith("data: ")) {
-            const data = line.substring(6);
-            console.log("[Widget API] Received SSE data:", data);
-            try {
-              const parsed: ChatTokenResponse = JSON.parse(data);
-
-              if (parsed.type === "token" && parsed.content) {
-                console.log("[Widget API] Token received:", parsed.content);
-                onToken(parsed.content);
-              } else if (parsed.type === "done") {
-                console.log("[Widget API] Stream completed successfully");
-                completedSuccessfully = true;
-                onComplete();
-                return;
-              } else if (parsed.type === "error" && parsed.error) {
-                console.error("[Widget API] Stream error:", parsed.error);
-                onError(parsed.error);
-                return;
-              }
-            } catch (e) {
-              console.error("Failed to parse SSE message:", e);
+    while (true) {
+      const { done, value } = await reader.read();
+
+      if (done) {
+        return { type: "dropped" };
+      }
+
+      buffer += decoder.decode(value, { stream: true });
+      const lines = buffer.split("\n");
+      buffer = lines.pop() || "";
+
+      for (const line of lines) {
+        if (line.startsWith("id: ")) {
+          onEventId(line.substring(4));
+        } else if (line.startsWith("data: ")) {
+          const data = line.substring(6);
+          try {
+            const parsed: ChatTokenResponse = JSON.parse(data);
+
+            if (parsed.type === "token" && parsed.content) {
+              onToken(parsed.content);
+            } else if (parsed.type === "done") {
+              console.log("[Widget API] Stream completed successfully");
+              return { type: "done" };
+            } else if (parsed.type === "error" && parsed.error) {
+              console.error("[Widget API] Stream error:", parsed.error);
+              return { type: "error", error: parsed.error };
             }
+          } catch (e) {
+            console.error("Failed to parse SSE message:", e);
           }
         }
       }
-
-      // If we exit the loop without receiving a "done" message, still call onComplete
-      if (!completedSuccessfully) {
-        console.log("[Widget API] Stream ended without 'done' message, completing anyway");
-        onComplete();
-      }
-    } catch (error) {
-      onError(error instanceof Error ? error.message : "Unknown error");
     }
   }
 }
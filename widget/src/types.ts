@@ -3,6 +3,7 @@ export interface WidgetConfig {
   initial_message: string;
   position: "bottom-right" | "bottom-left" | "top-right" | "top-left";
   bot_avatar?: string;
+  starter_questions?: string[];
 }
 
 export interface BotConfig {
@@ -23,12 +24,88 @@ export interface Session {
   expiresAt: string;
 }
 
+export interface Citation {
+  chunk_id: string;
+  source_id: string;
+  url?: string;
+  filename?: string;
+  distance: number;
+}
+
+export interface ToolCall {
+  id: string;
+  name: string;
+  status: "running" | "completed" | "failed";
+}
+
+export interface TokenUsage {
+  prompt_tokens: number;
+  completion_tokens: number;
+  total_tokens: number;
+}
+
 export interface ChatTokenResponse {
-  type: "token" | "done" | "error";
+  type:
+    | "start"
+    | "token"
+    | "sources"
+    | "tool_call"
+    | "usage"
+    | "fallback"
+    | "handoff"
+    | "suggestions"
+    | "message"
+    | "typing"
+    | "presence"
+    | "done"
+    | "error";
+  message_id?: string;
   content?: string;
+  sources?: Citation[];
+  tool_call?: ToolCall;
+  usage?: TokenUsage;
+  fallback?: "message" | "human";
+  handoff?: "pending" | "active" | "closed";
+  suggestions?: string[];
+  typing?: boolean;
+  presence?: "online" | "offline";
   error?: string;
 }
 
+// Frames the widget sends over the chat WebSocket
+export type ChatSocketRequest =
+  | { type: "message"; message: string }
+  | { type: "typing"; typing: boolean };
+
+export interface HandoffMessage {
+  id: string;
+  role: "user" | "operator";
+  content: string;
+  created_at: string;
+}
+
+export interface HandoffEvent {
+  type:
+    | "handoff_requested"
+    | "visitor_message"
+    | "operator_message"
+    | "handoff_closed"
+    | "visitor_presence"
+    | "visitor_typing"
+    | "operator_typing";
+  session_id: string;
+  bot_id: string;
+  reason?: string;
+  message?: HandoffMessage;
+  presence?: "online" | "offline";
+  typing?: boolean;
+}
+
+export interface MessageFeedbackRequest {
+  rating: "up" | "down";
+  comment?: string;
+}
+
 export interface CreateSessionRequest {
   bot_id: string;
 }